@@ -0,0 +1,75 @@
+package main
+
+import (
+	"time"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/bdav"
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+const bdavSchema = "bdav/1"
+
+type discJSON struct {
+	Version        string           `json:"version"`
+	Name           string           `json:"name"`
+	CharacterSet   jsonout.Enum     `json:"character_set"`
+	ResumePlayList string           `json:"resume_playlist,omitempty"`
+	Recordings     []*recordingJSON `json:"recordings"`
+}
+
+type recordingJSON struct {
+	Playlist      string       `json:"playlist"`
+	Virtual       bool         `json:"virtual"`
+	Title         string       `json:"title"`
+	Detail        string       `json:"detail"`
+	Channel       string       `json:"channel"`
+	ChannelNumber uint16       `json:"channel_number"`
+	CharacterSet  jsonout.Enum `json:"character_set"`
+	RecordTime    string       `json:"record_time,omitempty"` // RFC 3339, recorder wall clock
+	Duration      uint32       `json:"duration"`              // 45 kHz ticks
+	Played        bool         `json:"played"`
+	Protected     bool         `json:"protected"`
+	Maker         jsonout.Enum `json:"maker"`
+	PlayItems     int          `json:"play_items"`
+	Marks         int          `json:"marks"`
+}
+
+func DiscJSON(disc *bdav.Disc) *discJSON {
+	out := &discJSON{Recordings: []*recordingJSON{}}
+	if info := disc.Info; info != nil {
+		out.Version = string(info.Header.VersionNumber[:])
+		out.Name = info.UIAppInfo.Name
+		out.CharacterSet = jsonout.NewEnum(info.UIAppInfo.CharacterSet, bdav.CharacterSetName(info.UIAppInfo.CharacterSet))
+		out.ResumePlayList = info.UIAppInfo.ResumePlayList
+	}
+	for _, recording := range disc.Recordings() {
+		out.Recordings = append(out.Recordings, RecordingJSON(recording))
+	}
+	return out
+}
+
+func RecordingJSON(recording *bdav.Recording) *recordingJSON {
+	playlist := recording.Playlist
+	ui := playlist.UIAppInfo
+	out := &recordingJSON{
+		Playlist:      playlist.Name,
+		Virtual:       playlist.Virtual,
+		Title:         recording.Title,
+		Detail:        ui.Detail,
+		Channel:       recording.Channel,
+		ChannelNumber: ui.ChannelNumber,
+		CharacterSet:  jsonout.NewEnum(ui.CharacterSet, bdav.CharacterSetName(ui.CharacterSet)),
+		Duration:      uint32(clock.FromDuration45k(recording.Duration)),
+		Played:        ui.IsPlayedFlag,
+		Protected:     ui.ProtectFlag,
+		Maker:         jsonout.NewEnum(ui.MakerID, avchd.MakerName(ui.MakerID)),
+		PlayItems:     len(playlist.PlayList.PlayItems),
+		Marks:         len(playlist.Marks.Marks),
+	}
+	if !recording.RecordTime.IsZero() {
+		out.RecordTime = recording.RecordTime.Format(time.RFC3339)
+	}
+	return out
+}
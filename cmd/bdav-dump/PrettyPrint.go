@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/pkg/bdav"
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+func DiscPrint(disc *bdav.Disc) {
+	if info := disc.Info; info != nil {
+		PadPrintf(0, "Version: %s\n", string(info.Header.VersionNumber[:]))
+		PadPrintf(0, "Name: %s\n", info.UIAppInfo.Name)
+		if info.UIAppInfo.ResumePlayList != "" {
+			PadPrintf(0, "Resume: %s\n", info.UIAppInfo.ResumePlayList)
+		}
+	}
+
+	recordings := disc.Recordings()
+	PadPrintf(0, "Recordings: %d\n", len(recordings))
+	for _, recording := range recordings {
+		RecordingPrint(recording)
+	}
+}
+
+func RecordingPrint(recording *bdav.Recording) {
+	kind := "real"
+	if recording.Playlist.Virtual {
+		kind = "virtual"
+	}
+	recorded := "-"
+	if !recording.RecordTime.IsZero() {
+		recorded = recording.RecordTime.Format("2006-01-02 15:04")
+	}
+	PadPrintf(2, "%s (%s): %s  %s  %s  %s\n", recording.Playlist.Name, kind, recorded,
+		clock.FromDuration45k(recording.Duration), recording.Channel, recording.Title)
+	if detail := recording.Playlist.UIAppInfo.Detail; detail != "" {
+		PadPrintf(4, "%s\n", detail)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/bdav"
+)
+
+func PadPrintf(indent int, format string, args ...any) {
+	fmt.Printf(strings.Repeat(" ", indent)+format, args...)
+}
+
+func PadPrintln(indent int, args ...any) {
+	fmt.Print(strings.Repeat(" ", indent))
+	fmt.Println(args...)
+}
+
+func main() {
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: bdav-dump [--format=text|json] <disc-root-or-BDAV-dir>")
+		os.Exit(1)
+	}
+
+	root := flag.Arg(0)
+	disc, err := bdav.Open(root)
+	if err != nil {
+		fmt.Printf("Error opening BDAV tree: %+v\n", err)
+		os.Exit(1)
+	}
+	for _, problem := range disc.Problems {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", problem)
+	}
+
+	if *format == jsonout.FormatJSON {
+		if err := jsonout.Write(os.Stdout, bdavSchema, root, DiscJSON(disc)); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	PadPrintf(0, "BDAV: %s\n", disc.Root)
+	PadPrintln(0, "")
+	DiscPrint(disc)
+}
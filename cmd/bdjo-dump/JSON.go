@@ -0,0 +1,150 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	bdjo "github.com/parasense/bdmv_go/pkg/bdjo"
+)
+
+const bdjoSchema = "bdjo/1"
+
+type bdjoJSON struct {
+	Header                     *headerJSON                     `json:"header"`
+	TerminalInfo               *terminalInfoJSON               `json:"terminal_info"`
+	AppCacheInfo               []*appCacheItemJSON             `json:"app_cache_info"`
+	TableOfAccessiblePlaylists *tableOfAccessiblePlaylistsJSON `json:"table_of_accessible_playlists"`
+	ApplicationManagementTable []*applicationJSON              `json:"application_management_table"`
+	KeyInterestTable           []string                        `json:"key_interest_table"`
+	FileAccessInfo             string                          `json:"file_access_info"`
+}
+
+type headerJSON struct {
+	Type       string          `json:"type"`
+	Version    string          `json:"version"`
+	Extensions jsonout.Offsets `json:"extensions"`
+}
+
+type terminalInfoJSON struct {
+	DefaultFont                string `json:"default_font"`
+	InitialHAViConfigurationID uint8  `json:"initial_havi_configuration_id"`
+	MenuCallMask               bool   `json:"menu_call_mask"`
+	TitleSearchMask            bool   `json:"title_search_mask"`
+}
+
+type appCacheItemJSON struct {
+	Type     jsonout.Enum     `json:"type"`
+	Name     string           `json:"name"`
+	Language jsonout.Language `json:"language"`
+}
+
+type tableOfAccessiblePlaylistsJSON struct {
+	AccessToAllFlag            bool     `json:"access_to_all_flag"`
+	AutostartFirstPlaylistFlag bool     `json:"autostart_first_playlist_flag"`
+	Playlists                  []string `json:"playlists"`
+}
+
+type applicationJSON struct {
+	ControlCode        jsonout.Enum              `json:"control_code"`
+	Type               uint8                     `json:"type"`
+	OrganizationID     uint32                    `json:"organization_id"`
+	ApplicationID      uint16                    `json:"application_id"`
+	Profiles           []*applicationProfileJSON `json:"profiles"`
+	Priority           uint8                     `json:"priority"`
+	Binding            uint8                     `json:"binding"`
+	Visibility         uint8                     `json:"visibility"`
+	Names              []*applicationNameJSON    `json:"names"`
+	IconLocator        string                    `json:"icon_locator"`
+	IconFlags          uint16                    `json:"icon_flags"`
+	BaseDirectory      string                    `json:"base_directory"`
+	ClasspathExtension string                    `json:"classpath_extension"`
+	InitialClass       string                    `json:"initial_class"`
+	Parameters         []string                  `json:"parameters"`
+}
+
+type applicationProfileJSON struct {
+	Profile      uint16 `json:"profile"`
+	MajorVersion uint8  `json:"major_version"`
+	MinorVersion uint8  `json:"minor_version"`
+	MicroVersion uint8  `json:"micro_version"`
+}
+
+type applicationNameJSON struct {
+	Language jsonout.Language `json:"language"`
+	Name     string           `json:"name"`
+}
+
+func HeaderJSON(header *bdjo.BDJOHeader) *headerJSON {
+	return &headerJSON{
+		Type:       string(header.TypeIndicator[:]),
+		Version:    string(header.VersionNumber[:]),
+		Extensions: jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+func TerminalInfoJSON(terminalInfo *bdjo.TerminalInfo) *terminalInfoJSON {
+	return &terminalInfoJSON{
+		DefaultFont:                jsonout.String(terminalInfo.DefaultFont[:]),
+		InitialHAViConfigurationID: terminalInfo.InitialHAViConfigurationID,
+		MenuCallMask:               terminalInfo.MenuCallMask,
+		TitleSearchMask:            terminalInfo.TitleSearchMask,
+	}
+}
+
+func AppCacheInfoJSON(appCacheInfo *bdjo.AppCacheInfo) []*appCacheItemJSON {
+	out := make([]*appCacheItemJSON, len(appCacheInfo.AppCacheItems))
+	for i, item := range appCacheInfo.AppCacheItems {
+		out[i] = &appCacheItemJSON{
+			Type:     jsonout.NewEnum(item.Type, bdjo.AppCacheTypeName(item.Type)),
+			Name:     jsonout.String(item.Name[:]),
+			Language: jsonout.NewLanguage(item.Language),
+		}
+	}
+	return out
+}
+
+func TableOfAccessiblePlaylistsJSON(table *bdjo.TableOfAccessiblePlaylists) *tableOfAccessiblePlaylistsJSON {
+	out := &tableOfAccessiblePlaylistsJSON{
+		AccessToAllFlag:            table.AccessToAllFlag,
+		AutostartFirstPlaylistFlag: table.AutostartFirstPlaylistFlag,
+		Playlists:                  make([]string, len(table.Playlists)),
+	}
+	for i, playlist := range table.Playlists {
+		out.Playlists[i] = jsonout.String(playlist[:])
+	}
+	return out
+}
+
+func ApplicationManagementTableJSON(table *bdjo.ApplicationManagementTable) []*applicationJSON {
+	out := make([]*applicationJSON, len(table.Applications))
+	for i, app := range table.Applications {
+		appOut := &applicationJSON{
+			ControlCode:        jsonout.NewEnum(app.ControlCode, bdjo.ControlCodeName(app.ControlCode)),
+			Type:               app.Type,
+			OrganizationID:     app.OrganizationID,
+			ApplicationID:      app.ApplicationID,
+			Profiles:           make([]*applicationProfileJSON, len(app.Profiles)),
+			Priority:           app.Priority,
+			Binding:            app.Binding,
+			Visibility:         app.Visibility,
+			Names:              make([]*applicationNameJSON, len(app.Names)),
+			IconLocator:        app.IconLocator,
+			IconFlags:          app.IconFlags,
+			BaseDirectory:      app.BaseDirectory,
+			ClasspathExtension: app.ClasspathExtension,
+			InitialClass:       app.InitialClass,
+			Parameters:         append([]string{}, app.Parameters...),
+		}
+		for j, profile := range app.Profiles {
+			appOut.Profiles[j] = &applicationProfileJSON{
+				Profile:      profile.Profile,
+				MajorVersion: profile.MajorVersion,
+				MinorVersion: profile.MinorVersion,
+				MicroVersion: profile.MicroVersion,
+			}
+		}
+		for j, name := range app.Names {
+			appOut.Names[j] = &applicationNameJSON{Language: jsonout.NewLanguage(name.Language), Name: name.Name}
+		}
+		out[i] = appOut
+	}
+	return out
+}
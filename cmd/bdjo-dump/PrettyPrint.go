@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	bdjo "github.com/parasense/bdmv_go/pkg/bdjo"
+)
+
+func HeaderPrint(header *bdjo.BDJOHeader) {
+	fmt.Println("Header:")
+	PadPrintf(2, "Type: %s\n", string(header.TypeIndicator[:]))
+	PadPrintf(2, "Version: %s\n", string(header.VersionNumber[:]))
+	PadPrintf(2, "Offset: Extensions: [%d:%d]\n", header.Extensions.Start, header.Extensions.Stop)
+	PadPrintln(2, "---")
+}
+
+func TerminalInfoPrint(terminalInfo *bdjo.TerminalInfo) {
+	fmt.Println("TerminalInfo:")
+	PadPrintf(2, "Length: %d\n", terminalInfo.Length)
+	PadPrintf(2, "DefaultFont: %s\n", terminalInfo.DefaultFont[:])
+	PadPrintf(2, "InitialHAViConfigurationID: %d\n", terminalInfo.InitialHAViConfigurationID)
+	PadPrintf(2, "MenuCallMask: %t\n", terminalInfo.MenuCallMask)
+	PadPrintf(2, "TitleSearchMask: %t\n", terminalInfo.TitleSearchMask)
+	PadPrintln(2, "---")
+}
+
+func AppCacheInfoPrint(appCacheInfo *bdjo.AppCacheInfo) {
+	fmt.Println("AppCacheInfo:")
+	PadPrintf(2, "Length: %d\n", appCacheInfo.Length)
+	PadPrintf(2, "NumberOfItems: %d\n", appCacheInfo.NumberOfItems)
+	for i, item := range appCacheInfo.AppCacheItems {
+		PadPrintf(4, "AppCacheItems[%d]: %s %s (%s)\n", i, bdjo.AppCacheTypeName(item.Type), item.Name[:], item.Language[:])
+	}
+	PadPrintln(2, "---")
+}
+
+func TableOfAccessiblePlaylistsPrint(table *bdjo.TableOfAccessiblePlaylists) {
+	fmt.Println("TableOfAccessiblePlaylists:")
+	PadPrintf(2, "Length: %d\n", table.Length)
+	PadPrintf(2, "AccessToAllFlag: %t\n", table.AccessToAllFlag)
+	PadPrintf(2, "AutostartFirstPlaylistFlag: %t\n", table.AutostartFirstPlaylistFlag)
+	PadPrintf(2, "NumberOfPlaylists: %d\n", table.NumberOfPlaylists)
+	for i, playlist := range table.Playlists {
+		PadPrintf(4, "Playlists[%d]: %s.mpls\n", i, playlist[:])
+	}
+	PadPrintln(2, "---")
+}
+
+func ApplicationManagementTablePrint(table *bdjo.ApplicationManagementTable) {
+	fmt.Println("ApplicationManagementTable:")
+	PadPrintf(2, "Length: %d\n", table.Length)
+	PadPrintf(2, "NumberOfApplications: %d\n", table.NumberOfApplications)
+	for i, app := range table.Applications {
+		PadPrintf(4, "Applications[%d]:\n", i)
+		PadPrintf(6, "ControlCode: %d %s\n", app.ControlCode, bdjo.ControlCodeName(app.ControlCode))
+		PadPrintf(6, "Type: %d\n", app.Type)
+		PadPrintf(6, "OrganizationID: 0x%08X\n", app.OrganizationID)
+		PadPrintf(6, "ApplicationID: 0x%04X\n", app.ApplicationID)
+		for j, profile := range app.Profiles {
+			PadPrintf(6, "Profiles[%d]: %d, version %d.%d.%d\n", j, profile.Profile, profile.MajorVersion, profile.MinorVersion, profile.MicroVersion)
+		}
+		PadPrintf(6, "Priority: %d\n", app.Priority)
+		PadPrintf(6, "Binding: %d\n", app.Binding)
+		PadPrintf(6, "Visibility: %d\n", app.Visibility)
+		for j, name := range app.Names {
+			PadPrintf(6, "Names[%d]: %s %q\n", j, name.Language[:], name.Name)
+		}
+		PadPrintf(6, "IconLocator: %q\n", app.IconLocator)
+		PadPrintf(6, "IconFlags: 0x%04X\n", app.IconFlags)
+		PadPrintf(6, "BaseDirectory: %q\n", app.BaseDirectory)
+		PadPrintf(6, "ClasspathExtension: %q\n", app.ClasspathExtension)
+		PadPrintf(6, "InitialClass: %q\n", app.InitialClass)
+		PadPrintf(6, "Parameters: %q\n", app.Parameters)
+		PadPrintln(4, "---")
+	}
+	PadPrintln(2, "---")
+}
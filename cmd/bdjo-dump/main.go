@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	bdjo "github.com/parasense/bdmv_go/pkg/bdjo"
+)
+
+func PadPrintf(indent int, format string, args ...any) {
+	fmt.Printf(strings.Repeat(" ", indent)+format, args...)
+}
+
+func PadPrintln(indent int, args ...any) {
+	fmt.Print(strings.Repeat(" ", indent))
+	fmt.Println(args...)
+}
+
+func main() {
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: bdjo-dump [--format=text|json] <bdjo-file>")
+		os.Exit(1)
+	}
+
+	bdjoPath := flag.Arg(0)
+	header, terminalInfo, appCacheInfo, accessiblePlaylists, appManagementTable, keyInterestTable, fileAccessInfo, err := bdjo.ParseBDJO(bdjoPath)
+	if err != nil {
+		fmt.Printf("Error parsing BDJO file: %+v\n", err)
+		os.Exit(1)
+	}
+
+	if *format == jsonout.FormatJSON {
+		out := &bdjoJSON{
+			Header:                     HeaderJSON(header),
+			TerminalInfo:               TerminalInfoJSON(terminalInfo),
+			AppCacheInfo:               AppCacheInfoJSON(appCacheInfo),
+			TableOfAccessiblePlaylists: TableOfAccessiblePlaylistsJSON(accessiblePlaylists),
+			ApplicationManagementTable: ApplicationManagementTableJSON(appManagementTable),
+			KeyInterestTable:           keyInterestTable.Keys(),
+			FileAccessInfo:             fileAccessInfo.Path,
+		}
+		if out.KeyInterestTable == nil {
+			out.KeyInterestTable = []string{}
+		}
+		if err := jsonout.Write(os.Stdout, bdjoSchema, bdjoPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	PadPrintf(0, "BDJO File: %s\n", bdjoPath)
+	PadPrintln(0, "")
+	HeaderPrint(header)
+	PadPrintln(0, "")
+	TerminalInfoPrint(terminalInfo)
+	PadPrintln(0, "")
+	AppCacheInfoPrint(appCacheInfo)
+	PadPrintln(0, "")
+	TableOfAccessiblePlaylistsPrint(accessiblePlaylists)
+	PadPrintln(0, "")
+	ApplicationManagementTablePrint(appManagementTable)
+	PadPrintln(0, "")
+	PadPrintf(0, "KeyInterestTable: %s\n", strings.Join(keyInterestTable.Keys(), " "))
+	PadPrintf(0, "FileAccessInfo: %q\n", fileAccessInfo.Path)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+var (
+	chaptersPlaylist string
+	chaptersLanguage string
+	chaptersExport   string
+)
+
+func ChaptersFlags(flags *flag.FlagSet) {
+	flags.StringVar(&chaptersPlaylist, "playlist", "", "playlist name, e.g. 00800 (default: the main feature)")
+	flags.StringVar(&chaptersLanguage, "language", "eng", "language of the chapter names and title")
+	flags.Func("export", "write chapters as mkv, ogm or ffmetadata instead of text", func(value string) error {
+		switch value {
+		case "mkv", "ogm", "ffmetadata":
+			chaptersExport = value
+			return nil
+		}
+		return fmt.Errorf("unknown export format %q", value)
+	})
+}
+
+type chaptersJSON struct {
+	Playlist string         `json:"playlist"`
+	Language string         `json:"language"`
+	Title    string         `json:"title,omitempty"`
+	Chapters []*chapterJSON `json:"chapters"`
+}
+
+type chapterJSON struct {
+	Number int    `json:"number"`
+	Start  uint32 `json:"start"` // 45 kHz ticks from the start of the playlist
+	End    uint32 `json:"end"`
+	Name   string `json:"name,omitempty"`
+}
+
+// chaptersPlaylistName returns the playlist chosen by --playlist,
+// or the main feature, or "" when the disc has no playlists.
+func chaptersPlaylistName(disc *bdmv.Disc) string {
+	if chaptersPlaylist != "" {
+		return chaptersPlaylist
+	}
+	if playlist := disc.MainFeature(); playlist != nil {
+		return playlist.Name
+	}
+	return ""
+}
+
+func ChaptersJSON(disc *bdmv.Disc) any {
+	name := chaptersPlaylistName(disc)
+	out := &chaptersJSON{
+		Playlist: name,
+		Language: chaptersLanguage,
+		Title:    disc.Title(chaptersLanguage),
+		Chapters: []*chapterJSON{},
+	}
+	for _, chapter := range disc.Chapters(name, chaptersLanguage) {
+		out.Chapters = append(out.Chapters, &chapterJSON{
+			Number: chapter.Number,
+			Start:  uint32(chapter.Start),
+			End:    uint32(chapter.End),
+			Name:   chapter.Name,
+		})
+	}
+	return out
+}
+
+func ChaptersPrint(disc *bdmv.Disc) error {
+	name := chaptersPlaylistName(disc)
+	if _, ok := disc.Playlists[name]; !ok {
+		return fmt.Errorf("no playlist %q", name)
+	}
+	chapters := disc.Chapters(name, chaptersLanguage)
+
+	var err error
+	switch chaptersExport {
+	case "mkv":
+		err = bdmv.WriteMatroskaChapters(os.Stdout, chapters, chaptersLanguage)
+	case "ogm":
+		err = bdmv.WriteOGMChapters(os.Stdout, chapters)
+	case "ffmetadata":
+		err = bdmv.WriteFFMetadata(os.Stdout, chapters, disc.Title(chaptersLanguage))
+	default:
+		PadPrintf(2, "%s.mpls: %d chapters\n", name, len(chapters))
+		for _, chapter := range chapters {
+			PadPrintf(4, "%02d  %v - %v  %s\n", chapter.Number, chapter.Start, chapter.End, chapter.Name)
+		}
+	}
+	return err
+}
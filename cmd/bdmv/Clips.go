@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+)
+
+type clipJSON struct {
+	Name            string            `json:"name"`
+	ApplicationType jsonout.Enum      `json:"application_type"`
+	TSRecordingRate uint32            `json:"ts_recording_rate"`
+	SourcePackets   uint32            `json:"source_packets"`
+	Duration        uint32            `json:"duration"` // 45 kHz ticks
+	Streams         []*clipStreamJSON `json:"streams"`
+}
+
+// clipStreamJSON is one elementary stream of the first program.
+type clipStreamJSON struct {
+	PID      uint16            `json:"pid"`
+	Coding   jsonout.Enum      `json:"coding"`
+	Language *jsonout.Language `json:"language,omitempty"`
+}
+
+func ClipsJSON(disc *bdmv.Disc) any {
+	out := []*clipJSON{}
+	for _, name := range disc.ClipNames() {
+		out = append(out, ClipJSON(disc.Clips[name]))
+	}
+	return out
+}
+
+func ClipJSON(clip *bdmv.Clip) *clipJSON {
+	out := &clipJSON{
+		Name:     clip.Name,
+		Duration: uint32(clip.Duration()),
+		Streams:  []*clipStreamJSON{},
+	}
+	if clip.ClipInfo != nil {
+		out.ApplicationType = jsonout.NewEnum(clip.ClipInfo.ApplicationType, clpi.ClipApplication(clip.ClipInfo.ApplicationType))
+		out.TSRecordingRate = clip.ClipInfo.TSRecordingRate
+		out.SourcePackets = clip.ClipInfo.NumberOfSourcePackets
+	}
+	if clip.ProgramInfo == nil || len(clip.ProgramInfo.Programs) == 0 {
+		return out
+	}
+	for _, programStream := range clip.ProgramInfo.Programs[0].ProgramStreams {
+		if len(programStream.StreamCodingInfo) == 0 {
+			continue
+		}
+		out.Streams = append(out.Streams, ClipStreamJSON(programStream.StreamPID, programStream.StreamCodingInfo[0]))
+	}
+	return out
+}
+
+func ClipStreamJSON(pid uint16, sci clpi.StreamCodingInfo) *clipStreamJSON {
+	var base *clpi.BaseStreamCodingInfo
+	var language *[3]byte
+	switch info := sci.(type) {
+	case *clpi.StreamCodingInfoH264:
+		base = &info.BaseStreamCodingInfo
+	case *clpi.StreamCodingInfoH265:
+		base = &info.BaseStreamCodingInfo
+	case *clpi.StreamCodingInfoAudio:
+		base, language = &info.BaseStreamCodingInfo, &info.LanguageCode
+	case *clpi.StreamCodingTypePG:
+		base, language = &info.BaseStreamCodingInfo, &info.LanguageCode
+	case *clpi.StreamCodingTypeIG:
+		base, language = &info.BaseStreamCodingInfo, &info.LanguageCode
+	case *clpi.StreamCodingTypeText:
+		base, language = &info.BaseStreamCodingInfo, &info.LanguageCode
+	default:
+		return &clipStreamJSON{PID: pid}
+	}
+
+	out := &clipStreamJSON{
+		PID:    pid,
+		Coding: jsonout.NewEnum(base.StreamCodingType, clpi.StreamCodec(base.StreamCodingType)),
+	}
+	if language != nil {
+		lang := jsonout.NewLanguage(*language)
+		out.Language = &lang
+	}
+	return out
+}
+
+func ClipsPrint(disc *bdmv.Disc) error {
+	for _, name := range disc.ClipNames() {
+		ClipPrint(ClipJSON(disc.Clips[name]))
+	}
+	return nil
+}
+
+func ClipPrint(clip *clipJSON) {
+	PadPrintf(2, "%s.clpi: %s, %d source packets, %s\n",
+		clip.Name,
+		clock.Ticks45k(clip.Duration),
+		clip.SourcePackets,
+		clip.ApplicationType.Name,
+	)
+	for _, stream := range clip.Streams {
+		PadPrintf(4, "PID 0x%04X  %s", stream.PID, stream.Coding.Name)
+		if stream.Language != nil {
+			PadPrintf(0, "  %s", stream.Language.Code)
+		}
+		PadPrintln(0)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+type fontJSON struct {
+	Name       string   `json:"name"`
+	FontFormat string   `json:"font_format"`
+	Filename   string   `json:"filename"`
+	Styles     []string `json:"styles"`
+	SizeMin    *string  `json:"size_min,omitempty"`
+	SizeMax    *string  `json:"size_max,omitempty"`
+}
+
+func FontsJSON(disc *bdmv.Disc) any {
+	out := []*fontJSON{}
+	if disc.FontIndex == nil {
+		return out
+	}
+	for _, font := range disc.FontIndex.Fonts {
+		entry := &fontJSON{
+			Name:       font.Name,
+			FontFormat: font.FontFormat,
+			Filename:   font.Filename,
+			Styles:     append([]string{}, font.Styles...),
+		}
+		if font.Size != nil {
+			entry.SizeMin, entry.SizeMax = &font.Size.Min, &font.Size.Max
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func FontsPrint(disc *bdmv.Disc) error {
+	for _, font := range FontsJSON(disc).([]*fontJSON) {
+		PadPrintf(2, "%s: %s (%s)", font.Filename, font.Name, font.FontFormat)
+		if len(font.Styles) > 0 {
+			PadPrintf(0, " [%s]", strings.Join(font.Styles, ", "))
+		}
+		if font.SizeMin != nil {
+			PadPrintf(0, " size %s-%s", *font.SizeMin, *font.SizeMax)
+		}
+		PadPrintln(0)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/hdmv"
+)
+
+var graphDOT bool
+
+func GraphFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&graphDOT, "dot", false, "write the graph in Graphviz DOT instead of text")
+}
+
+type graphJSON struct {
+	Error               string             `json:"error,omitempty"`
+	Titles              []*graphTitleJSON  `json:"titles"`
+	Objects             []*graphObjectJSON `json:"objects"`
+	Playlists           []string           `json:"playlists"`
+	Unreachable         []*stepRefJSON     `json:"unreachable"`
+	Loops               [][]*stepRefJSON   `json:"loops"`
+	UnreferencedObjects []int              `json:"unreferenced_objects"`
+}
+
+// Err reports why the command could not run, so it fails the run.
+func (out *graphJSON) Err() error {
+	if out.Error == "" {
+		return nil
+	}
+	return errors.New(out.Error)
+}
+
+type graphTitleJSON struct {
+	Title     string `json:"title"`
+	Object    *int   `json:"object,omitempty"`
+	BDJObject string `json:"bdj_object,omitempty"`
+}
+
+type graphObjectJSON struct {
+	ID     int          `json:"id"`
+	Blocks []*blockJSON `json:"blocks"`
+}
+
+type blockJSON struct {
+	Start    int         `json:"start"`
+	End      int         `json:"end"`
+	Commands []string    `json:"commands"`
+	Edges    []*edgeJSON `json:"edges"`
+}
+
+// edgeJSON leads to a block (object and command), a title or a
+// playlist; with none of them and dynamic false it ends the object.
+type edgeJSON struct {
+	Kind     string `json:"kind"`
+	Object   *int   `json:"object,omitempty"`
+	Command  *int   `json:"command,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Playlist string `json:"playlist,omitempty"`
+	Dynamic  bool   `json:"dynamic"`
+}
+
+// stepRefJSON is a command, or the block starting at it.
+type stepRefJSON struct {
+	Object  int `json:"object"`
+	Command int `json:"command"`
+}
+
+func GraphJSON(disc *bdmv.Disc) any {
+	out := &graphJSON{
+		Titles:              []*graphTitleJSON{},
+		Objects:             []*graphObjectJSON{},
+		Playlists:           []string{},
+		Unreachable:         []*stepRefJSON{},
+		Loops:               [][]*stepRefJSON{},
+		UnreferencedObjects: []int{},
+	}
+	graph, err := disc.Graph()
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+
+	for _, title := range graph.Titles {
+		titleOut := &graphTitleJSON{Title: title.Name, BDJObject: title.BDJObject}
+		if title.Object >= 0 {
+			titleOut.Object = &title.Object
+		}
+		out.Titles = append(out.Titles, titleOut)
+	}
+	for id, blocks := range graph.Objects {
+		objectOut := &graphObjectJSON{ID: id, Blocks: []*blockJSON{}}
+		for _, block := range blocks {
+			objectOut.Blocks = append(objectOut.Blocks, BlockJSON(block))
+		}
+		out.Objects = append(out.Objects, objectOut)
+	}
+	out.Playlists = append(out.Playlists, graph.Playlists()...)
+	for _, step := range graph.Unreachable {
+		out.Unreachable = append(out.Unreachable, &stepRefJSON{step.Object, step.Command})
+	}
+	for _, loop := range graph.Loops {
+		loopOut := []*stepRefJSON{}
+		for _, block := range loop {
+			loopOut = append(loopOut, &stepRefJSON{block.Object, block.Start})
+		}
+		out.Loops = append(out.Loops, loopOut)
+	}
+	out.UnreferencedObjects = append(out.UnreferencedObjects, graph.UnreferencedObjects...)
+	return out
+}
+
+func BlockJSON(block *hdmv.Block) *blockJSON {
+	out := &blockJSON{Start: block.Start, End: block.End, Commands: []string{}, Edges: []*edgeJSON{}}
+	for _, nav := range block.Commands {
+		out.Commands = append(out.Commands, nav.Disassemble())
+	}
+	for _, edge := range block.Edges {
+		edgeOut := &edgeJSON{Kind: edge.Kind.String(), Playlist: edge.Playlist, Dynamic: edge.Dynamic}
+		if edge.Block != nil {
+			edgeOut.Object, edgeOut.Command = &edge.Block.Object, &edge.Block.Start
+		}
+		if edge.Title != nil {
+			edgeOut.Title = edge.Title.Name
+		}
+		out.Edges = append(out.Edges, edgeOut)
+	}
+	return out
+}
+
+func GraphPrint(disc *bdmv.Disc) error {
+	if graphDOT {
+		graph, err := disc.Graph()
+		if err != nil {
+			return err
+		}
+		return graph.WriteDOT(os.Stdout)
+	}
+
+	out := GraphJSON(disc).(*graphJSON)
+	if err := out.Err(); err != nil {
+		return err
+	}
+	blocks := 0
+	for _, object := range out.Objects {
+		blocks += len(object.Blocks)
+	}
+	PadPrintf(2, "%d movie objects, %d blocks, %d playlists played\n", len(out.Objects), blocks, len(out.Playlists))
+	for _, step := range out.Unreachable {
+		PadPrintf(2, "unreachable   movie_object %d command %d\n", step.Object, step.Command)
+	}
+	for _, loop := range out.Loops {
+		PadPrintf(2, "endless loop ")
+		for _, block := range loop {
+			PadPrintf(0, " %d:%04d", block.Object, block.Command)
+		}
+		PadPrintln(0)
+	}
+	for _, object := range out.UnreferencedObjects {
+		PadPrintf(2, "unreferenced  movie_object %d\n", object)
+	}
+	return nil
+}
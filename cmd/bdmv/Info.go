@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+type infoJSON struct {
+	Root          string   `json:"root"`
+	Layout        string   `json:"layout"`          // "BDMV" or "AVCHD"
+	Title         string   `json:"title,omitempty"` // From META/DL, English if present
+	Index         bool     `json:"index"`
+	Titles        int      `json:"titles"`
+	MovieObjects  int      `json:"movie_objects"`
+	Playlists     int      `json:"playlists"`
+	Clips         int      `json:"clips"`
+	Sounds        int      `json:"sounds"`
+	MetaLanguages []string `json:"meta_languages"`
+	Fonts         int      `json:"fonts"`
+	Problems      int      `json:"problems"`
+}
+
+func InfoJSON(disc *bdmv.Disc) any {
+	out := &infoJSON{
+		Root:          disc.Root,
+		Layout:        bdmv.LayoutBDMV.Name,
+		Playlists:     len(disc.Playlists),
+		Clips:         len(disc.Clips),
+		MetaLanguages: []string{},
+		Problems:      len(disc.Problems),
+	}
+	if disc.Layout != nil {
+		out.Layout = disc.Layout.Name
+	}
+	if disc.Index != nil {
+		out.Index = true
+		out.Titles = len(disc.Index.Titles)
+	}
+	if disc.MovieObjects != nil && disc.MovieObjects.MovieObjects != nil {
+		out.MovieObjects = len(disc.MovieObjects.MovieObjects.MovieObjects)
+	}
+	if disc.Sound != nil && disc.Sound.MetaData != nil {
+		out.Sounds = len(disc.Sound.MetaData.SampleAttrs)
+	}
+	for _, entry := range MetaJSON(disc).([]*metaJSON) {
+		out.MetaLanguages = append(out.MetaLanguages, entry.Language)
+		if out.Title == "" || entry.Language == "eng" {
+			out.Title = entry.Title
+		}
+	}
+	if disc.FontIndex != nil {
+		out.Fonts = len(disc.FontIndex.Fonts)
+	}
+	return out
+}
+
+func InfoPrint(disc *bdmv.Disc) error {
+	info := InfoJSON(disc).(*infoJSON)
+	PadPrintf(2, "Root: %s\n", info.Root)
+	PadPrintf(2, "Layout: %s\n", info.Layout)
+	if info.Title != "" {
+		PadPrintf(2, "Title: %s\n", info.Title)
+	}
+	PadPrintf(2, "index.bdmv: %t, %d titles\n", info.Index, info.Titles)
+	PadPrintf(2, "MovieObjects: %d\n", info.MovieObjects)
+	PadPrintf(2, "Playlists: %d\n", info.Playlists)
+	PadPrintf(2, "Clips: %d\n", info.Clips)
+	PadPrintf(2, "Sounds: %d\n", info.Sounds)
+	PadPrintf(2, "Meta languages: %v\n", info.MetaLanguages)
+	PadPrintf(2, "Fonts: %d\n", info.Fonts)
+	PadPrintf(2, "Problems: %d\n", info.Problems)
+	return nil
+}
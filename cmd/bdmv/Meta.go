@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+// metaJSON is the disc library of one language from META/DL.
+type metaJSON struct {
+	Language        string           `json:"language"`
+	Title           string           `json:"title"`
+	Thumbnails      []string         `json:"thumbnails"`
+	TableOfContents []*titleNameJSON `json:"table_of_contents"`
+}
+
+type titleNameJSON struct {
+	TitleNumber string `json:"title_number"`
+	Name        string `json:"name"`
+}
+
+func MetaJSON(disc *bdmv.Disc) any {
+	languages := make([]string, 0, len(disc.Meta))
+	for language := range disc.Meta {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	out := []*metaJSON{}
+	for _, language := range languages {
+		discInfo := &disc.Meta[language].DiscInfo
+		entry := &metaJSON{
+			Language:        language,
+			Title:           discInfo.Title.Name,
+			Thumbnails:      []string{},
+			TableOfContents: []*titleNameJSON{},
+		}
+		for _, thumbnail := range discInfo.Description.Thumbnails {
+			entry.Thumbnails = append(entry.Thumbnails, thumbnail.Href)
+		}
+		if discInfo.Description.TableOfContents != nil {
+			for _, title := range discInfo.Description.TableOfContents.TitleNames {
+				entry.TableOfContents = append(entry.TableOfContents, &titleNameJSON{
+					TitleNumber: title.TitleNumber,
+					Name:        title.Name,
+				})
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func MetaPrint(disc *bdmv.Disc) error {
+	for _, entry := range MetaJSON(disc).([]*metaJSON) {
+		PadPrintf(2, "[%s] %s\n", entry.Language, entry.Title)
+		for _, title := range entry.TableOfContents {
+			PadPrintf(4, "Title %s: %s\n", title.TitleNumber, title.Name)
+		}
+		for _, thumbnail := range entry.Thumbnails {
+			PadPrintf(4, "Thumbnail: %s\n", thumbnail)
+		}
+	}
+	return nil
+}
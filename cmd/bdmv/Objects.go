@@ -0,0 +1,88 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+)
+
+// movieObjectJSON is one movie object. ID is the index that
+// index.bdmv titles use in RefToMovieObjectID, starting at 0.
+type movieObjectJSON struct {
+	ID                  int            `json:"id"`
+	ResumeIntentionFlag bool           `json:"resume_intention_flag"`
+	MenuCallMask        bool           `json:"menu_call_mask"`
+	TitleSearchMask     bool           `json:"title_search_mask"`
+	Commands            []*commandJSON `json:"commands"`
+}
+
+type commandJSON struct {
+	Mnemonic               string `json:"mnemonic"`
+	Assembly               string `json:"assembly"`
+	Comment                string `json:"comment,omitempty"`
+	Destination            uint32 `json:"destination"`
+	Source                 uint32 `json:"source"`
+	ImmediateValueFlagDest bool   `json:"immediate_value_flag_dest"`
+	ImmediateValueFlagSrc  bool   `json:"immediate_value_flag_src"`
+}
+
+func ObjectsJSON(disc *bdmv.Disc) any {
+	out := []*movieObjectJSON{}
+	if disc.MovieObjects == nil || disc.MovieObjects.MovieObjects == nil {
+		return out
+	}
+	for i, movieObject := range disc.MovieObjects.MovieObjects.MovieObjects {
+		out = append(out, MovieObjectJSON(i, movieObject))
+	}
+	return out
+}
+
+func MovieObjectJSON(id int, movieObject *mobj.MovieObject) *movieObjectJSON {
+	out := &movieObjectJSON{
+		ID:                  id,
+		ResumeIntentionFlag: movieObject.ResumeIntentionFlag,
+		MenuCallMask:        movieObject.MenuCallMask,
+		TitleSearchMask:     movieObject.TitleSearchMask,
+		Commands:            make([]*commandJSON, len(movieObject.NavigationCommands)),
+	}
+	for i, nav := range movieObject.NavigationCommands {
+		out.Commands[i] = &commandJSON{
+			Mnemonic: mobj.GetCommand(
+				nav.CommandGroup,
+				nav.CommandSubGroup,
+				nav.BranchOption,
+				nav.CompareOption,
+				nav.SetOption,
+			),
+			Assembly:               nav.Disassemble(),
+			Comment:                nav.Comment(),
+			Destination:            nav.Destination,
+			Source:                 nav.Source,
+			ImmediateValueFlagDest: nav.ImmediateValueFlagDest,
+			ImmediateValueFlagSrc:  nav.ImmediateValueFlagSrc,
+		}
+	}
+	return out
+}
+
+func ObjectsPrint(disc *bdmv.Disc) error {
+	for _, movieObject := range ObjectsJSON(disc).([]*movieObjectJSON) {
+		MovieObjectPrint(movieObject)
+	}
+	return nil
+}
+
+func MovieObjectPrint(movieObject *movieObjectJSON) {
+	PadPrintf(2, "MovieObject[%d]: resume %t, menu call mask %t, title search mask %t\n",
+		movieObject.ID,
+		movieObject.ResumeIntentionFlag,
+		movieObject.MenuCallMask,
+		movieObject.TitleSearchMask,
+	)
+	for i, cmd := range movieObject.Commands {
+		if cmd.Comment != "" {
+			PadPrintf(4, "%04d  %-32s ; %s\n", i, cmd.Assembly, cmd.Comment)
+			continue
+		}
+		PadPrintf(4, "%04d  %s\n", i, cmd.Assembly)
+	}
+}
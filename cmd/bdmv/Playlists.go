@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+type playlistJSON struct {
+	Name      string        `json:"name"`
+	Duration  uint32        `json:"duration"` // 45 kHz ticks
+	PlayItems int           `json:"play_items"`
+	Chapters  int           `json:"chapters"`
+	Angles    int           `json:"angles"`
+	Clips     []string      `json:"clips"`
+	Streams   []*streamJSON `json:"streams"`
+}
+
+// streamJSON is one entry of the first PlayItem's stream table.
+type streamJSON struct {
+	Kind     string            `json:"kind"`
+	PID      uint16            `json:"pid"`
+	Coding   jsonout.Enum      `json:"coding"`
+	Language *jsonout.Language `json:"language,omitempty"`
+}
+
+func PlaylistsJSON(disc *bdmv.Disc) any {
+	out := []*playlistJSON{}
+	for _, name := range disc.PlaylistNames() {
+		out = append(out, PlaylistJSON(disc.Playlists[name]))
+	}
+	return out
+}
+
+func PlaylistJSON(playlist *bdmv.Playlist) *playlistJSON {
+	out := &playlistJSON{
+		Name:     playlist.Name,
+		Duration: uint32(playlist.Duration()),
+		Chapters: len(playlist.Chapters()),
+		Angles:   1,
+		Clips:    playlistClips(playlist),
+		Streams:  []*streamJSON{},
+	}
+	if playlist.PlayList == nil {
+		return out
+	}
+	out.PlayItems = len(playlist.PlayList.PlayItems)
+	for _, playItem := range playlist.PlayList.PlayItems {
+		if playItem.IsMultiAngle && int(playItem.NumberOfAngles) > out.Angles {
+			out.Angles = int(playItem.NumberOfAngles)
+		}
+	}
+	if len(playlist.PlayList.PlayItems) > 0 && playlist.PlayList.PlayItems[0].StreamTable != nil {
+		for _, item := range playlist.PlayList.PlayItems[0].StreamTable.Items {
+			for _, stream := range item.Streams {
+				out.Streams = append(out.Streams, StreamJSON(item.KindOf, stream))
+			}
+		}
+	}
+	return out
+}
+
+func StreamJSON(kind mpls.StreamTypeKindOf, stream *mpls.Stream) *streamJSON {
+	out := &streamJSON{Kind: string(kind)}
+
+	switch entry := stream.Entry.(type) {
+	case *mpls.StreamEntryTypeI:
+		out.PID = entry.RefToStreamPID
+	case *mpls.StreamEntryTypeII:
+		out.PID = entry.RefToStreamPID
+	case *mpls.StreamEntryTypeIII:
+		out.PID = entry.RefToStreamPID
+	}
+
+	var coding mpls.StreamCodingType
+	var language *[3]byte
+	switch attr := stream.Attr.(type) {
+	case *mpls.PrimaryVideoAttributesH264:
+		coding = attr.StreamCodingType
+	case *mpls.PrimaryVideoAttributesHEVC:
+		coding = attr.StreamCodingType
+	case *mpls.SecondaryVideoAttributes:
+		coding = attr.StreamCodingType
+	case *mpls.PrimaryAudioAttributes:
+		coding, language = attr.StreamCodingType, &attr.LanguageCode
+	case *mpls.SecondaryAudioAttributes:
+		coding, language = attr.StreamCodingType, &attr.LanguageCode
+	case *mpls.PGAttributes:
+		coding, language = attr.StreamCodingType, &attr.LanguageCode
+	case *mpls.IGAttributes:
+		coding, language = attr.StreamCodingType, &attr.LanguageCode
+	case *mpls.TextAttributes:
+		coding, language = attr.StreamCodingType, &attr.LanguageCode
+	}
+	out.Coding = jsonout.NewEnum(coding, mpls.StreamCodec(coding))
+	if language != nil {
+		lang := jsonout.NewLanguage(*language)
+		out.Language = &lang
+	}
+	return out
+}
+
+func PlaylistsPrint(disc *bdmv.Disc) error {
+	for _, name := range disc.PlaylistNames() {
+		PlaylistPrint(PlaylistJSON(disc.Playlists[name]))
+	}
+	return nil
+}
+
+func PlaylistPrint(playlist *playlistJSON) {
+	PadPrintf(2, "%s.mpls: %s, %d play items, %d chapters, %d angles\n",
+		playlist.Name,
+		clock.Ticks45k(playlist.Duration),
+		playlist.PlayItems,
+		playlist.Chapters,
+		playlist.Angles,
+	)
+	PadPrintf(4, "Clips: %s\n", strings.Join(playlist.Clips, " "))
+	for _, stream := range playlist.Streams {
+		PadPrintf(4, "%-20s PID 0x%04X  %s", stream.Kind, stream.PID, stream.Coding.Name)
+		if stream.Language != nil {
+			PadPrintf(0, "  %s", stream.Language.Code)
+		}
+		PadPrintln(0)
+	}
+}
+
+// playlistClips lists the main clip of every PlayItem in play order.
+func playlistClips(playlist *bdmv.Playlist) []string {
+	clips := []string{}
+	if playlist.PlayList == nil {
+		return clips
+	}
+	for _, playItem := range playlist.PlayList.PlayItems {
+		clips = append(clips, string(playItem.ClipInformationFileName[:]))
+	}
+	return clips
+}
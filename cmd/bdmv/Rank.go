@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+type rankJSON struct {
+	Name            string   `json:"name"`
+	Score           float64  `json:"score"`
+	MainFeature     bool     `json:"main_feature"`
+	Duration        uint32   `json:"duration"` // 45 kHz ticks
+	Chapters        int      `json:"chapters"`
+	AudioStreams    int      `json:"audio_streams"`
+	SubtitleStreams int      `json:"subtitle_streams"`
+	Coverage        float64  `json:"coverage"`
+	Backjumps       int      `json:"backjumps"`
+	Loop            bool     `json:"loop"`
+	DuplicateOf     string   `json:"duplicate_of,omitempty"`
+	ReorderOf       []string `json:"reorder_of"`
+}
+
+func RankJSON(disc *bdmv.Disc) any {
+	out := []*rankJSON{}
+	for _, rank := range disc.RankPlaylists() {
+		out = append(out, &rankJSON{
+			Name:            rank.Playlist.Name,
+			Score:           rank.Score,
+			MainFeature:     rank.MainFeature,
+			Duration:        uint32(rank.Duration),
+			Chapters:        rank.Chapters,
+			AudioStreams:    rank.AudioStreams,
+			SubtitleStreams: rank.SubtitleStreams,
+			Coverage:        rank.Coverage,
+			Backjumps:       rank.Backjumps,
+			Loop:            rank.Loop,
+			DuplicateOf:     rank.DuplicateOf,
+			ReorderOf:       append([]string{}, rank.ReorderOf...),
+		})
+	}
+	return out
+}
+
+func RankPrint(disc *bdmv.Disc) error {
+	for _, rank := range RankJSON(disc).([]*rankJSON) {
+		marker := " "
+		if rank.MainFeature {
+			marker = "*"
+		}
+		PadPrintf(1, "%s%s.mpls  %5.1f  %s  %3d ch  %2d audio  %2d subs  %3.0f%% clips",
+			marker,
+			rank.Name,
+			rank.Score,
+			clock.Ticks45k(rank.Duration),
+			rank.Chapters,
+			rank.AudioStreams,
+			rank.SubtitleStreams,
+			rank.Coverage*100,
+		)
+		if rank.Loop {
+			PadPrintf(0, "  loop")
+		}
+		if rank.DuplicateOf != "" {
+			PadPrintf(0, "  duplicate of %s", rank.DuplicateOf)
+		}
+		if len(rank.ReorderOf) > 0 {
+			PadPrintf(0, "  reorder of %s", strings.Join(rank.ReorderOf, " "))
+		}
+		PadPrintln(0)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/hdmv"
+)
+
+var (
+	playerSettings = &hdmv.Settings{PSRs: map[int]uint32{}}
+	simulateTrace  bool
+)
+
+// PlayerFlags adds the flags of the player settings, shared by the
+// commands that model a player.
+func PlayerFlags(flags *flag.FlagSet) {
+	flags.StringVar(&playerSettings.AudioLanguage, "audio-language", "", "PSR 16 audio language, e.g. eng")
+	flags.StringVar(&playerSettings.PGLanguage, "pg-language", "", "PSR 17 subtitle language")
+	flags.StringVar(&playerSettings.MenuLanguage, "menu-language", "", "PSR 18 menu language")
+	flags.StringVar(&playerSettings.CountryCode, "country", "", "PSR 19 country code, e.g. us")
+	flags.Func("region", "PSR 20 region: A, B or C (default A)", func(value string) error {
+		region, err := hdmv.ParseRegion(value)
+		playerSettings.RegionCode = region
+		return err
+	})
+	flags.Func("parental-level", "PSR 13 parental level, 0 to 255 (default 255, no limit)", func(value string) error {
+		level, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return err
+		}
+		playerSettings.ParentalLevel = new(uint32)
+		*playerSettings.ParentalLevel = uint32(level)
+		return nil
+	})
+	flags.Func("psr", "set any PSR as N=VALUE, e.g. 15=0x1 (repeatable)", func(value string) error {
+		number, setting, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("want N=VALUE, got %q", value)
+		}
+		psr, err := strconv.Atoi(number)
+		if err != nil {
+			return err
+		}
+		psrValue, err := strconv.ParseUint(setting, 0, 32)
+		if err != nil {
+			return err
+		}
+		playerSettings.PSRs[psr] = uint32(psrValue)
+		return nil
+	})
+	flags.BoolVar(&playerSettings.Output3D, "3d", false, "a 3D player set to 3D output on a 3D display")
+}
+
+func SimulateFlags(flags *flag.FlagSet) {
+	PlayerFlags(flags)
+	flags.BoolVar(&simulateTrace, "trace", false, "list every command executed")
+}
+
+type runJSON struct {
+	Title     string      `json:"title"` // "first_playback", "top_menu" or the title number
+	End       string      `json:"end"`
+	BDJObject string      `json:"bdj_object,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Feature   string      `json:"feature,omitempty"` // Playlist of the longest play
+	Plays     []*playJSON `json:"plays"`
+	Steps     int         `json:"steps"`
+	Trace     []*stepJSON `json:"trace,omitempty"`
+}
+
+type playJSON struct {
+	Object   int    `json:"object"`
+	Command  int    `json:"command"`
+	Playlist string `json:"playlist"`
+	PlayItem *int   `json:"play_item,omitempty"`
+	Mark     *int   `json:"mark,omitempty"`
+	Duration uint32 `json:"duration"` // 45 kHz ticks
+}
+
+type stepJSON struct {
+	Object   int    `json:"object"`
+	Command  int    `json:"command"`
+	Assembly string `json:"assembly"`
+}
+
+type simulateJSON struct {
+	Error string     `json:"error,omitempty"`
+	Runs  []*runJSON `json:"runs"`
+}
+
+// Err reports why the command could not run, so it fails the run.
+func (out *simulateJSON) Err() error {
+	if out.Error == "" {
+		return nil
+	}
+	return errors.New(out.Error)
+}
+
+func SimulateJSON(disc *bdmv.Disc) any {
+	out := &simulateJSON{Runs: []*runJSON{}}
+	vm, err := disc.NewVM(playerSettings)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.Runs = append(out.Runs, RunJSON(disc, "first_playback", vm.RunFirstPlayback()))
+	out.Runs = append(out.Runs, RunJSON(disc, "top_menu", vm.RunTopMenu()))
+	for i := range vm.Indexes.Titles {
+		out.Runs = append(out.Runs, RunJSON(disc, strconv.Itoa(i+1), vm.RunTitle(i+1)))
+	}
+	return out
+}
+
+func RunJSON(disc *bdmv.Disc, title string, result *hdmv.Result) *runJSON {
+	out := &runJSON{
+		Title:     title,
+		End:       result.End.String(),
+		BDJObject: result.BDJObject,
+		Plays:     []*playJSON{},
+		Steps:     len(result.Trace),
+	}
+	if result.Err != nil {
+		out.Error = result.Err.Error()
+	}
+	if feature := result.Feature(); feature != nil {
+		out.Feature = feature.Playlist
+	}
+	for _, play := range result.Plays {
+		playOut := &playJSON{
+			Object:   play.Object,
+			Command:  play.Command,
+			Playlist: play.Playlist,
+			Duration: uint32(play.Duration),
+		}
+		if play.PlayItem >= 0 {
+			playOut.PlayItem = &play.PlayItem
+		}
+		if play.Mark >= 0 {
+			playOut.Mark = &play.Mark
+		}
+		out.Plays = append(out.Plays, playOut)
+	}
+	if simulateTrace {
+		objects := disc.MovieObjects.MovieObjects.MovieObjects
+		for _, step := range result.Trace {
+			out.Trace = append(out.Trace, &stepJSON{
+				Object:   step.Object,
+				Command:  step.Command,
+				Assembly: objects[step.Object].NavigationCommands[step.Command].Disassemble(),
+			})
+		}
+	}
+	return out
+}
+
+func SimulatePrint(disc *bdmv.Disc) error {
+	out := SimulateJSON(disc).(*simulateJSON)
+	if err := out.Err(); err != nil {
+		return err
+	}
+	for _, run := range out.Runs {
+		RunPrint(run)
+	}
+	return nil
+}
+
+func RunPrint(run *runJSON) {
+	PadPrintf(2, "%-15s %s after %d commands", run.Title, run.End, run.Steps)
+	switch {
+	case run.BDJObject != "":
+		PadPrintf(0, ", BD-J object %s.bdjo", run.BDJObject)
+	case run.Error != "":
+		PadPrintf(0, ": %s", run.Error)
+	}
+	PadPrintln(0)
+	for _, step := range run.Trace {
+		PadPrintf(4, "%3d:%04d  %s\n", step.Object, step.Command, step.Assembly)
+	}
+	for _, play := range run.Plays {
+		PadPrintf(4, "play %s.mpls", play.Playlist)
+		if play.PlayItem != nil {
+			PadPrintf(0, " PlayItem %d", *play.PlayItem)
+		}
+		if play.Mark != nil {
+			PadPrintf(0, " mark %d", *play.Mark)
+		}
+		PadPrintf(0, "  %s  (object %d, command %d)", clock.Ticks45k(play.Duration), play.Object, play.Command)
+		if play.Playlist == run.Feature && len(run.Plays) > 1 {
+			PadPrintf(0, "  feature")
+		}
+		PadPrintln(0)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+type soundJSON struct {
+	Index            int    `json:"index"`
+	NumberOfChannels uint8  `json:"number_of_channels"`
+	SampleRate       uint32 `json:"sample_rate"`
+	BitsPerSample    uint8  `json:"bits_per_sample"`
+	NumberOfFrames   uint32 `json:"number_of_frames"`
+	Duration         uint64 `json:"duration"` // milliseconds
+}
+
+func SoundJSON(disc *bdmv.Disc) any {
+	out := []*soundJSON{}
+	if disc.Sound == nil || disc.Sound.MetaData == nil {
+		return out
+	}
+	for i, sound := range disc.Sound.MetaData.SampleAttrs {
+		entry := &soundJSON{
+			Index:            i,
+			NumberOfChannels: sound.NumberOfChannels,
+			SampleRate:       sound.SampleRate,
+			BitsPerSample:    sound.BitsPerSample,
+			NumberOfFrames:   sound.NumberOfFrames,
+		}
+		if sound.SampleRate != 0 {
+			entry.Duration = uint64(sound.NumberOfFrames) * 1000 / uint64(sound.SampleRate)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func SoundPrint(disc *bdmv.Disc) error {
+	for _, sound := range SoundJSON(disc).([]*soundJSON) {
+		PadPrintf(2, "Sound[%d]: %d ch, %d Hz, %d bit, %d frames, %d.%03d s\n",
+			sound.Index,
+			sound.NumberOfChannels,
+			sound.SampleRate,
+			sound.BitsPerSample,
+			sound.NumberOfFrames,
+			sound.Duration/1000,
+			sound.Duration%1000,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/hdmv"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+var streamsPlaylist string
+
+func StreamsFlags(flags *flag.FlagSet) {
+	flags.StringVar(&streamsPlaylist, "playlist", "", "playlist name, e.g. 00800 (default: the main feature)")
+	PlayerFlags(flags)
+}
+
+type streamsJSON struct {
+	Playlist  string                 `json:"playlist"`
+	Error     string                 `json:"error,omitempty"`
+	PlayItems []*playItemStreamsJSON `json:"play_items"`
+}
+
+// Err reports why the command could not run, so it fails the run.
+func (out *streamsJSON) Err() error {
+	if out.Error == "" {
+		return nil
+	}
+	return errors.New(out.Error)
+}
+
+// playItemStreamsJSON is what a player selects for one PlayItem. A null
+// stream means the player has nothing it can use.
+type playItemStreamsJSON struct {
+	PlayItem       int               `json:"play_item"`
+	Clip           string            `json:"clip"`
+	PrimaryAudio   *streamChoiceJSON `json:"primary_audio"`
+	PG             *streamChoiceJSON `json:"pg"`
+	PGDisplay      bool              `json:"pg_display"` // false: forced captions only
+	IG             *streamChoiceJSON `json:"ig"`
+	SecondaryAudio *streamChoiceJSON `json:"secondary_audio"`
+	SecondaryVideo *streamChoiceJSON `json:"secondary_video"`
+	DependentView  *streamJSON       `json:"dependent_view,omitempty"` // 3D only
+}
+
+type streamChoiceJSON struct {
+	Number int `json:"number"`
+	*streamJSON
+	Reason string `json:"reason"`
+}
+
+// streamsPlaylistName returns the playlist chosen by --playlist,
+// or the main feature, or "" when the disc has no playlists.
+func streamsPlaylistName(disc *bdmv.Disc) string {
+	if streamsPlaylist != "" {
+		return streamsPlaylist
+	}
+	if playlist := disc.MainFeature(); playlist != nil {
+		return playlist.Name
+	}
+	return ""
+}
+
+func StreamsJSON(disc *bdmv.Disc) any {
+	name := streamsPlaylistName(disc)
+	out := &streamsJSON{Playlist: name, PlayItems: []*playItemStreamsJSON{}}
+	selections, err := disc.DefaultStreams(name, playerSettings)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	playItems := disc.Playlists[name].PlayList.PlayItems
+	for i, selection := range selections {
+		playItemOut := &playItemStreamsJSON{
+			PlayItem:       i,
+			Clip:           string(playItems[i].ClipInformationFileName[:]),
+			PrimaryAudio:   StreamChoiceJSON(mpls.STREAM_TYPE_PRIMARY_AUDIO, selection.PrimaryAudio),
+			PG:             StreamChoiceJSON(mpls.STREAM_TYPE_PG, selection.PG),
+			PGDisplay:      selection.PGDisplay,
+			IG:             StreamChoiceJSON(mpls.STREAM_TYPE_IG, selection.IG),
+			SecondaryAudio: StreamChoiceJSON(mpls.STREAM_TYPE_SECONDARY_AUDIO, selection.SecondaryAudio),
+			SecondaryVideo: StreamChoiceJSON(mpls.STREAM_TYPE_SECONDARY_VIDEO, selection.SecondaryVideo),
+		}
+		if mvc := selection.DependentView; mvc != nil {
+			playItemOut.DependentView = StreamJSON(mpls.STREAM_TYPE_PRIMARY_VIDEO, &mpls.Stream{Entry: mvc.Entry, Attr: mvc.Attr})
+		}
+		out.PlayItems = append(out.PlayItems, playItemOut)
+	}
+	return out
+}
+
+func StreamChoiceJSON(kind mpls.StreamTypeKindOf, choice *hdmv.StreamChoice) *streamChoiceJSON {
+	if choice == nil {
+		return nil
+	}
+	return &streamChoiceJSON{
+		Number:     choice.Number,
+		streamJSON: StreamJSON(kind, choice.Stream),
+		Reason:     choice.Reason,
+	}
+}
+
+func StreamsPrint(disc *bdmv.Disc) error {
+	out := StreamsJSON(disc).(*streamsJSON)
+	if err := out.Err(); err != nil {
+		return err
+	}
+	PadPrintf(2, "%s.mpls\n", out.Playlist)
+	for _, playItem := range out.PlayItems {
+		PadPrintf(4, "PlayItem %d  %s\n", playItem.PlayItem, playItem.Clip)
+		StreamChoicePrint("Audio", playItem.PrimaryAudio)
+		StreamChoicePrint("Subtitles", playItem.PG)
+		StreamChoicePrint("Menu", playItem.IG)
+		StreamChoicePrint("Secondary audio", playItem.SecondaryAudio)
+		StreamChoicePrint("Secondary video", playItem.SecondaryVideo)
+		if view := playItem.DependentView; view != nil {
+			PadPrintf(6, "%-16s    PID 0x%04X  %s\n", "3D", view.PID, view.Coding.Name)
+		}
+	}
+	return nil
+}
+
+func StreamChoicePrint(label string, choice *streamChoiceJSON) {
+	if choice == nil {
+		PadPrintf(6, "%-16s none\n", label)
+		return
+	}
+	PadPrintf(6, "%-16s #%-2d PID 0x%04X  %s", label, choice.Number, choice.PID, choice.Coding.Name)
+	if choice.Language != nil {
+		PadPrintf(0, "  %s", choice.Language.Code)
+	}
+	PadPrintf(0, "  (%s)\n", choice.Reason)
+}
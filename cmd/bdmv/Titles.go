@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+type titleJSON struct {
+	Title         string  `json:"title"` // "first_playback", "top_menu" or the title number
+	ObjectType    string  `json:"object_type"`
+	AccessType    uint8   `json:"access_type"`
+	PlaybackType  uint8   `json:"playback_type"`
+	MovieObjectID *uint16 `json:"movie_object_id,omitempty"`
+	BDJObjectID   *string `json:"bdj_object_id,omitempty"`
+	Resolved      bool    `json:"resolved"`
+}
+
+func TitlesJSON(disc *bdmv.Disc) any {
+	out := []*titleJSON{}
+	if disc.Index == nil {
+		return out
+	}
+	if disc.Index.FirstPlayback != nil {
+		out = append(out, TitleJSON("first_playback", disc.Index.FirstPlayback))
+	}
+	if disc.Index.TopMenu != nil {
+		out = append(out, TitleJSON("top_menu", disc.Index.TopMenu))
+	}
+	for i, title := range disc.Index.Titles {
+		out = append(out, TitleJSON(strconv.Itoa(i+1), title))
+	}
+	return out
+}
+
+// TitleJSON describes what a title runs. Resolved reports whether the
+// movie object was found in MovieObject.bdmv; it is always false for BD-J.
+func TitleJSON(name string, title *bdmv.Title) *titleJSON {
+	out := &titleJSON{
+		Title:        name,
+		AccessType:   title.AccesType,
+		PlaybackType: title.PlaybackType,
+		Resolved:     title.MovieObject != nil,
+	}
+	switch title.ObjectType {
+	case 1:
+		out.ObjectType = "HDMV"
+		out.MovieObjectID = &title.RefToMovieObjectID
+	case 2:
+		out.ObjectType = "BD-J"
+		bdjObjectID := string(title.RefToBDJObjectID[:])
+		out.BDJObjectID = &bdjObjectID
+	default:
+		out.ObjectType = "unknown"
+	}
+	return out
+}
+
+func TitlesPrint(disc *bdmv.Disc) error {
+	for _, title := range TitlesJSON(disc).([]*titleJSON) {
+		TitlePrint(title)
+	}
+	return nil
+}
+
+func TitlePrint(title *titleJSON) {
+	switch {
+	case title.MovieObjectID != nil:
+		PadPrintf(2, "%-15s HDMV movie object %d", title.Title, *title.MovieObjectID)
+		if !title.Resolved {
+			PadPrintf(0, " (missing)")
+		}
+		PadPrintln(0)
+	case title.BDJObjectID != nil:
+		PadPrintf(2, "%-15s BD-J object %s.bdjo\n", title.Title, *title.BDJObjectID)
+	default:
+		PadPrintf(2, "%-15s %s\n", title.Title, title.ObjectType)
+	}
+}
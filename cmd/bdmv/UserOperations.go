@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+var userOperationsPlaylist string
+
+func UserOperationsFlags(flags *flag.FlagSet) {
+	flags.StringVar(&userOperationsPlaylist, "playlist", "", "playlist name, e.g. 00800 (default: every playlist)")
+}
+
+type userOperationsJSON struct {
+	Playlist       string                    `json:"playlist"`
+	BlocksSkipping bool                      `json:"blocks_skipping"` // Somewhere in the playlist
+	Spans          []*userOperationsSpanJSON `json:"spans"`
+}
+
+type userOperationsSpanJSON struct {
+	PlayItem       int      `json:"play_item"`
+	Start          uint32   `json:"start"` // 45 kHz ticks from the start of the playlist
+	End            uint32   `json:"end"`
+	BlocksSkipping bool     `json:"blocks_skipping"`
+	Prohibited     []string `json:"prohibited"`
+}
+
+func UserOperationsJSON(disc *bdmv.Disc) any {
+	names := disc.PlaylistNames()
+	if userOperationsPlaylist != "" {
+		names = []string{userOperationsPlaylist}
+	}
+	out := []*userOperationsJSON{}
+	for _, name := range names {
+		playlist, ok := disc.Playlists[name]
+		if !ok {
+			continue
+		}
+		playlistOut := &userOperationsJSON{Playlist: name, Spans: []*userOperationsSpanJSON{}}
+		for _, span := range playlist.UserOperations() {
+			spanOut := &userOperationsSpanJSON{
+				PlayItem:       span.PlayItem,
+				Start:          uint32(span.Start),
+				End:            uint32(span.End),
+				BlocksSkipping: span.Mask.BlocksSkipping(),
+				Prohibited:     span.Mask.Prohibited(),
+			}
+			playlistOut.BlocksSkipping = playlistOut.BlocksSkipping || spanOut.BlocksSkipping
+			playlistOut.Spans = append(playlistOut.Spans, spanOut)
+		}
+		out = append(out, playlistOut)
+	}
+	return out
+}
+
+func UserOperationsPrint(disc *bdmv.Disc) error {
+	for _, playlist := range UserOperationsJSON(disc).([]*userOperationsJSON) {
+		PadPrintf(2, "%s.mpls", playlist.Playlist)
+		if playlist.BlocksSkipping {
+			PadPrintf(0, "  blocks skipping")
+		}
+		PadPrintln(0)
+		for _, span := range playlist.Spans {
+			PadPrintf(4, "%s - %s  PlayItem %d  ", clock.Ticks45k(span.Start), clock.Ticks45k(span.End), span.PlayItem)
+			switch {
+			case len(span.Prohibited) == 0:
+				PadPrintf(0, "no restrictions")
+			case span.BlocksSkipping:
+				PadPrintf(0, "UNSKIPPABLE; no %s", strings.Join(span.Prohibited, ", "))
+			default:
+				PadPrintf(0, "no %s", strings.Join(span.Prohibited, ", "))
+			}
+			PadPrintln(0)
+		}
+	}
+	return nil
+}
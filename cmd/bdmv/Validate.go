@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+)
+
+type validateJSON struct {
+	OK       bool     `json:"ok"`
+	Problems []string `json:"problems"`
+}
+
+func ValidateJSON(disc *bdmv.Disc) any {
+	out := &validateJSON{
+		OK:       len(disc.Problems) == 0,
+		Problems: make([]string, len(disc.Problems)),
+	}
+	for i, problem := range disc.Problems {
+		out.Problems[i] = problem.Error()
+	}
+	return out
+}
+
+func ValidatePrint(disc *bdmv.Disc) error {
+	if len(disc.Problems) == 0 {
+		PadPrintln(2, "OK")
+		return nil
+	}
+	for _, problem := range disc.Problems {
+		PadPrintln(2, problem)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+)
+
+// errStreamProblems fails the run when streams do not check out.
+var errStreamProblems = errors.New("streams do not match the clips and playlists")
+
+type verifyStreamsJSON struct {
+	OK       bool                 `json:"ok"`
+	Problems []*streamProblemJSON `json:"problems"`
+}
+
+// streamProblemJSON is a bdmv.StreamError, or with kind "unreadable"
+// a stream that could not be read.
+type streamProblemJSON struct {
+	Kind     string        `json:"kind"`
+	Source   string        `json:"source"`
+	PID      uint16        `json:"pid,omitempty"`
+	Expected *jsonout.Enum `json:"expected,omitempty"`
+	Found    *jsonout.Enum `json:"found,omitempty"`
+	Message  string        `json:"message"`
+}
+
+func VerifyStreamsJSON(disc *bdmv.Disc) any {
+	out := &verifyStreamsJSON{Problems: []*streamProblemJSON{}}
+	for _, problem := range disc.VerifyStreams() {
+		out.Problems = append(out.Problems, StreamProblemJSON(problem))
+	}
+	out.OK = len(out.Problems) == 0
+	return out
+}
+
+// Err reports the problems found, so they fail the run.
+func (out *verifyStreamsJSON) Err() error {
+	if out.OK {
+		return nil
+	}
+	return errStreamProblems
+}
+
+func StreamProblemJSON(problem error) *streamProblemJSON {
+	out := &streamProblemJSON{Kind: "unreadable", Message: problem.Error()}
+
+	var fileErr *bdmv.FileError
+	if errors.As(problem, &fileErr) {
+		out.Source = fileErr.Path
+	}
+	var streamErr *bdmv.StreamError
+	if errors.As(problem, &streamErr) {
+		out.Kind = streamErr.Kind.String()
+		out.Source = streamErr.Source
+		out.PID = streamErr.PID
+		if streamErr.Kind != bdmv.StreamExtra {
+			out.Expected = codingEnum(streamErr.Expected)
+		}
+		if streamErr.Kind != bdmv.StreamMissing {
+			out.Found = codingEnum(streamErr.Found)
+		}
+	}
+	return out
+}
+
+func codingEnum(code uint8) *jsonout.Enum {
+	enum := jsonout.NewEnum(code, clpi.StreamCodec(clpi.StreamCodingType(code)))
+	return &enum
+}
+
+func VerifyStreamsPrint(disc *bdmv.Disc) error {
+	problems := disc.VerifyStreams()
+	if len(problems) == 0 {
+		PadPrintln(2, "OK")
+		return nil
+	}
+	for _, problem := range problems {
+		PadPrintln(2, problem)
+	}
+	return errStreamProblems
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/bdmv"
+	"github.com/parasense/bdmv_go/pkg/udf"
+)
+
+func PadPrintf(indent int, format string, args ...any) {
+	fmt.Printf(strings.Repeat(" ", indent)+format, args...)
+}
+
+func PadPrintln(indent int, args ...any) {
+	fmt.Print(strings.Repeat(" ", indent))
+	fmt.Println(args...)
+}
+
+// command is one bdmv subcommand. Text prints a disc to stdout and JSON
+// returns the value written as the data of a jsonout document. Text
+// returns an error when the command cannot run on the disc or the disc
+// fails its check; a JSON value reports the same through checked. Flags,
+// when set, adds the subcommand's own flags.
+type command struct {
+	Name    string
+	Summary string
+	Schema  string
+	Text    func(disc *bdmv.Disc) error
+	JSON    func(disc *bdmv.Disc) any
+	Flags   func(flags *flag.FlagSet)
+}
+
+// checked is implemented by the JSON value of a command that can fail.
+type checked interface {
+	Err() error
+}
+
+var commands = []*command{
+	{"info", "summary of the disc or file", "bdmv-info/1", InfoPrint, InfoJSON, nil},
+	{"playlists", "playlists with duration, clips, chapters and streams", "bdmv-playlists/1", PlaylistsPrint, PlaylistsJSON, nil},
+	{"rank", "playlists ranked best first, with the main feature marked", "bdmv-rank/1", RankPrint, RankJSON, nil},
+	{"chapters", "chapters of a playlist, or export them for remuxing", "bdmv-chapters/1", ChaptersPrint, ChaptersJSON, ChaptersFlags},
+	{"clips", "clips with duration and elementary streams", "bdmv-clips/1", ClipsPrint, ClipsJSON, nil},
+	{"titles", "index.bdmv titles and what they run", "bdmv-titles/1", TitlesPrint, TitlesJSON, nil},
+	{"objects", "movie objects and their navigation commands", "bdmv-objects/1", ObjectsPrint, ObjectsJSON, nil},
+	{"graph", "control-flow graph of the movie objects, as text, JSON or DOT", "bdmv-graph/1", GraphPrint, GraphJSON, GraphFlags},
+	{"simulate", "run the movie objects to see what each title plays", "bdmv-simulate/1", SimulatePrint, SimulateJSON, SimulateFlags},
+	{"streams", "audio and subtitle streams a player selects by default", "bdmv-streams/1", StreamsPrint, StreamsJSON, StreamsFlags},
+	{"uops", "user operations each playlist prohibits, and where skipping is blocked", "bdmv-uops/1", UserOperationsPrint, UserOperationsJSON, UserOperationsFlags},
+	{"sound", "sound.bdmv menu sounds", "bdmv-sound/1", SoundPrint, SoundJSON, nil},
+	{"meta", "disc library metadata per language", "bdmv-meta/1", MetaPrint, MetaJSON, nil},
+	{"fonts", "fonts listed in dvb.fontindex", "bdmv-fonts/1", FontsPrint, FontsJSON, nil},
+	{"validate", "parse everything and report problems", "bdmv-validate/1", ValidatePrint, ValidateJSON, nil},
+	{"verify-streams", "check clip and playlist streams against each .m2ts PMT", "bdmv-verify-streams/1", VerifyStreamsPrint, VerifyStreamsJSON, nil},
+}
+
+func usage() {
+	fmt.Println("Usage: bdmv <command> [--format=text|json] <file-or-disc-root>...")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range commands {
+		PadPrintf(2, "%-14s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Println()
+	fmt.Println("A path may be a single navigation file, a BDMV directory, a disc root")
+	fmt.Println("or a UDF disc image (.iso), which is read without mounting it.")
+	fmt.Println("Files are identified by their type indicator, not by their name.")
+	fmt.Println("Run \"bdmv <command> --help\" for the flags of a command.")
+}
+
+func lookup(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// open loads a disc root or BDMV directory with bdmv.Open, a UDF disc
+// image with bdmv.OpenFS, and anything else as a single file with
+// bdmv.OpenFile.
+func open(path string) (disc *bdmv.Disc, isDir bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if info.IsDir() {
+		disc, err = bdmv.Open(path)
+		return disc, true, err
+	}
+
+	// The image stays open for as long as the command runs: streams are
+	// read from it after loading.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if udf.IsImage(file) {
+		image, err := udf.New(file)
+		if err != nil {
+			file.Close()
+			return nil, false, err
+		}
+		disc, err = bdmv.OpenFS(image, ".")
+		return disc, true, err
+	}
+	file.Close()
+
+	disc, err = bdmv.OpenFile(path)
+	return disc, false, err
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+	cmd := lookup(os.Args[1])
+	if cmd == nil {
+		usage()
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("bdmv "+cmd.Name, flag.ExitOnError)
+	format := flags.String("format", jsonout.FormatText, "output format: text or json")
+	if cmd.Flags != nil {
+		cmd.Flags(flags)
+	}
+	flags.Parse(os.Args[2:])
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flags.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		disc, isDir, err := open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+
+		if *format == jsonout.FormatJSON {
+			data := cmd.JSON(disc)
+			if err := jsonout.Write(os.Stdout, cmd.Schema, path, data); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+				os.Exit(1)
+			}
+			if data, ok := data.(checked); ok && data.Err() != nil {
+				status = 1
+			}
+		} else {
+			if flags.NArg() > 1 {
+				PadPrintf(0, "%s:\n", path)
+			}
+			if err := cmd.Text(disc); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				status = 1
+			}
+		}
+
+		// validate reports problems itself. Everyone else only warns,
+		// and only a broken single file makes the run fail.
+		if cmd.Name == "validate" {
+			if len(disc.Problems) > 0 {
+				status = 1
+			}
+			continue
+		}
+		for _, problem := range disc.Problems {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", problem)
+		}
+		if !isDir && len(disc.Problems) > 0 {
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"time"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+)
+
+const clpiSchema = "clpi/1"
+
+type clpiJSON struct {
+	Header       *headerJSON       `json:"header"`
+	ClipInfo     *clipInfoJSON     `json:"clip_info"`
+	SequenceInfo *sequenceInfoJSON `json:"sequence_info"`
+	ProgramInfo  *programInfoJSON  `json:"program_info"`
+	CPI          *cpiJSON          `json:"cpi"`
+	ClipMarks    *clipMarksJSON    `json:"clip_marks"`
+	Extensions   *extensionsJSON   `json:"extensions"`
+}
+
+type headerJSON struct {
+	Type         string          `json:"type"`
+	Version      string          `json:"version"`
+	SequenceInfo jsonout.Offsets `json:"sequence_info"`
+	ProgramInfo  jsonout.Offsets `json:"program_info"`
+	CPI          jsonout.Offsets `json:"cpi"`
+	ClipMarks    jsonout.Offsets `json:"clip_marks"`
+	Extensions   jsonout.Offsets `json:"extensions"`
+}
+
+type clipInfoJSON struct {
+	Length                           uint32       `json:"length"`
+	ClipStreamType                   uint8        `json:"clip_stream_type"`
+	ApplicationType                  jsonout.Enum `json:"application_type"`
+	IsCC5                            bool         `json:"is_cc5"`
+	TSRecordingRate                  uint32       `json:"ts_recording_rate"`
+	NumberOfSourcePackets            uint32       `json:"number_of_source_packets"`
+	TSTypeInfoBlock                  jsonout.Hex  `json:"ts_type_info_block"`
+	FollowingClipStreamType          *uint8       `json:"following_clip_stream_type,omitempty"`
+	FollowingClipInformationFileName *string      `json:"following_clip_information_file_name,omitempty"`
+	FollowingClipCodecIdentifier     *string      `json:"following_clip_codec_identifier,omitempty"`
+}
+
+type sequenceInfoJSON struct {
+	Length       uint32             `json:"length"`
+	ATCSequences []*atcSequenceJSON `json:"atc_sequences"`
+}
+
+type atcSequenceJSON struct {
+	SPNATCStart  uint32             `json:"spn_atc_start"`
+	OffsetSTCID  uint8              `json:"offset_stc_id"`
+	STCSequences []*stcSequenceJSON `json:"stc_sequences"`
+}
+
+type stcSequenceJSON struct {
+	PCRPID                uint16 `json:"pcr_pid"`
+	SPNSTCStart           uint32 `json:"spn_stc_start"`
+	PresentationStartTime uint32 `json:"presentation_start_time"`
+	PresentationEndTime   uint32 `json:"presentation_end_time"`
+}
+
+type programInfoJSON struct {
+	Length   uint32         `json:"length"`
+	Programs []*programJSON `json:"programs"`
+}
+
+type programJSON struct {
+	SPNProgramSequenceStart uint32               `json:"spn_program_sequence_start"`
+	ProgramMapPID           uint16               `json:"program_map_pid"`
+	Streams                 []*programStreamJSON `json:"streams"`
+}
+
+type programStreamJSON struct {
+	StreamPID  uint16                `json:"stream_pid"`
+	CodingInfo *streamCodingInfoJSON `json:"coding_info"`
+}
+
+// streamCodingInfoJSON covers every stream coding info type. Kind names the
+// type and decides which of the optional fields are present.
+type streamCodingInfoJSON struct {
+	Kind             string            `json:"kind"`
+	Length           uint8             `json:"length"`
+	StreamCodingType jsonout.Enum      `json:"stream_coding_type"`
+	ISRCode          string            `json:"isrc"`
+	VideoFormat      *jsonout.Enum     `json:"video_format,omitempty"`
+	FrameRate        *jsonout.Enum     `json:"frame_rate,omitempty"`
+	AspectRatio      *jsonout.Enum     `json:"aspect_ratio,omitempty"`
+	OCFlag           *bool             `json:"oc_flag,omitempty"`
+	CRFlag           *bool             `json:"cr_flag,omitempty"`
+	DynamicRangeType *uint8            `json:"dynamic_range_type,omitempty"`
+	ColorSpace       *uint8            `json:"color_space,omitempty"`
+	HDRPlusFlag      *bool             `json:"hdr_plus_flag,omitempty"`
+	AudioFormat      *jsonout.Enum     `json:"audio_format,omitempty"`
+	SampleRate       *jsonout.Enum     `json:"sample_rate,omitempty"`
+	CharacterCode    *jsonout.Enum     `json:"character_code,omitempty"`
+	Language         *jsonout.Language `json:"language,omitempty"`
+}
+
+type cpiJSON struct {
+	Length           uint32                `json:"length"`
+	CPIType          uint8                 `json:"cpi_type"`
+	StreamPIDEntries []*streamPIDEntryJSON `json:"stream_pid_entries"`
+}
+
+type streamPIDEntryJSON struct {
+	StreamPID               uint16             `json:"stream_pid"`
+	EPStreamType            uint8              `json:"ep_stream_type"`
+	EPMapStreamStartAddr    uint32             `json:"ep_map_stream_start_addr"`
+	EPFineTableStartAddress uint32             `json:"ep_fine_table_start_address"`
+	CoarseEntries           []*coarseEntryJSON `json:"coarse_entries"`
+	FineEntries             []*fineEntryJSON   `json:"fine_entries"`
+}
+
+type coarseEntryJSON struct {
+	RefToEPFineID uint32 `json:"ref_to_ep_fine_id"`
+	PTSEPCoarse   uint16 `json:"pts_ep_coarse"`
+	SPNEPCoarse   uint32 `json:"spn_ep_coarse"`
+}
+
+type fineEntryJSON struct {
+	IsAngleChangePoint bool   `json:"is_angle_change_point"`
+	IEndPositionOffset uint8  `json:"i_end_position_offset"`
+	PTSEPFine          uint16 `json:"pts_ep_fine"`
+	SPNEPFine          uint32 `json:"spn_ep_fine"`
+}
+
+type clipMarksJSON struct {
+	Length uint32          `json:"length"`
+	Marks  []*clipMarkJSON `json:"marks"`
+}
+
+type clipMarkJSON struct {
+	MarkType       uint8  `json:"mark_type"`
+	MarkPID        uint16 `json:"mark_pid"`
+	MarkTimeStamp  uint32 `json:"mark_time_stamp"`
+	MarkEntryPoint uint32 `json:"mark_entry_point"`
+	MarkDuration   uint32 `json:"mark_duration"`
+}
+
+type extensionsJSON struct {
+	Length             uint32           `json:"length"`
+	EntryDataStartAddr uint32           `json:"entry_data_start_addr"`
+	Entries            []*extensionJSON `json:"entries"`
+}
+
+type extensionJSON struct {
+	Type         uint16 `json:"type"`
+	Version      uint16 `json:"version"`
+	StartAddress uint32 `json:"start_address"`
+	Length       uint32 `json:"length"`
+	Kind         string `json:"kind"`
+	Data         any    `json:"data"`
+}
+
+type extensionRawJSON struct {
+	Data jsonout.Hex `json:"data"`
+}
+
+type makersPrivateDataJSON struct {
+	Entries []*makerEntryJSON `json:"entries"`
+}
+
+type makerEntryJSON struct {
+	Maker         jsonout.Enum `json:"maker"`
+	ModelCode     uint16       `json:"model_code"`
+	RecordingTime string       `json:"recording_time,omitempty"` // RFC 3339
+	DST           bool         `json:"dst"`
+	GPS           *gpsJSON     `json:"gps,omitempty"`
+	Data          jsonout.Hex  `json:"data"`
+}
+
+type gpsJSON struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+	Time      float64 `json:"time"` // UTC seconds since midnight
+}
+
+type extentStartPointsJSON struct {
+	Length uint32   `json:"length"`
+	Points []uint32 `json:"points"`
+}
+
+func HeaderJSON(header *clpi.CLPIHeader) *headerJSON {
+	return &headerJSON{
+		Type:         string(header.TypeIndicator[:]),
+		Version:      string(header.VersionNumber[:]),
+		SequenceInfo: jsonout.Offsets{Start: header.SequenceInfo.Start, Stop: header.SequenceInfo.Stop},
+		ProgramInfo:  jsonout.Offsets{Start: header.ProgramInfo.Start, Stop: header.ProgramInfo.Stop},
+		CPI:          jsonout.Offsets{Start: header.CPI.Start, Stop: header.CPI.Stop},
+		ClipMarks:    jsonout.Offsets{Start: header.ClipMarks.Start, Stop: header.ClipMarks.Stop},
+		Extensions:   jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+func ClipInfoJSON(clipInfo *clpi.ClipInfo) *clipInfoJSON {
+	out := &clipInfoJSON{
+		Length:                clipInfo.Length,
+		ClipStreamType:        clipInfo.ClipStreamType,
+		ApplicationType:       jsonout.NewEnum(clipInfo.ApplicationType, clpi.ClipApplication(clipInfo.ApplicationType)),
+		IsCC5:                 clipInfo.IsCC5,
+		TSRecordingRate:       clipInfo.TSRecordingRate,
+		NumberOfSourcePackets: clipInfo.NumberOfSourcePackets,
+		TSTypeInfoBlock:       clipInfo.TSTypeInfoBlock[:],
+	}
+	if clipInfo.IsCC5 {
+		fileName := string(clipInfo.FollowingClipInformationFileName[:])
+		codec := string(clipInfo.FollowingClipCodecIdentifier[:])
+		out.FollowingClipStreamType = &clipInfo.FollowingClipStreamType
+		out.FollowingClipInformationFileName = &fileName
+		out.FollowingClipCodecIdentifier = &codec
+	}
+	return out
+}
+
+func SequenceInfoJSON(sequenceInfo *clpi.SequenceInfo) *sequenceInfoJSON {
+	out := &sequenceInfoJSON{
+		Length:       sequenceInfo.Length,
+		ATCSequences: make([]*atcSequenceJSON, len(sequenceInfo.ATCSequences)),
+	}
+	for i, atc := range sequenceInfo.ATCSequences {
+		atcOut := &atcSequenceJSON{
+			SPNATCStart:  atc.SPNATCStart,
+			OffsetSTCID:  atc.OffsetSTCID,
+			STCSequences: make([]*stcSequenceJSON, len(atc.STCSequences)),
+		}
+		for j, stc := range atc.STCSequences {
+			atcOut.STCSequences[j] = &stcSequenceJSON{
+				PCRPID:                stc.PCRPID,
+				SPNSTCStart:           stc.SPNSTCStart,
+				PresentationStartTime: uint32(stc.PresentationStartTime),
+				PresentationEndTime:   uint32(stc.PresentationEndTime),
+			}
+		}
+		out.ATCSequences[i] = atcOut
+	}
+	return out
+}
+
+func ProgramInfoJSON(pi *clpi.ProgramInfo) *programInfoJSON {
+	out := &programInfoJSON{
+		Length:   pi.Length,
+		Programs: make([]*programJSON, len(pi.Programs)),
+	}
+	for i, pgm := range pi.Programs {
+		pgmOut := &programJSON{
+			SPNProgramSequenceStart: pgm.SPNProgramSequenceStart,
+			ProgramMapPID:           pgm.ProgramMapPID,
+			Streams:                 make([]*programStreamJSON, len(pgm.ProgramStreams)),
+		}
+		for j, pgmstrm := range pgm.ProgramStreams {
+			streamOut := &programStreamJSON{StreamPID: pgmstrm.StreamPID}
+			if len(pgmstrm.StreamCodingInfo) > 0 {
+				streamOut.CodingInfo = StreamCodingInfoJSON(pgmstrm.StreamCodingInfo[0])
+			}
+			pgmOut.Streams[j] = streamOut
+		}
+		out.Programs[i] = pgmOut
+	}
+	return out
+}
+
+// StreamCodingInfoJSON converts stream coding info based on its type.
+func StreamCodingInfoJSON(sci clpi.StreamCodingInfo) *streamCodingInfoJSON {
+	switch info := sci.(type) {
+	case *clpi.StreamCodingInfoH264:
+		out := baseCodingInfoJSON("video", &info.BaseStreamCodingInfo)
+		out.VideoFormat, out.FrameRate, out.AspectRatio = videoJSON(info.VideoFormat, info.FrameRate, info.VideoAspectRatio)
+		out.OCFlag = &info.OCFlag
+		return out
+	case *clpi.StreamCodingInfoH265:
+		out := baseCodingInfoJSON("video_hevc", &info.BaseStreamCodingInfo)
+		out.VideoFormat, out.FrameRate, out.AspectRatio = videoJSON(info.VideoFormat, info.FrameRate, info.VideoAspectRatio)
+		out.OCFlag = &info.OCFlag
+		out.CRFlag = &info.CRFlag
+		out.DynamicRangeType = &info.DynamicRangeType
+		out.ColorSpace = &info.ColorSpace
+		out.HDRPlusFlag = &info.HDRPlusFlag
+		return out
+	case *clpi.StreamCodingInfoAudio:
+		out := baseCodingInfoJSON("audio", &info.BaseStreamCodingInfo)
+		audioFormat := jsonout.NewEnum(info.AudioFormat, clpi.AudioFormat(info.AudioFormat))
+		sampleRate := jsonout.NewEnum(info.SampleRate, clpi.AudioRate(info.SampleRate))
+		out.AudioFormat = &audioFormat
+		out.SampleRate = &sampleRate
+		out.Language = languageJSON(info.LanguageCode)
+		return out
+	case *clpi.StreamCodingTypePG:
+		out := baseCodingInfoJSON("pg", &info.BaseStreamCodingInfo)
+		out.Language = languageJSON(info.LanguageCode)
+		return out
+	case *clpi.StreamCodingTypeIG:
+		out := baseCodingInfoJSON("ig", &info.BaseStreamCodingInfo)
+		out.Language = languageJSON(info.LanguageCode)
+		return out
+	case *clpi.StreamCodingTypeText:
+		out := baseCodingInfoJSON("text", &info.BaseStreamCodingInfo)
+		characterCode := jsonout.NewEnum(info.CharacterCode, clpi.CharacterCode(info.CharacterCode))
+		out.CharacterCode = &characterCode
+		out.Language = languageJSON(info.LanguageCode)
+		return out
+	}
+	return nil
+}
+
+func baseCodingInfoJSON(kind string, info *clpi.BaseStreamCodingInfo) *streamCodingInfoJSON {
+	return &streamCodingInfoJSON{
+		Kind:             kind,
+		Length:           info.Length,
+		StreamCodingType: jsonout.NewEnum(info.StreamCodingType, clpi.StreamCodec(info.StreamCodingType)),
+		ISRCode:          jsonout.String(info.ISRCode[:]),
+	}
+}
+
+func videoJSON(format clpi.VideoFormatType, rate clpi.VideoRateType, aspect clpi.VideoAspectRatioType) (*jsonout.Enum, *jsonout.Enum, *jsonout.Enum) {
+	videoFormat := jsonout.NewEnum(format, clpi.VideoFormat(format))
+	frameRate := jsonout.NewEnum(rate, clpi.VideoRate(rate))
+	aspectRatio := jsonout.NewEnum(aspect, clpi.AspectRatio(aspect))
+	return &videoFormat, &frameRate, &aspectRatio
+}
+
+func languageJSON(code [3]byte) *jsonout.Language {
+	language := jsonout.NewLanguage(code)
+	return &language
+}
+
+func CPIJSON(cpi *clpi.CPI) *cpiJSON {
+	out := &cpiJSON{
+		Length:           cpi.Length,
+		CPIType:          cpi.CPIType,
+		StreamPIDEntries: make([]*streamPIDEntryJSON, len(cpi.StreamPIDEntries)),
+	}
+	for i, streamPidEntry := range cpi.StreamPIDEntries {
+		entryOut := &streamPIDEntryJSON{
+			StreamPID:               streamPidEntry.StreamPID,
+			EPStreamType:            streamPidEntry.EPStreamType,
+			EPMapStreamStartAddr:    streamPidEntry.EPMapStreamStartAddr,
+			EPFineTableStartAddress: streamPidEntry.EPFineTableStartAddress,
+			CoarseEntries:           make([]*coarseEntryJSON, len(streamPidEntry.CourseEntries)),
+			FineEntries:             make([]*fineEntryJSON, len(streamPidEntry.FineEntries)),
+		}
+		for j, courseEntry := range streamPidEntry.CourseEntries {
+			entryOut.CoarseEntries[j] = &coarseEntryJSON{
+				RefToEPFineID: courseEntry.RefToEPFineID,
+				PTSEPCoarse:   courseEntry.PTSEPCoarse,
+				SPNEPCoarse:   courseEntry.SPNEPCoarse,
+			}
+		}
+		for j, fineEntry := range streamPidEntry.FineEntries {
+			entryOut.FineEntries[j] = &fineEntryJSON{
+				IsAngleChangePoint: fineEntry.IsAngleChangePoint,
+				IEndPositionOffset: fineEntry.IEndPositionOffset,
+				PTSEPFine:          fineEntry.PTSEPFine,
+				SPNEPFine:          fineEntry.SPNEPFine,
+			}
+		}
+		out.StreamPIDEntries[i] = entryOut
+	}
+	return out
+}
+
+func ClipMarksJSON(clipMarks *clpi.ClipMarks) *clipMarksJSON {
+	out := &clipMarksJSON{
+		Length: clipMarks.Length,
+		Marks:  make([]*clipMarkJSON, len(clipMarks.MarkEntries)),
+	}
+	for i, entry := range clipMarks.MarkEntries {
+		out.Marks[i] = &clipMarkJSON{
+			MarkType:       entry.MarkType,
+			MarkPID:        entry.MarkPID,
+			MarkTimeStamp:  uint32(entry.MarkTimeStamp),
+			MarkEntryPoint: entry.MarkEntryPoint,
+			MarkDuration:   uint32(entry.MarkDuration),
+		}
+	}
+	return out
+}
+
+func ExtensionsJSON(extensions *clpi.Extensions) *extensionsJSON {
+	out := &extensionsJSON{
+		Length:             extensions.MetaData.Length,
+		EntryDataStartAddr: extensions.MetaData.EntryDataStartAddr,
+		Entries:            make([]*extensionJSON, len(extensions.EntriesMetaData)),
+	}
+	for i, metaData := range extensions.EntriesMetaData {
+		entry := &extensionJSON{
+			Type:         metaData.ExtDataType,
+			Version:      metaData.ExtDataVersion,
+			StartAddress: metaData.ExtDataStartAddress,
+			Length:       metaData.ExtDataLength,
+		}
+		if i < len(extensions.EntriesData) {
+			entry.Kind, entry.Data = ExtensionsEntryDataJSON(extensions.EntriesData[i])
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
+
+// ExtensionsEntryDataJSON returns the kind and the payload of an extension.
+func ExtensionsEntryDataJSON(entryData clpi.ExtensionEntryData) (string, any) {
+	switch ext := entryData.(type) {
+	case *clpi.ExtensionRaw:
+		return "raw", &extensionRawJSON{Data: ext.Data}
+	case *clpi.ExtensionMakersPrivateData:
+		return "makers_private_data", MakersPrivateDataJSON(ext.MakersPrivateData)
+	case *clpi.ExtensionExtentStartPoints:
+		points := make([]uint32, len(ext.PointEntries))
+		for i, pnt := range ext.PointEntries {
+			points[i] = pnt.Point
+		}
+		return "extent_start_points", &extentStartPointsJSON{Length: ext.Length, Points: points}
+	case *clpi.ExtensionProgramInfoSS:
+		return "program_info_ss", ProgramInfoJSON(ext)
+	case *clpi.ExtensionCPISS:
+		return "cpi_ss", CPIJSON(ext)
+	}
+	return "", nil
+}
+
+func MakersPrivateDataJSON(mpd *avchd.MakersPrivateData) *makersPrivateDataJSON {
+	out := &makersPrivateDataJSON{Entries: make([]*makerEntryJSON, len(mpd.Entries))}
+	for i, entry := range mpd.Entries {
+		metadata := entry.Metadata()
+		out.Entries[i] = &makerEntryJSON{
+			Maker:     jsonout.NewEnum(entry.MakerID, avchd.MakerName(entry.MakerID)),
+			ModelCode: entry.MakerModelCode,
+			DST:       metadata.DST,
+			Data:      entry.Data,
+		}
+		if !metadata.RecordingTime.IsZero() {
+			out.Entries[i].RecordingTime = metadata.RecordingTime.Format(time.RFC3339)
+		}
+		if gps := metadata.GPS; gps != nil {
+			out.Entries[i].GPS = &gpsJSON{Latitude: gps.Latitude, Longitude: gps.Longitude, Altitude: gps.Altitude, Time: gps.Time.Seconds()}
+		}
+	}
+	return out
+}
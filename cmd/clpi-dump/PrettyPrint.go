@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/parasense/bdmv_go/pkg/avchd"
 	clpi "github.com/parasense/bdmv_go/pkg/clpi"
 )
 
@@ -239,6 +241,10 @@ func ExtensionEntryMetaDataPrint(entryMetaData *clpi.ExtensionEntryMetaData) {
 
 func ExtensionsEntryDataPrint(entryData clpi.ExtensionEntryData) {
 	switch entryType := entryData.(type) {
+	case *clpi.ExtensionRaw:
+		ExtensionRawPrint(entryType)
+	case *clpi.ExtensionMakersPrivateData:
+		ExtensionMakersPrivateDataPrint(entryType)
 
 	case *clpi.ExtensionLPCMDownMixCoefficient:
 		//ExtensionLPCMDownMixCoefficientPrint(entryType)
@@ -254,6 +260,27 @@ func ExtensionsEntryDataPrint(entryData clpi.ExtensionEntryData) {
 	}
 }
 
+func ExtensionRawPrint(ext *clpi.ExtensionRaw) {
+	PadPrintln(4, "ExtensionRaw:")
+	PadPrintf(6, "Length: %d\n", len(ext.Data))
+	PadPrintf(6, "Data: % X\n", ext.Data)
+}
+
+func ExtensionMakersPrivateDataPrint(ext *clpi.ExtensionMakersPrivateData) {
+	PadPrintln(4, "ExtensionMakersPrivateData:")
+	for i, entry := range ext.Entries {
+		metadata := entry.Metadata()
+		PadPrintf(6, "Maker [%d]: %s, model 0x%04X\n", i+1, avchd.MakerName(entry.MakerID), entry.MakerModelCode)
+		if !metadata.RecordingTime.IsZero() {
+			PadPrintf(8, "RecordingTime: %s (DST: %t)\n", metadata.RecordingTime.Format(time.RFC3339), metadata.DST)
+		}
+		if metadata.GPS != nil {
+			PadPrintf(8, "GPS: %s\n", metadata.GPS)
+		}
+		PadPrintf(8, "Data: %d bytes, %d packs\n", len(entry.Data), len(metadata.Packs))
+	}
+}
+
 func ExtensionExtentStartPointsPrint(ext *clpi.ExtensionExtentStartPoints) {
 	PadPrintln(4, "ExtensionExtentStartPoints:")
 	PadPrintf(6, "Length: %d\n", ext.Length)
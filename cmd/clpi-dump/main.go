@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	clpi "github.com/parasense/bdmv_go/pkg/clpi"
 )
 
@@ -18,18 +20,43 @@ func PadPrintln(indent int, args ...any) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: clpi-parser <clpi-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: clpi-parser [--format=text|json] <clpi-file>")
 		os.Exit(1)
 	}
 
-	clpiPath := os.Args[1]
+	clpiPath := flag.Arg(0)
 	header, clipinfo, sequenceinfo, programinfo, cpi, clipMarks, extensions, err := clpi.ParseCLPI(clpiPath)
 	if err != nil {
 		fmt.Printf("Error parsing CLPI file: %+v\n", err)
 		os.Exit(1)
 	}
 
+	if *format == jsonout.FormatJSON {
+		out := &clpiJSON{
+			Header:       HeaderJSON(header),
+			ClipInfo:     ClipInfoJSON(clipinfo),
+			SequenceInfo: SequenceInfoJSON(sequenceinfo),
+			ProgramInfo:  ProgramInfoJSON(programinfo),
+			CPI:          CPIJSON(cpi),
+			ClipMarks:    ClipMarksJSON(clipMarks),
+		}
+		if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
+			out.Extensions = ExtensionsJSON(extensions)
+		}
+		if err := jsonout.Write(os.Stdout, clpiSchema, clpiPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	PadPrintf(0, "CLPI File: %s\n", clpiPath)
 	PadPrintln(0, "")
 	HeaderPrint(header)
@@ -0,0 +1,37 @@
+package main
+
+import (
+	fontdir "github.com/parasense/bdmv_go/pkg/fontdir"
+)
+
+const fontdirSchema = "fontdir/1"
+
+type fontJSON struct {
+	Name       string        `json:"name"`
+	FontFormat string        `json:"font_format"`
+	Filename   string        `json:"filename"`
+	Styles     []string      `json:"styles"`
+	Size       *fontSizeJSON `json:"size,omitempty"`
+}
+
+type fontSizeJSON struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+func FontDirectoryJSON(fontDir *fontdir.FontDirectory) []*fontJSON {
+	out := make([]*fontJSON, len(fontDir.Fonts))
+	for i, font := range fontDir.Fonts {
+		fontOut := &fontJSON{
+			Name:       font.Name,
+			FontFormat: font.FontFormat,
+			Filename:   font.Filename,
+			Styles:     append([]string{}, font.Styles...),
+		}
+		if font.Size != nil {
+			fontOut.Size = &fontSizeJSON{Min: font.Size.Min, Max: font.Size.Max}
+		}
+		out[i] = fontOut
+	}
+	return out
+}
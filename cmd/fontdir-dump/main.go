@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	fontdir "github.com/parasense/bdmv_go/pkg/fontdir"
 )
 
@@ -23,15 +25,28 @@ func PrintFontDirectory(fontDir *fontdir.FontDirectory) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run fontdir-dump <path-to-xml-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		log.Fatal("Usage: go run fontdir-dump [--format=text|json] <path-to-xml-file>")
 	}
 
-	filePath := os.Args[1]
+	filePath := flag.Arg(0)
 	fontDir, err := fontdir.ParseFontDirectory(filePath)
 	if err != nil {
 		log.Fatalf("Error parsing font directory: %v", err)
 	}
 
+	if *format == jsonout.FormatJSON {
+		if err := jsonout.Write(os.Stdout, fontdirSchema, filePath, FontDirectoryJSON(fontDir)); err != nil {
+			log.Fatalf("Error writing JSON: %v", err)
+		}
+		return
+	}
+
 	PrintFontDirectory(fontDir)
 }
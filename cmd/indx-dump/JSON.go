@@ -0,0 +1,177 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+	indx "github.com/parasense/bdmv_go/pkg/indx"
+)
+
+const indxSchema = "indx/1"
+
+type indxJSON struct {
+	Header     *headerJSON     `json:"header"`
+	AppInfo    *appInfoJSON    `json:"app_info"`
+	Indexes    *indexesJSON    `json:"indexes"`
+	Extensions *extensionsJSON `json:"extensions"`
+}
+
+type headerJSON struct {
+	Type       string          `json:"type"`
+	Version    string          `json:"version"`
+	AppInfo    jsonout.Offsets `json:"app_info"`
+	Indexes    jsonout.Offsets `json:"indexes"`
+	Extensions jsonout.Offsets `json:"extensions"`
+}
+
+type appInfoJSON struct {
+	Length                      uint32       `json:"length"`
+	InitialOutputModePreference bool         `json:"initial_output_mode_preference"`
+	SSContentExistFlag          bool         `json:"ss_content_exist_flag"`
+	InitialDynamicRangeType     uint8        `json:"initial_dynamic_range_type"`
+	VideoFormat                 jsonout.Enum `json:"video_format"`
+	FrameRate                   jsonout.Enum `json:"frame_rate"`
+	UserData                    jsonout.Hex  `json:"user_data"`
+}
+
+type indexesJSON struct {
+	Length             uint32       `json:"length"`
+	FirstPlaybackTitle *titleJSON   `json:"first_playback"`
+	TopMenuTitle       *titleJSON   `json:"top_menu"`
+	Titles             []*titleJSON `json:"titles"`
+}
+
+// titleJSON carries movie_object_id for HDMV titles (object_type 1)
+// and bdj_object_id for BD-J titles (object_type 2).
+type titleJSON struct {
+	ObjectType    uint8   `json:"object_type"`
+	AccessType    uint8   `json:"access_type"`
+	PlaybackType  uint8   `json:"playback_type"`
+	MovieObjectID *uint16 `json:"movie_object_id,omitempty"`
+	BDJObjectID   *string `json:"bdj_object_id,omitempty"`
+}
+
+type extensionsJSON struct {
+	Length             uint32           `json:"length"`
+	EntryDataStartAddr uint32           `json:"entry_data_start_addr"`
+	Entries            []*extensionJSON `json:"entries"`
+}
+
+type extensionJSON struct {
+	Type         uint16 `json:"type"`
+	Version      uint16 `json:"version"`
+	StartAddress uint32 `json:"start_address"`
+	Length       uint32 `json:"length"`
+	Kind         string `json:"kind"`
+	Data         any    `json:"data"`
+}
+
+type extensionRawJSON struct {
+	Data jsonout.Hex `json:"data"`
+}
+
+type extensionHEVCJSON struct {
+	Length          uint32 `json:"length"`
+	DiscType        uint8  `json:"disc_type"`
+	Exists4KFlag    bool   `json:"exists_4k_flag"`
+	HDRPlusFlag     bool   `json:"hdr_plus_flag"`
+	DolbyVisionFlag bool   `json:"dolby_vision_flag"`
+	HDRFlag         uint8  `json:"hdr_flag"`
+}
+
+func HeaderJSON(header *indx.INDXHeader) *headerJSON {
+	return &headerJSON{
+		Type:       string(header.TypeIndicator[:]),
+		Version:    string(header.VersionNumber[:]),
+		AppInfo:    jsonout.Offsets{Start: header.AppInfo.Start, Stop: header.AppInfo.Stop},
+		Indexes:    jsonout.Offsets{Start: header.Indexes.Start, Stop: header.Indexes.Stop},
+		Extensions: jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+// AppInfoJSON decodes the video format and frame rate with the same tables
+// the clip information uses.
+func AppInfoJSON(appinfo *indx.AppInfo) *appInfoJSON {
+	videoFormat := clpi.VideoFormatType(appinfo.VideoFormat)
+	frameRate := clpi.VideoRateType(appinfo.FrameRate)
+	return &appInfoJSON{
+		Length:                      appinfo.Length,
+		InitialOutputModePreference: appinfo.InitialOutputModePreference,
+		SSContentExistFlag:          appinfo.SSContentExistFlag,
+		InitialDynamicRangeType:     appinfo.InitialDynamicRangeType,
+		VideoFormat:                 jsonout.NewEnum(videoFormat, clpi.VideoFormat(videoFormat)),
+		FrameRate:                   jsonout.NewEnum(frameRate, clpi.VideoRate(frameRate)),
+		UserData:                    appinfo.UserData[:],
+	}
+}
+
+func IndexesJSON(indexes *indx.Indexes) *indexesJSON {
+	out := &indexesJSON{
+		Length:             indexes.Length,
+		FirstPlaybackTitle: TitleJSON(indexes.FirstPlaybackTitle),
+		TopMenuTitle:       TitleJSON(indexes.TopMenuTitle),
+		Titles:             make([]*titleJSON, len(indexes.Titles)),
+	}
+	for i, title := range indexes.Titles {
+		out.Titles[i] = TitleJSON(title)
+	}
+	return out
+}
+
+func TitleJSON(title *indx.Title) *titleJSON {
+	if title == nil {
+		return nil
+	}
+	out := &titleJSON{
+		ObjectType:   title.ObjectType,
+		AccessType:   title.AccesType,
+		PlaybackType: title.PlaybackType,
+	}
+	switch title.ObjectType {
+	case 1: // HDMV movie object
+		out.MovieObjectID = &title.RefToMovieObjectID
+	case 2: // BD-J object
+		bdjObjectID := string(title.RefToBDJObjectID[:])
+		out.BDJObjectID = &bdjObjectID
+	}
+	return out
+}
+
+func ExtensionsJSON(extensions *indx.Extensions) *extensionsJSON {
+	out := &extensionsJSON{
+		Length:             extensions.MetaData.Length,
+		EntryDataStartAddr: extensions.MetaData.EntryDataStartAddr,
+		Entries:            make([]*extensionJSON, len(extensions.EntriesMetaData)),
+	}
+	for i, metaData := range extensions.EntriesMetaData {
+		entry := &extensionJSON{
+			Type:         metaData.ExtDataType,
+			Version:      metaData.ExtDataVersion,
+			StartAddress: metaData.ExtDataStartAddress,
+			Length:       metaData.ExtDataLength,
+		}
+		if i < len(extensions.EntriesData) {
+			entry.Kind, entry.Data = ExtensionsEntryDataJSON(extensions.EntriesData[i])
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
+
+// ExtensionsEntryDataJSON returns the kind and the payload of an extension.
+func ExtensionsEntryDataJSON(entryData indx.ExtensionEntryData) (string, any) {
+	switch ext := entryData.(type) {
+	case *indx.ExtensionRaw:
+		return "raw", &extensionRawJSON{Data: ext.Data}
+	case *indx.ExtensionHEVC:
+		out := &extensionHEVCJSON{Length: ext.Length}
+		if ext.HEVCEntry != nil {
+			out.DiscType = ext.HEVCEntry.DiscType
+			out.Exists4KFlag = ext.HEVCEntry.Exists4KFlag
+			out.HDRPlusFlag = ext.HEVCEntry.HDRPlusFlag
+			out.DolbyVisionFlag = ext.HEVCEntry.DolbyVisionFlag
+			out.HDRFlag = ext.HEVCEntry.HDRFlag
+		}
+		return "hevc", out
+	}
+	return "", nil
+}
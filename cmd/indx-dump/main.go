@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	indx "github.com/parasense/bdmv_go/pkg/indx"
 )
 
@@ -18,18 +20,40 @@ func PadPrintln(indent int, args ...any) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: indx-parser <indx-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: indx-parser [--format=text|json] <indx-file>")
 		os.Exit(1)
 	}
 
-	indxPath := os.Args[1]
+	indxPath := flag.Arg(0)
 	header, appinfo, indexes, extData, err := indx.ParseINDX(indxPath)
 	if err != nil {
 		fmt.Printf("Error parsing INDX file: %+v\n", err)
 		os.Exit(1)
 	}
 
+	if *format == jsonout.FormatJSON {
+		out := &indxJSON{
+			Header:  HeaderJSON(header),
+			AppInfo: AppInfoJSON(appinfo),
+			Indexes: IndexesJSON(indexes),
+		}
+		if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
+			out.Extensions = ExtensionsJSON(extData)
+		}
+		if err := jsonout.Write(os.Stdout, indxSchema, indxPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	PadPrintf(0, "INDX File: %s\n", indxPath)
 
 	if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
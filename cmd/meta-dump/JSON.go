@@ -0,0 +1,78 @@
+package main
+
+import (
+	meta "github.com/parasense/bdmv_go/pkg/meta"
+)
+
+const metaSchema = "meta/1"
+
+type discLibJSON struct {
+	Title           string           `json:"title"`
+	NumSets         *int             `json:"num_sets,omitempty"`
+	SetNumber       *int             `json:"set_number,omitempty"`
+	Language        *string          `json:"language,omitempty"`
+	Rights          *string          `json:"rights,omitempty"`
+	Thumbnails      []*thumbnailJSON `json:"thumbnails"`
+	TableOfContents []*titleNameJSON `json:"table_of_contents"`
+	TitleInfos      []*titleInfoJSON `json:"title_infos"`
+}
+
+type thumbnailJSON struct {
+	Href string  `json:"href"`
+	Size *string `json:"size,omitempty"`
+}
+
+type titleNameJSON struct {
+	TitleNumber string `json:"title_number"`
+	Name        string `json:"name"`
+}
+
+type titleInfoJSON struct {
+	Name        string  `json:"name"`
+	RepTitle    *bool   `json:"rep_title,omitempty"`
+	Actor       *string `json:"actor,omitempty"`
+	Editor      *string `json:"editor,omitempty"`
+	AspectRatio *string `json:"aspect_ratio,omitempty"`
+}
+
+func DiscLibJSON(discLib *meta.DiscLib) *discLibJSON {
+	discInfo := &discLib.DiscInfo
+	out := &discLibJSON{
+		Title:           discInfo.Title.Name,
+		NumSets:         discInfo.Title.NumSets,
+		SetNumber:       discInfo.Title.SetNumber,
+		Language:        discInfo.Language,
+		Rights:          discInfo.Rights,
+		Thumbnails:      make([]*thumbnailJSON, len(discInfo.Description.Thumbnails)),
+		TableOfContents: []*titleNameJSON{},
+		TitleInfos:      make([]*titleInfoJSON, len(discLib.TitleInfos)),
+	}
+	for i, thumb := range discInfo.Description.Thumbnails {
+		out.Thumbnails[i] = &thumbnailJSON{Href: thumb.Href, Size: thumb.Size}
+	}
+	if discInfo.Description.TableOfContents != nil {
+		for _, title := range discInfo.Description.TableOfContents.TitleNames {
+			out.TableOfContents = append(out.TableOfContents, &titleNameJSON{
+				TitleNumber: title.TitleNumber,
+				Name:        title.Name,
+			})
+		}
+	}
+	for i, ti := range discLib.TitleInfos {
+		tiOut := &titleInfoJSON{
+			Name:     ti.Title.Name,
+			RepTitle: ti.Title.RepTitle,
+		}
+		if ti.Creator != nil {
+			tiOut.Actor = ti.Creator.Actor
+		}
+		if ti.Contributor != nil {
+			tiOut.Editor = ti.Contributor.Editor
+		}
+		if ti.Format != nil {
+			tiOut.AspectRatio = ti.Format.AspectRatio
+		}
+		out.TitleInfos[i] = tiOut
+	}
+	return out
+}
@@ -1,24 +1,40 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	meta "github.com/parasense/bdmv_go/pkg/meta"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: mobj-dump <mobj-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: meta-dump [--format=text|json] <bdmt-xml-file>")
 		os.Exit(1)
 	}
 
-	metaPath := os.Args[1]
+	metaPath := flag.Arg(0)
 
 	discLib, err := meta.ParseMETA(metaPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", metaPath, err)
+		os.Exit(1)
+	}
 
+	if *format == jsonout.FormatJSON {
+		if err := jsonout.Write(os.Stdout, metaSchema, metaPath, DiscLibJSON(discLib)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Print parsed data
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	mobj "github.com/parasense/bdmv_go/pkg/mobj"
+)
+
+func main() {
+	output := flag.String("o", "MovieObject.bdmv", "file to write")
+	version := flag.String("version", "0200", "version number of the written file: 0100 or 0200, over that of -base")
+	base := flag.String("base", "", "MovieObject.bdmv to take the version and extension data from")
+	flag.Parse()
+
+	// An explicit -version wins over the version of -base.
+	versionSet := false
+	flag.Visit(func(f *flag.Flag) {
+		versionSet = versionSet || f.Name == "version"
+	})
+	if flag.NArg() < 1 || len(*version) != 4 {
+		fmt.Println("Usage: mobj-asm [-o MovieObject.bdmv] [-version 0200] [-base <mobj-file>] <listing>")
+		os.Exit(1)
+	}
+
+	listingPath := flag.Arg(0)
+	listing, err := os.Open(listingPath)
+	if err != nil {
+		fmt.Printf("Error opening listing: %+v\n", err)
+		os.Exit(1)
+	}
+	movieObjects, err := mobj.Assemble(listing)
+	listing.Close()
+	if err != nil {
+		fmt.Printf("Error assembling %s: %+v\n", listingPath, err)
+		os.Exit(1)
+	}
+
+	header := &mobj.MOBJHeader{TypeIndicator: [4]byte{'M', 'O', 'B', 'J'}}
+	var extensions *mobj.Extensions
+	if *base != "" {
+		if header, _, extensions, err = mobj.ParseMOBJ(*base); err != nil {
+			fmt.Printf("Error parsing MOBJ file: %+v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *base == "" || versionSet {
+		copy(header.VersionNumber[:], *version)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Printf("Error creating output: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := mobj.WriteMOBJ(file, header, movieObjects, extensions); err != nil {
+		file.Close()
+		fmt.Printf("Error writing %s: %+v\n", *output, err)
+		os.Exit(1)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Printf("Error writing %s: %+v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: %d movie objects\n", *output, len(movieObjects.MovieObjects))
+}
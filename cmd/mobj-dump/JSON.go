@@ -0,0 +1,145 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	foo "github.com/parasense/bdmv_go/pkg/mobj"
+)
+
+const mobjSchema = "mobj/1"
+
+type mobjJSON struct {
+	Header       *headerJSON       `json:"header"`
+	MovieObjects *movieObjectsJSON `json:"movie_objects"`
+	Extensions   *extensionsJSON   `json:"extensions"`
+}
+
+type headerJSON struct {
+	Type         string          `json:"type"`
+	Version      string          `json:"version"`
+	MovieObjects jsonout.Offsets `json:"movie_objects"`
+	Extensions   jsonout.Offsets `json:"extensions"`
+}
+
+type movieObjectsJSON struct {
+	Length  uint32             `json:"length"`
+	Objects []*movieObjectJSON `json:"objects"`
+}
+
+type movieObjectJSON struct {
+	ResumeIntentionFlag bool                     `json:"resume_intention_flag"`
+	MenuCallMask        bool                     `json:"menu_call_mask"`
+	TitleSearchMask     bool                     `json:"title_search_mask"`
+	Commands            []*navigationCommandJSON `json:"commands"`
+}
+
+// navigationCommandJSON holds the raw opcode fields, the mnemonic
+// decoded from them, which is empty for an unknown opcode, and the
+// disassembled command.
+type navigationCommandJSON struct {
+	Mnemonic               string `json:"mnemonic"`
+	Assembly               string `json:"assembly"`
+	OperandCount           uint8  `json:"operand_count"`
+	CommandGroup           uint8  `json:"command_group"`
+	CommandSubGroup        uint8  `json:"command_sub_group"`
+	ImmediateValueFlagDest bool   `json:"immediate_value_flag_dest"`
+	ImmediateValueFlagSrc  bool   `json:"immediate_value_flag_src"`
+	BranchOption           uint8  `json:"branch_option"`
+	CompareOption          uint8  `json:"compare_option"`
+	SetOption              uint8  `json:"set_option"`
+	Destination            uint32 `json:"destination"`
+	Source                 uint32 `json:"source"`
+}
+
+type extensionsJSON struct {
+	Length             uint32           `json:"length"`
+	EntryDataStartAddr uint32           `json:"entry_data_start_addr"`
+	Entries            []*extensionJSON `json:"entries"`
+}
+
+type extensionJSON struct {
+	Type         uint16 `json:"type"`
+	Version      uint16 `json:"version"`
+	StartAddress uint32 `json:"start_address"`
+	Length       uint32 `json:"length"`
+	Kind         string `json:"kind"`
+	Data         any    `json:"data"`
+}
+
+type extensionRawJSON struct {
+	Data jsonout.Hex `json:"data"`
+}
+
+func HeaderJSON(header *foo.MOBJHeader) *headerJSON {
+	return &headerJSON{
+		Type:         string(header.TypeIndicator[:]),
+		Version:      string(header.VersionNumber[:]),
+		MovieObjects: jsonout.Offsets{Start: header.MovieObjects.Start, Stop: header.MovieObjects.Stop},
+		Extensions:   jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+func MovieObjectsJSON(movieObjects *foo.MovieObjects) *movieObjectsJSON {
+	out := &movieObjectsJSON{
+		Length:  movieObjects.Length,
+		Objects: make([]*movieObjectJSON, len(movieObjects.MovieObjects)),
+	}
+	for i, mobj := range movieObjects.MovieObjects {
+		objOut := &movieObjectJSON{
+			ResumeIntentionFlag: mobj.ResumeIntentionFlag,
+			MenuCallMask:        mobj.MenuCallMask,
+			TitleSearchMask:     mobj.TitleSearchMask,
+			Commands:            make([]*navigationCommandJSON, len(mobj.NavigationCommands)),
+		}
+		for j, nav := range mobj.NavigationCommands {
+			objOut.Commands[j] = NavigationCommandJSON(nav)
+		}
+		out.Objects[i] = objOut
+	}
+	return out
+}
+
+func NavigationCommandJSON(nav *foo.NavigationCommand) *navigationCommandJSON {
+	return &navigationCommandJSON{
+		Mnemonic: foo.GetCommand(
+			nav.CommandGroup,
+			nav.CommandSubGroup,
+			nav.BranchOption,
+			nav.CompareOption,
+			nav.SetOption,
+		),
+		Assembly:               nav.Disassemble(),
+		OperandCount:           nav.OperandCount,
+		CommandGroup:           nav.CommandGroup,
+		CommandSubGroup:        nav.CommandSubGroup,
+		ImmediateValueFlagDest: nav.ImmediateValueFlagDest,
+		ImmediateValueFlagSrc:  nav.ImmediateValueFlagSrc,
+		BranchOption:           nav.BranchOption,
+		CompareOption:          nav.CompareOption,
+		SetOption:              nav.SetOption,
+		Destination:            nav.Destination,
+		Source:                 nav.Source,
+	}
+}
+
+func ExtensionsJSON(extensions *foo.Extensions) *extensionsJSON {
+	out := &extensionsJSON{
+		Length:             extensions.MetaData.Length,
+		EntryDataStartAddr: extensions.MetaData.EntryDataStartAddr,
+		Entries:            make([]*extensionJSON, len(extensions.EntriesMetaData)),
+	}
+	for i, metaData := range extensions.EntriesMetaData {
+		entry := &extensionJSON{
+			Type:         metaData.ExtDataType,
+			Version:      metaData.ExtDataVersion,
+			StartAddress: metaData.ExtDataStartAddress,
+			Length:       metaData.ExtDataLength,
+		}
+		if i < len(extensions.EntriesData) {
+			if raw, ok := extensions.EntriesData[i].(*foo.ExtensionRaw); ok {
+				entry.Kind, entry.Data = "raw", &extensionRawJSON{Data: raw.Data}
+			}
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
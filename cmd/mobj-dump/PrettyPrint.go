@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	foo "github.com/parasense/bdmv_go/pkg/mobj"
 )
@@ -38,15 +39,7 @@ func ClipInfoPrint(movieObjects *foo.MovieObjects) {
 			PadPrintf(10, "SetOption: %d\n", nav.SetOption)
 			PadPrintf(10, "Destination: %d\n", nav.Destination)
 			PadPrintf(10, "Source: %d\n", nav.Source)
-			PadPrintf(8, "CMD: %s %+v, %+v\n",
-				foo.GetCommand(
-					nav.CommandGroup,
-					nav.CommandSubGroup,
-					nav.BranchOption,
-					nav.CompareOption,
-					nav.SetOption,
-				), nav.Destination, nav.Source,
-			)
+			PadPrintf(8, "CMD: %s\n", nav.Disassemble())
 			PadPrintln(8, "---")
 		}
 		PadPrintln(4, "---")
@@ -54,6 +47,19 @@ func ClipInfoPrint(movieObjects *foo.MovieObjects) {
 	PadPrintln(2, "---")
 }
 
+// ListingPrint prints every movie object as a program listing.
+func ListingPrint(movieObjects *foo.MovieObjects) error {
+	for i, mobj := range movieObjects.MovieObjects {
+		if i > 0 {
+			PadPrintln(0)
+		}
+		if err := mobj.WriteListing(os.Stdout, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func ExtensionsPrint(extensions *foo.Extensions) {
 	PadPrintln(0)
 	PadPrintln(0, "Extensions:")
@@ -90,24 +96,14 @@ func ExtensionEntryMetaDataPrint(entryMetaData *foo.ExtensionEntryMetaData) {
 }
 
 func ExtensionsEntryDataPrint(entryData foo.ExtensionEntryData) {
-	/*
-	   switch entryType := entryData.(type) {
-
-	   case //*clpi.ExtensionLPCMDownMixCoefficient:
-
-	   	//ExtensionLPCMDownMixCoefficientPrint(entryType)
-
-	   case //*clpi.ExtensionExtentStartPoints:
-
-	   	//ExtensionExtentStartPointsPrint(entryType)
-
-	   case //*clpi.ExtensionProgramInfoSS:
-
-	   	//ExtensionProgramInfoSSPrint(entryType)
-
-	   case //*clpi.ExtensionCPISS:
+	switch entryType := entryData.(type) {
+	case *foo.ExtensionRaw:
+		ExtensionRawPrint(entryType)
+	}
+}
 
-	   		//ExtensionCPISSPrint(entryType)
-	   	}
-	*/
+func ExtensionRawPrint(ext *foo.ExtensionRaw) {
+	PadPrintln(4, "ExtensionRaw:")
+	PadPrintf(6, "Length: %d\n", len(ext.Data))
+	PadPrintf(6, "Data: % X\n", ext.Data)
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	mobj "github.com/parasense/bdmv_go/pkg/mobj"
 )
 
@@ -18,18 +20,48 @@ func PadPrintln(indent int, args ...any) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: mobj-dump <mobj-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	listing := flag.Bool("listing", false, "print the movie objects as a program listing")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: mobj-dump [--format=text|json] [--listing] <mobj-file>")
 		os.Exit(1)
 	}
 
-	mobjPath := os.Args[1]
+	mobjPath := flag.Arg(0)
 	header, movieObjects, extensions, err := mobj.ParseMOBJ(mobjPath)
 	if err != nil {
 		fmt.Printf("Error parsing CLPI file: %+v\n", err)
 		os.Exit(1)
 	}
 
+	if *format == jsonout.FormatJSON {
+		out := &mobjJSON{
+			Header:       HeaderJSON(header),
+			MovieObjects: MovieObjectsJSON(movieObjects),
+		}
+		if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
+			out.Extensions = ExtensionsJSON(extensions)
+		}
+		if err := jsonout.Write(os.Stdout, mobjSchema, mobjPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *listing {
+		if err := ListingPrint(movieObjects); err != nil {
+			fmt.Printf("Error writing listing: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	PadPrintf(0, "MOBJ File: %s\n", mobjPath)
 	PadPrintln(0, "")
 	HeaderPrint(header)
@@ -0,0 +1,695 @@
+package main
+
+import (
+	"time"
+
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+const mplsSchema = "mpls/1"
+
+type mplsJSON struct {
+	Header     *headerJSON     `json:"header"`
+	AppInfo    *appInfoJSON    `json:"app_info"`
+	PlayList   *playListJSON   `json:"playlist"`
+	Marks      []*markJSON     `json:"marks"`
+	Extensions *extensionsJSON `json:"extensions"`
+}
+
+type headerJSON struct {
+	Type       string          `json:"type"`
+	Version    string          `json:"version"`
+	AppInfo    jsonout.Offsets `json:"app_info"`
+	PlayList   jsonout.Offsets `json:"playlist"`
+	Marks      jsonout.Offsets `json:"marks"`
+	Extensions jsonout.Offsets `json:"extensions"`
+}
+
+type appInfoJSON struct {
+	Length                        uint32           `json:"length"`
+	PlaybackType                  uint8            `json:"playback_type"`
+	PlaybackCount                 uint16           `json:"playback_count"`
+	UserOptions                   *userOptionsJSON `json:"user_options"`
+	RandomAccessFlag              bool             `json:"random_access_flag"`
+	AudioMixFlag                  bool             `json:"audio_mix_flag"`
+	LosslessBypassFlag            bool             `json:"lossless_bypass_flag"`
+	MVCBaseViewRFlag              bool             `json:"mvc_base_view_r_flag"`
+	SDRConversionNotificationFlag bool             `json:"sdr_conversion_notification_flag"`
+}
+
+// userOptionsJSON holds the UO mask; true means the operation is prohibited.
+type userOptionsJSON struct {
+	MenuCall                         bool `json:"menu_call"`
+	TitleSearch                      bool `json:"title_search"`
+	ChapterSearch                    bool `json:"chapter_search"`
+	TimeSearch                       bool `json:"time_search"`
+	SkipToNextPoint                  bool `json:"skip_to_next_point"`
+	SkipToPrevPoint                  bool `json:"skip_to_prev_point"`
+	PlayFirstPlay                    bool `json:"play_first_play"`
+	Stop                             bool `json:"stop"`
+	PauseOn                          bool `json:"pause_on"`
+	PauseOff                         bool `json:"pause_off"`
+	StillOff                         bool `json:"still_off"`
+	ForwardPlay                      bool `json:"forward_play"`
+	BackwardPlay                     bool `json:"backward_play"`
+	Resume                           bool `json:"resume"`
+	MoveUpSelectedButton             bool `json:"move_up_selected_button"`
+	MoveDownSelectedButton           bool `json:"move_down_selected_button"`
+	MoveLeftSelectedButton           bool `json:"move_left_selected_button"`
+	MoveRightSelectedButton          bool `json:"move_right_selected_button"`
+	SelectButton                     bool `json:"select_button"`
+	ActivateButton                   bool `json:"activate_button"`
+	SelectAndActivateButton          bool `json:"select_and_activate_button"`
+	PrimaryAudioStreamNumberChange   bool `json:"primary_audio_stream_number_change"`
+	AngleNumberChange                bool `json:"angle_number_change"`
+	PopupOn                          bool `json:"popup_on"`
+	PopupOff                         bool `json:"popup_off"`
+	PrimaryPGEnableDisable           bool `json:"primary_pg_enable_disable"`
+	PrimaryPGStreamNumberChange      bool `json:"primary_pg_stream_number_change"`
+	SecondaryVideoEnableDisable      bool `json:"secondary_video_enable_disable"`
+	SecondaryVideoStreamNumberChange bool `json:"secondary_video_stream_number_change"`
+	SecondaryAudioEnableDisable      bool `json:"secondary_audio_enable_disable"`
+	SecondaryAudioStreamNumberChange bool `json:"secondary_audio_stream_number_change"`
+	SecondaryPGStreamNumberChange    bool `json:"secondary_pg_stream_number_change"`
+}
+
+type playListJSON struct {
+	Length    uint32          `json:"length"`
+	PlayItems []*playItemJSON `json:"play_items"`
+	SubPaths  []*subPathJSON  `json:"sub_paths"`
+}
+
+type playItemJSON struct {
+	Length                   uint16               `json:"length"`
+	ClipInformationFileName  string               `json:"clip_information_file_name"`
+	ClipCodecIdentifier      string               `json:"clip_codec_identifier"`
+	IsMultiAngle             bool                 `json:"is_multi_angle"`
+	ConnectionCondition      uint8                `json:"connection_condition"`
+	RefToSTCID               uint8                `json:"ref_to_stc_id"`
+	INTime                   uint32               `json:"in_time"`
+	OUTTime                  uint32               `json:"out_time"`
+	UserOptions              *userOptionsJSON     `json:"user_options"`
+	PlayItemRandomAccessFlag bool                 `json:"play_item_random_access_flag"`
+	StillMode                uint8                `json:"still_mode"`
+	StillTime                uint16               `json:"still_time"`
+	IsDifferentAudios        bool                 `json:"is_different_audios"`
+	IsSeamlessAngleChange    bool                 `json:"is_seamless_angle_change"`
+	Angles                   []*playItemEntryJSON `json:"angles"`
+	StreamTable              *streamTableJSON     `json:"stream_table"`
+}
+
+type playItemEntryJSON struct {
+	FileName   string `json:"file_name"`
+	Codec      string `json:"codec"`
+	RefToSTCID uint8  `json:"ref_to_stc_id"`
+}
+
+type subPathJSON struct {
+	Length          uint32             `json:"length"`
+	SubPathType     jsonout.Enum       `json:"sub_path_type"`
+	IsRepeatSubPath bool               `json:"is_repeat_sub_path"`
+	SubPlayItems    []*subPlayItemJSON `json:"sub_play_items"`
+}
+
+type subPlayItemJSON struct {
+	Length              uint16               `json:"length"`
+	FileName            string               `json:"file_name"`
+	Codec               string               `json:"codec"`
+	ConnectionCondition uint8                `json:"connection_condition"`
+	IsMultiClipEntries  bool                 `json:"is_multi_clip_entries"`
+	RefToSTCID          uint8                `json:"ref_to_stc_id"`
+	INTime              uint32               `json:"in_time"`
+	OUTTime             uint32               `json:"out_time"`
+	SyncPlayItemID      uint16               `json:"sync_play_item_id"`
+	SyncStartPTS        uint32               `json:"sync_start_pts"`
+	MultiClipEntries    []*playItemEntryJSON `json:"multi_clip_entries"`
+}
+
+type streamTableJSON struct {
+	Length uint16            `json:"length"`
+	Items  []*streamItemJSON `json:"items"`
+}
+
+type streamItemJSON struct {
+	Kind    string        `json:"kind"`
+	Streams []*streamJSON `json:"streams"`
+}
+
+type streamJSON struct {
+	Entry      *streamEntryJSON `json:"entry"`
+	Attributes *streamAttrJSON  `json:"attributes"`
+}
+
+// streamEntryJSON covers the three stream entry types; the stream_type code
+// tells which of the reference fields are present.
+type streamEntryJSON struct {
+	Length         uint8        `json:"length"`
+	StreamType     jsonout.Enum `json:"stream_type"`
+	RefToSubPathID *uint8       `json:"ref_to_sub_path_id,omitempty"`
+	RefToSubClipID *uint8       `json:"ref_to_sub_clip_id,omitempty"`
+	RefToStreamPID uint16       `json:"ref_to_stream_pid"`
+}
+
+// streamAttrJSON covers every stream attributes type. Kind names the type
+// and decides which of the optional fields are present.
+type streamAttrJSON struct {
+	Kind               string            `json:"kind"`
+	Length             uint8             `json:"length"`
+	StreamCodingType   jsonout.Enum      `json:"stream_coding_type"`
+	VideoFormat        *jsonout.Enum     `json:"video_format,omitempty"`
+	VideoRate          *jsonout.Enum     `json:"video_rate,omitempty"`
+	DynamicRangeType   *uint8            `json:"dynamic_range_type,omitempty"`
+	ColorSpace         *uint8            `json:"color_space,omitempty"`
+	CRFlag             *bool             `json:"cr_flag,omitempty"`
+	HDRPlusFlag        *bool             `json:"hdr_plus_flag,omitempty"`
+	AudioFormat        *jsonout.Enum     `json:"audio_format,omitempty"`
+	AudioRate          *jsonout.Enum     `json:"audio_rate,omitempty"`
+	CharacterCode      *jsonout.Enum     `json:"character_code,omitempty"`
+	Language           *jsonout.Language `json:"language,omitempty"`
+	PrimaryAudioRefs   []int             `json:"primary_audio_refs,omitempty"`
+	SecondaryAudioRefs []int             `json:"secondary_audio_refs,omitempty"`
+	PIPPGRefs          []int             `json:"pip_pg_refs,omitempty"`
+}
+
+type markJSON struct {
+	MarkType        uint8  `json:"mark_type"`
+	RefToPlayItemID uint16 `json:"ref_to_play_item_id"`
+	MarkTimeStamp   uint32 `json:"mark_time_stamp"`
+	EntryESPID      uint16 `json:"entry_es_pid"`
+	Duration        uint32 `json:"duration"`
+}
+
+type extensionsJSON struct {
+	Length             uint32           `json:"length"`
+	EntryDataStartAddr uint32           `json:"entry_data_start_addr"`
+	Entries            []*extensionJSON `json:"entries"`
+}
+
+type extensionJSON struct {
+	Type         uint16 `json:"type"`
+	Version      uint16 `json:"version"`
+	StartAddress uint32 `json:"start_address"`
+	Length       uint32 `json:"length"`
+	Kind         string `json:"kind"`
+	Data         any    `json:"data"`
+}
+
+type extensionRawJSON struct {
+	Data jsonout.Hex `json:"data"`
+}
+
+type makersPrivateDataJSON struct {
+	Entries []*makerEntryJSON `json:"entries"`
+}
+
+type makerEntryJSON struct {
+	Maker         jsonout.Enum `json:"maker"`
+	ModelCode     uint16       `json:"model_code"`
+	RecordingTime string       `json:"recording_time,omitempty"` // RFC 3339
+	DST           bool         `json:"dst"`
+	GPS           *gpsJSON     `json:"gps,omitempty"`
+	Data          jsonout.Hex  `json:"data"`
+}
+
+type gpsJSON struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+	Time      float64 `json:"time"` // UTC seconds since midnight
+}
+
+type extensionSubPathJSON struct {
+	Length   uint32         `json:"length"`
+	SubPaths []*subPathJSON `json:"sub_paths"`
+}
+
+type mvcStreamJSON struct {
+	Length                  uint16           `json:"length"`
+	FixedOffsetPopUpFlag    bool             `json:"fixed_offset_popup_flag"`
+	Entry                   *streamEntryJSON `json:"entry"`
+	Attributes              *streamAttrJSON  `json:"attributes"`
+	NumberOfOffsetSequences uint8            `json:"number_of_offset_sequences"`
+}
+
+type extensionPIPJSON struct {
+	Length  uint32          `json:"length"`
+	Entries []*pipEntryJSON `json:"entries"`
+}
+
+type pipEntryJSON struct {
+	ClipRef           uint16              `json:"clip_ref"`
+	SecondaryVideoRef uint8               `json:"secondary_video_ref"`
+	TimelineType      uint8               `json:"timeline_type"`
+	LumaKeyFlag       bool                `json:"luma_key_flag"`
+	TrickPlayFlag     bool                `json:"trick_play_flag"`
+	UpperLimitLumaKey uint8               `json:"upper_limit_luma_key"`
+	DataAddress       uint32              `json:"data_address"`
+	Data              []*pipDataEntryJSON `json:"data"`
+}
+
+type pipDataEntryJSON struct {
+	Time        uint32       `json:"time"`
+	Xpos        uint16       `json:"xpos"`
+	Ypos        uint16       `json:"ypos"`
+	ScaleFactor jsonout.Enum `json:"scale_factor"`
+}
+
+type extensionStaticMetaDataJSON struct {
+	Length  uint32                     `json:"length"`
+	Entries []*staticMetaDataEntryJSON `json:"entries"`
+}
+
+type staticMetaDataEntryJSON struct {
+	DynamicRangeType             uint8     `json:"dynamic_range_type"`
+	DisplayPrimariesX            [3]uint16 `json:"display_primaries_x"`
+	DisplayPrimariesY            [3]uint16 `json:"display_primaries_y"`
+	WhitePointX                  uint16    `json:"white_point_x"`
+	WhitePointY                  uint16    `json:"white_point_y"`
+	MaxDisplayMasteringLuminance uint16    `json:"max_display_mastering_luminance"`
+	MinDisplayMasteringLuminance uint16    `json:"min_display_mastering_luminance"`
+	MaxCLL                       uint16    `json:"max_cll"`
+	MaxFALL                      uint16    `json:"max_fall"`
+}
+
+func HeaderJSON(header *mpls.MPLSHeader) *headerJSON {
+	return &headerJSON{
+		Type:       string(header.TypeIndicator[:]),
+		Version:    string(header.VersionNumber[:]),
+		AppInfo:    jsonout.Offsets{Start: header.AppInfo.Start, Stop: header.AppInfo.Stop},
+		PlayList:   jsonout.Offsets{Start: header.Playlist.Start, Stop: header.Playlist.Stop},
+		Marks:      jsonout.Offsets{Start: header.Marks.Start, Stop: header.Marks.Stop},
+		Extensions: jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+func AppInfoJSON(appinfo *mpls.AppInfo) *appInfoJSON {
+	return &appInfoJSON{
+		Length:                        appinfo.Length,
+		PlaybackType:                  appinfo.PlaybackType,
+		PlaybackCount:                 appinfo.PlaybackCount,
+		UserOptions:                   UserOptionsJSON(appinfo.UserOptions),
+		RandomAccessFlag:              appinfo.RandomAccessFlag,
+		AudioMixFlag:                  appinfo.AudioMixFlag,
+		LosslessBypassFlag:            appinfo.LosslessBypassFlag,
+		MVCBaseViewRFlag:              appinfo.MVCBaseViewRFlag,
+		SDRConversionNotificationFlag: appinfo.SDRConversionNotificationFlag,
+	}
+}
+
+func UserOptionsJSON(userOptions *mpls.UserOptions) *userOptionsJSON {
+	if userOptions == nil {
+		return nil
+	}
+	return &userOptionsJSON{
+		MenuCall:                         userOptions.MenuCall,
+		TitleSearch:                      userOptions.TitleSearch,
+		ChapterSearch:                    userOptions.ChapterSearch,
+		TimeSearch:                       userOptions.TimeSearch,
+		SkipToNextPoint:                  userOptions.SkipToNextPoint,
+		SkipToPrevPoint:                  userOptions.SkipToPrevPoint,
+		PlayFirstPlay:                    userOptions.PlayFirstPlay,
+		Stop:                             userOptions.Stop,
+		PauseOn:                          userOptions.PauseOn,
+		PauseOff:                         userOptions.PauseOff,
+		StillOff:                         userOptions.StillOff,
+		ForwardPlay:                      userOptions.ForwardPlay,
+		BackwardPlay:                     userOptions.BackwardPlay,
+		Resume:                           userOptions.Resume,
+		MoveUpSelectedButton:             userOptions.MoveUpSelectedButton,
+		MoveDownSelectedButton:           userOptions.MoveDownSelectedButton,
+		MoveLeftSelectedButton:           userOptions.MoveLeftSelectedButton,
+		MoveRightSelectedButton:          userOptions.MoveRightSelectedButton,
+		SelectButton:                     userOptions.SelectButton,
+		ActivateButton:                   userOptions.ActivateButton,
+		SelectAndActivateButton:          userOptions.SelectAndActivateButton,
+		PrimaryAudioStreamNumberChange:   userOptions.PrimaryAudioStreamNumberChange,
+		AngleNumberChange:                userOptions.AngleNumberChange,
+		PopupOn:                          userOptions.PopupOn,
+		PopupOff:                         userOptions.PopupOff,
+		PrimaryPGEnableDisable:           userOptions.PrimaryPGEnableDisable,
+		PrimaryPGStreamNumberChange:      userOptions.PrimaryPGStreamNumberChange,
+		SecondaryVideoEnableDisable:      userOptions.SecondaryVideoEnableDisable,
+		SecondaryVideoStreamNumberChange: userOptions.SecondaryVideoStreamNumberChange,
+		SecondaryAudioEnableDisable:      userOptions.SecondaryAudioEnableDisable,
+		SecondaryAudioStreamNumberChange: userOptions.SecondaryAudioStreamNumberChange,
+		SecondaryPGStreamNumberChange:    userOptions.SecondaryPGStreamNumberChange,
+	}
+}
+
+func PlayListJSON(playlist *mpls.PlayList) *playListJSON {
+	out := &playListJSON{
+		Length:    playlist.Length,
+		PlayItems: make([]*playItemJSON, len(playlist.PlayItems)),
+		SubPaths:  make([]*subPathJSON, len(playlist.SubPaths)),
+	}
+	for i, playItem := range playlist.PlayItems {
+		out.PlayItems[i] = PlayItemJSON(playItem)
+	}
+	for i, subPath := range playlist.SubPaths {
+		out.SubPaths[i] = SubPathJSON(subPath)
+	}
+	return out
+}
+
+func PlayItemJSON(playItem *mpls.PlayItem) *playItemJSON {
+	out := &playItemJSON{
+		Length:                   playItem.Length,
+		ClipInformationFileName:  string(playItem.ClipInformationFileName[:]),
+		ClipCodecIdentifier:      string(playItem.ClipCodecIdentifier[:]),
+		IsMultiAngle:             playItem.IsMultiAngle,
+		ConnectionCondition:      playItem.ConnectionCondition,
+		RefToSTCID:               playItem.RefToSTCID,
+		INTime:                   uint32(playItem.INTime),
+		OUTTime:                  uint32(playItem.OUTTime),
+		UserOptions:              UserOptionsJSON(playItem.UserOptions),
+		PlayItemRandomAccessFlag: playItem.PlayItemRandomAccessFlag,
+		StillMode:                playItem.StillMode,
+		StillTime:                playItem.StillTime,
+		IsDifferentAudios:        playItem.IsDifferentAudios,
+		IsSeamlessAngleChange:    playItem.IsSeamlessAngleChange,
+		Angles:                   make([]*playItemEntryJSON, len(playItem.Angles)),
+		StreamTable:              StreamTableJSON(playItem.StreamTable),
+	}
+	for i, angle := range playItem.Angles {
+		out.Angles[i] = PlayItemEntryJSON(angle)
+	}
+	return out
+}
+
+func PlayItemEntryJSON(playItemEntry *mpls.PlayItemEntry) *playItemEntryJSON {
+	return &playItemEntryJSON{
+		FileName:   string(playItemEntry.FileName[:]),
+		Codec:      string(playItemEntry.Codec[:]),
+		RefToSTCID: playItemEntry.RefToSTCID,
+	}
+}
+
+func SubPathJSON(subPath *mpls.SubPath) *subPathJSON {
+	out := &subPathJSON{
+		Length:          subPath.Length,
+		SubPathType:     jsonout.NewEnum(subPath.SubPathType, mpls.SubPathType(subPath.SubPathType)),
+		IsRepeatSubPath: subPath.IsRepeatSubPath,
+		SubPlayItems:    make([]*subPlayItemJSON, len(subPath.SubPlayItems)),
+	}
+	for i, subPlayItem := range subPath.SubPlayItems {
+		out.SubPlayItems[i] = SubPlayItemJSON(subPlayItem)
+	}
+	return out
+}
+
+func SubPlayItemJSON(subPlayItem *mpls.SubPlayItem) *subPlayItemJSON {
+	out := &subPlayItemJSON{
+		Length:              subPlayItem.Length,
+		FileName:            string(subPlayItem.FileName[:]),
+		Codec:               string(subPlayItem.Codec[:]),
+		ConnectionCondition: subPlayItem.ConnectionCondition,
+		IsMultiClipEntries:  subPlayItem.IsMultiClipEntries,
+		RefToSTCID:          subPlayItem.RefToSTCID,
+		INTime:              uint32(subPlayItem.INTime),
+		OUTTime:             uint32(subPlayItem.OUTTime),
+		SyncPlayItemID:      subPlayItem.SyncPlaytItemID,
+		SyncStartPTS:        uint32(subPlayItem.SyncStartPTS),
+		MultiClipEntries:    make([]*playItemEntryJSON, len(subPlayItem.MultiClipEntries)),
+	}
+	for i, entry := range subPlayItem.MultiClipEntries {
+		out.MultiClipEntries[i] = PlayItemEntryJSON(entry)
+	}
+	return out
+}
+
+func StreamTableJSON(streamTable *mpls.StreamTable) *streamTableJSON {
+	if streamTable == nil {
+		return nil
+	}
+	out := &streamTableJSON{
+		Length: streamTable.Length,
+		Items:  make([]*streamItemJSON, len(streamTable.Items)),
+	}
+	for i, item := range streamTable.Items {
+		streams := make([]*streamJSON, len(item.Streams))
+		for j, stream := range item.Streams {
+			streams[j] = &streamJSON{
+				Entry:      StreamEntryJSON(stream.Entry),
+				Attributes: StreamAttrJSON(stream.Attr),
+			}
+		}
+		out.Items[i] = &streamItemJSON{Kind: string(item.KindOf), Streams: streams}
+	}
+	return out
+}
+
+// StreamEntryJSON converts a stream entry based on its type.
+func StreamEntryJSON(streamEntry mpls.StreamEntry) *streamEntryJSON {
+	switch entry := streamEntry.(type) {
+	case *mpls.StreamEntryTypeI:
+		return &streamEntryJSON{
+			Length:         entry.Length,
+			StreamType:     jsonout.NewEnum(entry.StreamType, mpls.StreamType(entry.StreamType)),
+			RefToStreamPID: entry.RefToStreamPID,
+		}
+	case *mpls.StreamEntryTypeII:
+		return &streamEntryJSON{
+			Length:         entry.Length,
+			StreamType:     jsonout.NewEnum(entry.StreamType, mpls.StreamType(entry.StreamType)),
+			RefToSubPathID: &entry.RefToSubPathID,
+			RefToSubClipID: &entry.RefToSubClipID,
+			RefToStreamPID: entry.RefToStreamPID,
+		}
+	case *mpls.StreamEntryTypeIII:
+		return &streamEntryJSON{
+			Length:         entry.Length,
+			StreamType:     jsonout.NewEnum(entry.StreamType, mpls.StreamType(entry.StreamType)),
+			RefToSubPathID: &entry.RefToSubPathID,
+			RefToStreamPID: entry.RefToStreamPID,
+		}
+	}
+	return nil
+}
+
+// StreamAttrJSON converts stream attributes based on their specific type.
+func StreamAttrJSON(streamAttr mpls.StreamAttributes) *streamAttrJSON {
+	switch attr := streamAttr.(type) {
+	case *mpls.PrimaryVideoAttributesH264:
+		out := basicAttrJSON("primary_video", &attr.BasicAttributes)
+		out.VideoFormat, out.VideoRate = videoJSON(attr.Format, attr.Rate)
+		return out
+	case *mpls.PrimaryVideoAttributesHEVC:
+		out := basicAttrJSON("primary_video_hevc", &attr.BasicAttributes)
+		out.VideoFormat, out.VideoRate = videoJSON(attr.Format, attr.Rate)
+		out.DynamicRangeType = &attr.DynamicRangeType
+		out.ColorSpace = &attr.ColorSpace
+		out.CRFlag = &attr.CRFlag
+		out.HDRPlusFlag = &attr.HDRPlusFlag
+		return out
+	case *mpls.PrimaryAudioAttributes:
+		out := basicAttrJSON("primary_audio", &attr.BasicAttributes)
+		out.AudioFormat, out.AudioRate = audioJSON(attr.Format, attr.Rate)
+		out.Language = languageJSON(attr.LanguageCode)
+		return out
+	case *mpls.SecondaryAudioAttributes:
+		out := basicAttrJSON("secondary_audio", &attr.BasicAttributes)
+		out.AudioFormat, out.AudioRate = audioJSON(attr.Format, attr.Rate)
+		out.Language = languageJSON(attr.LanguageCode)
+		out.PrimaryAudioRefs = jsonout.Bytes(attr.PrimaryAudioRefs)
+		return out
+	case *mpls.SecondaryVideoAttributes:
+		out := basicAttrJSON("secondary_video", &attr.BasicAttributes)
+		out.VideoFormat, out.VideoRate = videoJSON(attr.Format, attr.Rate)
+		out.SecondaryAudioRefs = jsonout.Bytes(attr.SecondaryAudioRefs)
+		out.PIPPGRefs = jsonout.Bytes(attr.PIPPGRefs)
+		return out
+	case *mpls.PGAttributes:
+		out := basicAttrJSON("pg", &attr.BasicAttributes)
+		out.Language = languageJSON(attr.LanguageCode)
+		return out
+	case *mpls.IGAttributes:
+		out := basicAttrJSON("ig", &attr.BasicAttributes)
+		out.Language = languageJSON(attr.LanguageCode)
+		return out
+	case *mpls.TextAttributes:
+		out := basicAttrJSON("text", &attr.BasicAttributes)
+		characterCode := jsonout.NewEnum(attr.CharacterCode, mpls.CharacterCode(attr.CharacterCode))
+		out.CharacterCode = &characterCode
+		out.Language = languageJSON(attr.LanguageCode)
+		return out
+	}
+	return nil
+}
+
+func basicAttrJSON(kind string, attr *mpls.BasicAttributes) *streamAttrJSON {
+	return &streamAttrJSON{
+		Kind:             kind,
+		Length:           attr.Length,
+		StreamCodingType: jsonout.NewEnum(attr.StreamCodingType, mpls.StreamCodec(attr.StreamCodingType)),
+	}
+}
+
+func videoJSON(format mpls.VideoFormatType, rate mpls.VideoRateType) (*jsonout.Enum, *jsonout.Enum) {
+	videoFormat := jsonout.NewEnum(format, mpls.VideoFormat(format))
+	videoRate := jsonout.NewEnum(rate, mpls.VideoRate(rate))
+	return &videoFormat, &videoRate
+}
+
+func audioJSON(format mpls.AudioFormatType, rate mpls.AudioRateType) (*jsonout.Enum, *jsonout.Enum) {
+	audioFormat := jsonout.NewEnum(format, mpls.AudioFormat(format))
+	audioRate := jsonout.NewEnum(rate, mpls.AudioRate(rate))
+	return &audioFormat, &audioRate
+}
+
+func languageJSON(code [3]byte) *jsonout.Language {
+	language := jsonout.NewLanguage(code)
+	return &language
+}
+
+func PlaylistMarksJSON(playlistMarks *mpls.PlaylistMarks) []*markJSON {
+	out := make([]*markJSON, len(playlistMarks.Marks))
+	for i, mark := range playlistMarks.Marks {
+		out[i] = &markJSON{
+			MarkType:        mark.MarkType,
+			RefToPlayItemID: mark.RefToPlayItemID,
+			MarkTimeStamp:   uint32(mark.MarkTimeStamp),
+			EntryESPID:      mark.EntryESPID,
+			Duration:        uint32(mark.Duration),
+		}
+	}
+	return out
+}
+
+func ExtensionsJSON(extensions *mpls.Extensions) *extensionsJSON {
+	out := &extensionsJSON{
+		Length:             extensions.MetaData.Length,
+		EntryDataStartAddr: extensions.MetaData.EntryDataStartAddr,
+		Entries:            make([]*extensionJSON, len(extensions.EntriesMetaData)),
+	}
+	for i, metaData := range extensions.EntriesMetaData {
+		entry := &extensionJSON{
+			Type:         metaData.ExtDataType,
+			Version:      metaData.ExtDataVersion,
+			StartAddress: metaData.ExtDataStartAddress,
+			Length:       metaData.ExtDataLength,
+		}
+		if i < len(extensions.EntriesData) {
+			entry.Kind, entry.Data = ExtensionsEntryDataJSON(extensions.EntriesData[i])
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
+
+// ExtensionsEntryDataJSON returns the kind and the payload of an extension.
+func ExtensionsEntryDataJSON(entryData mpls.ExtensionEntryData) (string, any) {
+	switch ext := entryData.(type) {
+	case *mpls.ExtensionRaw:
+		return "raw", &extensionRawJSON{Data: ext.Data}
+	case *mpls.ExtensionMakersPrivateData:
+		return "makers_private_data", MakersPrivateDataJSON(ext.MakersPrivateData)
+	case *mpls.ExtensionPIP:
+		return "pip", ExtensionPIPJSON(ext)
+	case *mpls.ExtensionMVCStream:
+		return "mvc_stream", ExtensionMVCStreamJSON(ext)
+	case *mpls.ExtensionSubPath:
+		return "sub_path", ExtensionSubPathJSON(ext)
+	case *mpls.ExtensionStaticMetaData:
+		return "static_metadata", ExtensionStaticMetaDataJSON(ext)
+	}
+	return "", nil
+}
+
+func ExtensionSubPathJSON(subPathExtension *mpls.ExtensionSubPath) *extensionSubPathJSON {
+	out := &extensionSubPathJSON{
+		Length:   subPathExtension.Length,
+		SubPaths: make([]*subPathJSON, len(subPathExtension.SubPaths)),
+	}
+	for i, subPath := range subPathExtension.SubPaths {
+		out.SubPaths[i] = SubPathJSON(subPath)
+	}
+	return out
+}
+
+func ExtensionMVCStreamJSON(extensionMVCStream *mpls.ExtensionMVCStream) []*mvcStreamJSON {
+	out := make([]*mvcStreamJSON, len(extensionMVCStream.MVCStreams))
+	for i, mvcStream := range extensionMVCStream.MVCStreams {
+		out[i] = &mvcStreamJSON{
+			Length:                  mvcStream.Length,
+			FixedOffsetPopUpFlag:    mvcStream.FixedOffsetPopUpFlag,
+			Entry:                   StreamEntryJSON(mvcStream.Entry),
+			Attributes:              StreamAttrJSON(mvcStream.Attr),
+			NumberOfOffsetSequences: mvcStream.NumberOfOffsetSequences,
+		}
+	}
+	return out
+}
+
+func ExtensionPIPJSON(pip *mpls.ExtensionPIP) *extensionPIPJSON {
+	out := &extensionPIPJSON{
+		Length:  pip.Length,
+		Entries: make([]*pipEntryJSON, len(pip.PIPEntries)),
+	}
+	for i, pipEntry := range pip.PIPEntries {
+		entry := &pipEntryJSON{
+			ClipRef:           pipEntry.ClipRef,
+			SecondaryVideoRef: pipEntry.SecondaryVideoRef,
+			TimelineType:      pipEntry.TimelineType,
+			LumaKeyFlag:       pipEntry.LumaKeyFlag,
+			TrickPlayFlag:     pipEntry.TrickPlayFlag,
+			UpperLimitLumaKey: pipEntry.UpperLimitLumaKey,
+			DataAddress:       pipEntry.DataAddress,
+		}
+		if pipEntry.Data != nil {
+			entry.Data = make([]*pipDataEntryJSON, len(pipEntry.Data.Entries))
+			for j, dataEntry := range pipEntry.Data.Entries {
+				entry.Data[j] = &pipDataEntryJSON{
+					Time:        dataEntry.Time,
+					Xpos:        dataEntry.Xpos,
+					Ypos:        dataEntry.Ypos,
+					ScaleFactor: jsonout.NewEnum(dataEntry.ScaleFactor, mpls.PIPScaling(dataEntry.ScaleFactor)),
+				}
+			}
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
+
+func ExtensionStaticMetaDataJSON(smExtension *mpls.ExtensionStaticMetaData) *extensionStaticMetaDataJSON {
+	out := &extensionStaticMetaDataJSON{
+		Length:  smExtension.Length,
+		Entries: make([]*staticMetaDataEntryJSON, len(smExtension.Entries)),
+	}
+	for i, smEntry := range smExtension.Entries {
+		out.Entries[i] = &staticMetaDataEntryJSON{
+			DynamicRangeType:             smEntry.DynamicRangeType,
+			DisplayPrimariesX:            smEntry.DisplayPrimariesX,
+			DisplayPrimariesY:            smEntry.DisplayPrimariesY,
+			WhitePointX:                  smEntry.WhitePointX,
+			WhitePointY:                  smEntry.WhitePointY,
+			MaxDisplayMasteringLuminance: smEntry.MaxDisplayMasteringLuminance,
+			MinDisplayMasteringLuminance: smEntry.MinDisplayMasteringLuminance,
+			MaxCLL:                       smEntry.MaxCLL,
+			MaxFALL:                      smEntry.MaxFALL,
+		}
+	}
+	return out
+}
+
+func MakersPrivateDataJSON(mpd *avchd.MakersPrivateData) *makersPrivateDataJSON {
+	out := &makersPrivateDataJSON{Entries: make([]*makerEntryJSON, len(mpd.Entries))}
+	for i, entry := range mpd.Entries {
+		metadata := entry.Metadata()
+		out.Entries[i] = &makerEntryJSON{
+			Maker:     jsonout.NewEnum(entry.MakerID, avchd.MakerName(entry.MakerID)),
+			ModelCode: entry.MakerModelCode,
+			DST:       metadata.DST,
+			Data:      entry.Data,
+		}
+		if !metadata.RecordingTime.IsZero() {
+			out.Entries[i].RecordingTime = metadata.RecordingTime.Format(time.RFC3339)
+		}
+		if gps := metadata.GPS; gps != nil {
+			out.Entries[i].GPS = &gpsJSON{Latitude: gps.Latitude, Longitude: gps.Longitude, Altitude: gps.Altitude, Time: gps.Time.Seconds()}
+		}
+	}
+	return out
+}
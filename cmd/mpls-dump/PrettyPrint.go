@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/clock"
 	"github.com/parasense/bdmv_go/pkg/mpls"
 )
 
@@ -38,17 +41,15 @@ func PlayListPrint(playlist *mpls.PlayList) {
 	PadPrintf(2, "NumberOfSubPaths: %d\n", playlist.NumberOfSubPaths)
 	PadPrintln(2)
 
-	var totalDuration uint32
+	var totalDuration clock.Ticks45k
 	for i, playItem := range playlist.PlayItems {
-		inTime := Convert45KhzTimeToSeconds(playItem.INTime)
-		outTime := Convert45KhzTimeToSeconds(playItem.OUTTime)
-		duration := outTime - inTime
-		totalDuration += duration
+		totalDuration = totalDuration.Add(playItem.OUTTime.Sub(playItem.INTime))
 
 		PadPrintf(2, "PlayItem [%d]:\n", i)
 		PlayItemPrint(playItem)
 		PadPrintln(2, "---")
 	}
+	PadPrintf(1, "*Duration: %v\n", totalDuration)
 
 	for i, subPath := range playlist.SubPaths {
 		PadPrintf(2, "SubPath [%d]:\n", i)
@@ -59,17 +60,15 @@ func PlayListPrint(playlist *mpls.PlayList) {
 }
 
 func PlayItemPrint(playItem *mpls.PlayItem) {
-	inTime := Convert45KhzTimeToSeconds(playItem.INTime)
-	outTime := Convert45KhzTimeToSeconds(playItem.OUTTime)
 	PadPrintf(4, "Length: %d\n", playItem.Length)
 	PadPrintf(4, "Clip File: %s\n", playItem.ClipInformationFileName)
 	PadPrintf(4, "Codec ID: %s\n", playItem.ClipCodecIdentifier)
 	PadPrintf(4, "Multi-angle: %v\n", playItem.IsMultiAngle)
 	PadPrintf(4, "ConnectionCondition: %d\n", playItem.ConnectionCondition)
 	PadPrintf(4, "RefToSTCID: %d\n", playItem.RefToSTCID)
-	PadPrintf(4, "InTime: %d (%d)\n", playItem.INTime, inTime)
-	PadPrintf(4, "OUTime: %d (%d)\n", playItem.OUTTime, outTime)
-	PadPrintf(6, "*Duration: %v\n", outTime-inTime)
+	PadPrintf(4, "InTime: %d (%v)\n", playItem.INTime, playItem.INTime)
+	PadPrintf(4, "OUTime: %d (%v)\n", playItem.OUTTime, playItem.OUTTime)
+	PadPrintf(6, "*Duration: %v\n", playItem.OUTTime.Sub(playItem.INTime))
 	USerOptionsPrint(playItem.UserOptions)
 	PadPrintf(4, "PlayItemRandomAccessFlag: %v\n", playItem.PlayItemRandomAccessFlag)
 	PadPrintf(4, "StillMode: %v\n", playItem.StillMode)
@@ -105,8 +104,10 @@ func USerOptionsPrint(userOptions *mpls.UserOptions) {
 	PadPrintf(6, "TimeSearch: %v\n", userOptions.TimeSearch)
 	PadPrintf(6, "SkipToNextPoint: %v\n", userOptions.SkipToNextPoint)
 	PadPrintf(6, "SkipToPrevPoint: %v\n", userOptions.SkipToPrevPoint)
+	PadPrintf(6, "PlayFirstPlay: %v\n", userOptions.PlayFirstPlay)
 	PadPrintf(6, "Stop: %v\n", userOptions.Stop)
 	PadPrintf(6, "PauseOn: %v\n", userOptions.PauseOn)
+	PadPrintf(6, "PauseOff: %v\n", userOptions.PauseOff)
 	PadPrintf(6, "StillOff %v\n", userOptions.StillOff)
 	PadPrintf(6, "ForwardPlay: %v\n", userOptions.ForwardPlay)
 	PadPrintf(6, "BackwardPlay: %v\n", userOptions.BackwardPlay)
@@ -138,17 +139,15 @@ func PlayItemEntryPrint(playItemEntry *mpls.PlayItemEntry) {
 }
 
 func SubPlayItemPrint(subPlayItem *mpls.SubPlayItem) {
-	inTime := Convert45KhzTimeToSeconds(subPlayItem.INTime)
-	outTime := Convert45KhzTimeToSeconds(subPlayItem.OUTTime)
 	PadPrintf(8, "Length: %d\n", subPlayItem.Length)
 	PadPrintf(8, "FileName: %s\n", subPlayItem.FileName)
 	PadPrintf(8, "Codec: %s\n", subPlayItem.Codec)
 	PadPrintf(8, "ConnectionCondition: %d\n", subPlayItem.ConnectionCondition)
 	PadPrintf(8, "IsMultiClipEntries: %v\n", subPlayItem.IsMultiClipEntries)
 	PadPrintf(8, "RefToSTCID: %d\n", subPlayItem.RefToSTCID)
-	PadPrintf(8, "InTime: %d (%d)\n", subPlayItem.INTime, inTime)
-	PadPrintf(8, "OUTime: %d (%d)\n", subPlayItem.OUTTime, outTime)
-	PadPrintf(10, "*Duration: %v\n", outTime-inTime)
+	PadPrintf(8, "InTime: %d (%v)\n", subPlayItem.INTime, subPlayItem.INTime)
+	PadPrintf(8, "OUTime: %d (%v)\n", subPlayItem.OUTTime, subPlayItem.OUTTime)
+	PadPrintf(10, "*Duration: %v\n", subPlayItem.OUTTime.Sub(subPlayItem.INTime))
 	PadPrintf(8, "SyncPlaytItemID: %d\n", subPlayItem.SyncPlaytItemID)
 	PadPrintf(8, "SyncStartPTS: %d\n", subPlayItem.SyncStartPTS)
 	PadPrintf(8, "NumberOfMultiClipEntries: %d\n", subPlayItem.NumberOfMultiClipEntries)
@@ -348,8 +347,7 @@ func PlaylistMarksPrint(playlistMarks *mpls.PlaylistMarks) {
 	PadPrintf(0, "Chapter Marks: [%d]\n", len(playlistMarks.Marks))
 	for i, mark := range playlistMarks.Marks {
 		if mark.MarkType == 1 { // Chapter mark
-			timestamp := Parse45KhzTimestamp(mark.MarkTimeStamp)
-			PadPrintf(2, "Chapter [%d]: at [%v] (PlayItem: %d)\n", i+1, timestamp, mark.RefToPlayItemID)
+			PadPrintf(2, "Chapter [%d]: at [%v] (PlayItem: %d)\n", i+1, mark.MarkTimeStamp, mark.RefToPlayItemID)
 			MarkEntryPrint(mark)
 			PadPrintln(2, "---")
 		}
@@ -405,6 +403,10 @@ func ExtensionEntryMetaDataPrint(entryMetaData *mpls.ExtensionEntryMetaData) {
 
 func ExtensionsEntryDataPrint(entryData mpls.ExtensionEntryData) {
 	switch entryType := entryData.(type) {
+	case *mpls.ExtensionRaw:
+		ExtensionRawPrint(entryType)
+	case *mpls.ExtensionMakersPrivateData:
+		ExtensionMakersPrivateDataPrint(entryType)
 	case *mpls.ExtensionPIP:
 		ExtensionPIPPrint(entryType)
 	case *mpls.ExtensionMVCStream:
@@ -416,6 +418,27 @@ func ExtensionsEntryDataPrint(entryData mpls.ExtensionEntryData) {
 	}
 }
 
+func ExtensionRawPrint(ext *mpls.ExtensionRaw) {
+	PadPrintln(4, "ExtensionRaw:")
+	PadPrintf(6, "Length: %d\n", len(ext.Data))
+	PadPrintf(6, "Data: % X\n", ext.Data)
+}
+
+func ExtensionMakersPrivateDataPrint(ext *mpls.ExtensionMakersPrivateData) {
+	PadPrintln(4, "ExtensionMakersPrivateData:")
+	for i, entry := range ext.Entries {
+		metadata := entry.Metadata()
+		PadPrintf(6, "Maker [%d]: %s, model 0x%04X\n", i+1, avchd.MakerName(entry.MakerID), entry.MakerModelCode)
+		if !metadata.RecordingTime.IsZero() {
+			PadPrintf(8, "RecordingTime: %s (DST: %t)\n", metadata.RecordingTime.Format(time.RFC3339), metadata.DST)
+		}
+		if metadata.GPS != nil {
+			PadPrintf(8, "GPS: %s\n", metadata.GPS)
+		}
+		PadPrintf(8, "Data: %d bytes, %d packs\n", len(entry.Data), len(metadata.Packs))
+	}
+}
+
 //
 // The actual extensions
 //
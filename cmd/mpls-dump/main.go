@@ -1,11 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	mpls "github.com/parasense/bdmv_go/pkg/mpls"
 )
 
@@ -18,30 +19,42 @@ func PadPrintln(indent int, args ...any) {
 	fmt.Println(args...)
 }
 
-// Parse45KhzTimestamp converts a timestamp in 45kHz units to a duration
-// returns a quantity of time in seconds.
-func Parse45KhzTimestamp(timestamp uint32) time.Duration {
-	return time.Duration(uint32(timestamp) / 45000)
-}
-
-// returns a quantity of time in seconds.
-func Convert45KhzTimeToSeconds(timestamp uint32) uint32 {
-	return uint32(timestamp / 45000)
-}
-
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: mpls-parser <mpls-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: mpls-parser [--format=text|json] <mpls-file>")
 		os.Exit(1)
 	}
 
-	mplsPath := os.Args[1]
+	mplsPath := flag.Arg(0)
 	header, appinfo, playlist, chapterMarks, extData, err := mpls.ParseMPLS(mplsPath)
 	if err != nil {
 		fmt.Printf("Error parsing MPLS file: %+v\n", err)
 		os.Exit(1)
 	}
 
+	if *format == jsonout.FormatJSON {
+		out := &mplsJSON{
+			Header:   HeaderJSON(header),
+			AppInfo:  AppInfoJSON(appinfo),
+			PlayList: PlayListJSON(playlist),
+			Marks:    PlaylistMarksJSON(chapterMarks),
+		}
+		if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
+			out.Extensions = ExtensionsJSON(extData)
+		}
+		if err := jsonout.Write(os.Stdout, mplsSchema, mplsPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	PadPrintf(0, "MPLS File: %s\n", mplsPath)
 	HeaderPrint(header)
 	AppInfoPrint(appinfo)
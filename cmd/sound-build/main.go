@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	bclk "github.com/parasense/bdmv_go/pkg/sound"
+)
+
+func main() {
+	output := flag.String("o", "sound.bdmv", "file to write")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: sound-build [-o sound.bdmv] <wav-file>...")
+		os.Exit(1)
+	}
+
+	var wavs []io.Reader
+	for _, wavPath := range flag.Args() {
+		wav, err := os.Open(wavPath)
+		if err != nil {
+			fmt.Printf("Error opening WAV file: %+v\n", err)
+			os.Exit(1)
+		}
+		defer wav.Close()
+		wavs = append(wavs, wav)
+	}
+
+	header, soundMetaData, soundData, err := bclk.BuildBCLK(wavs...)
+	if err != nil {
+		fmt.Printf("Error reading WAV files: %+v\n", err)
+		os.Exit(1)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Printf("Error creating output: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := bclk.WriteBCLK(file, header, soundMetaData, soundData); err != nil {
+		file.Close()
+		fmt.Printf("Error writing %s: %+v\n", *output, err)
+		os.Exit(1)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Printf("Error writing %s: %+v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: %d sounds\n", *output, len(soundMetaData.SampleAttrs))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bclk "github.com/parasense/bdmv_go/pkg/sound"
+)
+
+// ExtractWAVs writes sound N to dir/soundNN.wav, creating dir if needed.
+func ExtractWAVs(dir string, soundMetaData *bclk.SoundMetaData, soundData *bclk.SoundData) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, attr := range soundMetaData.SampleAttrs {
+		path := filepath.Join(dir, fmt.Sprintf("sound%02d.wav", i))
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := bclk.WriteWAV(file, attr, soundData.Data[i]); err != nil {
+			file.Close()
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		PadPrintf(0, "%s: %d ch, %d Hz, %d frames\n", path, attr.NumberOfChannels, attr.SampleRate, attr.NumberOfFrames)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/parasense/bdmv_go/internal/jsonout"
+	bclk "github.com/parasense/bdmv_go/pkg/sound"
+)
+
+const soundSchema = "sound/1"
+
+type soundJSON struct {
+	Header *headerJSON        `json:"header"`
+	Length uint32             `json:"length"`
+	Sounds []*sampleAttrsJSON `json:"sounds"`
+}
+
+type headerJSON struct {
+	Type          string          `json:"type"`
+	Version       string          `json:"version"`
+	SoundMetaData jsonout.Offsets `json:"sound_meta_data"`
+	SoundObjects  jsonout.Offsets `json:"sound_objects"`
+	Extensions    jsonout.Offsets `json:"extensions"`
+}
+
+// sampleAttrsJSON describes one sound. The samples themselves are not
+// emitted; Samples is the number of 16-bit samples over all channels.
+type sampleAttrsJSON struct {
+	NumberOfChannels uint8  `json:"number_of_channels"`
+	SampleRate       uint32 `json:"sample_rate"`
+	BitsPerSample    uint8  `json:"bits_per_sample"`
+	SoundDataIndex   uint32 `json:"sound_data_index"`
+	NumberOfFrames   uint32 `json:"number_of_frames"`
+	Samples          int    `json:"samples"`
+}
+
+func HeaderJSON(header *bclk.BCLKHeader) *headerJSON {
+	return &headerJSON{
+		Type:          string(header.TypeIndicator[:]),
+		Version:       string(header.VersionNumber[:]),
+		SoundMetaData: jsonout.Offsets{Start: header.SoundMetaData.Start, Stop: header.SoundMetaData.Stop},
+		SoundObjects:  jsonout.Offsets{Start: header.SoundObjects.Start, Stop: header.SoundObjects.Stop},
+		Extensions:    jsonout.Offsets{Start: header.Extensions.Start, Stop: header.Extensions.Stop},
+	}
+}
+
+func SoundsJSON(soundMetaData *bclk.SoundMetaData, soundData *bclk.SoundData) []*sampleAttrsJSON {
+	out := make([]*sampleAttrsJSON, len(soundMetaData.SampleAttrs))
+	for i, sound := range soundMetaData.SampleAttrs {
+		out[i] = &sampleAttrsJSON{
+			NumberOfChannels: sound.NumberOfChannels,
+			SampleRate:       sound.SampleRate,
+			BitsPerSample:    sound.BitsPerSample,
+			SoundDataIndex:   sound.SoundDataIndex,
+			NumberOfFrames:   sound.NumberOfFrames,
+		}
+		if i < len(soundData.Data) && soundData.Data[i] != nil {
+			out[i].Samples = len(*soundData.Data[i])
+		}
+	}
+	return out
+}
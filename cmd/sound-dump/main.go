@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/parasense/bdmv_go/internal/jsonout"
 	bclk "github.com/parasense/bdmv_go/pkg/sound"
 )
 
@@ -18,18 +20,46 @@ func PadPrintln(indent int, args ...any) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: mobj-dump <mobj-file>")
+	format := flag.String("format", jsonout.FormatText, "output format: text or json")
+	extract := flag.String("extract", "", "write every sound as a WAV file into this directory")
+	flag.Parse()
+	if err := jsonout.CheckFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: sound-dump [--format=text|json] [--extract DIR] <sound-file>")
 		os.Exit(1)
 	}
 
-	mobjPath := os.Args[1]
+	mobjPath := flag.Arg(0)
 	header, soundMetaData, soundData, err := bclk.ParseBCLK(mobjPath)
 	if err != nil {
 		fmt.Printf("Error parsing CLPI file: %+v\n", err)
 		os.Exit(1)
 	}
 
+	if *extract != "" {
+		if err := ExtractWAVs(*extract, soundMetaData, soundData); err != nil {
+			fmt.Printf("Error extracting sounds: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *format == jsonout.FormatJSON {
+		out := &soundJSON{
+			Header: HeaderJSON(header),
+			Length: soundMetaData.Length,
+			Sounds: SoundsJSON(soundMetaData, soundData),
+		}
+		if err := jsonout.Write(os.Stdout, soundSchema, mobjPath, out); err != nil {
+			fmt.Printf("Error writing JSON: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	PadPrintf(0, "BCLK File: %s\n", mobjPath)
 	PadPrintln(0, "")
 	HeaderPrint(header)
@@ -0,0 +1,42 @@
+// Package fsfile opens files of an fs.FS for the parsers, which seek
+// around in the files they read.
+package fsfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// File is an open file that can be read anywhere. *os.File and the
+// files of package udf are Files.
+type File interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// Open opens name in fsys as a File. Files that cannot seek are read
+// into memory.
+func Open(fsys fs.FS, name string) (File, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if seekable, ok := file.(File); ok {
+		return seekable, nil
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
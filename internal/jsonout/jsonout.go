@@ -0,0 +1,100 @@
+// Package jsonout holds the pieces shared by the --format=json mode of the
+// dump commands. The schema itself is documented in documentation.md.
+//
+// Each command builds its JSON view from its own structs rather than by
+// marshalling the parser types directly, so a schema stays put when a
+// parser changes.
+package jsonout
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// Output formats accepted by the --format flag.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// CheckFormat returns an error when format is not a known output format.
+func CheckFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Document is the top level object every dump command writes.
+// Schema names the layout of Data and carries a version, e.g. "mpls/1".
+type Document struct {
+	Schema string `json:"schema"`
+	File   string `json:"file"`
+	Data   any    `json:"data"`
+}
+
+// Write encodes one Document to w.
+func Write(w io.Writer, schema, file string, data any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&Document{Schema: schema, File: file, Data: data})
+}
+
+// Enum is an enumerated field emitted both raw and decoded.
+// Name is empty when the code is not in the decoding table.
+type Enum struct {
+	Code uint32 `json:"code"`
+	Name string `json:"name"`
+}
+
+// NewEnum pairs a raw code with its decoded name.
+func NewEnum[T ~uint8 | ~uint16 | ~uint32](code T, name string) Enum {
+	return Enum{Code: uint32(code), Name: name}
+}
+
+// Language is an ISO 639-2 language code with its English and native names.
+type Language struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Native string `json:"native"`
+}
+
+// NewLanguage decodes a 3-byte ISO 639-2 language code.
+func NewLanguage(code [3]byte) Language {
+	eng, nat := mpls.LanguageCode(code)
+	return Language{Code: String(code[:]), Name: eng, Native: nat}
+}
+
+// Offsets is a [start, stop) byte range within the file.
+type Offsets struct {
+	Start int64 `json:"start"`
+	Stop  int64 `json:"stop"`
+}
+
+// Hex is a byte slice emitted as a lower case hex string.
+type Hex []byte
+
+func (h Hex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+// String converts a fixed-size ASCII field, dropping NUL padding.
+func String(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// Bytes converts a list of 8-bit numbers so it is not emitted as base64.
+func Bytes(b []uint8) []int {
+	list := make([]int, len(b))
+	for i, v := range b {
+		list[i] = int(v)
+	}
+	return list
+}
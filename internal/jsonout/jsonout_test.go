@@ -0,0 +1,57 @@
+package jsonout
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+func TestWrite(t *testing.T) {
+	data := struct {
+		Coding   Enum     `json:"stream_coding_type"`
+		Language Language `json:"language"`
+		Data     Hex      `json:"data"`
+	}{
+		Coding:   NewEnum(mpls.STREAM_TYPE_VIDEO_H264, mpls.StreamCodec(mpls.STREAM_TYPE_VIDEO_H264)),
+		Language: NewLanguage([3]byte{'e', 'n', 'g'}),
+		Data:     []byte{0xDE, 0xAD},
+	}
+
+	got := &bytes.Buffer{}
+	if err := Write(got, "test/1", "00000.mpls", data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `{
+  "schema": "test/1",
+  "file": "00000.mpls",
+  "data": {
+    "stream_coding_type": {
+      "code": 27,
+      "name": "H264 VIDEO"
+    },
+    "language": {
+      "code": "eng",
+      "name": "English",
+      "native": "English"
+    },
+    "data": "dead"
+  }
+}
+`
+	if got.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON} {
+		if err := CheckFormat(format); err != nil {
+			t.Errorf("CheckFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := CheckFormat("xml"); err == nil {
+		t.Errorf("CheckFormat(\"xml\") should fail")
+	}
+}
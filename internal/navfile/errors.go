@@ -0,0 +1,39 @@
+package navfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseError reports where in a file parsing failed. The packages that
+// parse navigation files export it under their own name.
+type ParseError struct {
+	File   string // Path of the file, empty when not parsed from a named file
+	Offset int64  // Byte offset of the structure that failed to parse
+	Path   string // Structure path, e.g. "PlayList.PlayItems[2]"
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s at offset %d: %v", e.Path, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("%s: %s at offset %d: %v", e.File, e.Path, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// SectionError reports an error from reading the section of filePath
+// that starts at start. An error that already carries a *ParseError keeps
+// its more precise offset and path and only gains the file name; any
+// other error is reported at the start of the section.
+func SectionError(filePath, section string, start int64, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = filePath
+		return parseErr
+	}
+	return &ParseError{File: filePath, Offset: start, Path: section, Err: err}
+}
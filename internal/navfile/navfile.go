@@ -0,0 +1,94 @@
+// Package navfile holds what the parsers and writers of the navigation
+// file packages (mpls, clpi, indx, mobj, ...) share.
+package navfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WithLength prefixes body with its own length, encoded as a big-endian U.
+// This is how every variable sized structure of a navigation file starts.
+func WithLength[U uint8 | uint16 | uint32](body []byte) ([]byte, error) {
+	length := U(len(body))
+	if int(length) != len(body) {
+		return nil, fmt.Errorf("length %d does not fit in a %d-byte length field", len(body), binary.Size(length))
+	}
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, length)
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// Section is one section of a file as it was read: where it started,
+// its bytes up to the next section, padding included, and what the
+// structure parsed from it encoded to at the time.
+type Section struct {
+	Start   int64
+	Raw     []byte
+	Encoded []byte
+}
+
+// ReadSection reads the section of file from start up to stop.
+// encoded is the encoding of the structure parsed from it, nil when it
+// could not be encoded.
+func ReadSection(file io.ReadSeeker, start, stop int64, encoded []byte) (*Section, error) {
+	if stop < start {
+		return nil, fmt.Errorf("section ends at %d before it starts at %d", stop, start)
+	}
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to section start: %w", err)
+	}
+	section := &Section{Start: start, Raw: make([]byte, stop-start), Encoded: encoded}
+	if _, err := io.ReadFull(file, section.Raw); err != nil {
+		return nil, fmt.Errorf("failed to read section: %w", err)
+	}
+	return section, nil
+}
+
+// Unchanged reports whether data, a fresh encoding of the section's
+// structure, is what it encoded to when read.
+func (section *Section) Unchanged(data []byte) bool {
+	return section != nil && section.Encoded != nil && bytes.Equal(section.Encoded, data)
+}
+
+// Bytes returns the bytes to write for the section: as read when data
+// shows it is unchanged, else data.
+func (section *Section) Bytes(data []byte) []byte {
+	if section.Unchanged(data) {
+		return section.Raw
+	}
+	return data
+}
+
+// Layout lays out the sections of a file one after the other.
+type Layout struct {
+	Base int64 // Address of the first section, after the file header
+	buf  bytes.Buffer
+}
+
+// Add appends a section encoded as data and returns where it starts and
+// stops. An unchanged section is written as read, with its padding, and
+// at its original address if the sections before it leave that free;
+// the gap up to it is zero filled. A changed section follows right after
+// the one before it. section may be nil, for a structure not read from a
+// file.
+func (layout *Layout) Add(section *Section, data []byte) (start, stop int64) {
+	start = layout.Base + int64(layout.buf.Len())
+	if section.Unchanged(data) {
+		if section.Start > start {
+			layout.buf.Write(make([]byte, section.Start-start))
+			start = section.Start
+		}
+		data = section.Raw
+	}
+	layout.buf.Write(data)
+	return start, start + int64(len(data))
+}
+
+// Bytes returns the sections laid out so far.
+func (layout *Layout) Bytes() []byte {
+	return layout.buf.Bytes()
+}
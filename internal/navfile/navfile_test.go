@@ -0,0 +1,61 @@
+package navfile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	// Two sections, each followed by two bytes of padding.
+	file := bytes.NewReader([]byte{0xAA, 0xAA, 1, 2, 3, 0, 0, 4, 5, 0, 0})
+	first, err := ReadSection(file, 2, 7, []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("ReadSection() error = %v", err)
+	}
+	second, err := ReadSection(file, 7, 11, []byte{4, 5})
+	if err != nil {
+		t.Fatalf("ReadSection() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		first  []byte
+		second []byte
+		want   []byte
+		starts [2]int64
+	}{
+		{name: "unchanged", first: []byte{1, 2, 3}, second: []byte{4, 5}, want: []byte{1, 2, 3, 0, 0, 4, 5, 0, 0}, starts: [2]int64{2, 7}},
+		{name: "first shrinks", first: []byte{1}, second: []byte{4, 5}, want: []byte{1, 0, 0, 0, 0, 4, 5, 0, 0}, starts: [2]int64{2, 7}},
+		{name: "first grows", first: []byte{1, 2, 3, 4, 5, 6}, second: []byte{4, 5}, want: []byte{1, 2, 3, 4, 5, 6, 4, 5, 0, 0}, starts: [2]int64{2, 8}},
+		{name: "second changes", first: []byte{1, 2, 3}, second: []byte{6}, want: []byte{1, 2, 3, 0, 0, 6}, starts: [2]int64{2, 7}},
+	}
+	for _, test := range tests {
+		layout := &Layout{Base: 2}
+		start1, _ := layout.Add(first, test.first)
+		start2, _ := layout.Add(second, test.second)
+		if got := layout.Bytes(); !bytes.Equal(got, test.want) || [2]int64{start1, start2} != test.starts {
+			t.Errorf("%s: got % x at %d, %d, want % x at %d", test.name, got, start1, start2, test.want, test.starts)
+		}
+	}
+
+	// A structure not read from a file is laid out as encoded.
+	layout := &Layout{Base: 2}
+	if start, stop := layout.Add(nil, []byte{7}); start != 2 || stop != 3 {
+		t.Errorf("Add(nil) = %d, %d, want 2, 3", start, stop)
+	}
+}
+
+func TestSectionError(t *testing.T) {
+	inner := &ParseError{Offset: 60, Path: "PlayList.PlayItems[1]", Err: io.ErrUnexpectedEOF}
+	if err := SectionError("00000.mpls", "PlayList", 58, inner); err != inner || inner.File != "00000.mpls" {
+		t.Errorf("SectionError() of a ParseError = %v, want it with the file name", err)
+	}
+
+	var parseErr *ParseError
+	err := SectionError("00000.mpls", "PlayList", 58, io.ErrUnexpectedEOF)
+	if !errors.As(err, &parseErr) || parseErr.Offset != 58 || parseErr.Path != "PlayList" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("SectionError() = %v, want it at the section start", err)
+	}
+}
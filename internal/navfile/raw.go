@@ -0,0 +1,43 @@
+package navfile
+
+import (
+	"fmt"
+	"io"
+)
+
+// ExtensionRaw holds the undecoded bytes of an extension entry, so the
+// entry survives a parse and write round trip unchanged. The packages
+// with an extensions block embed it in their own ExtensionRaw.
+type ExtensionRaw struct {
+	Data []byte
+}
+
+// ReadEntry reads length bytes of extension data from start, which must
+// not run past stop, the end of the extensions block.
+func (raw *ExtensionRaw) ReadEntry(file io.ReadSeeker, start, stop int64, length uint32) error {
+	// Sanity check
+	if start+int64(length) > stop {
+		return fmt.Errorf("extension data of %d bytes overruns the extensions block", length)
+	}
+
+	// Jump to the start offset
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", start, err)
+	}
+
+	raw.Data = make([]byte, length)
+	if _, err := io.ReadFull(file, raw.Data); err != nil {
+		return fmt.Errorf("failed to read ExtensionRaw.Data: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary returns the extension data as it was read.
+func (raw *ExtensionRaw) MarshalBinary() ([]byte, error) {
+	return raw.Data, nil
+}
+
+func (raw *ExtensionRaw) String() string {
+	return fmt.Sprintf("ExtensionRaw{Data: %d bytes}", len(raw.Data))
+}
@@ -0,0 +1,116 @@
+package avchd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+// MakersPrivateData is the maker's private data of one .MPL or .CPI file.
+type MakersPrivateData struct {
+	Entries []*MakerEntry
+	raw     *navfile.Section // As read, for MarshalBinary to keep its layout
+}
+
+// MakerEntry is the data block one maker wrote.
+type MakerEntry struct {
+	MakerID        uint16
+	MakerModelCode uint16
+	Data           []byte
+}
+
+// ParseMakersPrivateData decodes the extension data of a maker's private
+// data entry. data starts at the length field.
+func ParseMakersPrivateData(data []byte) (*MakersPrivateData, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("maker's private data of %d bytes has no length", len(data))
+	}
+	length := binary.BigEndian.Uint32(data)
+	if uint64(length)+4 > uint64(len(data)) {
+		return nil, fmt.Errorf("maker's private data length %d overruns %d bytes", length, len(data))
+	}
+	mpd := &MakersPrivateData{}
+	if length == 0 {
+		return mpd, nil
+	}
+	data = data[:4+length]
+
+	if len(data) < 12 {
+		return nil, fmt.Errorf("maker's private data of %d bytes has no entry count", len(data))
+	}
+	dataBlockStart := binary.BigEndian.Uint32(data[4:])
+	count := int(data[11])
+	if 12+12*count > len(data) {
+		return nil, fmt.Errorf("%d maker entries overrun %d bytes", count, len(data))
+	}
+
+	mpd.Entries = make([]*MakerEntry, count)
+	for i := range mpd.Entries {
+		entry := data[12+12*i:]
+		start := uint64(dataBlockStart) + uint64(binary.BigEndian.Uint32(entry[4:]))
+		end := start + uint64(binary.BigEndian.Uint32(entry[8:]))
+		if end > uint64(len(data)) {
+			return nil, fmt.Errorf("maker entry %d data [%d:%d] overruns %d bytes", i, start, end, len(data))
+		}
+		mpd.Entries[i] = &MakerEntry{
+			MakerID:        binary.BigEndian.Uint16(entry),
+			MakerModelCode: binary.BigEndian.Uint16(entry[2:]),
+			Data:           bytes.Clone(data[start:end]),
+		}
+	}
+
+	encoded, _ := mpd.encode()
+	mpd.raw = &navfile.Section{Raw: bytes.Clone(data), Encoded: encoded}
+	return mpd, nil
+}
+
+// MarshalBinary encodes the maker's private data, including its length
+// field. Data parsed from a file is written back as read while its
+// entries are unchanged, whatever the layout of its data blocks; else
+// the data blocks follow the entry table back to back.
+func (mpd *MakersPrivateData) MarshalBinary() ([]byte, error) {
+	data, err := mpd.encode()
+	if err != nil {
+		return nil, err
+	}
+	return mpd.raw.Bytes(data), nil
+}
+
+func (mpd *MakersPrivateData) encode() ([]byte, error) {
+	if len(mpd.Entries) == 0 {
+		return make([]byte, 4), nil
+	}
+	if len(mpd.Entries) > 0xFF {
+		return nil, fmt.Errorf("too many maker entries: %d", len(mpd.Entries))
+	}
+
+	dataBlockStart := 12 + 12*len(mpd.Entries)
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(0)) // length, set below
+	binary.Write(buf, binary.BigEndian, uint32(dataBlockStart))
+	buf.Write(make([]byte, 3))
+	buf.WriteByte(uint8(len(mpd.Entries)))
+
+	var start uint32
+	for _, entry := range mpd.Entries {
+		binary.Write(buf, binary.BigEndian, entry.MakerID)
+		binary.Write(buf, binary.BigEndian, entry.MakerModelCode)
+		binary.Write(buf, binary.BigEndian, start)
+		binary.Write(buf, binary.BigEndian, uint32(len(entry.Data)))
+		start += uint32(len(entry.Data))
+	}
+	for _, entry := range mpd.Entries {
+		buf.Write(entry.Data)
+	}
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data, uint32(len(data)-4))
+	return data, nil
+}
+
+// Metadata decodes the packs of the entry's data block.
+func (entry *MakerEntry) Metadata() *Metadata {
+	return Decode(ParsePacks(entry.Data))
+}
@@ -0,0 +1,33 @@
+package avchd
+
+// ExtDataType of the maker's private data entry in .MPL and .CPI files.
+const EXT_DATA_TYPE_MAKERS_PRIVATE_DATA = 0x1000
+
+// maker_ID values.
+const (
+	MAKER_PANASONIC = 0x0103
+	MAKER_SONY      = 0x0108
+	MAKER_CANON     = 0x1011
+	MAKER_JVC       = 0x1104
+)
+
+// Pack tags of the MDPM metadata.
+const (
+	PACK_RECORDING_DATE    = 0x18 // time zone, year, month
+	PACK_RECORDING_TIME    = 0x19 // day, hour, minute, second
+	PACK_GPS_LATITUDE_REF  = 0xB1 // 'N' or 'S'
+	PACK_GPS_LATITUDE_DEG  = 0xB2
+	PACK_GPS_LATITUDE_MIN  = 0xB3
+	PACK_GPS_LATITUDE_SEC  = 0xB4
+	PACK_GPS_LONGITUDE_REF = 0xB5 // 'E' or 'W'
+	PACK_GPS_LONGITUDE_DEG = 0xB6
+	PACK_GPS_LONGITUDE_MIN = 0xB7
+	PACK_GPS_LONGITUDE_SEC = 0xB8
+	PACK_GPS_ALTITUDE_REF  = 0xB9 // 0 above sea level, 1 below
+	PACK_GPS_ALTITUDE      = 0xBA
+	PACK_GPS_TIME_HOUR     = 0xBB
+	PACK_GPS_TIME_MINUTE   = 0xBC
+	PACK_GPS_TIME_SECOND   = 0xBD
+	PACK_MAKE_MODEL        = 0xE0 // maker_ID, model code
+	PACK_UNUSED            = 0xFF
+)
@@ -0,0 +1,133 @@
+package avchd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Pack is one 5-byte MDPM pack: a tag and four bytes of data.
+type Pack struct {
+	Tag  uint8
+	Data [4]byte
+}
+
+// Metadata is what Decode understood of a run of packs.
+type Metadata struct {
+	MakerID       uint16    // From the make/model pack, 0 when absent
+	ModelCode     uint16    // From the make/model pack, 0 when absent
+	RecordingTime time.Time // Local time of the recording, zero when absent
+	DST           bool      // Daylight saving time was in effect
+	GPS           *GPS      // nil when the camera had no fix
+	Packs         []Pack    // Every pack, decoded or not
+}
+
+// GPS is a GPS fix. Latitude and longitude are in degrees, negative
+// south and west; altitude is in metres, negative below sea level.
+type GPS struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Time      time.Duration // UTC time of day of the fix
+}
+
+func (gps *GPS) String() string {
+	return fmt.Sprintf("%.6f, %.6f, %.1f m", gps.Latitude, gps.Longitude, gps.Altitude)
+}
+
+// ParsePacks splits data into 5-byte packs. A trailing partial pack and
+// unused (0xFF) packs are dropped.
+func ParsePacks(data []byte) (packs []Pack) {
+	for ; len(data) >= 5; data = data[5:] {
+		if data[0] == PACK_UNUSED {
+			continue
+		}
+		packs = append(packs, Pack{Tag: data[0], Data: [4]byte(data[1:5])})
+	}
+	return packs
+}
+
+// rational decodes pack data as a 16-bit numerator over a 16-bit
+// denominator.
+func (pack Pack) rational() float64 {
+	denominator := binary.BigEndian.Uint16(pack.Data[2:])
+	if denominator == 0 {
+		return 0
+	}
+	return float64(binary.BigEndian.Uint16(pack.Data[:2])) / float64(denominator)
+}
+
+// Decode picks the known packs out of packs. Packs that are missing or
+// malformed leave their fields zero.
+func Decode(packs []Pack) *Metadata {
+	metadata := &Metadata{Packs: packs}
+	byTag := make(map[uint8]Pack, len(packs))
+	for _, pack := range packs {
+		byTag[pack.Tag] = pack
+	}
+
+	if pack, ok := byTag[PACK_MAKE_MODEL]; ok {
+		metadata.MakerID = binary.BigEndian.Uint16(pack.Data[:2])
+		metadata.ModelCode = binary.BigEndian.Uint16(pack.Data[2:])
+	}
+
+	date, hasDate := byTag[PACK_RECORDING_DATE]
+	clock, hasClock := byTag[PACK_RECORDING_TIME]
+	if hasDate && hasClock {
+		metadata.RecordingTime, metadata.DST = recordingTime(date, clock)
+	}
+
+	if _, ok := byTag[PACK_GPS_LATITUDE_DEG]; ok {
+		metadata.GPS = decodeGPS(byTag)
+	}
+
+	return metadata
+}
+
+// recordingTime decodes the date pack (time zone, BCD year and month)
+// and the time pack (BCD day, hour, minute and second).
+//
+// The time zone byte holds the DST flag in bit 6, the sign in bit 5, the
+// hours in bits 4-1 and a half hour in bit 0.
+func recordingTime(date, clock Pack) (time.Time, bool) {
+	var fields [7]int
+	for i, b := range []byte{date.Data[1], date.Data[2], date.Data[3], clock.Data[0], clock.Data[1], clock.Data[2], clock.Data[3]} {
+		value, ok := bcd(b)
+		if !ok {
+			return time.Time{}, false
+		}
+		fields[i] = value
+	}
+
+	zone := date.Data[0]
+	offset := int(zone>>1&0x0F)*3600 + int(zone&0x01)*1800
+	if zone&0x20 != 0 {
+		offset = -offset
+	}
+	return time.Date(fields[0]*100+fields[1], time.Month(fields[2]), fields[3], fields[4], fields[5], fields[6], 0,
+		time.FixedZone("", offset)), zone&0x40 != 0
+}
+
+func decodeGPS(byTag map[uint8]Pack) *GPS {
+	degrees := func(deg, min, sec uint8) float64 {
+		return byTag[deg].rational() + byTag[min].rational()/60 + byTag[sec].rational()/3600
+	}
+	gps := &GPS{
+		Latitude:  degrees(PACK_GPS_LATITUDE_DEG, PACK_GPS_LATITUDE_MIN, PACK_GPS_LATITUDE_SEC),
+		Longitude: degrees(PACK_GPS_LONGITUDE_DEG, PACK_GPS_LONGITUDE_MIN, PACK_GPS_LONGITUDE_SEC),
+		Altitude:  byTag[PACK_GPS_ALTITUDE].rational(),
+		Time: time.Duration(byTag[PACK_GPS_TIME_HOUR].rational()*float64(time.Hour)) +
+			time.Duration(byTag[PACK_GPS_TIME_MINUTE].rational()*float64(time.Minute)) +
+			time.Duration(byTag[PACK_GPS_TIME_SECOND].rational()*float64(time.Second)),
+	}
+	if byTag[PACK_GPS_LATITUDE_REF].Data[0] == 'S' {
+		gps.Latitude = -gps.Latitude
+	}
+	if byTag[PACK_GPS_LONGITUDE_REF].Data[0] == 'W' {
+		gps.Longitude = -gps.Longitude
+	}
+	if byTag[PACK_GPS_ALTITUDE_REF].Data[0] == 1 {
+		gps.Altitude = -gps.Altitude
+	}
+	return gps
+}
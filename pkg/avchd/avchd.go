@@ -0,0 +1,70 @@
+package avchd
+
+/*
+	Remarks:
+
+	AVCHD camcorders and SD cards record into the same navigation files a
+	Blu-ray disc uses, under 8.3 names on a FAT file system:
+
+		PRIVATE/AVCHD/BDMV/INDEX.BDM
+		PRIVATE/AVCHD/BDMV/MOVIEOBJ.BDM
+		PRIVATE/AVCHD/BDMV/PLAYLIST/xxxxx.MPL
+		PRIVATE/AVCHD/BDMV/CLIPINF/xxxxx.CPI
+		PRIVATE/AVCHD/BDMV/STREAM/xxxxx.MTS
+
+	The type indicators are the Blu-ray ones ("INDX", "MOBJ", "MPLS",
+	"HDMV"); the version is "0100" for AVCHD 1.0 and "0200" or "0300" for
+	the 2.0 extensions (progressive and 3D recording).
+
+	What AVCHD adds is maker's private data in the extension block of the
+	.MPL and .CPI files (ExtDataType 0x1000, any version):
+
+		length                      32
+		if length != 0:
+		  data_block_start_address  32  from the start of the length field
+		  reserved                  24
+		  number_of_maker_entries    8
+		  for each entry:
+		    maker_ID                16
+		    maker_model_code        16
+		    mpd_start_address       32  from data_block_start_address
+		    mpd_length              32
+		  padding
+		  data blocks
+
+	Most makers fill their data blocks with the 5-byte packs of the
+	"MDPM" metadata the camera also writes into the video stream: one tag
+	byte and four data bytes. Decode picks the recording time, GPS fix and
+	make/model out of them; other packs are kept but left alone.
+*/
+
+import (
+	"fmt"
+)
+
+// MakerName returns the name of a maker_ID, or a hex string for
+// makers that are not known.
+func MakerName(id uint16) string {
+	switch id {
+	case MAKER_PANASONIC:
+		return "Panasonic"
+	case MAKER_SONY:
+		return "Sony"
+	case MAKER_CANON:
+		return "Canon"
+	case MAKER_JVC:
+		return "JVC"
+	default:
+		return fmt.Sprintf("0x%04X", id)
+	}
+}
+
+// bcd decodes a packed BCD byte. ok is false for a byte that is not BCD,
+// e.g. the 0xFF of an unset field.
+func bcd(b byte) (value int, ok bool) {
+	high, low := b>>4, b&0x0F
+	if high > 9 || low > 9 {
+		return 0, false
+	}
+	return int(high)*10 + int(low), true
+}
@@ -0,0 +1,132 @@
+package avchd
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+// testPacks is a Sony camera's fix at 35°39'30"S 139°45'0"W, 12.5 m,
+// recorded 2023-07-14 09:05:30 at UTC+9:30 with DST.
+var testPacks = []byte{
+	PACK_MAKE_MODEL, 0x01, 0x08, 0x00, 0x2A,
+	PACK_RECORDING_DATE, 0x40 | 9<<1 | 1, 0x20, 0x23, 0x07,
+	PACK_RECORDING_TIME, 0x14, 0x09, 0x05, 0x30,
+	PACK_UNUSED, 0xFF, 0xFF, 0xFF, 0xFF,
+	PACK_GPS_LATITUDE_REF, 'S', 0, 0, 0,
+	PACK_GPS_LATITUDE_DEG, 0, 35, 0, 1,
+	PACK_GPS_LATITUDE_MIN, 0, 39, 0, 1,
+	PACK_GPS_LATITUDE_SEC, 0, 30, 0, 1,
+	PACK_GPS_LONGITUDE_REF, 'W', 0, 0, 0,
+	PACK_GPS_LONGITUDE_DEG, 0, 139, 0, 1,
+	PACK_GPS_LONGITUDE_MIN, 0, 45, 0, 1,
+	PACK_GPS_LONGITUDE_SEC, 0, 0, 0, 1,
+	PACK_GPS_ALTITUDE, 0, 25, 0, 2,
+	PACK_GPS_TIME_HOUR, 0, 23, 0, 1,
+	PACK_GPS_TIME_MINUTE, 0, 35, 0, 1,
+	PACK_GPS_TIME_SECOND, 0, 30, 0, 1,
+	0x00, 0x01, // partial pack
+}
+
+func TestDecode(t *testing.T) {
+	metadata := Decode(ParsePacks(testPacks))
+
+	if len(metadata.Packs) != 15 {
+		t.Errorf("%d packs, want 15", len(metadata.Packs))
+	}
+	if MakerName(metadata.MakerID) != "Sony" || metadata.ModelCode != 0x2A {
+		t.Errorf("maker, model = %s, 0x%X", MakerName(metadata.MakerID), metadata.ModelCode)
+	}
+
+	want := time.Date(2023, time.July, 14, 9, 5, 30, 0, time.FixedZone("", 9*3600+1800))
+	if !metadata.RecordingTime.Equal(want) || !metadata.DST {
+		t.Errorf("RecordingTime, DST = %s, %t, want %s, true", metadata.RecordingTime, metadata.DST, want)
+	}
+	if _, offset := metadata.RecordingTime.Zone(); offset != 9*3600+1800 {
+		t.Errorf("zone offset = %d", offset)
+	}
+
+	gps := metadata.GPS
+	if gps == nil {
+		t.Fatal("GPS = nil")
+	}
+	if math.Abs(gps.Latitude+35.658333) > 1e-6 || math.Abs(gps.Longitude+139.75) > 1e-6 || gps.Altitude != 12.5 {
+		t.Errorf("GPS = %s", gps)
+	}
+	if gps.Time != 23*time.Hour+35*time.Minute+30*time.Second {
+		t.Errorf("GPS.Time = %s", gps.Time)
+	}
+
+	// Unset BCD fields leave the time alone.
+	if metadata := Decode(ParsePacks([]byte{
+		PACK_RECORDING_DATE, 0, 0xFF, 0xFF, 0xFF,
+		PACK_RECORDING_TIME, 0xFF, 0xFF, 0xFF, 0xFF,
+	})); !metadata.RecordingTime.IsZero() || metadata.GPS != nil {
+		t.Errorf("Decode() of unset packs = %+v", metadata)
+	}
+}
+
+func TestMakersPrivateData(t *testing.T) {
+	mpd := &MakersPrivateData{Entries: []*MakerEntry{
+		{MakerID: MAKER_SONY, MakerModelCode: 0x2A, Data: testPacks},
+		{MakerID: MAKER_CANON, MakerModelCode: 7, Data: []byte{1, 2, 3}},
+	}}
+	data, err := mpd.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseMakersPrivateData(data)
+	if err != nil {
+		t.Fatalf("ParseMakersPrivateData() error = %v", err)
+	}
+	if len(got.Entries) != 2 || got.Entries[1].MakerID != MAKER_CANON || got.Entries[1].MakerModelCode != 7 ||
+		!bytes.Equal(got.Entries[0].Data, testPacks) || !bytes.Equal(got.Entries[1].Data, []byte{1, 2, 3}) {
+		t.Errorf("ParseMakersPrivateData() = %+v", got.Entries)
+	}
+	if again, _ := got.MarshalBinary(); !bytes.Equal(again, data) {
+		t.Errorf("MarshalBinary() did not round trip")
+	}
+
+	if empty, err := ParseMakersPrivateData([]byte{0, 0, 0, 0}); err != nil || len(empty.Entries) != 0 {
+		t.Errorf("ParseMakersPrivateData(empty) = %+v, %v", empty, err)
+	}
+	if _, err := ParseMakersPrivateData(data[:len(data)-1]); err == nil {
+		t.Error("ParseMakersPrivateData() accepted truncated data")
+	}
+}
+
+func TestMakersPrivateDataKeepsLayout(t *testing.T) {
+	// Two entries with a padded entry table and their data blocks in
+	// reverse order.
+	data := []byte{
+		0, 0, 0, 40, // length
+		0, 0, 0, 0x28, // data block start
+		0, 0, 0, 2, // entry count
+		0x01, 0x08, 0, 0x2A, 0, 0, 0, 2, 0, 0, 0, 2,
+		0x01, 0x03, 0, 7, 0, 0, 0, 0, 0, 0, 0, 2,
+		0, 0, 0, 0, // padding
+		3, 4, 1, 2,
+	}
+	mpd, err := ParseMakersPrivateData(data)
+	if err != nil {
+		t.Fatalf("ParseMakersPrivateData() error = %v", err)
+	}
+	if got, _ := mpd.MarshalBinary(); !bytes.Equal(got, data) {
+		t.Errorf("MarshalBinary() = % x, want it as read % x", got, data)
+	}
+
+	mpd.Entries[1].Data = []byte{5}
+	got, err := mpd.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, data) {
+		t.Fatal("MarshalBinary() kept the bytes as read after an edit")
+	}
+	again, err := ParseMakersPrivateData(got)
+	if err != nil || len(again.Entries) != 2 || !bytes.Equal(again.Entries[0].Data, []byte{1, 2}) || !bytes.Equal(again.Entries[1].Data, []byte{5}) {
+		t.Errorf("ParseMakersPrivateData(edited) = %+v, %v", again, err)
+	}
+}
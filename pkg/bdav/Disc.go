@@ -0,0 +1,205 @@
+package bdav
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// Disc holds the parsed navigation files of one BDAV tree.
+type Disc struct {
+	Root      string // Path to the BDAV directory
+	fsys      fs.FS
+	Info      *Info
+	Playlists map[string]*Playlist // Keyed by file name, e.g. "01001.rpls"
+
+	// Problems collects every file that failed to parse. A non-empty
+	// slice does not mean the rest of the Disc is unusable.
+	Problems []error
+}
+
+// Info is the parsed content of info.bdav.
+type Info struct {
+	Header            *BDAVHeader
+	UIAppInfo         *UIAppInfoBDAV
+	TableOfPlayLists  *TableOfPlayLists
+	MakersPrivateData *avchd.MakersPrivateData
+}
+
+// Playlist is the parsed content of one PLAYLIST/*.rpls or *.vpls file.
+type Playlist struct {
+	Name              string // File name, e.g. "01001.rpls"
+	Virtual           bool   // A .vpls edit rather than a recording
+	Header            *PLSTHeader
+	UIAppInfo         *UIAppInfoPlayList
+	PlayList          *mpls.PlayList
+	Marks             *mpls.PlaylistMarks
+	MakersPrivateData *avchd.MakersPrivateData
+}
+
+// Recording is one playlist as the recorder's menu presents it.
+type Recording struct {
+	Playlist   *Playlist
+	Title      string
+	Channel    string
+	RecordTime time.Time // Zero when the recorder did not set it
+	Duration   time.Duration
+}
+
+// Open loads the BDAV tree at root. The root may be either the BDAV
+// directory itself or a disc root that contains a BDAV directory.
+// An error is returned only when root is not a usable directory; parse
+// failures are collected in Disc.Problems.
+func Open(root string) (disc *Disc, err error) {
+	return OpenFS(osFS{}, root)
+}
+
+// OpenFS is Open for the directory root of fsys, "." for its top. With
+// a disc image opened by package udf, a disc is read without mounting it.
+func OpenFS(fsys fs.FS, root string) (disc *Disc, err error) {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disc root: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("disc root %s is not a directory", root)
+	}
+	if nested := joinPath(fsys, root, "BDAV"); isDir(fsys, nested) {
+		root = nested
+	}
+
+	disc = &Disc{
+		Root:      root,
+		fsys:      fsys,
+		Playlists: make(map[string]*Playlist),
+	}
+
+	disc.loadInfo(joinPath(fsys, root, "info.bdav"))
+	disc.loadPlaylists()
+
+	return disc, nil
+}
+
+func isDir(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.IsDir()
+}
+
+// problem records a problem found while loading the disc.
+func (disc *Disc) problem(err error) {
+	disc.Problems = append(disc.Problems, err)
+}
+
+func (disc *Disc) loadInfo(filePath string) {
+	header, uiAppInfo, tableOfPlayLists, makersPrivateData, err := ParseBDAVFS(disc.fsys, filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Info = &Info{
+		Header:            header,
+		UIAppInfo:         uiAppInfo,
+		TableOfPlayLists:  tableOfPlayLists,
+		MakersPrivateData: makersPrivateData,
+	}
+}
+
+// loadPlaylists loads every .rpls and .vpls file of the PLAYLIST
+// directory. Matching is case insensitive.
+func (disc *Disc) loadPlaylists() {
+	dir := joinPath(disc.fsys, disc.Root, "PLAYLIST")
+	entries, err := fs.ReadDir(disc.fsys, dir)
+	if err != nil {
+		disc.problem(&FileError{Path: dir, Err: err})
+		return
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".rpls" && ext != ".vpls") {
+			continue
+		}
+		disc.loadPlaylist(entry.Name(), joinPath(disc.fsys, dir, entry.Name()))
+	}
+}
+
+func (disc *Disc) loadPlaylist(name, filePath string) {
+	header, uiAppInfo, playList, marks, makersPrivateData, err := ParsePLSTFS(disc.fsys, filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Playlists[name] = &Playlist{
+		Name:              name,
+		Virtual:           strings.EqualFold(filepath.Ext(name), ".vpls"),
+		Header:            header,
+		UIAppInfo:         uiAppInfo,
+		PlayList:          playList,
+		Marks:             marks,
+		MakersPrivateData: makersPrivateData,
+	}
+}
+
+// Name returns the disc name set on the recorder, if any.
+func (disc *Disc) Name() string {
+	if disc.Info == nil || disc.Info.UIAppInfo == nil {
+		return ""
+	}
+	return disc.Info.UIAppInfo.Name
+}
+
+// Recordings returns the playlists in the order of info.bdav's table of
+// playlists, followed by any playlists the table does not list, by name.
+func (disc *Disc) Recordings() (recordings []*Recording) {
+	seen := map[string]bool{}
+	add := func(name string) {
+		playlist, ok := disc.Playlists[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		recordings = append(recordings, newRecording(playlist))
+	}
+
+	if disc.Info != nil && disc.Info.TableOfPlayLists != nil {
+		for _, name := range disc.Info.TableOfPlayLists.PlayLists {
+			add(name)
+		}
+	}
+	names := make([]string, 0, len(disc.Playlists))
+	for name := range disc.Playlists {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, cmp.Compare)
+	for _, name := range names {
+		add(name)
+	}
+	return recordings
+}
+
+// newRecording takes the title, channel and time from the playlist's
+// UIAppInfo. The duration is the one the menu shows, or else the sum of
+// the PlayItems.
+func newRecording(playlist *Playlist) *Recording {
+	recording := &Recording{Playlist: playlist}
+	if ui := playlist.UIAppInfo; ui != nil {
+		recording.Title = ui.Name
+		recording.Channel = ui.ChannelName
+		recording.RecordTime = ui.RecordTime
+		recording.Duration = ui.Duration
+	}
+	if recording.Duration == 0 && playlist.PlayList != nil {
+		for _, playItem := range playlist.PlayList.PlayItems {
+			if playItem.OUTTime > playItem.INTime {
+				recording.Duration += playItem.OUTTime.Sub(playItem.INTime).Duration()
+			}
+		}
+	}
+	return recording
+}
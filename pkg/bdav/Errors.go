@@ -0,0 +1,24 @@
+package bdav
+
+import (
+	"fmt"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+// ParseError reports where in a file parsing failed.
+type ParseError = navfile.ParseError
+
+// FileError reports a file of the BDAV tree that could not be read.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
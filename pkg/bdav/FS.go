@@ -0,0 +1,25 @@
+package bdav
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// osFS opens operating system paths as they are, so that a Disc opened
+// from a directory keeps reporting the paths it was given. Its names are
+// not fs.ValidPath names; it is only meant for the Disc's own lookups.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// joinPath joins path elements the way fsys names its files.
+func joinPath(fsys fs.FS, elem ...string) string {
+	if _, ok := fsys.(osFS); ok {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
@@ -0,0 +1,143 @@
+package bdav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// BDAVHeader represents the 40 byte header of info.bdav
+type BDAVHeader struct {
+	TypeIndicator     [4]byte // "BDAV"
+	VersionNumber     [4]byte // "0100", "0200" or "0300"
+	UIAppInfo         *OffsetsUint32
+	TableOfPlayLists  *OffsetsUint32
+	MakersPrivateData *OffsetsUint32
+}
+
+// PLSTHeader represents the 40 byte header of a .rpls or .vpls file
+type PLSTHeader struct {
+	TypeIndicator     [4]byte // "PLST"
+	VersionNumber     [4]byte // "0100", "0200" or "0300"
+	UIAppInfo         *OffsetsUint32
+	Playlist          *OffsetsUint32
+	Marks             *OffsetsUint32
+	MakersPrivateData *OffsetsUint32
+}
+
+// OffsetsUint32 represents the start and stop offsets of a section.
+// A section that is not present has both Start and Stop 0.
+type OffsetsUint32 struct {
+	Start,
+	Stop int64
+}
+
+// readHeader reads the type, the version and the section start
+// addresses that follow them, then skips the reserved bytes up to the
+// 40 byte mark. It returns the file size too.
+func readHeader(file io.ReadSeeker, typeIndicator, versionNumber *[4]byte, starts []uint32) (eof int64, err error) {
+	if eof, err = file.Seek(0, io.SeekEnd); err != nil {
+		return 0, fmt.Errorf("failed to seek to file end address: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek to file start address: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, typeIndicator); err != nil {
+		return 0, fmt.Errorf("failed to read header.TypeIndicator: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, versionNumber); err != nil {
+		return 0, fmt.Errorf("failed to read header.VersionNumber: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, starts); err != nil {
+		return 0, fmt.Errorf("failed to read header start addresses: %w", err)
+	}
+
+	// skip reserve space
+	if _, err := file.Seek(40, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek past header reserve space: %w", err)
+	}
+
+	return eof, nil
+}
+
+// sections turns the start addresses of consecutive sections into
+// offsets. Each section ends where the next present one starts, the
+// last at eof. A start address of 0 marks a section as absent.
+func sections(eof int64, starts ...uint32) []*OffsetsUint32 {
+	offsets := make([]*OffsetsUint32, len(starts))
+	stop := eof
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] == 0 {
+			offsets[i] = &OffsetsUint32{}
+			continue
+		}
+		offsets[i] = &OffsetsUint32{Start: int64(starts[i]), Stop: stop}
+		stop = int64(starts[i])
+	}
+	return offsets
+}
+
+func ReadBDAVHeader(file io.ReadSeeker) (header *BDAVHeader, err error) {
+	header = &BDAVHeader{}
+
+	// TableOfPlayLists, MakersPrivateData
+	starts := make([]uint32, 2)
+	eof, err := readHeader(file, &header.TypeIndicator, &header.VersionNumber, starts)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := sections(eof, 40, starts[0], starts[1])
+	header.UIAppInfo, header.TableOfPlayLists, header.MakersPrivateData = offsets[0], offsets[1], offsets[2]
+
+	return header, nil
+}
+
+func ReadPLSTHeader(file io.ReadSeeker) (header *PLSTHeader, err error) {
+	header = &PLSTHeader{}
+
+	// PlayList, PlayListMark, MakersPrivateData
+	starts := make([]uint32, 3)
+	eof, err := readHeader(file, &header.TypeIndicator, &header.VersionNumber, starts)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := sections(eof, 40, starts[0], starts[1], starts[2])
+	header.UIAppInfo, header.Playlist, header.Marks, header.MakersPrivateData = offsets[0], offsets[1], offsets[2], offsets[3]
+
+	return header, nil
+}
+
+func (offsets *OffsetsUint32) String() string {
+	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
+}
+
+// String returns a string representation of the BDAVHeader.
+func (header *BDAVHeader) String() string {
+	return fmt.Sprintf(
+		"Header{Type: %s, Version: %s, Offset UIAppInfo: %s, Offset TableOfPlayLists: %s, Offset MakersPrivateData: %s}",
+		string(header.TypeIndicator[:]),
+		string(header.VersionNumber[:]),
+		header.UIAppInfo,
+		header.TableOfPlayLists,
+		header.MakersPrivateData,
+	)
+}
+
+// String returns a string representation of the PLSTHeader.
+func (header *PLSTHeader) String() string {
+	return fmt.Sprintf(
+		"Header{Type: %s, Version: %s, Offset UIAppInfo: %s, Offset PlayList: %s, Offset Marks: %s, Offset MakersPrivateData: %s}",
+		string(header.TypeIndicator[:]),
+		string(header.VersionNumber[:]),
+		header.UIAppInfo,
+		header.Playlist,
+		header.Marks,
+		header.MakersPrivateData,
+	)
+}
@@ -0,0 +1,51 @@
+package bdav
+
+import (
+	"unicode/utf16"
+)
+
+// character_set of the names and texts of a BDAV tree.
+const (
+	CHARACTER_SET_UTF8      = 0x01 // Unicode 8-bit
+	CHARACTER_SET_UTF16BE   = 0x02 // Unicode 16-bit Big Endian
+	CHARACTER_SET_SHIFT_JIS = 0x03 // Japanese
+	CHARACTER_SET_EUC_KR    = 0x04 // Korean
+	CHARACTER_SET_GB18030   = 0x05 // Chinese National Standard
+	CHARACTER_SET_CN_GB     = 0x06 // Chinese
+	CHARACTER_SET_BIG5      = 0x07 // Traditional Chinese
+)
+
+func CharacterSetName(code uint8) string {
+	switch code {
+	case CHARACTER_SET_UTF8:
+		return "UTF8"
+	case CHARACTER_SET_UTF16BE:
+		return "UTF16BE"
+	case CHARACTER_SET_SHIFT_JIS:
+		return "SHIFT JIS"
+	case CHARACTER_SET_EUC_KR:
+		return "EUC KR"
+	case CHARACTER_SET_GB18030:
+		return "GB18030-2000"
+	case CHARACTER_SET_CN_GB:
+		return "GB2312"
+	case CHARACTER_SET_BIG5:
+		return "BIG5"
+	default:
+		return ""
+	}
+}
+
+// decodeText returns text in the given character set as a Go string.
+// UTF-16 is converted; the legacy character sets are returned byte for
+// byte, for the caller to convert with the CharacterSet of the section.
+func decodeText(characterSet uint8, text []byte) string {
+	if characterSet != CHARACTER_SET_UTF16BE {
+		return string(text)
+	}
+	units := make([]uint16, len(text)/2)
+	for i := range units {
+		units[i] = uint16(text[2*i])<<8 | uint16(text[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
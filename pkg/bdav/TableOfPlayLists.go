@@ -0,0 +1,43 @@
+package bdav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// TableOfPlayLists lists the playlists of the disc in the order the
+// recorder's menu shows them.
+type TableOfPlayLists struct {
+	Length            uint32
+	NumberOfPlayLists uint16
+	PlayLists         []string // File names, e.g. "01001.rpls"
+}
+
+func ReadTableOfPlayLists(file io.ReadSeeker, offsets *OffsetsUint32) (table *TableOfPlayLists, err error) {
+	table = &TableOfPlayLists{}
+
+	// Jump to start address
+	if _, err := file.Seek(offsets.Start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to start address: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &table.Length); err != nil {
+		return nil, fmt.Errorf("failed to read table.Length: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &table.NumberOfPlayLists); err != nil {
+		return nil, fmt.Errorf("failed to read table.NumberOfPlayLists: %w", err)
+	}
+
+	table.PlayLists = make([]string, table.NumberOfPlayLists)
+	for i := range table.PlayLists {
+		var name [10]byte
+		if err := binary.Read(file, binary.BigEndian, &name); err != nil {
+			return nil, &ParseError{Offset: offsets.Start + 6 + 10*int64(i), Path: fmt.Sprintf("TableOfPlayLists.PlayLists[%d]", i), Err: err}
+		}
+		table.PlayLists[i] = string(name[:])
+	}
+
+	return table, nil
+}
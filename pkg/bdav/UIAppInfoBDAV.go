@@ -0,0 +1,59 @@
+package bdav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// UIAppInfoBDAV is what the recorder shows about the disc as a whole.
+type UIAppInfoBDAV struct {
+	Length                  uint32
+	CharacterSet            uint8
+	LanguageCode            [3]byte
+	ProtectFlag             bool    // The disc is PIN protected
+	PIN                     [4]byte // Four ASCII digits
+	ResumeValidFlag         bool
+	ResumePlayList          string // File name, e.g. "01001.rpls"; empty when not valid
+	RefToMenuThumbnailIndex uint16 // 0xFFFF when there is none
+	Name                    string // The disc name, e.g. "Holiday 2019"
+}
+
+func ReadUIAppInfoBDAV(file io.ReadSeeker, offsets *OffsetsUint32) (uiAppInfo *UIAppInfoBDAV, err error) {
+	uiAppInfo = &UIAppInfoBDAV{}
+
+	// Jump to start address
+	if _, err := file.Seek(offsets.Start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to start address: %w", err)
+	}
+
+	var fixed struct {
+		Length                  uint32
+		CharacterSet            uint8
+		LanguageCode            [3]byte
+		ProtectFlag             uint8 // 7-bits reserve, 1-bit flag
+		PIN                     [4]byte
+		ResumeValidFlag         uint8 // 7-bits reserve, 1-bit flag
+		ResumePlayList          [10]byte
+		RefToMenuThumbnailIndex uint16
+		NameLength              uint8
+		Name                    [255]byte
+	}
+	if err := binary.Read(file, binary.BigEndian, &fixed); err != nil {
+		return nil, fmt.Errorf("failed to read UIAppInfoBDAV: %w", err)
+	}
+
+	uiAppInfo.Length = fixed.Length
+	uiAppInfo.CharacterSet = fixed.CharacterSet
+	uiAppInfo.LanguageCode = fixed.LanguageCode
+	uiAppInfo.ProtectFlag = fixed.ProtectFlag&0x01 != 0
+	uiAppInfo.PIN = fixed.PIN
+	uiAppInfo.ResumeValidFlag = fixed.ResumeValidFlag&0x01 != 0
+	if uiAppInfo.ResumeValidFlag {
+		uiAppInfo.ResumePlayList = string(fixed.ResumePlayList[:])
+	}
+	uiAppInfo.RefToMenuThumbnailIndex = fixed.RefToMenuThumbnailIndex
+	uiAppInfo.Name = decodeText(fixed.CharacterSet, fixed.Name[:min(int(fixed.NameLength), len(fixed.Name))])
+
+	return uiAppInfo, nil
+}
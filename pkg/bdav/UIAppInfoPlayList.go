@@ -0,0 +1,105 @@
+package bdav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// UIAppInfoPlayList is what the recorder shows about one recording.
+type UIAppInfoPlayList struct {
+	Length         uint32
+	CharacterSet   uint8
+	LanguageCode   [3]byte
+	ProtectFlag    bool          // The playlist is PIN protected
+	IsPlayedFlag   bool          // The recording has been watched
+	IsEditedFlag   bool          // The recording has been edited since it was made
+	RecordTime     time.Time     // Recorder wall clock; zero when not set
+	Duration       time.Duration // As shown in the menu
+	MakerID        uint16
+	MakerModelCode uint16
+	ChannelNumber  uint16
+	ChannelName    string // e.g. "BBC One HD"
+	Name           string // The programme title
+	Detail         string // The programme description, from the EPG
+}
+
+func ReadUIAppInfoPlayList(file io.ReadSeeker, offsets *OffsetsUint32) (uiAppInfo *UIAppInfoPlayList, err error) {
+	uiAppInfo = &UIAppInfoPlayList{}
+
+	// Jump to start address
+	if _, err := file.Seek(offsets.Start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to start address: %w", err)
+	}
+
+	var fixed struct {
+		Length            uint32
+		CharacterSet      uint8
+		LanguageCode      [3]byte
+		Flags             uint8 // protect, is_played, is_edited, 5-bits reserve
+		_                 uint8
+		RecordTime        [7]byte // BCD YYYY MM DD hh mm ss
+		_                 uint8
+		Duration          [3]byte // BCD hh mm ss
+		MakerID           uint16
+		MakerModelCode    uint16
+		ChannelNumber     uint16
+		ChannelNameLength uint8
+		ChannelName       [20]byte
+		NameLength        uint8
+		Name              [255]byte
+		DetailLength      uint16
+	}
+	if err := binary.Read(file, binary.BigEndian, &fixed); err != nil {
+		return nil, fmt.Errorf("failed to read UIAppInfoPlayList: %w", err)
+	}
+
+	detail := make([]byte, min(int(fixed.DetailLength), 1200))
+	if _, err := io.ReadFull(file, detail); err != nil {
+		return nil, fmt.Errorf("failed to read UIAppInfoPlayList.Detail: %w", err)
+	}
+
+	uiAppInfo.Length = fixed.Length
+	uiAppInfo.CharacterSet = fixed.CharacterSet
+	uiAppInfo.LanguageCode = fixed.LanguageCode
+	uiAppInfo.ProtectFlag = fixed.Flags&0x80 != 0
+	uiAppInfo.IsPlayedFlag = fixed.Flags&0x40 != 0
+	uiAppInfo.IsEditedFlag = fixed.Flags&0x20 != 0
+	uiAppInfo.RecordTime = bcdTime(fixed.RecordTime)
+	if hms, ok := bcdFields(fixed.Duration[:]); ok {
+		uiAppInfo.Duration = time.Duration(hms[0])*time.Hour + time.Duration(hms[1])*time.Minute + time.Duration(hms[2])*time.Second
+	}
+	uiAppInfo.MakerID = fixed.MakerID
+	uiAppInfo.MakerModelCode = fixed.MakerModelCode
+	uiAppInfo.ChannelNumber = fixed.ChannelNumber
+	uiAppInfo.ChannelName = decodeText(fixed.CharacterSet, fixed.ChannelName[:min(int(fixed.ChannelNameLength), len(fixed.ChannelName))])
+	uiAppInfo.Name = decodeText(fixed.CharacterSet, fixed.Name[:min(int(fixed.NameLength), len(fixed.Name))])
+	uiAppInfo.Detail = decodeText(fixed.CharacterSet, detail)
+
+	return uiAppInfo, nil
+}
+
+// bcdFields decodes packed BCD bytes, two digits each. ok is false when
+// any byte is not BCD, e.g. the 0xFF of an unset field.
+func bcdFields(data []byte) (fields []int, ok bool) {
+	fields = make([]int, len(data))
+	for i, b := range data {
+		if b>>4 > 9 || b&0x0F > 9 {
+			return nil, false
+		}
+		fields[i] = int(b>>4)*10 + int(b&0x0F)
+	}
+	return fields, true
+}
+
+// bcdTime decodes a BCD YYYY MM DD hh mm ss time stamp. The file does not
+// say which time zone the recorder's clock was set to, so the time is
+// given in UTC.
+func bcdTime(data [7]byte) time.Time {
+	fields, ok := bcdFields(data[:])
+	if !ok || fields[2] == 0 {
+		return time.Time{}
+	}
+	return time.Date(fields[0]*100+fields[1], time.Month(fields[2]), fields[3], fields[4], fields[5], fields[6], 0, time.UTC)
+}
@@ -0,0 +1,225 @@
+package bdav
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+/*
+	Remarks:
+
+	Set-top recorders write BD-RE and BD-R discs in the BDAV format:
+
+		BDAV/info.bdav
+		BDAV/menu.tidx, BDAV/menu.tdt1, menu.tdt2   (thumbnails, not parsed)
+		BDAV/PLAYLIST/xxxxx.rpls                    (real playlists)
+		BDAV/PLAYLIST/xxxxx.vpls                    (virtual playlists)
+		BDAV/CLIPINF/xxxxx.clpi
+		BDAV/STREAM/xxxxx.m2ts
+
+	A real playlist owns its clips; it is a recording. A virtual playlist
+	is an edit that only refers to parts of the clips of real playlists.
+
+	info.bdav:
+
+		type_indicator "BDAV", version_number     8 bytes
+		TableOfPlayLists_start_address           32
+		MakersPrivateData_start_address          32
+		reserved                                 24 bytes
+		UIAppInfoBDAV                            at 40
+		TableOfPlayLists
+		MakersPrivateData
+
+	xxxxx.rpls and xxxxx.vpls:
+
+		type_indicator "PLST", version_number     8 bytes
+		PlayList_start_address                   32
+		PlayListMark_start_address               32
+		MakersPrivateData_start_address          32
+		reserved                                 20 bytes
+		UIAppInfoPlayList                        at 40
+		PlayList
+		PlayListMark
+		MakersPrivateData
+
+	PlayList and PlayListMark have the layout of their mpls namesakes and
+	are read with the mpls readers. MakersPrivateData is the structure
+	AVCHD uses too; see package avchd.
+
+	UIAppInfoPlayList holds what the recorder's menu shows: the programme
+	title and description, the channel and the recording time. Names are
+	in the CharacterSet of their section; UTF-16 is converted, other
+	character sets are left as they are.
+*/
+
+// ParseBDAV parses an info.bdav file and returns its sections.
+// makersPrivateData is nil when the file has none.
+func ParseBDAV(filePath string) (
+	header *BDAVHeader,
+	uiAppInfo *UIAppInfoBDAV,
+	tableOfPlayLists *TableOfPlayLists,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseBDAV(file, filePath)
+}
+
+// ParseBDAVFS is ParseBDAV for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseBDAVFS(fsys fs.FS, name string) (
+	header *BDAVHeader,
+	uiAppInfo *UIAppInfoBDAV,
+	tableOfPlayLists *TableOfPlayLists,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseBDAV(file, name)
+}
+
+func parseBDAV(file io.ReadSeeker, filePath string) (
+	header *BDAVHeader,
+	uiAppInfo *UIAppInfoBDAV,
+	tableOfPlayLists *TableOfPlayLists,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	// Header
+	if header, err = ReadBDAVHeader(file); err != nil {
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
+	}
+
+	// UIAppInfoBDAV
+	if uiAppInfo, err = ReadUIAppInfoBDAV(file, header.UIAppInfo); err != nil {
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "UIAppInfoBDAV", header.UIAppInfo.Start, err)
+	}
+
+	// TableOfPlayLists
+	if tableOfPlayLists, err = ReadTableOfPlayLists(file, header.TableOfPlayLists); err != nil {
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "TableOfPlayLists", header.TableOfPlayLists.Start, err)
+	}
+
+	// MakersPrivateData
+	if makersPrivateData, err = ReadMakersPrivateData(file, header.MakersPrivateData); err != nil {
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "MakersPrivateData", header.MakersPrivateData.Start, err)
+	}
+
+	return header, uiAppInfo, tableOfPlayLists, makersPrivateData, nil
+}
+
+// ParsePLST parses a .rpls or .vpls file and returns its sections.
+// makersPrivateData is nil when the file has none.
+func ParsePLST(filePath string) (
+	header *PLSTHeader,
+	uiAppInfo *UIAppInfoPlayList,
+	playlist *mpls.PlayList,
+	marks *mpls.PlaylistMarks,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parsePLST(file, filePath)
+}
+
+// ParsePLSTFS is ParsePLST for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParsePLSTFS(fsys fs.FS, name string) (
+	header *PLSTHeader,
+	uiAppInfo *UIAppInfoPlayList,
+	playlist *mpls.PlayList,
+	marks *mpls.PlaylistMarks,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parsePLST(file, name)
+}
+
+func parsePLST(file io.ReadSeeker, filePath string) (
+	header *PLSTHeader,
+	uiAppInfo *UIAppInfoPlayList,
+	playlist *mpls.PlayList,
+	marks *mpls.PlaylistMarks,
+	makersPrivateData *avchd.MakersPrivateData,
+	err error,
+) {
+	// Header
+	if header, err = ReadPLSTHeader(file); err != nil {
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
+	}
+
+	// UIAppInfoPlayList
+	if uiAppInfo, err = ReadUIAppInfoPlayList(file, header.UIAppInfo); err != nil {
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "UIAppInfoPlayList", header.UIAppInfo.Start, err)
+	}
+
+	// PlayList
+	if playlist, err = mpls.ReadPlayList(file, &mpls.OffsetsUint32{Start: header.Playlist.Start, Stop: header.Playlist.Stop}); err != nil {
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "PlayList", header.Playlist.Start, err)
+	}
+
+	// PlayListMark
+	if marks, err = mpls.ReadMarks(file, &mpls.OffsetsUint32{Start: header.Marks.Start, Stop: header.Marks.Stop}); err != nil {
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "PlayListMark", header.Marks.Start, err)
+	}
+
+	// MakersPrivateData
+	if makersPrivateData, err = ReadMakersPrivateData(file, header.MakersPrivateData); err != nil {
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "MakersPrivateData", header.MakersPrivateData.Start, err)
+	}
+
+	return header, uiAppInfo, playlist, marks, makersPrivateData, nil
+}
+
+// ReadMakersPrivateData reads the maker's private data section. It
+// returns nil for a section that is not present.
+func ReadMakersPrivateData(file io.ReadSeeker, offsets *OffsetsUint32) (*avchd.MakersPrivateData, error) {
+	if offsets.Start == 0 {
+		return nil, nil
+	}
+
+	// Jump to start address
+	if _, err := file.Seek(offsets.Start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to start address: %w", err)
+	}
+
+	// Sanity check
+	if offsets.Stop < offsets.Start {
+		return nil, fmt.Errorf("section ends at %d before it starts at %d", offsets.Stop, offsets.Start)
+	}
+
+	data := make([]byte, offsets.Stop-offsets.Start)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, fmt.Errorf("failed to read MakersPrivateData: %w", err)
+	}
+
+	return avchd.ParseMakersPrivateData(data)
+}
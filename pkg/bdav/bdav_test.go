@@ -0,0 +1,205 @@
+package bdav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// fields writes big-endian fields one after the other.
+func fields(values ...any) []byte {
+	buf := &bytes.Buffer{}
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			value = []byte(text)
+		}
+		binary.Write(buf, binary.BigEndian, value)
+	}
+	return buf.Bytes()
+}
+
+// padded returns text in a field of size bytes.
+func padded(text string, size int) []byte {
+	return append([]byte(text), make([]byte, size-len(text))...)
+}
+
+// file lays out a 40 byte header and its sections, filling in the start
+// addresses of every section but the first.
+func file(typeIndicator string, sections ...[]byte) []byte {
+	header := fields(typeIndicator, "0100")
+	start := 40 + len(sections[0])
+	for _, section := range sections[1:] {
+		header = binary.BigEndian.AppendUint32(header, uint32(start))
+		start += len(section)
+	}
+	header = append(header, make([]byte, 40-len(header))...)
+	return slices.Concat(append([][]byte{header}, sections...)...)
+}
+
+func testInfo() []byte {
+	// The disc name in UTF-16.
+	name := fields([]uint16{'T', 'V', ' ', 0x00E9})
+	uiAppInfo := slices.Concat(
+		fields(uint8(CHARACTER_SET_UTF16BE), "eng", uint8(0), "0000", uint8(1), "01002.rpls", uint16(0xFFFF)),
+		fields(uint8(len(name))), padded(string(name), 255),
+	)
+	mpd, _ := (&avchd.MakersPrivateData{Entries: []*avchd.MakerEntry{{MakerID: avchd.MAKER_PANASONIC, Data: []byte{1, 2}}}}).MarshalBinary()
+	return file("BDAV",
+		slices.Concat(fields(uint32(len(uiAppInfo))), uiAppInfo),
+		fields(uint32(22), uint16(2), "01002.rpls", "01001.rpls"),
+		mpd,
+	)
+}
+
+func testPlaylist(t *testing.T, title, channel string, recordTime, duration []byte) []byte {
+	t.Helper()
+	_, _, playlist, marks, _, err := mpls.ParseMPLS("../mpls/testdata/00000.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	playlistBytes, err := playlist.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	marksBytes, err := marks.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uiAppInfo := slices.Concat(
+		fields(uint8(CHARACTER_SET_UTF8), "eng", uint8(0x40), uint8(0)),
+		recordTime, []byte{0}, duration,
+		fields(uint16(avchd.MAKER_SONY), uint16(0x31), uint16(101)),
+		fields(uint8(len(channel))), padded(channel, 20),
+		fields(uint8(len(title))), padded(title, 255),
+		fields(uint16(4), "News"),
+	)
+	return file("PLST", slices.Concat(fields(uint32(len(uiAppInfo))), uiAppInfo), playlistBytes, marksBytes)
+}
+
+func writeFile(t *testing.T, filePath string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParsePLST(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "01001.rpls")
+	writeFile(t, filePath, testPlaylist(t, "Evening News", "BBC One",
+		[]byte{0x20, 0x19, 0x12, 0x24, 0x18, 0x00, 0x05}, []byte{0x00, 0x29, 0x30}))
+
+	header, ui, playlist, marks, mpd, err := ParsePLST(filePath)
+	if err != nil {
+		t.Fatalf("ParsePLST() error = %v", err)
+	}
+	if string(header.TypeIndicator[:]) != "PLST" || header.MakersPrivateData.Start != 0 {
+		t.Errorf("header = %s", header)
+	}
+	if ui.Name != "Evening News" || ui.ChannelName != "BBC One" || ui.ChannelNumber != 101 || ui.Detail != "News" ||
+		!ui.IsPlayedFlag || ui.ProtectFlag || ui.MakerID != avchd.MAKER_SONY {
+		t.Errorf("UIAppInfoPlayList = %+v", ui)
+	}
+	if want := time.Date(2019, time.December, 24, 18, 0, 5, 0, time.UTC); !ui.RecordTime.Equal(want) {
+		t.Errorf("RecordTime = %s, want %s", ui.RecordTime, want)
+	}
+	if ui.Duration != 29*time.Minute+30*time.Second {
+		t.Errorf("Duration = %s", ui.Duration)
+	}
+	if len(playlist.PlayItems) == 0 || marks == nil || mpd != nil {
+		t.Errorf("PlayList, Marks, MakersPrivateData = %v, %v, %v", playlist, marks, mpd)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "BDAV", "info.bdav"), testInfo())
+	writeFile(t, filepath.Join(root, "BDAV", "PLAYLIST", "01001.rpls"), testPlaylist(t, "Film", "ZDF",
+		[]byte{0x20, 0x20, 0x01, 0x02, 0x20, 0x15, 0x00}, []byte{0xFF, 0xFF, 0xFF}))
+	writeFile(t, filepath.Join(root, "BDAV", "PLAYLIST", "01002.rpls"), testPlaylist(t, "News", "ARD",
+		[]byte{0x20, 0x20, 0x01, 0x01, 0x20, 0x00, 0x00}, []byte{0x00, 0x15, 0x00}))
+	writeFile(t, filepath.Join(root, "BDAV", "PLAYLIST", "02001.VPLS"), testPlaylist(t, "Film (cut)", "ZDF",
+		[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, []byte{0x01, 0x00, 0x00}))
+	writeFile(t, filepath.Join(root, "BDAV", "PLAYLIST", "03001.rpls"), []byte("PLST0100"))
+
+	disc, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if disc.Name() != "TV é" || disc.Info.UIAppInfo.ResumePlayList != "01002.rpls" {
+		t.Errorf("Name(), ResumePlayList = %q, %q", disc.Name(), disc.Info.UIAppInfo.ResumePlayList)
+	}
+	if mpd := disc.Info.MakersPrivateData; mpd == nil || len(mpd.Entries) != 1 || mpd.Entries[0].MakerID != avchd.MAKER_PANASONIC {
+		t.Errorf("MakersPrivateData = %+v", mpd)
+	}
+
+	// The table lists 01002 first; the virtual playlist is not in it.
+	var titles []string
+	for _, recording := range disc.Recordings() {
+		titles = append(titles, recording.Title)
+	}
+	if want := []string{"News", "Film", "Film (cut)"}; !slices.Equal(titles, want) {
+		t.Fatalf("Recordings() titles = %v, want %v", titles, want)
+	}
+	recordings := disc.Recordings()
+	if recordings[0].Channel != "ARD" || recordings[0].Duration != 15*time.Minute {
+		t.Errorf("Recordings()[0] = %+v", recordings[0])
+	}
+	// No duration in the menu: the PlayItems are summed.
+	if recordings[1].Duration == 0 || recordings[1].Playlist.Virtual {
+		t.Errorf("Recordings()[1] = %+v", recordings[1])
+	}
+	if !recordings[2].Playlist.Virtual || !recordings[2].RecordTime.IsZero() {
+		t.Errorf("Recordings()[2] = %+v", recordings[2])
+	}
+
+	var fileErr *FileError
+	if len(disc.Problems) != 1 || !errors.As(disc.Problems[0], &fileErr) || filepath.Base(fileErr.Path) != "03001.rpls" {
+		t.Errorf("Problems = %v, want 03001.rpls", disc.Problems)
+	}
+}
+
+func TestNewRecordingSkipsInvertedPlayItems(t *testing.T) {
+	playlist := &Playlist{PlayList: &mpls.PlayList{PlayItems: []*mpls.PlayItem{
+		{INTime: 45000, OUTTime: 90000},
+		{INTime: 90000, OUTTime: 45000},
+	}}}
+	if got := newRecording(playlist).Duration; got != time.Second {
+		t.Errorf("Duration = %s, want 1s", got)
+	}
+}
+
+func TestOpenFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"BDAV/info.bdav": {Data: testInfo()},
+		"BDAV/PLAYLIST/01002.rpls": {Data: testPlaylist(t, "News", "ARD",
+			[]byte{0x20, 0x20, 0x01, 0x01, 0x20, 0x00, 0x00}, []byte{0x00, 0x15, 0x00})},
+	}
+
+	disc, err := OpenFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS() error = %v", err)
+	}
+	if disc.Root != "BDAV" || disc.Name() != "TV é" || len(disc.Problems) != 0 {
+		t.Fatalf("Root, Name(), Problems = %q, %q, %v", disc.Root, disc.Name(), disc.Problems)
+	}
+	if recordings := disc.Recordings(); len(recordings) != 1 || recordings[0].Title != "News" {
+		t.Errorf("Recordings() = %+v", recordings)
+	}
+
+	if _, err := OpenFS(fsys, "BDAV/info.bdav"); err == nil {
+		t.Error("OpenFS() accepted a file as the disc root")
+	}
+}
@@ -0,0 +1,71 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// AppCacheInfo lists the JAR files and directories the player loads
+// into its application cache before the applications start.
+type AppCacheInfo struct {
+	Length        uint32
+	NumberOfItems uint8
+	AppCacheItems []*AppCacheItem
+}
+
+type AppCacheItem struct {
+	Type     uint8   // APP_CACHE_TYPE_JAR or APP_CACHE_TYPE_DIRECTORY
+	Name     [5]byte // BDMV/JAR/xxxxx.jar or the directory BDMV/JAR/xxxxx
+	Language [3]byte // ISO 639-2
+}
+
+// ReadAppCacheInfo reads the AppCacheInfo at the current position and
+// leaves the file at its end.
+func ReadAppCacheInfo(file io.ReadSeeker) (appCacheInfo *AppCacheInfo, err error) {
+	appCacheInfo = &AppCacheInfo{}
+
+	if err := binary.Read(file, binary.BigEndian, &appCacheInfo.Length); err != nil {
+		return nil, fmt.Errorf("failed to read appCacheInfo.Length: %w", err)
+	}
+
+	end, err := CalculateEndOffset(file, appCacheInfo.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &appCacheInfo.NumberOfItems); err != nil {
+		return nil, fmt.Errorf("failed to read NumberOfItems: %w", err)
+	}
+
+	// skip 1-byte reserve space
+	if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
+	}
+
+	appCacheInfo.AppCacheItems = make([]*AppCacheItem, appCacheInfo.NumberOfItems)
+	for i := range appCacheInfo.AppCacheItems {
+		item := &AppCacheItem{}
+		if err := binary.Read(file, binary.BigEndian, &item.Type); err != nil {
+			return nil, fmt.Errorf("failed to read AppCacheItems[%d].Type: %w", i, err)
+		}
+		if err := binary.Read(file, binary.BigEndian, &item.Name); err != nil {
+			return nil, fmt.Errorf("failed to read AppCacheItems[%d].Name: %w", i, err)
+		}
+		if err := binary.Read(file, binary.BigEndian, &item.Language); err != nil {
+			return nil, fmt.Errorf("failed to read AppCacheItems[%d].Language: %w", i, err)
+		}
+
+		// skip 3-byte reserve space
+		if _, err := file.Seek(3, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
+		}
+		appCacheInfo.AppCacheItems[i] = item
+	}
+
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to the end of AppCacheInfo: %w", err)
+	}
+
+	return appCacheInfo, nil
+}
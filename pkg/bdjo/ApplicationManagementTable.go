@@ -0,0 +1,240 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ApplicationManagementTable lists the Xlets the BD-J object runs.
+type ApplicationManagementTable struct {
+	Length               uint32
+	NumberOfApplications uint8
+	Applications         []*Application
+}
+
+type Application struct {
+	ControlCode        uint8  // CONTROL_CODE_AUTOSTART, ...
+	Type               uint8  // 4-bits 0b11110000
+	OrganizationID     uint32 // With ApplicationID, the Xlet's AppID
+	ApplicationID      uint16
+	DescriptorLength   uint32
+	Profiles           []*ApplicationProfile // 4-bit count
+	Priority           uint8
+	Binding            uint8 // 2-bits 0b11000000
+	Visibility         uint8 // 2-bits 0b00110000
+	Names              []*ApplicationName
+	IconLocator        string
+	IconFlags          uint16
+	BaseDirectory      string // Under BDMV/JAR, e.g. "00000"
+	ClasspathExtension string
+	InitialClass       string // The Xlet class, e.g. "com.example.MainXlet"
+	Parameters         []string
+}
+
+// ApplicationProfile is a BD-J profile and version the application needs.
+type ApplicationProfile struct {
+	Profile      uint16
+	MajorVersion uint8
+	MinorVersion uint8
+	MicroVersion uint8
+}
+
+type ApplicationName struct {
+	Language [3]byte // ISO 639-2
+	Name     string
+}
+
+// ReadApplicationManagementTable reads the table at the current position
+// and leaves the file at its end.
+func ReadApplicationManagementTable(file io.ReadSeeker) (table *ApplicationManagementTable, err error) {
+	table = &ApplicationManagementTable{}
+
+	if err := binary.Read(file, binary.BigEndian, &table.Length); err != nil {
+		return nil, fmt.Errorf("failed to read table.Length: %w", err)
+	}
+
+	end, err := CalculateEndOffset(file, table.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &table.NumberOfApplications); err != nil {
+		return nil, fmt.Errorf("failed to read NumberOfApplications: %w", err)
+	}
+
+	// skip 1-byte reserve space
+	if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
+	}
+
+	table.Applications = make([]*Application, table.NumberOfApplications)
+	for i := range table.Applications {
+		start, _ := ftell(file)
+		if table.Applications[i], err = ReadApplication(file); err != nil {
+			return nil, &ParseError{
+				Offset: start,
+				Path:   fmt.Sprintf("ApplicationManagementTable.Applications[%d]", i),
+				Err:    err,
+			}
+		}
+	}
+
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to the end of ApplicationManagementTable: %w", err)
+	}
+
+	return table, nil
+}
+
+func ReadApplication(file io.ReadSeeker) (app *Application, err error) {
+	app = &Application{}
+
+	if err := binary.Read(file, binary.BigEndian, &app.ControlCode); err != nil {
+		return nil, fmt.Errorf("failed to read ControlCode: %w", err)
+	}
+
+	var buffer uint8
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	app.Type = (buffer & 0xF0) >> 4
+
+	if err := binary.Read(file, binary.BigEndian, &app.OrganizationID); err != nil {
+		return nil, fmt.Errorf("failed to read OrganizationID: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &app.ApplicationID); err != nil {
+		return nil, fmt.Errorf("failed to read ApplicationID: %w", err)
+	}
+
+	// The application descriptor
+	if err := binary.Read(file, binary.BigEndian, &app.DescriptorLength); err != nil {
+		return nil, fmt.Errorf("failed to read DescriptorLength: %w", err)
+	}
+
+	end, err := CalculateEndOffset(file, app.DescriptorLength)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	app.Profiles = make([]*ApplicationProfile, (buffer&0xF0)>>4)
+	for i := range app.Profiles {
+		profile := &ApplicationProfile{}
+		if err := binary.Read(file, binary.BigEndian, &profile.Profile); err != nil {
+			return nil, fmt.Errorf("failed to read Profiles[%d].Profile: %w", i, err)
+		}
+		var version [4]uint8 // major, minor, micro, reserved
+		if err := binary.Read(file, binary.BigEndian, &version); err != nil {
+			return nil, fmt.Errorf("failed to read Profiles[%d] version: %w", i, err)
+		}
+		profile.MajorVersion, profile.MinorVersion, profile.MicroVersion = version[0], version[1], version[2]
+		app.Profiles[i] = profile
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &app.Priority); err != nil {
+		return nil, fmt.Errorf("failed to read Priority: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	app.Binding = (buffer & 0xC0) >> 6
+	app.Visibility = (buffer & 0x30) >> 4
+
+	// Names, padded to a 16-bit boundary
+	var namesLength uint16
+	if err := binary.Read(file, binary.BigEndian, &namesLength); err != nil {
+		return nil, fmt.Errorf("failed to read names length: %w", err)
+	}
+	namesEnd, err := CalculateEndOffset(file, namesLength)
+	if err != nil {
+		return nil, err
+	}
+	for pos, _ := ftell(file); pos < namesEnd; pos, _ = ftell(file) {
+		name := &ApplicationName{}
+		if err := binary.Read(file, binary.BigEndian, &name.Language); err != nil {
+			return nil, fmt.Errorf("failed to read Names[%d].Language: %w", len(app.Names), err)
+		}
+		var length uint8
+		if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+			return nil, fmt.Errorf("failed to read Names[%d] length: %w", len(app.Names), err)
+		}
+		if name.Name, err = readString(file, int(length)); err != nil {
+			return nil, fmt.Errorf("failed to read Names[%d].Name: %w", len(app.Names), err)
+		}
+		app.Names = append(app.Names, name)
+	}
+	if _, err := file.Seek(namesEnd+int64(namesLength%2), io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek past names: %w", err)
+	}
+
+	if app.IconLocator, err = readPaddedString(file); err != nil {
+		return nil, fmt.Errorf("failed to read IconLocator: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &app.IconFlags); err != nil {
+		return nil, fmt.Errorf("failed to read IconFlags: %w", err)
+	}
+
+	if app.BaseDirectory, err = readPaddedString(file); err != nil {
+		return nil, fmt.Errorf("failed to read BaseDirectory: %w", err)
+	}
+
+	if app.ClasspathExtension, err = readPaddedString(file); err != nil {
+		return nil, fmt.Errorf("failed to read ClasspathExtension: %w", err)
+	}
+
+	if app.InitialClass, err = readPaddedString(file); err != nil {
+		return nil, fmt.Errorf("failed to read InitialClass: %w", err)
+	}
+
+	// Parameters, padded to a 16-bit boundary
+	var parametersLength uint8
+	if err := binary.Read(file, binary.BigEndian, &parametersLength); err != nil {
+		return nil, fmt.Errorf("failed to read parameters length: %w", err)
+	}
+	parametersEnd, err := CalculateEndOffset(file, parametersLength)
+	if err != nil {
+		return nil, err
+	}
+	for pos, _ := ftell(file); pos < parametersEnd; pos, _ = ftell(file) {
+		var length uint8
+		if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+			return nil, fmt.Errorf("failed to read Parameters[%d] length: %w", len(app.Parameters), err)
+		}
+		parameter, err := readString(file, int(length))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Parameters[%d]: %w", len(app.Parameters), err)
+		}
+		app.Parameters = append(app.Parameters, parameter)
+	}
+
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to the end of the application descriptor: %w", err)
+	}
+
+	return app, nil
+}
+
+// readPaddedString reads an 8-bit length and that many bytes, then skips
+// the byte that pads the field to a 16-bit boundary.
+func readPaddedString(file io.ReadSeeker) (string, error) {
+	var length uint8
+	if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	text, err := readString(file, int(length))
+	if err != nil {
+		return "", err
+	}
+	if length%2 == 0 {
+		if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,6 @@
+package bdjo
+
+import "github.com/parasense/bdmv_go/internal/navfile"
+
+// ParseError reports where in a file parsing failed.
+type ParseError = navfile.ParseError
@@ -0,0 +1,63 @@
+package bdjo
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed .bdjo file: the sections
+// ParseBDJO returns one by one.
+type File struct {
+	Header                     *BDJOHeader
+	TerminalInfo               *TerminalInfo
+	AppCacheInfo               *AppCacheInfo
+	TableOfAccessiblePlaylists *TableOfAccessiblePlaylists
+	ApplicationManagementTable *ApplicationManagementTable
+	KeyInterestTable           *KeyInterestTable
+	FileAccessInfo             *FileAccessInfo
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *BDJOHeader,
+	terminalInfo *TerminalInfo,
+	appCacheInfo *AppCacheInfo,
+	tableOfAccessiblePlaylists *TableOfAccessiblePlaylists,
+	applicationManagementTable *ApplicationManagementTable,
+	keyInterestTable *KeyInterestTable,
+	fileAccessInfo *FileAccessInfo,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, terminalInfo, appCacheInfo, tableOfAccessiblePlaylists, applicationManagementTable, keyInterestTable, fileAccessInfo}, nil
+}
+
+// ParseFile parses the .bdjo file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseBDJO(filePath))
+}
+
+// ParseFS parses the .bdjo file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseBDJOFS(fsys, name))
+}
+
+// ParseReader parses a .bdjo file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseBDJO(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as a .bdjo file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses a .bdjo file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
@@ -0,0 +1,27 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// FileAccessInfo is the directory of the disc the applications may read.
+type FileAccessInfo struct {
+	Length uint16
+	Path   string // e.g. "." for the whole disc
+}
+
+func ReadFileAccessInfo(file io.ReadSeeker) (fileAccessInfo *FileAccessInfo, err error) {
+	fileAccessInfo = &FileAccessInfo{}
+
+	if err := binary.Read(file, binary.BigEndian, &fileAccessInfo.Length); err != nil {
+		return nil, fmt.Errorf("failed to read fileAccessInfo.Length: %w", err)
+	}
+
+	if fileAccessInfo.Path, err = readString(file, int(fileAccessInfo.Length)); err != nil {
+		return nil, fmt.Errorf("failed to read Path: %w", err)
+	}
+
+	return fileAccessInfo, nil
+}
@@ -0,0 +1,66 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// BDJOHeader represents the 12 byte header of a BDJO file
+type BDJOHeader struct {
+	TypeIndicator [4]byte // "BDJO"
+	VersionNumber [4]byte // "0100" or "0200"
+	Extensions    *OffsetsUint32
+}
+
+type OffsetsUint32 struct {
+	Start,
+	Stop int64
+}
+
+func ReadBDJOHeader(file io.ReadSeeker) (header *BDJOHeader, err error) {
+	header = &BDJOHeader{}
+
+	var eof int64
+	if eof, err = file.Seek(0, io.SeekEnd); err != nil {
+		return nil, fmt.Errorf("failed to seek to file end address: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to file start address: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &header.TypeIndicator); err != nil {
+		return nil, fmt.Errorf("failed to read header.TypeIndicator: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &header.VersionNumber); err != nil {
+		return nil, fmt.Errorf("failed to read header.VersionNumber: %w", err)
+	}
+
+	var buffer uint32
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read header.Extensions.Start: %w", err)
+	}
+
+	header.Extensions = &OffsetsUint32{Start: 0, Stop: 0}
+	if buffer != 0 {
+		header.Extensions = &OffsetsUint32{Start: int64(buffer), Stop: eof}
+	}
+
+	return header, nil
+}
+
+func (offsets *OffsetsUint32) String() string {
+	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
+}
+
+// String returns a string representation of the BDJOHeader.
+func (header *BDJOHeader) String() string {
+	return fmt.Sprintf(
+		"Header{Type: %s, Version: %s, Offset Extensions: %s}",
+		string(header.TypeIndicator[:]),
+		string(header.VersionNumber[:]),
+		header.Extensions,
+	)
+}
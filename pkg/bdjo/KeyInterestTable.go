@@ -0,0 +1,69 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// KeyInterestTable flags the remote control keys the applications want
+// delivered to them instead of to the player.
+type KeyInterestTable struct {
+	VKPlay                 bool // 0x80000000
+	VKStop                 bool // 0x40000000
+	VKFastForward          bool // 0x20000000
+	VKRewind               bool // 0x10000000
+	VKTrackNext            bool // 0x08000000
+	VKTrackPrevious        bool // 0x04000000
+	VKPause                bool // 0x02000000
+	VKStillOff             bool // 0x01000000
+	VKSecondaryAudioEnable bool // 0x00800000
+	VKSecondaryVideoEnable bool // 0x00400000
+	PGTextSTEnable         bool // 0x00200000
+}
+
+func ReadKeyInterestTable(file io.ReadSeeker) (keys *KeyInterestTable, err error) {
+	var buffer uint32
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+
+	return &KeyInterestTable{
+		VKPlay:                 buffer&0x80000000 != 0,
+		VKStop:                 buffer&0x40000000 != 0,
+		VKFastForward:          buffer&0x20000000 != 0,
+		VKRewind:               buffer&0x10000000 != 0,
+		VKTrackNext:            buffer&0x08000000 != 0,
+		VKTrackPrevious:        buffer&0x04000000 != 0,
+		VKPause:                buffer&0x02000000 != 0,
+		VKStillOff:             buffer&0x01000000 != 0,
+		VKSecondaryAudioEnable: buffer&0x00800000 != 0,
+		VKSecondaryVideoEnable: buffer&0x00400000 != 0,
+		PGTextSTEnable:         buffer&0x00200000 != 0,
+	}, nil
+}
+
+// Keys returns the names of the keys of interest, e.g. "VK_PLAY".
+func (keys *KeyInterestTable) Keys() (names []string) {
+	for _, key := range []struct {
+		set  bool
+		name string
+	}{
+		{keys.VKPlay, "VK_PLAY"},
+		{keys.VKStop, "VK_STOP"},
+		{keys.VKFastForward, "VK_FAST_FWD"},
+		{keys.VKRewind, "VK_REWIND"},
+		{keys.VKTrackNext, "VK_TRACK_NEXT"},
+		{keys.VKTrackPrevious, "VK_TRACK_PREV"},
+		{keys.VKPause, "VK_PAUSE"},
+		{keys.VKStillOff, "VK_STILL_OFF"},
+		{keys.VKSecondaryAudioEnable, "VK_SECONDARY_AUDIO_ENABLE_DISABLE"},
+		{keys.VKSecondaryVideoEnable, "VK_SECONDARY_VIDEO_ENABLE_DISABLE"},
+		{keys.PGTextSTEnable, "VK_PG_TEXTST_ENABLE_DISABLE"},
+	} {
+		if key.set {
+			names = append(names, key.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,42 @@
+package bdjo
+
+// Application control codes
+const (
+	CONTROL_CODE_AUTOSTART uint8 = 0x01 // Started with the title
+	CONTROL_CODE_PRESENT   uint8 = 0x02 // Started on request of another application
+	CONTROL_CODE_DESTROY   uint8 = 0x03
+	CONTROL_CODE_KILL      uint8 = 0x04
+)
+
+// App cache item types
+const (
+	APP_CACHE_TYPE_JAR       uint8 = 0x01
+	APP_CACHE_TYPE_DIRECTORY uint8 = 0x02
+)
+
+// ControlCodeName returns the name of an application control code,
+// e.g. "AUTOSTART", or "" for an unknown code.
+func ControlCodeName(code uint8) string {
+	switch code {
+	case CONTROL_CODE_AUTOSTART:
+		return "AUTOSTART"
+	case CONTROL_CODE_PRESENT:
+		return "PRESENT"
+	case CONTROL_CODE_DESTROY:
+		return "DESTROY"
+	case CONTROL_CODE_KILL:
+		return "KILL"
+	}
+	return ""
+}
+
+// AppCacheTypeName returns "jar", "directory", or "" for an unknown type.
+func AppCacheTypeName(itemType uint8) string {
+	switch itemType {
+	case APP_CACHE_TYPE_JAR:
+		return "jar"
+	case APP_CACHE_TYPE_DIRECTORY:
+		return "directory"
+	}
+	return ""
+}
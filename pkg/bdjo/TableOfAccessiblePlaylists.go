@@ -0,0 +1,57 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// TableOfAccessiblePlaylists lists the playlists the applications may play.
+type TableOfAccessiblePlaylists struct {
+	Length                     uint32
+	NumberOfPlaylists          uint16 // 11-bits
+	AccessToAllFlag            bool   //  1-bit, every playlist of the disc
+	AutostartFirstPlaylistFlag bool   //  1-bit, play Playlists[0] when the title starts
+	Playlists                  [][5]byte
+}
+
+// ReadTableOfAccessiblePlaylists reads the table at the current position
+// and leaves the file at its end.
+func ReadTableOfAccessiblePlaylists(file io.ReadSeeker) (table *TableOfAccessiblePlaylists, err error) {
+	table = &TableOfAccessiblePlaylists{}
+
+	if err := binary.Read(file, binary.BigEndian, &table.Length); err != nil {
+		return nil, fmt.Errorf("failed to read table.Length: %w", err)
+	}
+
+	end, err := CalculateEndOffset(file, table.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer uint32
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	table.NumberOfPlaylists = uint16(buffer >> 21)            // 0b11111111111 << 21
+	table.AccessToAllFlag = buffer&0x00100000 != 0            // 1 << 20
+	table.AutostartFirstPlaylistFlag = buffer&0x00080000 != 0 // 1 << 19
+
+	table.Playlists = make([][5]byte, table.NumberOfPlaylists)
+	for i := range table.Playlists {
+		if err := binary.Read(file, binary.BigEndian, &table.Playlists[i]); err != nil {
+			return nil, fmt.Errorf("failed to read Playlists[%d]: %w", i, err)
+		}
+
+		// skip 1-byte reserve space
+		if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
+		}
+	}
+
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to the end of TableOfAccessiblePlaylists: %w", err)
+	}
+
+	return table, nil
+}
@@ -0,0 +1,50 @@
+package bdjo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// TerminalInfo is how the player is set up for the BD-J object.
+type TerminalInfo struct {
+	Length                     uint32
+	DefaultFont                [5]byte // Font file of AUXDATA/dvb.fontindex, "*****" for none
+	InitialHAViConfigurationID uint8   // 4-bits 0b11110000
+	MenuCallMask               bool    // 1-bit  0b00001000
+	TitleSearchMask            bool    // 1-bit  0b00000100
+}
+
+// ReadTerminalInfo reads the TerminalInfo at the current position and
+// leaves the file at its end.
+func ReadTerminalInfo(file io.ReadSeeker) (terminalInfo *TerminalInfo, err error) {
+	terminalInfo = &TerminalInfo{}
+
+	if err := binary.Read(file, binary.BigEndian, &terminalInfo.Length); err != nil {
+		return nil, fmt.Errorf("failed to read terminalInfo.Length: %w", err)
+	}
+
+	end, err := CalculateEndOffset(file, terminalInfo.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &terminalInfo.DefaultFont); err != nil {
+		return nil, fmt.Errorf("failed to read DefaultFont: %w", err)
+	}
+
+	var buffer uint8
+	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	terminalInfo.InitialHAViConfigurationID = (buffer & 0xF0) >> 4
+	terminalInfo.MenuCallMask = buffer&0x08 != 0
+	terminalInfo.TitleSearchMask = buffer&0x04 != 0
+
+	// skip the 34-bit reserve space
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to the end of TerminalInfo: %w", err)
+	}
+
+	return terminalInfo, nil
+}
@@ -0,0 +1,148 @@
+package bdjo
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+/*
+	Remarks:
+		BDMV/BDJO/xxxxx.bdjo
+
+	A BD-J object is what an index.bdmv title of object type 2 runs,
+	named by the title's RefToBDJObjectID. Unlike the other navigation
+	files its header has no section addresses: the sections follow one
+	another, each led by its own 32-bit length.
+
+		Header                      12 bytes
+		TerminalInfo
+		AppCacheInfo
+		TableOfAccessiblePlaylists
+		ApplicationManagementTable
+		KeyInterestTable            4 bytes
+		FileAccessInfo              16-bit length
+		Extensions                  (optional, not parsed)
+*/
+
+// ParseBDJO parses a BDJO file and returns its sections.
+func ParseBDJO(filePath string) (
+	header *BDJOHeader,
+	terminalInfo *TerminalInfo,
+	appCacheInfo *AppCacheInfo,
+	accessiblePlaylists *TableOfAccessiblePlaylists,
+	appManagementTable *ApplicationManagementTable,
+	keyInterestTable *KeyInterestTable,
+	fileAccessInfo *FileAccessInfo,
+	err error,
+) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseBDJO(file, filePath)
+}
+
+// ParseBDJOFS is ParseBDJO for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseBDJOFS(fsys fs.FS, name string) (
+	header *BDJOHeader,
+	terminalInfo *TerminalInfo,
+	appCacheInfo *AppCacheInfo,
+	accessiblePlaylists *TableOfAccessiblePlaylists,
+	appManagementTable *ApplicationManagementTable,
+	keyInterestTable *KeyInterestTable,
+	fileAccessInfo *FileAccessInfo,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseBDJO(file, name)
+}
+
+func parseBDJO(file io.ReadSeeker, filePath string) (
+	header *BDJOHeader,
+	terminalInfo *TerminalInfo,
+	appCacheInfo *AppCacheInfo,
+	accessiblePlaylists *TableOfAccessiblePlaylists,
+	appManagementTable *ApplicationManagementTable,
+	keyInterestTable *KeyInterestTable,
+	fileAccessInfo *FileAccessInfo,
+	err error,
+) {
+	fail := func(section string, err error) error {
+		offset, _ := ftell(file)
+		return navfile.SectionError(filePath, section, offset, err)
+	}
+
+	// Header
+	if header, err = ReadBDJOHeader(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
+	}
+
+	// TerminalInfo
+	if terminalInfo, err = ReadTerminalInfo(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("TerminalInfo", err)
+	}
+
+	// AppCacheInfo
+	if appCacheInfo, err = ReadAppCacheInfo(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("AppCacheInfo", err)
+	}
+
+	// TableOfAccessiblePlaylists
+	if accessiblePlaylists, err = ReadTableOfAccessiblePlaylists(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("TableOfAccessiblePlaylists", err)
+	}
+
+	// ApplicationManagementTable
+	if appManagementTable, err = ReadApplicationManagementTable(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("ApplicationManagementTable", err)
+	}
+
+	// KeyInterestTable
+	if keyInterestTable, err = ReadKeyInterestTable(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("KeyInterestTable", err)
+	}
+
+	// FileAccessInfo
+	if fileAccessInfo, err = ReadFileAccessInfo(file); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fail("FileAccessInfo", err)
+	}
+
+	return header, terminalInfo, appCacheInfo, accessiblePlaylists, appManagementTable, keyInterestTable, fileAccessInfo, nil
+}
+
+// Why this is not part of the standard library boggles the mind.
+// Seek gives your current position when you use it. So if you seek zero
+// ahead, Seek() returns your current position.
+func ftell(file io.ReadSeeker) (int64, error) {
+	return file.Seek(0, io.SeekCurrent)
+}
+
+func CalculateEndOffset[U uint8 | uint16 | uint32](file io.ReadSeeker, length U) (int64, error) {
+	currentPos, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current position: %w", err)
+	}
+	return currentPos + int64(length), nil
+}
+
+// readString reads a string of length bytes.
+func readString(file io.Reader, length int) (string, error) {
+	buffer := make([]byte, length)
+	if _, err := io.ReadFull(file, buffer); err != nil {
+		return "", err
+	}
+	return string(buffer), nil
+}
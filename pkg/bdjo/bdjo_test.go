@@ -0,0 +1,118 @@
+package bdjo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fields writes big-endian fields one after the other.
+func fields(values ...any) []byte {
+	buf := &bytes.Buffer{}
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			value = []byte(text)
+		}
+		binary.Write(buf, binary.BigEndian, value)
+	}
+	return buf.Bytes()
+}
+
+// section prefixes body with its 32-bit length.
+func section(body []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+func testBDJO() []byte {
+	descriptor := slices.Concat(
+		fields(uint8(0x10), uint16(1), uint8(1), uint8(0), uint8(0), uint8(0)), // 1 profile: 1.0.0
+		fields(uint8(5), uint8(0xC0)),                                          // priority, binding 3, visibility 0
+		fields(uint16(9), "eng", uint8(5), "Menus", uint8(0)),                  // 9 bytes of names, padded
+		fields(uint8(0), uint8(0)),                                             // no icon locator, padded
+		fields(uint16(0)),                                                      // icon flags
+		fields(uint8(5), "00000"),                                              // base directory
+		fields(uint8(0), uint8(0)),                                             // no classpath extension, padded
+		fields(uint8(13), "com.a.MainXlt"),                                     // initial class
+		fields(uint8(6), uint8(2), "-d", uint8(2), "-v", uint8(0)),             // parameters, padded
+	)
+	return slices.Concat(
+		fields("BDJO0200", uint32(0)),
+		section(fields("*****", uint8(0x18), uint32(0))), // HAVi 1, menu call mask
+		section(fields(uint8(1), uint8(0), uint8(APP_CACHE_TYPE_JAR), "00000", "eng", [3]byte{})),
+		section(fields(uint32(2<<21|1<<19), "00800", uint8(0), "00001", uint8(0))),
+		section(slices.Concat(
+			fields(uint8(1), uint8(0)),
+			fields(CONTROL_CODE_AUTOSTART, uint8(0x10), uint32(0x56789ABC), uint16(0x4001)),
+			section(descriptor),
+		)),
+		fields(uint32(0xC0200000)), // VK_PLAY, VK_STOP, PG/TextST
+		fields(uint16(1), "."),
+	)
+}
+
+func TestParseBDJO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "00000.bdjo")
+	if err := os.WriteFile(path, testBDJO(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	header, terminalInfo, appCacheInfo, playlists, table, keys, fileAccessInfo, err := ParseBDJO(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(header.VersionNumber[:]) != "0200" || header.Extensions.Start != 0 {
+		t.Errorf("header = %s", header)
+	}
+	if terminalInfo.DefaultFont != [5]byte{'*', '*', '*', '*', '*'} || terminalInfo.InitialHAViConfigurationID != 1 ||
+		!terminalInfo.MenuCallMask || terminalInfo.TitleSearchMask {
+		t.Errorf("TerminalInfo = %+v", terminalInfo)
+	}
+	if len(appCacheInfo.AppCacheItems) != 1 || string(appCacheInfo.AppCacheItems[0].Name[:]) != "00000" {
+		t.Errorf("AppCacheInfo = %+v", appCacheInfo)
+	}
+	if playlists.NumberOfPlaylists != 2 || playlists.AccessToAllFlag || !playlists.AutostartFirstPlaylistFlag ||
+		string(playlists.Playlists[1][:]) != "00001" {
+		t.Errorf("TableOfAccessiblePlaylists = %+v", playlists)
+	}
+
+	if len(table.Applications) != 1 {
+		t.Fatalf("%d applications, want 1", len(table.Applications))
+	}
+	app := table.Applications[0]
+	if app.ControlCode != CONTROL_CODE_AUTOSTART || app.Type != 1 || app.OrganizationID != 0x56789ABC || app.ApplicationID != 0x4001 {
+		t.Errorf("application = %+v", app)
+	}
+	if len(app.Profiles) != 1 || *app.Profiles[0] != (ApplicationProfile{Profile: 1, MajorVersion: 1}) {
+		t.Errorf("Profiles = %v", app.Profiles)
+	}
+	if app.Priority != 5 || app.Binding != 3 || len(app.Names) != 1 || app.Names[0].Name != "Menus" {
+		t.Errorf("Priority, Binding, Names = %d, %d, %v", app.Priority, app.Binding, app.Names)
+	}
+	if app.BaseDirectory != "00000" || app.InitialClass != "com.a.MainXlt" || app.IconLocator != "" ||
+		!slices.Equal(app.Parameters, []string{"-d", "-v"}) {
+		t.Errorf("BaseDirectory, InitialClass, Parameters = %q, %q, %q", app.BaseDirectory, app.InitialClass, app.Parameters)
+	}
+
+	if !slices.Equal(keys.Keys(), []string{"VK_PLAY", "VK_STOP", "VK_PG_TEXTST_ENABLE_DISABLE"}) {
+		t.Errorf("Keys() = %v", keys.Keys())
+	}
+	if fileAccessInfo.Path != "." {
+		t.Errorf("FileAccessInfo.Path = %q", fileAccessInfo.Path)
+	}
+}
+
+func TestParseBDJOTruncated(t *testing.T) {
+	data := testBDJO()
+	path := filepath.Join(t.TempDir(), "00000.bdjo")
+	if err := os.WriteFile(path, data[:len(data)-20], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var parseErr *ParseError
+	if _, _, _, _, _, _, _, err := ParseBDJO(path); !errors.As(err, &parseErr) || parseErr.Path == "" {
+		t.Errorf("ParseBDJO() error = %v, want a *ParseError", err)
+	}
+}
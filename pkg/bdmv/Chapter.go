@@ -0,0 +1,116 @@
+package bdmv
+
+import (
+	"sort"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+// Chapter is one entry mark of a playlist, placed on the playlist's own
+// timeline: Start is 0 at the IN time of the first PlayItem and runs on
+// through every PlayItem that follows.
+type Chapter struct {
+	Number int // From 1, in playback order
+	Start  clock.Ticks45k
+	End    clock.Ticks45k // Start of the next chapter, or the playlist duration
+	Name   string         // From META/TN; empty when the disc names none
+}
+
+// Chapters returns the chapters of the playlist without names.
+// Marks that point at a missing PlayItem are skipped, a mark outside its
+// PlayItem's IN/OUT span is moved to the nearest end of that span, and a
+// mark that then lands on the start of an earlier mark is dropped.
+func (playlist *Playlist) Chapters() []*Chapter {
+	chapters, _ := playlist.chapters()
+	return chapters
+}
+
+// chapters returns the chapters of the playlist and, for each, the entry
+// mark it comes from, counting the entry marks from 0 in file order.
+func (playlist *Playlist) chapters() (chapters []*Chapter, marks []int) {
+	chapters = []*Chapter{}
+	if playlist.Marks == nil || playlist.PlayList == nil {
+		return chapters, nil
+	}
+
+	// offsets[i] is where PlayItem i starts on the playlist timeline.
+	playItems := playlist.PlayList.PlayItems
+	offsets := make([]clock.Ticks45k, len(playItems))
+	var total clock.Ticks45k
+	for i, playItem := range playItems {
+		offsets[i] = total
+		if playItem.OUTTime > playItem.INTime {
+			total = total.Add(playItem.OUTTime.Sub(playItem.INTime))
+		}
+	}
+
+	type entryMark struct {
+		start clock.Ticks45k
+		mark  int
+	}
+	entryMarks := []entryMark{}
+	mark := -1
+	for _, markEntry := range playlist.Marks.Marks {
+		if markEntry.MarkType != 1 {
+			continue
+		}
+		mark++
+		if int(markEntry.RefToPlayItemID) >= len(playItems) {
+			continue
+		}
+		playItem := playItems[markEntry.RefToPlayItemID]
+		timeStamp := min(max(markEntry.MarkTimeStamp, playItem.INTime), max(playItem.OUTTime, playItem.INTime))
+		entryMarks = append(entryMarks, entryMark{
+			start: offsets[markEntry.RefToPlayItemID].Add(timeStamp.Sub(playItem.INTime)),
+			mark:  mark,
+		})
+	}
+
+	sort.SliceStable(entryMarks, func(i, j int) bool {
+		return entryMarks[i].start < entryMarks[j].start
+	})
+	for i, entryMark := range entryMarks {
+		if i > 0 && entryMark.start == entryMarks[i-1].start {
+			continue
+		}
+		chapters = append(chapters, &Chapter{Number: len(chapters) + 1, Start: entryMark.start})
+		marks = append(marks, entryMark.mark)
+	}
+	for i, chapter := range chapters {
+		chapter.End = total
+		if i+1 < len(chapters) {
+			chapter.End = chapters[i+1].Start
+		}
+	}
+	return chapters, marks
+}
+
+// Chapters returns the chapters of the named playlist with their names
+// in the given language, e.g. "eng". META/TN names the entry marks in
+// file order, so a chapter takes the name of the mark it comes from.
+// Chapters without a name in that language keep an empty Name. It
+// returns nil for an unknown playlist.
+func (disc *Disc) Chapters(playlistName, language string) []*Chapter {
+	playlist, ok := disc.Playlists[playlistName]
+	if !ok {
+		return nil
+	}
+	chapters, marks := playlist.chapters()
+	if trackNames := disc.TrackNames[language][playlistName]; trackNames != nil {
+		for i, chapter := range chapters {
+			if marks[i] < len(trackNames.ChapterNames) {
+				chapter.Name = trackNames.ChapterNames[marks[i]]
+			}
+		}
+	}
+	return chapters
+}
+
+// Title returns the disc title from META/DL in the given language,
+// or the empty string when the disc has none.
+func (disc *Disc) Title(language string) string {
+	if discLib, ok := disc.Meta[language]; ok {
+		return discLib.DiscInfo.Title.Name
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package bdmv
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+/*
+	Remarks:
+
+	The three chapter formats that remuxing tools read:
+
+	Matroska XML, for mkvmerge --chapters:
+		<Chapters><EditionEntry><ChapterAtom>
+			<ChapterTimeStart>00:00:00.000000000</ChapterTimeStart>
+			<ChapterDisplay><ChapterString>..</ChapterString>..
+
+	OGM, also read by mkvmerge:
+		CHAPTER01=00:00:00.000
+		CHAPTER01NAME=Chapter 01
+
+	FFMETADATA, for ffmpeg -i in.txt -map_metadata 1:
+		;FFMETADATA1
+		[CHAPTER]
+		TIMEBASE=1/45000
+		START=0
+		END=123456
+		title=Chapter 01
+
+	A chapter without a name from the disc is written as "Chapter NN",
+	since Matroska and OGM both require one.
+*/
+
+// ChapterName returns the chapter's name, or "Chapter NN" when it has none.
+func (chapter *Chapter) ChapterName() string {
+	if chapter.Name != "" {
+		return chapter.Name
+	}
+	return fmt.Sprintf("Chapter %02d", chapter.Number)
+}
+
+type matroskaChapters struct {
+	XMLName      xml.Name             `xml:"Chapters"`
+	EditionEntry matroskaEditionEntry `xml:"EditionEntry"`
+}
+
+type matroskaEditionEntry struct {
+	EditionFlagDefault int                   `xml:"EditionFlagDefault"`
+	ChapterAtoms       []matroskaChapterAtom `xml:"ChapterAtom"`
+}
+
+type matroskaChapterAtom struct {
+	ChapterTimeStart string                 `xml:"ChapterTimeStart"`
+	ChapterTimeEnd   string                 `xml:"ChapterTimeEnd"`
+	ChapterDisplay   matroskaChapterDisplay `xml:"ChapterDisplay"`
+}
+
+type matroskaChapterDisplay struct {
+	ChapterString   string `xml:"ChapterString"`
+	ChapterLanguage string `xml:"ChapterLanguage"`
+}
+
+// WriteMatroskaChapters writes chapters as Matroska chapter XML.
+// language is the ISO 639-2 code of the names; "" is written as "und".
+func WriteMatroskaChapters(w io.Writer, chapters []*Chapter, language string) error {
+	if language == "" {
+		language = "und"
+	}
+	doc := matroskaChapters{EditionEntry: matroskaEditionEntry{EditionFlagDefault: 1}}
+	for _, chapter := range chapters {
+		doc.EditionEntry.ChapterAtoms = append(doc.EditionEntry.ChapterAtoms, matroskaChapterAtom{
+			ChapterTimeStart: matroskaTime(chapter.Start.Duration()),
+			ChapterTimeEnd:   matroskaTime(chapter.End.Duration()),
+			ChapterDisplay: matroskaChapterDisplay{
+				ChapterString:   chapter.ChapterName(),
+				ChapterLanguage: language,
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE Chapters SYSTEM \"matroskachapters.dtd\">\n"); err != nil {
+		return fmt.Errorf("failed to write Matroska chapters: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write Matroska chapters: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write Matroska chapters: %w", err)
+	}
+	return nil
+}
+
+// matroskaTime formats d as HH:MM:SS.nnnnnnnnn.
+func matroskaTime(d time.Duration) string {
+	ns := int64(d)
+	return fmt.Sprintf("%02d:%02d:%02d.%09d",
+		ns/int64(time.Hour), ns/int64(time.Minute)%60, ns/int64(time.Second)%60, ns%int64(time.Second))
+}
+
+// WriteOGMChapters writes chapters as OGM CHAPTERxx= lines.
+func WriteOGMChapters(w io.Writer, chapters []*Chapter) error {
+	bw := bufio.NewWriter(w)
+	for _, chapter := range chapters {
+		fmt.Fprintf(bw, "CHAPTER%02d=%s\n", chapter.Number, chapter.Start)
+		fmt.Fprintf(bw, "CHAPTER%02dNAME=%s\n", chapter.Number, singleLine(chapter.ChapterName()))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write OGM chapters: %w", err)
+	}
+	return nil
+}
+
+// WriteFFMetadata writes chapters as an ffmpeg FFMETADATA file, with
+// times in 45 kHz ticks. A non-empty title becomes the global title.
+func WriteFFMetadata(w io.Writer, chapters []*Chapter, title string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, ";FFMETADATA1")
+	if title != "" {
+		fmt.Fprintf(bw, "title=%s\n", ffmetadataEscape(title))
+	}
+	for _, chapter := range chapters {
+		fmt.Fprintln(bw, "[CHAPTER]")
+		fmt.Fprintln(bw, "TIMEBASE=1/45000")
+		fmt.Fprintf(bw, "START=%d\n", chapter.Start)
+		fmt.Fprintf(bw, "END=%d\n", chapter.End)
+		fmt.Fprintf(bw, "title=%s\n", ffmetadataEscape(chapter.ChapterName()))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write FFMETADATA: %w", err)
+	}
+	return nil
+}
+
+// ffmetadataEscape puts a backslash before the characters FFMETADATA
+// treats as syntax: '=', ';', '#', '\' and newline.
+func ffmetadataEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// singleLine replaces line breaks, which would end an OGM value early.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
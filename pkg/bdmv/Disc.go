@@ -0,0 +1,169 @@
+package bdmv
+
+import (
+	"io/fs"
+	"sort"
+
+	"github.com/parasense/bdmv_go/pkg/bdjo"
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+	"github.com/parasense/bdmv_go/pkg/fontdir"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/meta"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+	"github.com/parasense/bdmv_go/pkg/sound"
+)
+
+// Disc holds every parsed navigation file of one BDMV tree,
+// with the cross-references between them already resolved.
+type Disc struct {
+	Root         string  // Path to the BDMV directory
+	Layout       *Layout // Blu-ray or AVCHD file names
+	Index        *Index
+	MovieObjects *MovieObjects
+	Playlists    map[string]*Playlist  // Keyed by 5-digit name, e.g. "00800"
+	Clips        map[string]*Clip      // Keyed by 5-digit name, e.g. "00001"
+	BDJObjects   map[string]*BDJObject // Keyed by 5-digit name, e.g. "00000"
+	Sound        *Sound
+	Meta         map[string]*meta.DiscLib               // Keyed by language code, e.g. "eng"
+	TrackNames   map[string]map[string]*meta.TrackNames // Keyed by language code, then playlist name
+	FontIndex    *fontdir.FontDirectory
+
+	// Problems collects every file that failed to parse and every
+	// reference that could not be resolved. A non-empty slice does not
+	// mean the rest of the Disc is unusable.
+	Problems []error
+
+	fsys fs.FS // Where the files are read from; see files
+}
+
+// Index is the parsed content of index.bdmv.
+type Index struct {
+	Header     *indx.INDXHeader
+	AppInfo    *indx.AppInfo
+	Indexes    *indx.Indexes
+	Extensions *indx.Extensions
+
+	FirstPlayback *Title
+	TopMenu       *Title
+	Titles        []*Title // Titles[0] is title number 1
+}
+
+// Title links an index.bdmv title entry to the movie object or BD-J
+// object it runs. MovieObject is nil for BD-J titles, BDJObject for HDMV
+// titles, and either for unresolved references.
+type Title struct {
+	*indx.Title
+	MovieObject *mobj.MovieObject
+	BDJObject   *BDJObject
+}
+
+// MovieObjects is the parsed content of MovieObject.bdmv.
+type MovieObjects struct {
+	Header       *mobj.MOBJHeader
+	MovieObjects *mobj.MovieObjects
+	Extensions   *mobj.Extensions
+}
+
+// Playlist is the parsed content of one PLAYLIST/*.mpls file.
+type Playlist struct {
+	Name       string
+	Header     *mpls.MPLSHeader
+	AppInfo    *mpls.AppInfo
+	PlayList   *mpls.PlayList
+	Marks      *mpls.PlaylistMarks
+	Extensions *mpls.Extensions
+
+	PlayItems []*PlayItem // Parallel to PlayList.PlayItems
+}
+
+// PlayItem links an mpls PlayItem to the clips it plays.
+// Angles[0] is the clip named by ClipInformationFileName,
+// the remaining entries are the clips of the other angles.
+// A nil entry marks a clip that could not be resolved.
+type PlayItem struct {
+	*mpls.PlayItem
+	Clip   *Clip
+	Angles []*Clip
+}
+
+// Clip is the parsed content of one CLIPINF/*.clpi file.
+type Clip struct {
+	Name         string
+	Header       *clpi.CLPIHeader
+	ClipInfo     *clpi.ClipInfo
+	SequenceInfo *clpi.SequenceInfo
+	ProgramInfo  *clpi.ProgramInfo
+	CPI          *clpi.CPI
+	ClipMarks    *clpi.ClipMarks
+	Extensions   *clpi.Extensions
+}
+
+// BDJObject is the parsed content of one BDJO/*.bdjo file.
+type BDJObject struct {
+	Name                       string
+	Header                     *bdjo.BDJOHeader
+	TerminalInfo               *bdjo.TerminalInfo
+	AppCacheInfo               *bdjo.AppCacheInfo
+	TableOfAccessiblePlaylists *bdjo.TableOfAccessiblePlaylists
+	ApplicationManagementTable *bdjo.ApplicationManagementTable
+	KeyInterestTable           *bdjo.KeyInterestTable
+	FileAccessInfo             *bdjo.FileAccessInfo
+}
+
+// Sound is the parsed content of AUXDATA/sound.bdmv.
+type Sound struct {
+	Header   *sound.BCLKHeader
+	MetaData *sound.SoundMetaData
+	Data     *sound.SoundData
+}
+
+// Duration returns the playing time of the playlist,
+// the sum of the IN/OUT span of every PlayItem.
+func (playlist *Playlist) Duration() (ticks clock.Ticks45k) {
+	if playlist.PlayList == nil {
+		return 0
+	}
+	for _, playItem := range playlist.PlayList.PlayItems {
+		if playItem.OUTTime > playItem.INTime {
+			ticks = ticks.Add(playItem.OUTTime.Sub(playItem.INTime))
+		}
+	}
+	return ticks
+}
+
+// Duration returns the presentation time of the clip,
+// the sum of the span of every STC sequence.
+func (clip *Clip) Duration() (ticks clock.Ticks45k) {
+	if clip.SequenceInfo == nil {
+		return 0
+	}
+	for _, atcSequence := range clip.SequenceInfo.ATCSequences {
+		for _, stcSequence := range atcSequence.STCSequences {
+			if stcSequence.PresentationEndTime > stcSequence.PresentationStartTime {
+				ticks = ticks.Add(stcSequence.PresentationEndTime.Sub(stcSequence.PresentationStartTime))
+			}
+		}
+	}
+	return ticks
+}
+
+// PlaylistNames returns the playlist names in ascending order.
+func (disc *Disc) PlaylistNames() []string {
+	return sortedKeys(disc.Playlists)
+}
+
+// ClipNames returns the clip names in ascending order.
+func (disc *Disc) ClipNames() []string {
+	return sortedKeys(disc.Clips)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,84 @@
+package bdmv
+
+import (
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/clpi"
+)
+
+// FileError reports a navigation file that could not be parsed.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ReferenceError reports a cross-reference that points at nothing,
+// for example a PlayItem naming a clip that has no .clpi file.
+// Indexes in Source count from 0, like the slices of Disc, so the
+// first title of index.bdmv, title number 1, is "Title[0]".
+type ReferenceError struct {
+	Source string // Where the reference was found, e.g. "PLAYLIST/00800.mpls PlayItem[2]"
+	Target string // What it refers to, e.g. "CLIPINF/00055.clpi"
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s: dangling reference to %s", e.Source, e.Target)
+}
+
+// StreamErrorKind says how a transport stream differs from its navigation files.
+type StreamErrorKind int
+
+const (
+	StreamMissing       StreamErrorKind = iota // Listed in a navigation file, not in the PMT
+	StreamExtra                                // In the PMT, not listed in the clpi file
+	StreamCodecMismatch                        // In both, with different coding types
+)
+
+func (k StreamErrorKind) String() string {
+	switch k {
+	case StreamMissing:
+		return "missing"
+	case StreamExtra:
+		return "extra"
+	case StreamCodecMismatch:
+		return "codec mismatch"
+	}
+	return fmt.Sprintf("StreamErrorKind(%d)", int(k))
+}
+
+// StreamError reports an elementary stream on which a clip's .m2ts file
+// and the clpi or mpls file describing it disagree.
+type StreamError struct {
+	Source   string // Where the stream is listed, e.g. "PLAYLIST/00800.mpls PlayItem[2]"
+	Stream   string // The transport stream, e.g. "STREAM/00001.m2ts"
+	Kind     StreamErrorKind
+	PID      uint16
+	Expected uint8 // Coding type in Source; unset for StreamExtra
+	Found    uint8 // stream_type in the PMT; unset for StreamMissing
+}
+
+func (e *StreamError) Error() string {
+	switch e.Kind {
+	case StreamMissing:
+		return fmt.Sprintf("%s: PID 0x%04X (%s) is not in %s", e.Source, e.PID, codecName(e.Expected), e.Stream)
+	case StreamExtra:
+		return fmt.Sprintf("%s: PID 0x%04X (%s) in %s is not listed", e.Source, e.PID, codecName(e.Found), e.Stream)
+	}
+	return fmt.Sprintf("%s: PID 0x%04X is %s, but %s carries %s",
+		e.Source, e.PID, codecName(e.Expected), e.Stream, codecName(e.Found))
+}
+
+func codecName(code uint8) string {
+	if name := clpi.StreamCodec(clpi.StreamCodingType(code)); name != "" {
+		return name
+	}
+	return fmt.Sprintf("stream type 0x%02X", code)
+}
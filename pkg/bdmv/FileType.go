@@ -0,0 +1,96 @@
+package bdmv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// FileType identifies the kind of a navigation file.
+type FileType int
+
+const (
+	FileTypeUnknown      FileType = iota
+	FileTypePlaylist              // PLAYLIST/xxxxx.mpls or .MPL, type indicator "MPLS"
+	FileTypeClipInfo              // CLIPINF/xxxxx.clpi or .CPI, type indicator "HDMV"
+	FileTypeIndex                 // index.bdmv or INDEX.BDM, type indicator "INDX"
+	FileTypeMovieObjects          // MovieObject.bdmv or MOVIEOBJ.BDM, type indicator "MOBJ"
+	FileTypeSound                 // AUXDATA/sound.bdmv, type indicator "BCLK"
+	FileTypeMeta                  // META/DL/bdmt_xxx.xml, root element <disclib>
+	FileTypeFontIndex             // AUXDATA/dvb.fontindex, root element <fontdirectory>
+	FileTypeBDJObject             // BDJO/xxxxx.bdjo, type indicator "BDJO"
+)
+
+func (fileType FileType) String() string {
+	switch fileType {
+	case FileTypePlaylist:
+		return "MPLS"
+	case FileTypeClipInfo:
+		return "CLPI"
+	case FileTypeIndex:
+		return "INDX"
+	case FileTypeMovieObjects:
+		return "MOBJ"
+	case FileTypeSound:
+		return "BCLK"
+	case FileTypeMeta:
+		return "META"
+	case FileTypeFontIndex:
+		return "FONTINDEX"
+	case FileTypeBDJObject:
+		return "BDJO"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// DetectFileType reads the start of a file and returns its kind.
+// Binary files are told apart by their 4-byte type indicator, XML files
+// by their root element; the file name is not looked at.
+func DetectFileType(filePath string) (FileType, error) {
+	return DetectFileTypeFS(osFS{}, filePath)
+}
+
+// DetectFileTypeFS is DetectFileType for the file name in fsys.
+func DetectFileTypeFS(fsys fs.FS, filePath string) (FileType, error) {
+	file, err := fsys.Open(filePath)
+	if err != nil {
+		return FileTypeUnknown, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return FileTypeUnknown, fmt.Errorf("failed to read type indicator: %w", err)
+	}
+	return detectFileType(head[:n]), nil
+}
+
+func detectFileType(head []byte) FileType {
+	if len(head) >= 4 {
+		switch string(head[:4]) {
+		case "MPLS":
+			return FileTypePlaylist
+		case "HDMV":
+			return FileTypeClipInfo
+		case "INDX":
+			return FileTypeIndex
+		case "MOBJ":
+			return FileTypeMovieObjects
+		case "BCLK":
+			return FileTypeSound
+		case "BDJO":
+			return FileTypeBDJObject
+		}
+	}
+
+	switch {
+	case bytes.Contains(head, []byte("<disclib")), bytes.Contains(head, []byte(":disclib")):
+		return FileTypeMeta
+	case bytes.Contains(head, []byte("<fontdirectory")):
+		return FileTypeFontIndex
+	}
+	return FileTypeUnknown
+}
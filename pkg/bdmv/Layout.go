@@ -0,0 +1,90 @@
+package bdmv
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Layout names the files of a BDMV tree. Blu-ray discs use the long
+// names; AVCHD camcorder media use 8.3 names on a FAT file system.
+type Layout struct {
+	Name         string // "BDMV" or "AVCHD"
+	Index        string // e.g. "index.bdmv"
+	MovieObjects string // e.g. "MovieObject.bdmv"
+	Playlist     string // Extension of the PLAYLIST files, e.g. ".mpls"
+	ClipInfo     string // Extension of the CLIPINF files, e.g. ".clpi"
+	Stream       string // Extension of the STREAM files, e.g. ".m2ts"
+}
+
+var (
+	LayoutBDMV  = &Layout{"BDMV", "index.bdmv", "MovieObject.bdmv", ".mpls", ".clpi", ".m2ts"}
+	LayoutAVCHD = &Layout{"AVCHD", "INDEX.BDM", "MOVIEOBJ.BDM", ".MPL", ".CPI", ".MTS"}
+)
+
+// bdmvDirs are the places a BDMV directory is looked for under a disc
+// root: a Blu-ray disc, an AVCHD SD card or camcorder, and an AVCHD
+// folder copied off a card.
+var bdmvDirs = [][]string{
+	{"BDMV"},
+	{"PRIVATE", "AVCHD", "BDMV"},
+	{"AVCHD", "BDMV"},
+}
+
+// layout returns the disc's layout; a Disc built by hand is a Blu-ray one.
+func (disc *Disc) layout() *Layout {
+	if disc.Layout == nil {
+		return LayoutBDMV
+	}
+	return disc.Layout
+}
+
+// detectLayout tells the layouts apart by the name of the index file.
+func detectLayout(fsys fs.FS, bdmvDir string) *Layout {
+	if _, ok := lookup(fsys, bdmvDir, LayoutAVCHD.Index); ok {
+		if _, ok := lookup(fsys, bdmvDir, LayoutBDMV.Index); !ok {
+			return LayoutAVCHD
+		}
+	}
+	return LayoutBDMV
+}
+
+// layoutOf returns the layout a single file's name belongs to.
+func layoutOf(filePath string) *Layout {
+	switch strings.ToUpper(filepath.Ext(filePath)) {
+	case LayoutAVCHD.Playlist, LayoutAVCHD.ClipInfo, ".BDM":
+		return LayoutAVCHD
+	}
+	return LayoutBDMV
+}
+
+// lookup returns the path of the entry of dir named name, ignoring case.
+// FAT media show up in upper or lower case depending on how they are
+// mounted. When nothing matches, the exact name is returned with ok false.
+func lookup(fsys fs.FS, dir, name string) (path string, ok bool) {
+	path = joinPath(fsys, dir, name)
+	if _, err := fs.Stat(fsys, path); err == nil {
+		return path, true
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return path, false
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Name(), name) {
+			return joinPath(fsys, dir, entry.Name()), true
+		}
+	}
+	return path, false
+}
+
+// lookupPath resolves each element of a path under dir with lookup.
+func lookupPath(fsys fs.FS, dir string, elem ...string) (path string, ok bool) {
+	path, ok = dir, true
+	for _, name := range elem {
+		var found bool
+		path, found = lookup(fsys, path, name)
+		ok = ok && found
+	}
+	return path, ok
+}
@@ -0,0 +1,68 @@
+package bdmv
+
+import (
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/hdmv"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// NewVM returns an HDMV VM over the disc's index.bdmv, MovieObject.bdmv
+// and playlists, to find out what a player with the given settings plays.
+func (disc *Disc) NewVM(settings *hdmv.Settings) (*hdmv.VM, error) {
+	if disc.Index == nil || disc.Index.Indexes == nil {
+		return nil, fmt.Errorf("no index.bdmv")
+	}
+	if disc.MovieObjects == nil || disc.MovieObjects.MovieObjects == nil {
+		return nil, fmt.Errorf("no MovieObject.bdmv")
+	}
+	playlists := make(map[string]*hdmv.Playlist, len(disc.Playlists))
+	for name, playlist := range disc.Playlists {
+		playlists[name] = &hdmv.Playlist{PlayList: playlist.PlayList, Marks: playlist.Marks}
+	}
+	return hdmv.New(disc.Index.Indexes, disc.MovieObjects.MovieObjects, playlists, settings)
+}
+
+// Graph returns the control-flow graph of the disc's movie objects.
+func (disc *Disc) Graph() (*hdmv.Graph, error) {
+	if disc.Index == nil || disc.Index.Indexes == nil {
+		return nil, fmt.Errorf("no index.bdmv")
+	}
+	if disc.MovieObjects == nil || disc.MovieObjects.MovieObjects == nil {
+		return nil, fmt.Errorf("no MovieObject.bdmv")
+	}
+	return hdmv.BuildGraph(disc.Index.Indexes, disc.MovieObjects.MovieObjects), nil
+}
+
+// DefaultStreams returns the streams a player with the given settings
+// selects for each PlayItem of a playlist.
+func (disc *Disc) DefaultStreams(name string, settings *hdmv.Settings) ([]*hdmv.StreamSelection, error) {
+	playlist, ok := disc.Playlists[name]
+	if !ok || playlist.PlayList == nil {
+		return nil, fmt.Errorf("no playlist %q", name)
+	}
+	registers, err := settings.Registers()
+	if err != nil {
+		return nil, err
+	}
+
+	var mvc *mpls.ExtensionMVCStream
+	if playlist.Extensions != nil {
+		for _, data := range playlist.Extensions.EntriesData {
+			if ext, ok := data.(*mpls.ExtensionMVCStream); ok {
+				mvc = ext
+			}
+		}
+	}
+
+	selections := make([]*hdmv.StreamSelection, len(playlist.PlayList.PlayItems))
+	for i, playItem := range playlist.PlayList.PlayItems {
+		// The MVC extension has an entry per PlayItem, in order.
+		var mvcStream *mpls.MVCStream
+		if mvc != nil && i < len(mvc.MVCStreams) {
+			mvcStream = mvc.MVCStreams[i]
+		}
+		selections[i] = hdmv.SelectStreams(playItem.StreamTable, mvcStream, registers)
+	}
+	return selections, nil
+}
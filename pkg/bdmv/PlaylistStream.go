@@ -0,0 +1,273 @@
+package bdmv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+)
+
+/*
+	Remarks:
+
+	A PlaylistStream joins the byte ranges of the .m2ts files that the
+	PlayItems of a playlist play, in order, into one stream of source
+	packets that a demuxer can read as if it were a single file.
+
+	Each PlayItem is cut at entry points of its clip's EP map, looked up
+	for the first stream PID of the CPI (the video on every BD-ROM clip):
+	it starts at the last entry point at or before its IN time and ends
+	before the first entry point at or after its OUT time, so every frame
+	from IN to OUT can be decoded. The search is limited to the source
+	packets of the STC sequence the PlayItem refers to. A clip without an
+	EP map is played whole.
+
+	Nothing is copied: reads go straight to the .m2ts files, and only the
+	file currently being read is kept open.
+*/
+
+// StreamSegment is the part of one PlayItem's .m2ts file that a
+// PlaylistStream plays.
+type StreamSegment struct {
+	PlayItem int    // Index in the playlist
+	Clip     string // Clip name, e.g. "00001"
+	Path     string // Path of the .m2ts file
+	Start    int64  // Byte range in the .m2ts file, [Start, End)
+	End      int64
+	Offset   int64          // Where the segment starts in the PlaylistStream
+	Time     clock.Ticks45k // Where the PlayItem starts on the playlist timeline
+
+	in     clock.Ticks45k
+	out    clock.Ticks45k
+	points clpi.EntryPoints // Entry points between Start and End
+}
+
+// PlaylistStream reads the PlayItems of a playlist as one continuous,
+// seekable stream of source packets.
+type PlaylistStream struct {
+	Segments []*StreamSegment
+
+	size     int64
+	position int64
+	fsys     fs.FS
+	file     fsfile.File
+	filePath string
+}
+
+// OpenPlaylistStream opens the named playlist as a PlaylistStream. angle
+// picks the clip of multi-angle PlayItems, from 0; PlayItems with fewer
+// angles play their first. Close the stream when done.
+func (disc *Disc) OpenPlaylistStream(name string, angle int) (*PlaylistStream, error) {
+	playlist, ok := disc.Playlists[name]
+	if !ok {
+		return nil, fmt.Errorf("no playlist %s", name)
+	}
+	if playlist.PlayList == nil || len(playlist.PlayItems) == 0 {
+		return nil, fmt.Errorf("playlist %s has no linked PlayItems", name)
+	}
+
+	stream := &PlaylistStream{fsys: disc.files()}
+	var time clock.Ticks45k
+	for i, playItem := range playlist.PlayItems {
+		clip := playItem.Clip
+		if angle < len(playItem.Angles) {
+			clip = playItem.Angles[angle]
+		}
+		if clip == nil {
+			return nil, fmt.Errorf("playlist %s PlayItem[%d]: clip not found", name, i)
+		}
+
+		segment, err := disc.newStreamSegment(playItem, clip)
+		if err != nil {
+			return nil, fmt.Errorf("playlist %s PlayItem[%d]: %w", name, i, err)
+		}
+		segment.PlayItem = i
+		segment.Offset = stream.size
+		segment.Time = time
+		stream.Segments = append(stream.Segments, segment)
+
+		stream.size += segment.End - segment.Start
+		if playItem.OUTTime > playItem.INTime {
+			time = time.Add(playItem.OUTTime.Sub(playItem.INTime))
+		}
+	}
+	return stream, nil
+}
+
+// newStreamSegment cuts the .m2ts file of clip at the entry points
+// around the IN and OUT times of playItem.
+func (disc *Disc) newStreamSegment(playItem *PlayItem, clip *Clip) (*StreamSegment, error) {
+	filePath := disc.path("STREAM", clip.Name+disc.layout().Stream)
+	info, err := fs.Stat(disc.files(), filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	segment := &StreamSegment{
+		Clip:  clip.Name,
+		Path:  filePath,
+		Start: 0,
+		End:   info.Size() - info.Size()%192,
+		in:    playItem.INTime,
+		out:   playItem.OUTTime,
+	}
+	if clip.CPI == nil || len(clip.CPI.StreamPIDEntries) == 0 {
+		return segment, nil
+	}
+
+	// Keep to the source packets of the PlayItem's STC sequence.
+	first, last := stcSequenceSPNs(clip, playItem.RefToSTCID)
+	segment.Start = max(segment.Start, clpi.SPNToOffset(first))
+	segment.End = min(segment.End, clpi.SPNToOffset(last))
+	for _, point := range clip.CPI.StreamPIDEntries[0].EntryPoints() {
+		if point.SPN >= first && point.SPN < last {
+			segment.points = append(segment.points, point)
+		}
+	}
+
+	if point := segment.points.At(playItem.INTime.Ticks90k()); point != nil {
+		segment.Start = point.ByteOffset()
+	}
+	after := sort.Search(len(segment.points), func(i int) bool {
+		return segment.points[i].PTS >= playItem.OUTTime.Ticks90k()
+	})
+	if after < len(segment.points) {
+		segment.End = segment.points[after].ByteOffset()
+	}
+	segment.End = max(segment.End, segment.Start)
+	return segment, nil
+}
+
+// stcSequenceSPNs returns the SPN range [first, last) of the STC sequence
+// with the given id, or of the whole clip when there is no such sequence.
+func stcSequenceSPNs(clip *Clip, stcID uint8) (first, last uint32) {
+	last = ^uint32(0)
+	if clip.SequenceInfo == nil {
+		return first, last
+	}
+	for _, atcSequence := range clip.SequenceInfo.ATCSequences {
+		for j, stcSequence := range atcSequence.STCSequences {
+			if int(atcSequence.OffsetSTCID)+j != int(stcID) {
+				continue
+			}
+			first = stcSequence.SPNSTCStart
+			if j+1 < len(atcSequence.STCSequences) {
+				last = atcSequence.STCSequences[j+1].SPNSTCStart
+			}
+			return first, last
+		}
+	}
+	return first, last
+}
+
+// Size returns the length of the stream in bytes.
+func (stream *PlaylistStream) Size() int64 {
+	return stream.size
+}
+
+// Read reads from the current position. A read stops at the end of a
+// segment; the next one continues in the next segment's file.
+func (stream *PlaylistStream) Read(p []byte) (n int, err error) {
+	if stream.position >= stream.size {
+		return 0, io.EOF
+	}
+	segment := stream.segmentAt(stream.position)
+	if err := stream.openFile(segment.Path); err != nil {
+		return 0, err
+	}
+
+	remaining := segment.Offset + (segment.End - segment.Start) - stream.position
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err = stream.file.ReadAt(p, segment.Start+stream.position-segment.Offset)
+	stream.position += int64(n)
+	if err == io.EOF && n == len(p) {
+		err = nil
+	}
+	if err == io.EOF {
+		err = fmt.Errorf("%s is shorter than its segment: %w", segment.Path, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+// Seek sets the byte position of the next Read, as io.Seeker does.
+func (stream *PlaylistStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += stream.position
+	case io.SeekEnd:
+		offset += stream.size
+	default:
+		return stream.position, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return stream.position, errors.New("negative position")
+	}
+	stream.position = offset
+	return offset, nil
+}
+
+// SeekTime moves to the entry point at or before the given time on the
+// playlist timeline and returns the new byte position. Past the end of
+// the playlist it moves to the end of the stream.
+func (stream *PlaylistStream) SeekTime(time clock.Ticks45k) (int64, error) {
+	i := sort.Search(len(stream.Segments), func(i int) bool {
+		return stream.Segments[i].Time > time
+	}) - 1
+	if i < 0 {
+		return stream.Seek(0, io.SeekStart)
+	}
+	segment := stream.Segments[i]
+	if time-segment.Time >= segment.out-segment.in && i+1 == len(stream.Segments) {
+		return stream.Seek(0, io.SeekEnd)
+	}
+
+	offset := segment.Offset
+	pts := segment.in.Add(time - segment.Time).Ticks90k()
+	if point := segment.points.At(pts); point != nil && point.ByteOffset() > segment.Start {
+		offset += point.ByteOffset() - segment.Start
+	}
+	return stream.Seek(offset, io.SeekStart)
+}
+
+// Close closes the file being read.
+func (stream *PlaylistStream) Close() error {
+	if stream.file == nil {
+		return nil
+	}
+	err := stream.file.Close()
+	stream.file, stream.filePath = nil, ""
+	return err
+}
+
+// segmentAt returns the segment holding byte position, which must be
+// inside the stream.
+func (stream *PlaylistStream) segmentAt(position int64) *StreamSegment {
+	i := sort.Search(len(stream.Segments), func(i int) bool {
+		segment := stream.Segments[i]
+		return segment.Offset+segment.End-segment.Start > position
+	})
+	return stream.Segments[i]
+}
+
+func (stream *PlaylistStream) openFile(filePath string) error {
+	if stream.filePath == filePath {
+		return nil
+	}
+	if err := stream.Close(); err != nil {
+		return err
+	}
+	file, err := fsfile.Open(stream.fsys, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+	stream.file, stream.filePath = file, filePath
+	return nil
+}
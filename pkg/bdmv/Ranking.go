@@ -0,0 +1,281 @@
+package bdmv
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+/*
+	Remarks:
+
+	Ranking is a heuristic, not something the disc states anywhere.
+	The main feature is usually the longest playlist, but studios pad
+	discs with decoys: loops that repeat one segment, copies under a
+	second name, and "obfuscation" playlists that play the same
+	segments as the real one in a scrambled order.
+
+	A segment is one PlayItem reduced to (clip, IN, OUT). Two playlists
+	with the same segments in the same order are duplicates; with the
+	same segments in a different order they are reorders of each other.
+
+	The score mixes five measures, each scaled to the best value on the
+	disc so that scores compare within a disc but not across discs:
+
+		duration        50
+		clip coverage   20  share of the disc's clip time played once
+		chapters        10
+		audio streams   10  in the first PlayItem's StreamTable
+		subtitles       10  PG and text subtitle streams, likewise
+
+	Loops have their score halved. Among reorders the one that jumps
+	backwards through the clip numbers least is preferred, as authoring
+	tools lay out the real feature in ascending clip order.
+*/
+
+// Weights of the score components. They add up to 100.
+const (
+	rankWeightDuration  = 50
+	rankWeightCoverage  = 20
+	rankWeightChapters  = 10
+	rankWeightAudio     = 10
+	rankWeightSubtitles = 10
+)
+
+// PlaylistRank holds the measures and verdict for one playlist.
+type PlaylistRank struct {
+	Playlist *Playlist
+
+	Duration        clock.Ticks45k
+	Chapters        int     // Entry marks
+	AudioStreams    int     // Primary audio streams of the first PlayItem
+	SubtitleStreams int     // PG and text subtitle streams of the first PlayItem
+	Coverage        float64 // Distinct clip time played, as a share of all clip time on the disc
+	Backjumps       int     // PlayItems whose clip number is lower than the one before
+
+	Loop        bool     // Plays the same segment more than once
+	DuplicateOf string   // Name of an earlier playlist with the same segments in the same order
+	ReorderOf   []string // Names of playlists with the same segments in another order
+	MainFeature bool     // The highest ranked playlist that is not a duplicate
+
+	Score float64 // 0 to 100
+}
+
+// Obfuscated reports whether other playlists play the same segments in another order.
+func (rank *PlaylistRank) Obfuscated() bool {
+	return len(rank.ReorderOf) > 0
+}
+
+type segment struct {
+	clip    string
+	in, out clock.Ticks45k
+}
+
+// RankPlaylists scores every playlist of the disc and returns them
+// best first. The first entry not flagged as a duplicate is marked
+// as the main feature. Ties are broken by backjumps, then by name.
+func (disc *Disc) RankPlaylists() []*PlaylistRank {
+	var clipTime uint64
+	for _, clip := range disc.Clips {
+		clipTime += uint64(clip.Duration())
+	}
+
+	ranks := make([]*PlaylistRank, 0, len(disc.Playlists))
+	segments := make(map[string][]segment, len(disc.Playlists))
+	for _, name := range disc.PlaylistNames() {
+		playlist := disc.Playlists[name]
+		rank, segs := newPlaylistRank(playlist, clipTime)
+		ranks = append(ranks, rank)
+		segments[name] = segs
+	}
+
+	flagDuplicates(ranks, segments)
+	scoreRanks(ranks)
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].Score != ranks[j].Score {
+			return ranks[i].Score > ranks[j].Score
+		}
+		if ranks[i].Backjumps != ranks[j].Backjumps {
+			return ranks[i].Backjumps < ranks[j].Backjumps
+		}
+		return ranks[i].Playlist.Name < ranks[j].Playlist.Name
+	})
+
+	for _, rank := range ranks {
+		if rank.DuplicateOf == "" && rank.Duration > 0 {
+			rank.MainFeature = true
+			break
+		}
+	}
+	return ranks
+}
+
+// MainFeature returns the playlist RankPlaylists marks as the main feature,
+// or nil when the disc has no playlist with a duration.
+func (disc *Disc) MainFeature() *Playlist {
+	for _, rank := range disc.RankPlaylists() {
+		if rank.MainFeature {
+			return rank.Playlist
+		}
+	}
+	return nil
+}
+
+func newPlaylistRank(playlist *Playlist, clipTime uint64) (*PlaylistRank, []segment) {
+	rank := &PlaylistRank{
+		Playlist: playlist,
+		Duration: playlist.Duration(),
+		Chapters: len(playlist.Chapters()),
+	}
+	if playlist.PlayList == nil {
+		return rank, nil
+	}
+
+	playItems := playlist.PlayList.PlayItems
+	if len(playItems) > 0 && playItems[0].StreamTable != nil {
+		for _, item := range playItems[0].StreamTable.Items {
+			switch item.KindOf {
+			case mpls.STREAM_TYPE_PRIMARY_AUDIO:
+				rank.AudioStreams = len(item.Streams)
+			case mpls.STREAM_TYPE_PG:
+				rank.SubtitleStreams = len(item.Streams)
+			}
+		}
+	}
+
+	segs := make([]segment, len(playItems))
+	seen := make(map[segment]bool, len(playItems))
+	for i, playItem := range playItems {
+		segs[i] = segment{
+			clip: string(playItem.ClipInformationFileName[:]),
+			in:   playItem.INTime,
+			out:  playItem.OUTTime,
+		}
+		if seen[segs[i]] {
+			rank.Loop = true
+		}
+		seen[segs[i]] = true
+		if i > 0 && segs[i].clip < segs[i-1].clip {
+			rank.Backjumps++
+		}
+	}
+	if clipTime > 0 {
+		rank.Coverage = min(float64(distinctTime(segs))/float64(clipTime), 1)
+	}
+	return rank, segs
+}
+
+// distinctTime returns the clip time the segments play, counting the
+// time where segments of the same clip overlap once.
+func distinctTime(segs []segment) uint64 {
+	sorted := slices.Clone(segs)
+	slices.SortFunc(sorted, func(a, b segment) int {
+		return cmp.Or(strings.Compare(a.clip, b.clip), cmp.Compare(a.in, b.in))
+	})
+
+	var total uint64
+	var merged *segment
+	for _, seg := range sorted {
+		if seg.out <= seg.in {
+			continue
+		}
+		if merged != nil && seg.clip == merged.clip && seg.in <= merged.out {
+			merged.out = max(merged.out, seg.out)
+			continue
+		}
+		if merged != nil {
+			total += uint64(merged.out - merged.in)
+		}
+		merged = &seg
+	}
+	if merged != nil {
+		total += uint64(merged.out - merged.in)
+	}
+	return total
+}
+
+// flagDuplicates groups playlists by their segments. Within a group an
+// identical order makes the later name a duplicate of the earlier one,
+// any other order makes them reorders of each other. Duplicates take no
+// part in reorders. ranks must be in ascending name order.
+func flagDuplicates(ranks []*PlaylistRank, segments map[string][]segment) {
+	groups := map[string][]*PlaylistRank{}
+	for _, rank := range ranks {
+		segs := segments[rank.Playlist.Name]
+		if len(segs) == 0 {
+			continue
+		}
+		key := segmentSetKey(segs)
+		groups[key] = append(groups[key], rank)
+	}
+
+	for _, group := range groups {
+		originals := []*PlaylistRank{}
+		for _, rank := range group {
+			for _, original := range originals {
+				if slices.Equal(segments[rank.Playlist.Name], segments[original.Playlist.Name]) {
+					rank.DuplicateOf = original.Playlist.Name
+					break
+				}
+			}
+			if rank.DuplicateOf == "" {
+				originals = append(originals, rank)
+			}
+		}
+		for _, rank := range originals {
+			for _, other := range originals {
+				if other != rank {
+					rank.ReorderOf = append(rank.ReorderOf, other.Playlist.Name)
+				}
+			}
+		}
+	}
+}
+
+// segmentSetKey identifies the multiset of segments regardless of order.
+func segmentSetKey(segs []segment) string {
+	keys := make([]string, len(segs))
+	for i, seg := range segs {
+		keys[i] = fmt.Sprintf("%s:%d-%d", seg.clip, seg.in, seg.out)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "|")
+}
+
+// scoreRanks scales each measure to the best value among ranks.
+func scoreRanks(ranks []*PlaylistRank) {
+	var maxDuration clock.Ticks45k
+	var maxChapters, maxAudio, maxSubtitles int
+	var maxCoverage float64
+	for _, rank := range ranks {
+		maxDuration = max(maxDuration, rank.Duration)
+		maxChapters = max(maxChapters, rank.Chapters)
+		maxAudio = max(maxAudio, rank.AudioStreams)
+		maxSubtitles = max(maxSubtitles, rank.SubtitleStreams)
+		maxCoverage = max(maxCoverage, rank.Coverage)
+	}
+
+	for _, rank := range ranks {
+		rank.Score = rankWeightDuration*ratio(float64(rank.Duration), float64(maxDuration)) +
+			rankWeightCoverage*ratio(rank.Coverage, maxCoverage) +
+			rankWeightChapters*ratio(float64(rank.Chapters), float64(maxChapters)) +
+			rankWeightAudio*ratio(float64(rank.AudioStreams), float64(maxAudio)) +
+			rankWeightSubtitles*ratio(float64(rank.SubtitleStreams), float64(maxSubtitles))
+		if rank.Loop {
+			rank.Score /= 2
+		}
+	}
+}
+
+func ratio(value, best float64) float64 {
+	if best == 0 {
+		return 0
+	}
+	return value / best
+}
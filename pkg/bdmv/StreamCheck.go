@@ -0,0 +1,243 @@
+package bdmv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+
+	"github.com/parasense/bdmv_go/pkg/clpi"
+	"github.com/parasense/bdmv_go/pkg/m2ts"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+/*
+	Remarks:
+
+	VerifyStreams checks the navigation files against what the transport
+	streams really carry. Each clip's STREAM/xxxxx.m2ts is read only up
+	to its PMT, which a BD-ROM stream repeats from its first packets on.
+
+	Against CLIPINF/xxxxx.clpi, every PID of ProgramInfo must be in the
+	PMT with the same coding type, and every PID of the PMT must be listed.
+
+	Against PLAYLIST/xxxxx.mpls, every stream a PlayItem's StreamTable
+	refers to in its own clip must be in the PMT of each angle's clip,
+	with the same coding type. Streams of an out-of-mux SubPath (stream
+	entry type 2) live in another clip and are not checked. A playlist
+	needs only a subset of a clip's streams, so extra PIDs are reported
+	against the clpi file alone.
+
+	The stream types of a PMT use the same codes as the clpi and mpls
+	coding types, e.g. 0x1B for H.264, so they compare as they are.
+*/
+
+// pmtSearchPackets is how far into a stream VerifyStreams looks for the PMT.
+const pmtSearchPackets = 8 * m2ts.AlignedUnitSize / m2ts.SourcePacketSize
+
+// VerifyStreams compares the elementary streams in each clip's .m2ts
+// file with those its clpi file and the playlists list. It returns a
+// *StreamError for each difference, and a *FileError for each stream
+// that cannot be read. It does not add to Disc.Problems.
+func (disc *Disc) VerifyStreams() (problems []error) {
+	pmts := map[string]map[uint16]uint8{} // Clip name to PID to stream type; nil when unreadable
+
+	layout := disc.layout()
+	for _, name := range disc.ClipNames() {
+		streamPath := disc.path("STREAM", name+layout.Stream)
+		pmt, err := readStreamTypes(disc.files(), streamPath)
+		if err != nil {
+			problems = append(problems, &FileError{Path: streamPath, Err: err})
+			pmts[name] = nil
+			continue
+		}
+		pmts[name] = pmt
+		problems = append(problems, verifyClipStreams(layout, disc.Clips[name], pmt)...)
+	}
+
+	for _, name := range disc.PlaylistNames() {
+		playlist := disc.Playlists[name]
+		for i, playItem := range playlist.PlayItems {
+			if playItem == nil || playItem.StreamTable == nil {
+				continue
+			}
+			for _, clip := range playItem.Angles {
+				if clip == nil || pmts[clip.Name] == nil {
+					continue
+				}
+				source := fmt.Sprintf("PLAYLIST/%s%s PlayItem[%d]", name, layout.Playlist, i)
+				problems = append(problems, verifyPlayItemStreams(layout, source, clip.Name, playItem.StreamTable, pmts[clip.Name])...)
+			}
+		}
+	}
+	return problems
+}
+
+// readStreamTypes reads a transport stream up to its PMTs and returns
+// the stream type of every elementary stream PID they list.
+func readStreamTypes(fsys fs.FS, filePath string) (map[uint16]uint8, error) {
+	file, err := m2ts.OpenFS(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	for range pmtSearchPackets {
+		if _, err := file.ReadPacket(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if pmtsComplete(file.Reader) {
+			types := map[uint16]uint8{}
+			for _, pmt := range file.PMTs {
+				for _, stream := range pmt.Streams {
+					types[stream.PID] = stream.StreamType
+				}
+			}
+			return types, nil
+		}
+	}
+	return nil, fmt.Errorf("no PAT and PMT in the first %d source packets", pmtSearchPackets)
+}
+
+// pmtsComplete reports whether the reader has seen the PMT of every
+// program in the PAT.
+func pmtsComplete(reader *m2ts.Reader) bool {
+	if reader.PAT == nil {
+		return false
+	}
+	for _, program := range reader.PAT.Programs {
+		if _, ok := reader.PMTs[program.ProgramNumber]; program.ProgramNumber != 0 && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func verifyClipStreams(layout *Layout, clip *Clip, pmt map[uint16]uint8) (problems []error) {
+	if clip.ProgramInfo == nil {
+		return nil
+	}
+	source := fmt.Sprintf("CLIPINF/%s%s", clip.Name, layout.ClipInfo)
+	stream := fmt.Sprintf("STREAM/%s%s", clip.Name, layout.Stream)
+
+	listed := map[uint16]bool{}
+	for _, program := range clip.ProgramInfo.Programs {
+		for _, programStream := range program.ProgramStreams {
+			listed[programStream.StreamPID] = true
+			expected, ok := clipStreamCodingType(programStream)
+			if !ok {
+				continue
+			}
+			if problem := compareStream(source, stream, programStream.StreamPID, expected, pmt); problem != nil {
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	for _, pid := range sortedPIDs(pmt) {
+		if !listed[pid] {
+			problems = append(problems, &StreamError{Source: source, Stream: stream, Kind: StreamExtra, PID: pid, Found: pmt[pid]})
+		}
+	}
+	return problems
+}
+
+func verifyPlayItemStreams(layout *Layout, source, clipName string, streamTable *mpls.StreamTable, pmt map[uint16]uint8) (problems []error) {
+	stream := fmt.Sprintf("STREAM/%s%s", clipName, layout.Stream)
+	for _, item := range streamTable.Items {
+		for _, s := range item.Streams {
+			pid, ok := inMuxStreamPID(s.Entry)
+			if !ok {
+				continue
+			}
+			expected, ok := playlistStreamCodingType(s.Attr)
+			if !ok {
+				continue
+			}
+			if problem := compareStream(source, stream, pid, expected, pmt); problem != nil {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems
+}
+
+// compareStream checks one listed stream against the PMT.
+func compareStream(source, stream string, pid uint16, expected uint8, pmt map[uint16]uint8) error {
+	found, ok := pmt[pid]
+	if !ok {
+		return &StreamError{Source: source, Stream: stream, Kind: StreamMissing, PID: pid, Expected: expected}
+	}
+	if found != expected {
+		return &StreamError{Source: source, Stream: stream, Kind: StreamCodecMismatch, PID: pid, Expected: expected, Found: found}
+	}
+	return nil
+}
+
+// inMuxStreamPID returns the PID of a stream entry that points into the
+// PlayItem's own clip, i.e. any entry but an out-of-mux SubPath one.
+func inMuxStreamPID(entry mpls.StreamEntry) (uint16, bool) {
+	switch entry := entry.(type) {
+	case *mpls.StreamEntryTypeI:
+		return entry.RefToStreamPID, true
+	case *mpls.StreamEntryTypeIII:
+		return entry.RefToStreamPID, true
+	}
+	return 0, false
+}
+
+func playlistStreamCodingType(attr mpls.StreamAttributes) (uint8, bool) {
+	switch attr := attr.(type) {
+	case *mpls.PrimaryVideoAttributesH264:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.PrimaryVideoAttributesHEVC:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.SecondaryVideoAttributes:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.PrimaryAudioAttributes:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.SecondaryAudioAttributes:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.PGAttributes:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.IGAttributes:
+		return uint8(attr.StreamCodingType), true
+	case *mpls.TextAttributes:
+		return uint8(attr.StreamCodingType), true
+	}
+	return 0, false
+}
+
+func clipStreamCodingType(programStream *clpi.ProgramStream) (uint8, bool) {
+	if len(programStream.StreamCodingInfo) == 0 {
+		return 0, false
+	}
+	switch info := programStream.StreamCodingInfo[0].(type) {
+	case *clpi.StreamCodingInfoH264:
+		return uint8(info.StreamCodingType), true
+	case *clpi.StreamCodingInfoH265:
+		return uint8(info.StreamCodingType), true
+	case *clpi.StreamCodingInfoAudio:
+		return uint8(info.StreamCodingType), true
+	case *clpi.StreamCodingTypePG:
+		return uint8(info.StreamCodingType), true
+	case *clpi.StreamCodingTypeIG:
+		return uint8(info.StreamCodingType), true
+	case *clpi.StreamCodingTypeText:
+		return uint8(info.StreamCodingType), true
+	}
+	return 0, false
+}
+
+func sortedPIDs(pmt map[uint16]uint8) []uint16 {
+	pids := make([]uint16, 0, len(pmt))
+	for pid := range pmt {
+		pids = append(pids, pid)
+	}
+	slices.Sort(pids)
+	return pids
+}
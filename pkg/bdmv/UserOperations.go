@@ -0,0 +1,54 @@
+package bdmv
+
+import (
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// UserOperationSpan is one PlayItem of a playlist on the playlist's
+// timeline, with the user operations a player refuses while it plays.
+type UserOperationSpan struct {
+	PlayItem int
+	Start    clock.Ticks45k
+	End      clock.Ticks45k
+	Mask     *mpls.UserOptions // The AppInfo mask OR the PlayItem mask
+}
+
+// UserOperations returns the effective user-operation mask of every
+// PlayItem of the playlist, in play order.
+func (playlist *Playlist) UserOperations() []*UserOperationSpan {
+	spans := []*UserOperationSpan{}
+	if playlist.PlayList == nil {
+		return spans
+	}
+	var appMask *mpls.UserOptions
+	if playlist.AppInfo != nil {
+		appMask = playlist.AppInfo.UserOptions
+	}
+
+	var total clock.Ticks45k
+	for i, playItem := range playlist.PlayList.PlayItems {
+		span := &UserOperationSpan{
+			PlayItem: i,
+			Start:    total,
+			Mask:     appMask.Or(playItem.UserOptions),
+		}
+		if playItem.OUTTime > playItem.INTime {
+			total = total.Add(playItem.OUTTime.Sub(playItem.INTime))
+		}
+		span.End = total
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// UserOperationsAt returns the effective user-operation mask at a time
+// on the playlist's timeline, or nil when the time is past the end.
+func (playlist *Playlist) UserOperationsAt(time clock.Ticks45k) *mpls.UserOptions {
+	for _, span := range playlist.UserOperations() {
+		if time >= span.Start && time < span.End {
+			return span.Mask
+		}
+	}
+	return nil
+}
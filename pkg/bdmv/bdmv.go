@@ -0,0 +1,494 @@
+package bdmv
+
+/*
+	Remarks:
+
+	A BDMV tree looks like this:
+
+		BDMV/index.bdmv
+		BDMV/MovieObject.bdmv
+		BDMV/PLAYLIST/xxxxx.mpls
+		BDMV/CLIPINF/xxxxx.clpi
+		BDMV/STREAM/xxxxx.m2ts
+		BDMV/BDJO/xxxxx.bdjo          (BD-J discs)
+		BDMV/AUXDATA/sound.bdmv       (optional)
+		BDMV/META/DL/bdmt_xxx.xml     (optional)
+		BDMV/META/TN/tnmt_xxx_yyyyy.xml (optional)
+		BDMV/AUXDATA/dvb.fontindex    (optional)
+
+	AVCHD camcorder media hold the same tree under PRIVATE/AVCHD/BDMV with
+	8.3 names: INDEX.BDM, MOVIEOBJ.BDM and .MPL, .CPI and .MTS files. The
+	Layout of a Disc records which names it uses. File names are matched
+	without regard to case.
+
+	Open() loads every navigation file it can find and links them together.
+	A broken or missing file does not stop the load; it is recorded in
+	Disc.Problems and the remaining files are still parsed.
+
+	OpenFile() loads a single navigation file into an otherwise empty Disc,
+	so tools can treat one file and a whole tree the same way.
+
+	OpenFS() and OpenFileFS() read from an fs.FS instead of the operating
+	system's files. Package udf provides one for Blu-ray disc images, so
+	an ISO file is read without mounting it.
+*/
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/parasense/bdmv_go/pkg/bdjo"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+	"github.com/parasense/bdmv_go/pkg/fontdir"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/meta"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+	"github.com/parasense/bdmv_go/pkg/sound"
+)
+
+// Open loads the BDMV tree at root. The root may be either the BDMV
+// directory itself or a disc root that contains a BDMV directory, at
+// BDMV or, for AVCHD media, at PRIVATE/AVCHD/BDMV.
+// An error is returned only when root is not a usable directory; parse
+// failures and dangling references are collected in Disc.Problems.
+func Open(root string) (disc *Disc, err error) {
+	return OpenFS(osFS{}, root)
+}
+
+// OpenFS is Open for the directory root of fsys, "." for its top. With
+// a disc image opened by package udf, a disc is read without mounting it.
+func OpenFS(fsys fs.FS, root string) (disc *Disc, err error) {
+	bdmvDir, err := findBDMVDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	disc = &Disc{
+		Root:       bdmvDir,
+		Layout:     detectLayout(fsys, bdmvDir),
+		fsys:       fsys,
+		Playlists:  make(map[string]*Playlist),
+		Clips:      make(map[string]*Clip),
+		BDJObjects: make(map[string]*BDJObject),
+		Meta:       make(map[string]*meta.DiscLib),
+		TrackNames: make(map[string]map[string]*meta.TrackNames),
+	}
+
+	disc.loadIndex(disc.path(disc.Layout.Index))
+	disc.loadMovieObjects(disc.path(disc.Layout.MovieObjects))
+	disc.loadClips()
+	disc.loadPlaylists()
+	disc.loadBDJObjects()
+	disc.loadSound()
+	disc.loadMeta()
+	disc.loadTrackNames()
+	disc.loadFontIndex()
+
+	disc.linkTitles()
+	disc.linkPlayItems()
+
+	return disc, nil
+}
+
+// OpenFile loads the single navigation file at filePath. The parser is
+// picked from the file's type indicator, not from its name. An error is
+// returned when the file cannot be read or identified; parse failures are
+// collected in Disc.Problems, as with Open. References are not resolved.
+func OpenFile(filePath string) (disc *Disc, err error) {
+	return OpenFileFS(osFS{}, filePath)
+}
+
+// OpenFileFS is OpenFile for the file name in fsys.
+func OpenFileFS(fsys fs.FS, filePath string) (disc *Disc, err error) {
+	fileType, err := DetectFileTypeFS(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	disc = &Disc{
+		Root:       dirPath(fsys, filePath),
+		Layout:     layoutOf(filePath),
+		fsys:       fsys,
+		Playlists:  make(map[string]*Playlist),
+		Clips:      make(map[string]*Clip),
+		BDJObjects: make(map[string]*BDJObject),
+		Meta:       make(map[string]*meta.DiscLib),
+		TrackNames: make(map[string]map[string]*meta.TrackNames),
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	switch fileType {
+	case FileTypePlaylist:
+		disc.loadPlaylist(name, filePath)
+	case FileTypeClipInfo:
+		disc.loadClip(name, filePath)
+	case FileTypeIndex:
+		disc.loadIndex(filePath)
+	case FileTypeMovieObjects:
+		disc.loadMovieObjects(filePath)
+	case FileTypeSound:
+		disc.loadSoundFile(filePath)
+	case FileTypeBDJObject:
+		disc.loadBDJObject(name, filePath)
+	case FileTypeMeta:
+		disc.loadMetaFile(metaLanguage(filepath.Base(filePath)), filePath)
+	case FileTypeFontIndex:
+		disc.loadFontIndexFile(filePath)
+	default:
+		return nil, fmt.Errorf("%s: unknown navigation file type", filePath)
+	}
+
+	return disc, nil
+}
+
+// findBDMVDir returns the BDMV directory for root.
+func findBDMVDir(fsys fs.FS, root string) (string, error) {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return "", fmt.Errorf("failed to open disc root: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("disc root %s is not a directory", root)
+	}
+
+	for _, elem := range bdmvDirs {
+		nested, ok := lookupPath(fsys, root, elem...)
+		if info, err := fs.Stat(fsys, nested); ok && err == nil && info.IsDir() {
+			return nested, nil
+		}
+	}
+
+	return root, nil
+}
+
+// path returns the path of a file relative to the BDMV directory.
+// Each element is matched without regard to case; a missing file keeps
+// the name it was asked for.
+func (disc *Disc) path(elem ...string) string {
+	path, _ := lookupPath(disc.files(), disc.Root, elem...)
+	return path
+}
+
+// problem records a problem found while loading the disc.
+func (disc *Disc) problem(err error) {
+	disc.Problems = append(disc.Problems, err)
+}
+
+func (disc *Disc) loadIndex(filePath string) {
+	header, appInfo, indexes, extensions, err := indx.ParseINDXFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Index = &Index{
+		Header:     header,
+		AppInfo:    appInfo,
+		Indexes:    indexes,
+		Extensions: extensions,
+	}
+}
+
+func (disc *Disc) loadMovieObjects(filePath string) {
+	header, movieObjects, extensions, err := mobj.ParseMOBJFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.MovieObjects = &MovieObjects{
+		Header:       header,
+		MovieObjects: movieObjects,
+		Extensions:   extensions,
+	}
+}
+
+func (disc *Disc) loadClips() {
+	ext := disc.layout().ClipInfo
+	for _, name := range disc.listDir("CLIPINF", ext) {
+		disc.loadClip(name, disc.path("CLIPINF", name+ext))
+	}
+}
+
+func (disc *Disc) loadClip(name, filePath string) {
+	header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensions, err := clpi.ParseCLPIFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Clips[name] = &Clip{
+		Name:         name,
+		Header:       header,
+		ClipInfo:     clipInfo,
+		SequenceInfo: sequenceInfo,
+		ProgramInfo:  programInfo,
+		CPI:          cpi,
+		ClipMarks:    clipMarks,
+		Extensions:   extensions,
+	}
+}
+
+func (disc *Disc) loadPlaylists() {
+	ext := disc.layout().Playlist
+	for _, name := range disc.listDir("PLAYLIST", ext) {
+		disc.loadPlaylist(name, disc.path("PLAYLIST", name+ext))
+	}
+}
+
+func (disc *Disc) loadPlaylist(name, filePath string) {
+	header, appInfo, playList, marks, extensions, err := mpls.ParseMPLSFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Playlists[name] = &Playlist{
+		Name:       name,
+		Header:     header,
+		AppInfo:    appInfo,
+		PlayList:   playList,
+		Marks:      marks,
+		Extensions: extensions,
+	}
+}
+
+// loadBDJObjects loads every BDJO/*.bdjo file. Only BD-J discs have a
+// BDJO directory, so a missing one is not a problem.
+func (disc *Disc) loadBDJObjects() {
+	if _, err := fs.Stat(disc.files(), disc.path("BDJO")); err != nil {
+		return
+	}
+	for _, name := range disc.listDir("BDJO", ".bdjo") {
+		disc.loadBDJObject(name, disc.path("BDJO", name+".bdjo"))
+	}
+}
+
+func (disc *Disc) loadBDJObject(name, filePath string) {
+	header, terminalInfo, appCacheInfo, accessiblePlaylists, appManagementTable, keyInterestTable, fileAccessInfo, err := bdjo.ParseBDJOFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.BDJObjects[name] = &BDJObject{
+		Name:                       name,
+		Header:                     header,
+		TerminalInfo:               terminalInfo,
+		AppCacheInfo:               appCacheInfo,
+		TableOfAccessiblePlaylists: accessiblePlaylists,
+		ApplicationManagementTable: appManagementTable,
+		KeyInterestTable:           keyInterestTable,
+		FileAccessInfo:             fileAccessInfo,
+	}
+}
+
+// loadSound loads AUXDATA/sound.bdmv. Most discs have none, so a missing
+// file is not a problem.
+func (disc *Disc) loadSound() {
+	filePath := disc.path("AUXDATA", "sound.bdmv")
+	if _, err := fs.Stat(disc.files(), filePath); err != nil {
+		return
+	}
+	disc.loadSoundFile(filePath)
+}
+
+func (disc *Disc) loadSoundFile(filePath string) {
+	header, metaData, data, err := sound.ParseBCLKFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Sound = &Sound{
+		Header:   header,
+		MetaData: metaData,
+		Data:     data,
+	}
+}
+
+// loadMeta loads every META/DL/bdmt_xxx.xml file, keyed by its language code.
+func (disc *Disc) loadMeta() {
+	dir := disc.path("META", "DL")
+	entries, err := fs.ReadDir(disc.files(), dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if entry.IsDir() || !strings.HasPrefix(name, "bdmt_") || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		disc.loadMetaFile(metaLanguage(name), joinPath(disc.files(), dir, entry.Name()))
+	}
+}
+
+func (disc *Disc) loadMetaFile(language, filePath string) {
+	discLib, err := meta.ParseMETAFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.Meta[language] = discLib
+}
+
+// metaLanguage returns the language code of a bdmt_xxx.xml file name,
+// or the lower case base name when it does not follow that pattern.
+func metaLanguage(name string) string {
+	name = strings.ToLower(name)
+	return strings.TrimSuffix(strings.TrimPrefix(name, "bdmt_"), ".xml")
+}
+
+// loadTrackNames loads every META/TN/tnmt_xxx_yyyyy.xml file,
+// keyed by its language code and playlist name.
+func (disc *Disc) loadTrackNames() {
+	dir := disc.path("META", "TN")
+	entries, err := fs.ReadDir(disc.files(), dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if entry.IsDir() || !strings.HasPrefix(name, "tnmt_") || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		// tnmt_eng_00800.xml
+		language, playlist, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, "tnmt_"), ".xml"), "_")
+		if !ok {
+			continue
+		}
+		filePath := joinPath(disc.files(), dir, entry.Name())
+		trackNames, err := meta.ParseTrackNamesFS(disc.files(), filePath)
+		if err != nil {
+			disc.problem(&FileError{Path: filePath, Err: err})
+			continue
+		}
+		if disc.TrackNames[language] == nil {
+			disc.TrackNames[language] = make(map[string]*meta.TrackNames)
+		}
+		disc.TrackNames[language][playlist] = trackNames
+	}
+}
+
+// loadFontIndex loads AUXDATA/dvb.fontindex. Only BD-J discs with their
+// own fonts carry one, so a missing file is not a problem.
+func (disc *Disc) loadFontIndex() {
+	filePath := disc.path("AUXDATA", "dvb.fontindex")
+	if _, err := fs.Stat(disc.files(), filePath); err != nil {
+		return
+	}
+	disc.loadFontIndexFile(filePath)
+}
+
+func (disc *Disc) loadFontIndexFile(filePath string) {
+	fontIndex, err := fontdir.ParseFontDirectoryFS(disc.files(), filePath)
+	if err != nil {
+		disc.problem(&FileError{Path: filePath, Err: err})
+		return
+	}
+	disc.FontIndex = fontIndex
+}
+
+// listDir returns the base names (without extension) of the files in a
+// BDMV sub-directory that carry the given extension. Matching is case
+// insensitive because some authoring tools write upper case names.
+func (disc *Disc) listDir(dir, ext string) (names []string) {
+	entries, err := fs.ReadDir(disc.files(), disc.path(dir))
+	if err != nil {
+		disc.problem(&FileError{Path: disc.path(dir), Err: err})
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+	}
+	return names
+}
+
+// linkTitles resolves each index.bdmv title to its movie object.
+func (disc *Disc) linkTitles() {
+	if disc.Index == nil || disc.Index.Indexes == nil {
+		return
+	}
+	indexes := disc.Index.Indexes
+	index := disc.layout().Index
+
+	disc.Index.FirstPlayback = disc.linkTitle(index+" FirstPlayback", indexes.FirstPlaybackTitle)
+	disc.Index.TopMenu = disc.linkTitle(index+" TopMenu", indexes.TopMenuTitle)
+
+	disc.Index.Titles = make([]*Title, len(indexes.Titles))
+	for i, title := range indexes.Titles {
+		disc.Index.Titles[i] = disc.linkTitle(fmt.Sprintf("%s Title[%d]", index, i), title)
+	}
+}
+
+func (disc *Disc) linkTitle(source string, title *indx.Title) *Title {
+	if title == nil {
+		return nil
+	}
+	linked := &Title{Title: title}
+
+	// HDMV titles refer to a movie object, BD-J titles to a .bdjo file.
+	if title.ObjectType == 2 {
+		name := string(title.RefToBDJObjectID[:])
+		if linked.BDJObject = disc.BDJObjects[name]; linked.BDJObject == nil {
+			disc.problem(&ReferenceError{Source: source, Target: fmt.Sprintf("BDJO/%s.bdjo", name)})
+		}
+		return linked
+	}
+	if title.ObjectType != 1 {
+		return linked
+	}
+
+	if disc.MovieObjects == nil || disc.MovieObjects.MovieObjects == nil {
+		disc.problem(&ReferenceError{
+			Source: source,
+			Target: fmt.Sprintf("%s object %d", disc.layout().MovieObjects, title.RefToMovieObjectID),
+		})
+		return linked
+	}
+
+	objects := disc.MovieObjects.MovieObjects.MovieObjects
+	if int(title.RefToMovieObjectID) >= len(objects) {
+		disc.problem(&ReferenceError{
+			Source: source,
+			Target: fmt.Sprintf("%s object %d", disc.layout().MovieObjects, title.RefToMovieObjectID),
+		})
+		return linked
+	}
+
+	linked.MovieObject = objects[title.RefToMovieObjectID]
+	return linked
+}
+
+// linkPlayItems resolves each PlayItem (and each of its angles) to a clip.
+func (disc *Disc) linkPlayItems() {
+	for _, name := range disc.PlaylistNames() {
+		playlist := disc.Playlists[name]
+		if playlist.PlayList == nil {
+			continue
+		}
+
+		playlist.PlayItems = make([]*PlayItem, len(playlist.PlayList.PlayItems))
+		for i, playItem := range playlist.PlayList.PlayItems {
+			linked := &PlayItem{
+				PlayItem: playItem,
+				Angles:   make([]*Clip, len(playItem.Angles)),
+			}
+			for j, angle := range playItem.Angles {
+				clipName := string(angle.FileName[:])
+				clip, ok := disc.Clips[clipName]
+				if !ok {
+					disc.problem(&ReferenceError{
+						Source: fmt.Sprintf("PLAYLIST/%s%s PlayItem[%d] Angle[%d]", name, disc.layout().Playlist, i, j),
+						Target: fmt.Sprintf("CLIPINF/%s%s", clipName, disc.layout().ClipInfo),
+					})
+				}
+				linked.Angles[j] = clip
+			}
+			if len(linked.Angles) > 0 {
+				linked.Clip = linked.Angles[0]
+			}
+			playlist.PlayItems[i] = linked
+		}
+	}
+}
@@ -0,0 +1,750 @@
+package bdmv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/clpi"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/meta"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+func TestOpenEmptyTree(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "BDMV", "PLAYLIST"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	disc, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if disc.Root != filepath.Join(root, "BDMV") {
+		t.Errorf("Root = %q, want the nested BDMV directory", disc.Root)
+	}
+	if disc.Index != nil || disc.MovieObjects != nil {
+		t.Errorf("expected no index or movie objects on an empty tree")
+	}
+
+	// index.bdmv, MovieObject.bdmv and CLIPINF/ are all missing.
+	var fileErr *FileError
+	count := 0
+	for _, problem := range disc.Problems {
+		if errors.As(problem, &fileErr) {
+			count++
+		}
+	}
+	if count != 3 {
+		t.Errorf("got %d file problems, want 3: %v", count, disc.Problems)
+	}
+}
+
+func TestOpenAVCHD(t *testing.T) {
+	// An SD card mounted with lower case short names.
+	root := t.TempDir()
+	bdmvDir := filepath.Join(root, "private", "avchd", "BDMV")
+	files := map[string]string{
+		"PLAYLIST/00000.mpl": "../mpls/testdata/00000.mpls",
+		"CLIPINF/00001.CPI":  "../clpi/testdata/00001.clpi",
+		"INDEX.BDM":          "bdmv_test.go",
+	}
+	for name, source := range files {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filePath := filepath.Join(bdmvDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	disc, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if disc.Root != bdmvDir || disc.Layout != LayoutAVCHD {
+		t.Fatalf("Root, Layout = %q, %s, want %q, AVCHD", disc.Root, disc.Layout.Name, bdmvDir)
+	}
+	if disc.Playlists["00000"] == nil || disc.Clips["00001"] == nil {
+		t.Fatalf("loaded playlists %v and clips %v", disc.PlaylistNames(), disc.ClipNames())
+	}
+
+	var fileErr *FileError
+	var refErr *ReferenceError
+	var paths, targets []string
+	for _, problem := range disc.Problems {
+		switch {
+		case errors.As(problem, &fileErr):
+			paths = append(paths, filepath.Base(fileErr.Path))
+		case errors.As(problem, &refErr):
+			targets = append(targets, refErr.Target)
+		}
+	}
+	if !slices.Equal(paths, []string{"INDEX.BDM", "MOVIEOBJ.BDM"}) {
+		t.Errorf("file problems for %v, want INDEX.BDM and MOVIEOBJ.BDM", paths)
+	}
+	if !slices.Contains(targets, "CLIPINF/00002.CPI") {
+		t.Errorf("reference problems for %v, want CLIPINF/00002.CPI", targets)
+	}
+}
+
+func TestOpenFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, source := range map[string]string{
+		"BDMV/PLAYLIST/00000.mpls": "../mpls/testdata/00000.mpls",
+		"BDMV/CLIPINF/00001.clpi":  "../clpi/testdata/00001.clpi",
+		"BDMV/STREAM/00001.m2ts":   "bdmv_test.go",
+	} {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+
+	disc, err := OpenFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS() error = %v", err)
+	}
+	if disc.Root != "BDMV" || disc.Layout != LayoutBDMV {
+		t.Fatalf("Root, Layout = %q, %s, want BDMV", disc.Root, disc.Layout.Name)
+	}
+	if disc.Playlists["00000"] == nil || disc.Clips["00001"] == nil {
+		t.Fatalf("loaded playlists %v and clips %v", disc.PlaylistNames(), disc.ClipNames())
+	}
+
+	var fileErr *FileError
+	var paths []string
+	for _, problem := range disc.Problems {
+		if errors.As(problem, &fileErr) {
+			paths = append(paths, fileErr.Path)
+		}
+	}
+	if !slices.Equal(paths, []string{"BDMV/index.bdmv", "BDMV/MovieObject.bdmv"}) {
+		t.Errorf("file problems for %v, want index.bdmv and MovieObject.bdmv", paths)
+	}
+
+	// Streams are read from the file system too.
+	problems := disc.VerifyStreams()
+	if len(problems) != 1 || !errors.As(problems[0], &fileErr) || fileErr.Path != "BDMV/STREAM/00001.m2ts" {
+		t.Errorf("VerifyStreams() = %v, want a problem reading BDMV/STREAM/00001.m2ts", problems)
+	}
+
+	if disc, err := OpenFileFS(fsys, "BDMV/PLAYLIST/00000.mpls"); err != nil || disc.Playlists["00000"] == nil || disc.Root != "BDMV/PLAYLIST" {
+		t.Errorf("OpenFileFS() = %+v, %v", disc, err)
+	}
+}
+
+// testdata/linked has two movie objects and two titles, title 2 naming
+// object 5, and one playlist whose second PlayItem plays clips 00002 and
+// 00003, which have no .clpi file.
+func TestOpenLinks(t *testing.T) {
+	disc, err := Open("testdata/linked")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if disc.Index == nil || disc.MovieObjects == nil {
+		t.Fatalf("Open() did not load index.bdmv and MovieObject.bdmv: %v", disc.Problems)
+	}
+
+	objects := disc.MovieObjects.MovieObjects.MovieObjects
+	if title := disc.Index.FirstPlayback; title == nil || title.MovieObject != objects[1] {
+		t.Errorf("FirstPlayback = %+v, want movie object 1", title)
+	}
+	if title := disc.Index.Titles[0]; title.MovieObject != objects[0] {
+		t.Errorf("Titles[0] = %+v, want movie object 0", title)
+	}
+
+	playlist := disc.Playlists["00000"]
+	if playlist == nil || len(playlist.PlayItems) != 2 {
+		t.Fatalf("Playlists[00000] = %+v, want two linked PlayItems", playlist)
+	}
+	if playItem := playlist.PlayItems[0]; playItem.Clip == nil || playItem.Clip != disc.Clips["00001"] {
+		t.Errorf("PlayItems[0].Clip = %+v, want clip 00001", playItem.Clip)
+	}
+}
+
+func TestOpenDanglingReferences(t *testing.T) {
+	disc, err := Open("testdata/linked")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	var refErr *ReferenceError
+	var references []string
+	for _, problem := range disc.Problems {
+		if !errors.As(problem, &refErr) {
+			t.Errorf("unexpected problem %v", problem)
+			continue
+		}
+		references = append(references, refErr.Source+" -> "+refErr.Target)
+	}
+	want := []string{
+		"index.bdmv Title[1] -> MovieObject.bdmv object 5",
+		"PLAYLIST/00000.mpls PlayItem[1] Angle[0] -> CLIPINF/00002.clpi",
+		"PLAYLIST/00000.mpls PlayItem[1] Angle[1] -> CLIPINF/00003.clpi",
+	}
+	if !slices.Equal(references, want) {
+		t.Errorf("reference problems = %q, want %q", references, want)
+	}
+
+	if title := disc.Index.Titles[1]; title.MovieObject != nil {
+		t.Errorf("Titles[1].MovieObject = %+v, want nil", title.MovieObject)
+	}
+	if playItem := disc.Playlists["00000"].PlayItems[1]; playItem.Clip != nil || len(playItem.Angles) != 2 {
+		t.Errorf("PlayItems[1] = %+v, want two unresolved angles", playItem)
+	}
+}
+
+func TestOpenNotADirectory(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("Open() on a missing path should fail")
+	}
+}
+
+func TestDetectFileType(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     FileType
+	}{
+		{"../mpls/testdata/00000.mpls", FileTypePlaylist},
+		{"../clpi/testdata/00001.clpi", FileTypeClipInfo},
+		{"bdmv_test.go", FileTypeUnknown},
+	}
+	for _, tt := range tests {
+		got, err := DetectFileType(tt.filePath)
+		if err != nil {
+			t.Errorf("DetectFileType(%s) error = %v", tt.filePath, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("DetectFileType(%s) = %v, want %v", tt.filePath, got, tt.want)
+		}
+	}
+}
+
+func TestOpenFileIgnoresName(t *testing.T) {
+	data, err := os.ReadFile("../mpls/testdata/00000.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A playlist saved under a misleading name is still read as a playlist.
+	filePath := filepath.Join(t.TempDir(), "00042.clpi")
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	disc, err := OpenFile(filePath)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if len(disc.Problems) != 0 {
+		t.Errorf("OpenFile() problems = %v", disc.Problems)
+	}
+	if _, ok := disc.Playlists["00042"]; !ok || len(disc.Clips) != 0 {
+		t.Errorf("OpenFile() loaded %d playlists and %d clips, want playlist 00042 only",
+			len(disc.Playlists), len(disc.Clips))
+	}
+
+	if _, err := OpenFile("bdmv_test.go"); err == nil {
+		t.Errorf("OpenFile() on a Go source file should fail")
+	}
+}
+
+// testPlaylist builds a playlist from (clip, IN, OUT) segments.
+func testPlaylist(name string, chapters int, segs ...segment) *Playlist {
+	playlist := &Playlist{
+		Name:     name,
+		PlayList: &mpls.PlayList{},
+		Marks:    &mpls.PlaylistMarks{},
+	}
+	for _, seg := range segs {
+		playItem := &mpls.PlayItem{INTime: seg.in, OUTTime: seg.out}
+		copy(playItem.ClipInformationFileName[:], seg.clip)
+		playlist.PlayList.PlayItems = append(playlist.PlayList.PlayItems, playItem)
+	}
+	// Spread the marks over the first PlayItem, so none collapse.
+	for i := range chapters {
+		mark := &mpls.MarkEntry{MarkType: 1}
+		if len(segs) > 0 {
+			mark.MarkTimeStamp = segs[0].in + (segs[0].out-segs[0].in)*clock.Ticks45k(i)/clock.Ticks45k(chapters)
+		}
+		playlist.Marks.Marks = append(playlist.Marks.Marks, mark)
+	}
+	return playlist
+}
+
+func testClip(name string, ticks clock.Ticks45k) *Clip {
+	return &Clip{
+		Name: name,
+		SequenceInfo: &clpi.SequenceInfo{
+			ATCSequences: []*clpi.ATCSequence{{
+				STCSequences: []*clpi.STCSequence{{PresentationEndTime: ticks}},
+			}},
+		},
+	}
+}
+
+func TestLinkBDJTitles(t *testing.T) {
+	bdjTitle := func(name string) *indx.Title {
+		title := &indx.Title{ObjectType: 2}
+		copy(title.RefToBDJObjectID[:], name)
+		return title
+	}
+	disc := &Disc{
+		Index: &Index{Indexes: &indx.Indexes{
+			Titles: []*indx.Title{bdjTitle("00001"), bdjTitle("00002")},
+		}},
+		BDJObjects: map[string]*BDJObject{"00001": {Name: "00001"}},
+	}
+	disc.linkTitles()
+
+	if title := disc.Index.Titles[0]; title.BDJObject == nil || title.BDJObject.Name != "00001" || title.MovieObject != nil {
+		t.Errorf("Titles[0] = %+v, want BD-J object 00001", title)
+	}
+	var refErr *ReferenceError
+	if len(disc.Problems) != 1 || !errors.As(disc.Problems[0], &refErr) || refErr.Target != "BDJO/00002.bdjo" {
+		t.Errorf("Problems = %v, want the missing BDJO/00002.bdjo", disc.Problems)
+	}
+}
+
+func TestRankPlaylists(t *testing.T) {
+	const minute = 45000 * 60
+	a := segment{"00001", 0, 40 * minute}
+	b := segment{"00002", 0, 30 * minute}
+	c := segment{"00003", 0, 30 * minute}
+	trailer := segment{"00004", 0, 2 * minute}
+
+	disc := &Disc{
+		Clips: map[string]*Clip{
+			"00001": testClip("00001", 40*minute),
+			"00002": testClip("00002", 30*minute),
+			"00003": testClip("00003", 30*minute),
+			"00004": testClip("00004", 2*minute),
+		},
+		Playlists: map[string]*Playlist{},
+	}
+	for _, playlist := range []*Playlist{
+		testPlaylist("00100", 0, trailer, trailer, trailer), // loop
+		testPlaylist("00200", 12, c, a, b),                  // reorder
+		testPlaylist("00300", 12, a, b, c),                  // main feature
+		testPlaylist("00400", 12, a, b, c),                  // duplicate of 00300
+		testPlaylist("00500", 1, trailer),
+	} {
+		disc.Playlists[playlist.Name] = playlist
+	}
+
+	ranks := disc.RankPlaylists()
+	byName := map[string]*PlaylistRank{}
+	for _, rank := range ranks {
+		byName[rank.Playlist.Name] = rank
+	}
+
+	// 00200 and 00300 score the same; 00300 wins by not jumping back.
+	if main := disc.MainFeature(); main == nil || main.Name != "00300" {
+		t.Fatalf("MainFeature() = %v, want 00300", main)
+	}
+	if ranks[0].Playlist.Name != "00300" {
+		t.Errorf("ranks[0] = %s, want 00300", ranks[0].Playlist.Name)
+	}
+	if byName["00400"].DuplicateOf != "00300" {
+		t.Errorf("00400 DuplicateOf = %q, want 00300", byName["00400"].DuplicateOf)
+	}
+	if !slices.Equal(byName["00200"].ReorderOf, []string{"00300"}) || !byName["00300"].Obfuscated() {
+		t.Errorf("00200 ReorderOf = %v, 00300 ReorderOf = %v", byName["00200"].ReorderOf, byName["00300"].ReorderOf)
+	}
+	if byName["00400"].Obfuscated() {
+		t.Errorf("a duplicate should not be listed as a reorder")
+	}
+	if !byName["00100"].Loop || byName["00500"].Loop {
+		t.Errorf("only 00100 should be flagged as a loop")
+	}
+	if byName["00100"].Score >= byName["00500"].Score {
+		t.Errorf("loop score %.2f should rank below the single trailer at %.2f", byName["00100"].Score, byName["00500"].Score)
+	}
+	if byName["00300"].Coverage != 100.0/102.0 {
+		t.Errorf("00300 Coverage = %v, want 100/102", byName["00300"].Coverage)
+	}
+}
+
+func TestRankPlaylistsOverlappingPlayItems(t *testing.T) {
+	const minute = 45000 * 60
+	disc := &Disc{
+		Clips: map[string]*Clip{
+			"00001": testClip("00001", 60*minute),
+			"00002": testClip("00002", 40*minute),
+		},
+		Playlists: map[string]*Playlist{},
+	}
+
+	// 00001 is played over 0-30, 20-40 and 35-50 minutes: 50 minutes, not 65.
+	// The 10-minute replay of 00002 adds nothing.
+	playlist := testPlaylist("00100", 0,
+		segment{"00001", 0, 30 * minute},
+		segment{"00002", 0, 40 * minute},
+		segment{"00001", 20 * minute, 40 * minute},
+		segment{"00002", 10 * minute, 20 * minute},
+		segment{"00001", 35 * minute, 50 * minute},
+	)
+	disc.Playlists[playlist.Name] = playlist
+
+	rank := disc.RankPlaylists()[0]
+	if want := 90.0 / 100.0; rank.Coverage != want {
+		t.Errorf("Coverage = %v, want %v", rank.Coverage, want)
+	}
+}
+
+func TestPlaylistChapters(t *testing.T) {
+	const second = 45000
+	playlist := testPlaylist("00800", 0,
+		segment{"00001", 100 * second, 160 * second},
+		segment{"00002", 10 * second, 40 * second},
+	)
+	playlist.Marks.Marks = []*mpls.MarkEntry{
+		{MarkType: 1, RefToPlayItemID: 1, MarkTimeStamp: 20 * second},
+		{MarkType: 1, RefToPlayItemID: 0, MarkTimeStamp: 100 * second},
+		{MarkType: 2, RefToPlayItemID: 0, MarkTimeStamp: 130 * second}, // link point, not a chapter
+		{MarkType: 1, RefToPlayItemID: 0, MarkTimeStamp: 90 * second},  // before IN, moved to IN
+		{MarkType: 1, RefToPlayItemID: 5, MarkTimeStamp: 0},            // no such PlayItem
+	}
+
+	// The mark moved to IN lands on the mark at 100 s and is dropped.
+	want := []Chapter{
+		{Number: 1, Start: 0, End: 70 * second},
+		{Number: 2, Start: 70 * second, End: 90 * second},
+	}
+	checkChapters(t, playlist.Chapters(), want)
+
+	// META/TN names the entry marks in file order, not in time order.
+	disc := &Disc{
+		Playlists: map[string]*Playlist{playlist.Name: playlist},
+		TrackNames: map[string]map[string]*meta.TrackNames{
+			"eng": {playlist.Name: {ChapterNames: []string{"Second", "First", "Dropped", "Missing"}}},
+		},
+	}
+	want[0].Name, want[1].Name = "First", "Second"
+	checkChapters(t, disc.Chapters(playlist.Name, "eng"), want)
+}
+
+func checkChapters(t *testing.T, got []*Chapter, want []Chapter) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d chapters, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("chapter %d = %+v, want %+v", i, *got[i], want[i])
+		}
+	}
+}
+
+func TestPlaylistUserOperations(t *testing.T) {
+	const second = 45000
+	playlist := testPlaylist("00001", 0,
+		segment{"00010", 0, 30 * second}, // The warning
+		segment{"00011", 0, 90 * second}, // The trailer
+	)
+	playlist.AppInfo = &mpls.AppInfo{UserOptions: &mpls.UserOptions{MenuCall: true}}
+	playlist.PlayList.PlayItems[0].UserOptions = &mpls.UserOptions{
+		ChapterSearch:   true,
+		TimeSearch:      true,
+		SkipToNextPoint: true,
+		ForwardPlay:     true,
+	}
+
+	spans := playlist.UserOperations()
+	if len(spans) != 2 || spans[1].Start != 30*second || spans[1].End != 120*second {
+		t.Fatalf("UserOperations() = %+v", spans)
+	}
+	if got := spans[0].Mask.Prohibited(); !slices.Equal(got, []string{"menu call", "chapter search", "time search", "skip to next chapter", "fast forward"}) {
+		t.Errorf("PlayItem 0 prohibits %q", got)
+	}
+	if !spans[0].Mask.BlocksSkipping() || spans[1].Mask.BlocksSkipping() {
+		t.Error("only the warning should block skipping")
+	}
+	if mask := playlist.UserOperationsAt(60 * second); mask == nil || !mask.MenuCall || mask.ForwardPlay {
+		t.Errorf("UserOperationsAt(60s) = %+v", mask)
+	}
+	if playlist.UserOperationsAt(120*second) != nil {
+		t.Error("UserOperationsAt(end) is not nil")
+	}
+}
+
+func TestOpenTrackNames(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "BDMV", "META", "TN")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tnmt := `<?xml version="1.0" encoding="UTF-8"?>
+<tn:tnmt xmlns:tn="urn:BDA:bdmv;tn">
+  <tn:chapters>
+    <tn:name>Opening</tn:name>
+    <tn:name>Fish &amp; Chips</tn:name>
+  </tn:chapters>
+</tn:tnmt>
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnmt_eng_00800.xml"), []byte(tnmt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	disc, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	trackNames := disc.TrackNames["eng"]["00800"]
+	if trackNames == nil || !slices.Equal(trackNames.ChapterNames, []string{"Opening", "Fish & Chips"}) {
+		t.Fatalf("TrackNames[eng][00800] = %+v", trackNames)
+	}
+
+	disc.Playlists["00800"] = testPlaylist("00800", 3, segment{"00001", 0, 45000})
+	chapters := disc.Chapters("00800", "eng")
+	if chapters[1].Name != "Fish & Chips" || chapters[2].Name != "" || chapters[2].ChapterName() != "Chapter 03" {
+		t.Errorf("chapter names = %q %q %q", chapters[0].Name, chapters[1].Name, chapters[2].Name)
+	}
+}
+
+func TestChapterExport(t *testing.T) {
+	chapters := []*Chapter{
+		{Number: 1, Start: 0, End: 45000 * 61, Name: "Intro"},
+		{Number: 2, Start: 45000*61 + 45, End: 45000 * 3600},
+	}
+
+	var ogm bytes.Buffer
+	if err := WriteOGMChapters(&ogm, chapters); err != nil {
+		t.Fatal(err)
+	}
+	wantOGM := "CHAPTER01=00:00:00.000\nCHAPTER01NAME=Intro\n" +
+		"CHAPTER02=00:01:01.001\nCHAPTER02NAME=Chapter 02\n"
+	if ogm.String() != wantOGM {
+		t.Errorf("OGM =\n%s\nwant\n%s", ogm.String(), wantOGM)
+	}
+
+	var ffmetadata bytes.Buffer
+	if err := WriteFFMetadata(&ffmetadata, chapters[:1], "A=B; C"); err != nil {
+		t.Fatal(err)
+	}
+	wantFFMetadata := ";FFMETADATA1\ntitle=A\\=B\\; C\n" +
+		"[CHAPTER]\nTIMEBASE=1/45000\nSTART=0\nEND=2745000\ntitle=Intro\n"
+	if ffmetadata.String() != wantFFMetadata {
+		t.Errorf("FFMETADATA =\n%s\nwant\n%s", ffmetadata.String(), wantFFMetadata)
+	}
+
+	var mkv bytes.Buffer
+	if err := WriteMatroskaChapters(&mkv, chapters[1:], ""); err != nil {
+		t.Fatal(err)
+	}
+	wantMKV := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE Chapters SYSTEM "matroskachapters.dtd">
+<Chapters>
+  <EditionEntry>
+    <EditionFlagDefault>1</EditionFlagDefault>
+    <ChapterAtom>
+      <ChapterTimeStart>00:01:01.001000000</ChapterTimeStart>
+      <ChapterTimeEnd>01:00:00.000000000</ChapterTimeEnd>
+      <ChapterDisplay>
+        <ChapterString>Chapter 02</ChapterString>
+        <ChapterLanguage>und</ChapterLanguage>
+      </ChapterDisplay>
+    </ChapterAtom>
+  </EditionEntry>
+</Chapters>
+`
+	if mkv.String() != wantMKV {
+		t.Errorf("Matroska XML =\n%s\nwant\n%s", mkv.String(), wantMKV)
+	}
+}
+
+// testPSIPacket wraps a long-form PSI section in one source packet.
+func testPSIPacket(pid uint16, tableID uint8, extension uint16, body []byte) []byte {
+	section := []byte{tableID, 0xB0, uint8(5 + len(body) + 4), uint8(extension >> 8), uint8(extension), 0xC1, 0, 0}
+	section = append(section, body...)
+	crc := uint32(0xFFFFFFFF)
+	for _, b := range section {
+		crc ^= uint32(b) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	section = append(section, uint8(crc>>24), uint8(crc>>16), uint8(crc>>8), uint8(crc))
+
+	packet := []byte{0, 0, 0, 0, 0x47, 0x40 | uint8(pid>>8), uint8(pid), 0x10, 0}
+	packet = append(packet, section...)
+	return append(packet, bytes.Repeat([]byte{0xFF}, 192-len(packet))...)
+}
+
+func TestVerifyStreams(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "STREAM"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stream := testPSIPacket(0x0000, 0x00, 1, []byte{0x00, 0x01, 0xE1, 0x00})
+	stream = append(stream, testPSIPacket(0x0100, 0x02, 1, []byte{
+		0xF0, 0x01, 0xF0, 0x00,
+		0x1B, 0xF0, 0x11, 0xF0, 0x00, // H.264 on 0x1011
+		0x81, 0xF1, 0x00, 0xF0, 0x00, // AC-3 on 0x1100
+		0x90, 0xF2, 0x00, 0xF0, 0x00, // PG on 0x1200, not in the clpi file
+	})...)
+	if err := os.WriteFile(filepath.Join(root, "STREAM", "00001.m2ts"), stream, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	clip := testClip("00001", 0)
+	clip.ProgramInfo = &clpi.ProgramInfo{Programs: []*clpi.Program{{ProgramStreams: []*clpi.ProgramStream{
+		{StreamPID: 0x1011, StreamCodingInfo: []clpi.StreamCodingInfo{&clpi.StreamCodingInfoH264{
+			BaseStreamCodingInfo: clpi.BaseStreamCodingInfo{StreamCodingType: clpi.STREAM_TYPE_VIDEO_H264}}}},
+		{StreamPID: 0x1100, StreamCodingInfo: []clpi.StreamCodingInfo{&clpi.StreamCodingInfoAudio{
+			BaseStreamCodingInfo: clpi.BaseStreamCodingInfo{StreamCodingType: clpi.STREAM_TYPE_AUDIO_DTS}}}},
+	}}}}
+	noStream := testClip("00002", 0)
+
+	audio := func(entry mpls.StreamEntry) *mpls.Stream {
+		return &mpls.Stream{Entry: entry, Attr: &mpls.PrimaryAudioAttributes{
+			BasicAttributes: mpls.BasicAttributes{StreamCodingType: mpls.STREAM_TYPE_AUDIO_AC3}}}
+	}
+	playlist := testPlaylist("00800", 0, segment{"00001", 0, 45000})
+	playlist.PlayItems = []*PlayItem{{
+		PlayItem: playlist.PlayList.PlayItems[0],
+		Clip:     clip,
+		Angles:   []*Clip{clip, noStream},
+	}}
+	playlist.PlayList.PlayItems[0].StreamTable = &mpls.StreamTable{Items: []*mpls.StreamItem{{
+		KindOf: mpls.STREAM_TYPE_PRIMARY_AUDIO,
+		Streams: []*mpls.Stream{
+			audio(&mpls.StreamEntryTypeI{RefToStreamPID: 0x1100}),
+			audio(&mpls.StreamEntryTypeI{RefToStreamPID: 0x1101}),
+			audio(&mpls.StreamEntryTypeII{RefToStreamPID: 0x1102}), // out-of-mux, not checked
+		},
+	}}}
+
+	disc := &Disc{
+		Root:      root,
+		Clips:     map[string]*Clip{"00001": clip, "00002": noStream},
+		Playlists: map[string]*Playlist{"00800": playlist},
+	}
+	var got []string
+	for _, problem := range disc.VerifyStreams() {
+		var streamErr *StreamError
+		var fileErr *FileError
+		switch {
+		case errors.As(problem, &streamErr):
+			got = append(got, streamErr.Source+" "+streamErr.Kind.String())
+		case errors.As(problem, &fileErr) && errors.Is(fileErr, os.ErrNotExist):
+			got = append(got, filepath.Base(fileErr.Path)+" unreadable")
+		default:
+			t.Errorf("unexpected problem %v", problem)
+		}
+	}
+	want := []string{
+		"CLIPINF/00001.clpi codec mismatch",
+		"CLIPINF/00001.clpi extra",
+		"00002.m2ts unreadable",
+		"PLAYLIST/00800.mpls PlayItem[0] missing",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("VerifyStreams() = %q, want %q", got, want)
+	}
+}
+
+func TestPlaylistStream(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "STREAM"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Every source packet is filled with a byte naming its clip and number.
+	writeStream := func(name string, fill byte, packets int) {
+		var data []byte
+		for i := range packets {
+			data = append(data, bytes.Repeat([]byte{fill + byte(i)}, 192)...)
+		}
+		if err := os.WriteFile(filepath.Join(root, "STREAM", name+".m2ts"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeStream("00001", 0x10, 10)
+	writeStream("00002", 0x20, 3)
+
+	// Entry points of clip 00001 at 0 s, 1 s and 2 s, on SPN 0, 4 and 8.
+	clip1 := testClip("00001", 0)
+	clip1.CPI = &clpi.CPI{StreamPIDEntries: []*clpi.StreamPIDEntry{{
+		StreamPID:     0x1011,
+		CourseEntries: []*clpi.CourseEntry{{}},
+		FineEntries: []*clpi.FineEntry{
+			{PTSEPFine: 0, SPNEPFine: 0},
+			{PTSEPFine: 90000 >> 9, SPNEPFine: 4},
+			{PTSEPFine: 180000 >> 9, SPNEPFine: 8},
+		},
+	}}}
+	clip2 := testClip("00002", 0)
+
+	// 1 s to 1.5 s of clip 00001, then the first second of clip 00002.
+	playlist := testPlaylist("00800", 0, segment{"00001", 45000, 67500}, segment{"00002", 0, 45000})
+	playlist.PlayItems = []*PlayItem{
+		{PlayItem: playlist.PlayList.PlayItems[0], Clip: clip1, Angles: []*Clip{clip1}},
+		{PlayItem: playlist.PlayList.PlayItems[1], Clip: clip2, Angles: []*Clip{clip2}},
+	}
+	disc := &Disc{
+		Root:      root,
+		Clips:     map[string]*Clip{"00001": clip1, "00002": clip2},
+		Playlists: map[string]*Playlist{"00800": playlist},
+	}
+
+	stream, err := disc.OpenPlaylistStream("00800", 0)
+	if err != nil {
+		t.Fatalf("OpenPlaylistStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	packets := func(data []byte) (fills []byte) {
+		for i := 0; i < len(data); i += 192 {
+			fills = append(fills, data[i])
+		}
+		return fills
+	}
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if got, want := packets(data), []byte{0x14, 0x15, 0x16, 0x17, 0x20, 0x21, 0x22}; !bytes.Equal(got, want) || stream.Size() != 7*192 {
+		t.Errorf("stream packets = %x, size %d, want %x", got, stream.Size(), want)
+	}
+
+	seeks := []struct {
+		time clock.Ticks45k
+		want int64
+	}{
+		{22500 - 1, 0},           // inside the first PlayItem, back to its entry point
+		{22500, 4 * 192},         // the start of the second PlayItem
+		{22500 + 45000, 7 * 192}, // the end of the playlist
+	}
+	for _, tt := range seeks {
+		if got, err := stream.SeekTime(tt.time); err != nil || got != tt.want {
+			t.Errorf("SeekTime(%d) = %d, %v, want %d", tt.time, got, err, tt.want)
+		}
+	}
+
+	if _, err := stream.Seek(3*192+100, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 192)
+	if n, err := io.ReadFull(stream, buf); err != nil || buf[91] != 0x17 || buf[92] != 0x20 {
+		t.Errorf("read across the segments = %d bytes %x..%x, %v", n, buf[91], buf[92], err)
+	}
+}
@@ -0,0 +1,100 @@
+package clock
+
+import (
+	"fmt"
+)
+
+// FrameRate is a video frame rate as the fraction Numerator/Denominator
+// frames per second, e.g. 24000/1001 for 23.976 Hz.
+type FrameRate struct {
+	Numerator   uint64
+	Denominator uint64
+}
+
+// The frame rates allowed for Blu-ray video.
+var (
+	FrameRate23976 = FrameRate{24000, 1001}
+	FrameRate24    = FrameRate{24, 1}
+	FrameRate25    = FrameRate{25, 1}
+	FrameRate2997  = FrameRate{30000, 1001}
+	FrameRate50    = FrameRate{50, 1}
+	FrameRate5994  = FrameRate{60000, 1001}
+)
+
+// VideoRate returns the frame rate for the video_rate code of an MPLS or
+// CLPI stream, or the zero FrameRate for an unknown code.
+func VideoRate(code uint8) FrameRate {
+	switch code {
+	case 1:
+		return FrameRate23976
+	case 2:
+		return FrameRate24
+	case 3:
+		return FrameRate25
+	case 4:
+		return FrameRate2997
+	case 6:
+		return FrameRate50
+	case 7:
+		return FrameRate5994
+	default:
+		return FrameRate{}
+	}
+}
+
+// Nominal returns the whole number of frames a timecode second holds,
+// e.g. 30 for 29.97 Hz.
+func (rate FrameRate) Nominal() uint64 {
+	if rate.Denominator == 0 {
+		return 0
+	}
+	return (rate.Numerator + rate.Denominator/2) / rate.Denominator
+}
+
+// DropFrame reports whether SMPTE timecode at this rate uses drop-frame
+// counting, which is the case for 29.97 Hz and 59.94 Hz.
+func (rate FrameRate) DropFrame() bool {
+	nominal := rate.Nominal()
+	return rate.Denominator == 1001 && (nominal == 30 || nominal == 60)
+}
+
+func (rate FrameRate) String() string {
+	if rate.Denominator == 1 {
+		return fmt.Sprintf("%d Hz", rate.Numerator)
+	}
+	return fmt.Sprintf("%.3f Hz", float64(rate.Numerator)/float64(rate.Denominator))
+}
+
+// frames returns the number of whole frames shown in ticks of a clock running at hz.
+func (rate FrameRate) frames(ticks, hz uint64) uint64 {
+	return ticks * rate.Numerator / (hz * rate.Denominator)
+}
+
+// timecode formats ticks of a clock running at hz as HH:MM:SS:FF, or
+// HH:MM:SS;FF with drop-frame counting. Drop-frame skips frame numbers
+// 0 and 1 (0 to 3 at 59.94 Hz) at the start of every minute except
+// each tenth, so the timecode keeps up with the wall clock.
+func (rate FrameRate) timecode(ticks, hz uint64) string {
+	nominal := rate.Nominal()
+	if nominal == 0 {
+		return ""
+	}
+	frame := rate.frames(ticks, hz)
+	separator := ":"
+
+	if rate.DropFrame() {
+		separator = ";"
+		drop := nominal / 15 // 2 at 29.97 Hz, 4 at 59.94 Hz
+		framesPerMinute := nominal*60 - drop
+		framesPer10Minutes := framesPerMinute*10 + drop
+		tens, rest := frame/framesPer10Minutes, frame%framesPer10Minutes
+		frame += 9 * drop * tens
+		if rest > drop {
+			frame += drop * ((rest - drop) / framesPerMinute)
+		}
+	}
+
+	ff := frame % nominal
+	seconds := frame / nominal
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", seconds/3600, seconds/60%60, seconds%60, separator, ff)
+}
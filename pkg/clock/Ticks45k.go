@@ -0,0 +1,59 @@
+package clock
+
+import (
+	"time"
+)
+
+// Ticks45k is a time on the 45 kHz clock used by mpls and clpi files,
+// e.g. PlayItem IN/OUT times, mark time stamps and STC sequence bounds.
+type Ticks45k uint32
+
+// FromDuration45k returns the 45 kHz time nearest to d.
+func FromDuration45k(d time.Duration) Ticks45k {
+	return Ticks45k(fromDuration(d, Rate45k))
+}
+
+// Duration returns t as a duration, exact to the nanosecond.
+func (t Ticks45k) Duration() time.Duration {
+	return duration(uint64(t), Rate45k)
+}
+
+// Seconds returns t in seconds.
+func (t Ticks45k) Seconds() float64 {
+	return float64(t) / Rate45k
+}
+
+// Ticks90k returns t on the 90 kHz clock.
+func (t Ticks45k) Ticks90k() Ticks90k {
+	return Ticks90k(uint64(t) * 2)
+}
+
+// Add returns t+u, wrapping at 2^32.
+func (t Ticks45k) Add(u Ticks45k) Ticks45k {
+	return t + u
+}
+
+// Sub returns t-u, wrapping at 2^32.
+func (t Ticks45k) Sub(u Ticks45k) Ticks45k {
+	return t - u
+}
+
+// Mul returns t*n, wrapping at 2^32.
+func (t Ticks45k) Mul(n uint32) Ticks45k {
+	return t * Ticks45k(n)
+}
+
+// Before reports whether t is earlier than u.
+func (t Ticks45k) Before(u Ticks45k) bool {
+	return t < u
+}
+
+// String returns t as HH:MM:SS.mmm.
+func (t Ticks45k) String() string {
+	return format(uint64(t), Rate45k)
+}
+
+// SMPTE returns t as a SMPTE timecode at the given frame rate.
+func (t Ticks45k) SMPTE(rate FrameRate) string {
+	return rate.timecode(uint64(t), Rate45k)
+}
@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"time"
+)
+
+// Ticks90k is a 33-bit PTS on the 90 kHz MPEG system clock,
+// as found in PES headers and the EP map.
+type Ticks90k uint64
+
+// PTSMask keeps the 33 bits of a PTS.
+const PTSMask Ticks90k = 1<<33 - 1
+
+// FromDuration90k returns the 90 kHz time nearest to d, wrapped at 2^33.
+func FromDuration90k(d time.Duration) Ticks90k {
+	return Ticks90k(fromDuration(d, Rate90k)) & PTSMask
+}
+
+// Duration returns t as a duration, exact to the nanosecond.
+func (t Ticks90k) Duration() time.Duration {
+	return duration(uint64(t&PTSMask), Rate90k)
+}
+
+// Seconds returns t in seconds.
+func (t Ticks90k) Seconds() float64 {
+	return float64(t&PTSMask) / Rate90k
+}
+
+// Ticks45k returns t on the 45 kHz clock, dropping the lowest bit
+// the way the navigation files do.
+func (t Ticks90k) Ticks45k() Ticks45k {
+	return Ticks45k((t & PTSMask) >> 1)
+}
+
+// Add returns t+u, wrapping at 2^33.
+func (t Ticks90k) Add(u Ticks90k) Ticks90k {
+	return (t + u) & PTSMask
+}
+
+// Sub returns t-u, wrapping at 2^33.
+func (t Ticks90k) Sub(u Ticks90k) Ticks90k {
+	return (t - u) & PTSMask
+}
+
+// Mul returns t*n, wrapping at 2^33.
+func (t Ticks90k) Mul(n uint64) Ticks90k {
+	return (t * Ticks90k(n)) & PTSMask
+}
+
+// Before reports whether t is earlier than u.
+func (t Ticks90k) Before(u Ticks90k) bool {
+	return t&PTSMask < u&PTSMask
+}
+
+// String returns t as HH:MM:SS.mmm.
+func (t Ticks90k) String() string {
+	return format(uint64(t&PTSMask), Rate90k)
+}
+
+// SMPTE returns t as a SMPTE timecode at the given frame rate.
+func (t Ticks90k) SMPTE(rate FrameRate) string {
+	return rate.timecode(uint64(t&PTSMask), Rate90k)
+}
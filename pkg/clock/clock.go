@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+	Remarks:
+
+	Blu-ray keeps time with the 90 kHz MPEG system clock. The navigation
+	files store most times at half that rate, 45 kHz, in 32 bits: IN/OUT
+	times, marks, STC sequence bounds. Transport streams and the EP map
+	carry the full 33-bit 90 kHz PTS.
+
+	Arithmetic wraps the same way the counters on disc do: Ticks45k at
+	2^32, Ticks90k at 2^33. A difference of two times is therefore
+	correct across a wrap as long as the true span is shorter than the
+	counter's range.
+*/
+
+const (
+	Rate45k = 45000 // Ticks45k per second
+	Rate90k = 90000 // Ticks90k per second
+)
+
+// duration converts ticks of a clock running at rate Hz to a duration,
+// truncated to the nanosecond. ticks*1e9 does not overflow for 33 bits.
+func duration(ticks uint64, rate uint64) time.Duration {
+	return time.Duration(ticks * uint64(time.Second) / rate)
+}
+
+// fromDuration converts a duration to ticks of a clock running at rate Hz,
+// rounded to the nearest tick. Negative durations give zero.
+func fromDuration(d time.Duration, rate uint64) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return (uint64(d)*rate + uint64(time.Second)/2) / uint64(time.Second)
+}
+
+// format writes ticks of a clock running at rate Hz as HH:MM:SS.mmm,
+// truncated to the millisecond. Hours are not wrapped at 24.
+func format(ticks uint64, rate uint64) string {
+	ms := ticks * 1000 / rate
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,106 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicks45kDuration(t *testing.T) {
+	tests := []struct {
+		ticks Ticks45k
+		want  time.Duration
+	}{
+		{0, 0},
+		{1, 22222 * time.Nanosecond},
+		{45000, time.Second},
+		{45000*3600 + 45, time.Hour + time.Millisecond},
+		{0xFFFFFFFF, 95443717666666 * time.Nanosecond},
+	}
+	for _, tt := range tests {
+		if got := tt.ticks.Duration(); got != tt.want {
+			t.Errorf("Ticks45k(%d).Duration() = %v, want %v", tt.ticks, got, tt.want)
+		}
+	}
+}
+
+func TestTicksString(t *testing.T) {
+	if got := Ticks45k(45000*(3600+23*60+4) + 45*567).String(); got != "01:23:04.567" {
+		t.Errorf("Ticks45k.String() = %q", got)
+	}
+	if got := Ticks90k(90000 * 25 * 3600).String(); got != "25:00:00.000" {
+		t.Errorf("Ticks90k.String() = %q, hours should not wrap", got)
+	}
+}
+
+func TestFromDuration(t *testing.T) {
+	if got := FromDuration45k(90 * time.Minute); got != 45000*90*60 {
+		t.Errorf("FromDuration45k(90m) = %d", got)
+	}
+	// 33333 ns is 1.49999 ticks at 45 kHz, rounded down.
+	if got := FromDuration45k(33333 * time.Nanosecond); got != 1 {
+		t.Errorf("FromDuration45k(33333ns) = %d, want 1", got)
+	}
+	if got := FromDuration45k(-time.Second); got != 0 {
+		t.Errorf("FromDuration45k(-1s) = %d, want 0", got)
+	}
+	if got := FromDuration90k(time.Second).Ticks45k(); got != 45000 {
+		t.Errorf("FromDuration90k(1s).Ticks45k() = %d", got)
+	}
+}
+
+func TestArithmeticWraps(t *testing.T) {
+	if got := Ticks45k(10).Sub(20); got != 0xFFFFFFF6 {
+		t.Errorf("Ticks45k(10).Sub(20) = %#x", uint32(got))
+	}
+	if got := Ticks45k(0xFFFFFFF6).Add(20).Sub(0xFFFFFFF6); got != 20 {
+		t.Errorf("span across the wrap = %d, want 20", got)
+	}
+	if got := PTSMask.Add(3); got != 2 {
+		t.Errorf("PTSMask.Add(3) = %d, want 2", got)
+	}
+	if got := Ticks90k(5).Sub(7); got != PTSMask-1 {
+		t.Errorf("Ticks90k(5).Sub(7) = %#x", uint64(got))
+	}
+	if got := Ticks45k(1000).Mul(3); got != 3000 {
+		t.Errorf("Ticks45k(1000).Mul(3) = %d", got)
+	}
+	if got := Ticks45k(0x80000000).Ticks90k(); got != 0x100000000 {
+		t.Errorf("Ticks45k.Ticks90k() = %#x", uint64(got))
+	}
+}
+
+func TestSMPTE(t *testing.T) {
+	tests := []struct {
+		name  string
+		ticks Ticks45k
+		rate  FrameRate
+		want  string
+	}{
+		{"24 Hz", 45000*61 + 45000/24*5, FrameRate24, "00:01:01:05"},
+		{"25 Hz", 45000*3600 + 45000/25*24, FrameRate25, "01:00:00:24"},
+		{"23.976 Hz runs slow", 45000 * 3600, FrameRate23976, "00:59:56:09"},
+		// 1800 and 17982 frames at 30000/1001 are 1501.5 ticks each.
+		{"29.97 Hz drops at the minute", 2702700, FrameRate2997, "00:01:00;02"},
+		{"29.97 Hz keeps the tenth minute", 26999973, FrameRate2997, "00:10:00;00"},
+		{"59.94 Hz drops four", 2702700, FrameRate5994, "00:01:00;04"},
+	}
+	for _, tt := range tests {
+		if got := tt.ticks.SMPTE(tt.rate); got != tt.want {
+			t.Errorf("%s: SMPTE() = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := tt.ticks.Ticks90k().SMPTE(tt.rate); got != tt.want {
+			t.Errorf("%s: Ticks90k SMPTE() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := Ticks45k(45000).SMPTE(FrameRate{}); got != "" {
+		t.Errorf("SMPTE() at a zero frame rate = %q, want empty", got)
+	}
+}
+
+func TestVideoRate(t *testing.T) {
+	for code, want := range map[uint8]FrameRate{1: FrameRate23976, 4: FrameRate2997, 7: FrameRate5994, 5: {}} {
+		if got := VideoRate(code); got != want {
+			t.Errorf("VideoRate(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 type CPI struct {
@@ -28,6 +32,8 @@ type CourseEntry struct {
 	RefToEPFineID uint32 // 18-bits 0b11111111_11111111_11000000_00000000
 	PTSEPCoarse   uint16 // 14-bits 0b00000000_00000000_00111111_11111111
 	SPNEPCoarse   uint32 // 32-bits
+
+	fineEntries []*FineEntry // The fine entries it covered as read; see refToEPFineIDs
 }
 
 type FineEntry struct {
@@ -84,7 +90,9 @@ func ReadCPI(file io.ReadSeeker, offsets *OffsetsUint32) (cpi *CPI, err error) {
 	// Capture StreamPID entries metadata here
 	cpi.StreamPIDEntries = make([]*StreamPIDEntry, cpi.NumberOfStreamPIDEntries)
 	for i := range cpi.StreamPIDEntries {
-		cpi.StreamPIDEntries[i], err = ReadStreamPIDEntry(file)
+		if cpi.StreamPIDEntries[i], err = ReadStreamPIDEntry(file); err != nil {
+			return nil, err
+		}
 	}
 
 	for i, streamPID := range cpi.StreamPIDEntries {
@@ -123,6 +131,8 @@ func ReadCPI(file io.ReadSeeker, offsets *OffsetsUint32) (cpi *CPI, err error) {
 				return nil, err
 			}
 		}
+
+		streamPID.linkCourseEntries()
 	}
 
 	return cpi, nil
@@ -151,7 +161,7 @@ func ReadStreamPIDEntry(file io.ReadSeeker) (entry *StreamPIDEntry, err error) {
 
 	// 0b00000011_11111111_11111100_00000000
 	//         ^^ ^^^^^^^^ ^^^^^^
-	entry.NumberOfEPCoarseEntries = uint16((buf32 & 0x03FFC000) >> 10)
+	entry.NumberOfEPCoarseEntries = uint16((buf32 & 0x03FFFC00) >> 10)
 
 	var buf8 uint8
 	if err := binary.Read(file, binary.BigEndian, &buf8); err != nil {
@@ -218,6 +228,227 @@ func ReadStreamPIDFineEntry(file io.ReadSeeker) (fe *FineEntry, err error) {
 	return fe, err
 }
 
+// PTS returns the part of the entry point PTS held by PTSEPCoarse, bits 32
+// to 19. Bit 19 is also the top bit of PTSEPFine, so it is taken from there.
+func (ce *CourseEntry) PTS() clock.Ticks90k {
+	return clock.Ticks90k(ce.PTSEPCoarse&^1) << 19
+}
+
+// PTS returns the part of the entry point PTS held by PTSEPFine, bits 19 to 9.
+func (fe *FineEntry) PTS() clock.Ticks90k {
+	return clock.Ticks90k(fe.PTSEPFine) << 9
+}
+
+// EntryPointPTS returns the PTS of the entry point described by a fine
+// entry and the coarse entry it belongs to. The low 9 bits are not stored,
+// so the result is at most 511 ticks (5.7 ms) before the real PTS.
+func EntryPointPTS(ce *CourseEntry, fe *FineEntry) clock.Ticks90k {
+	return ce.PTS() | fe.PTS()
+}
+
+// MarshalBinary encodes the CPI section, including its length field.
+// The EP maps are laid out back to back after the StreamPID entries, and
+// EPMapStreamStartAddr and EPFineTableStartAddress are recomputed to match.
+// The RefToEPFineID of the coarse entries follows the fine entries; see
+// refToEPFineIDs. A CPI without a Length or StreamPID entries is written as
+// a zero length, which is how real discs record a clip without an EP map.
+func (cpi *CPI) MarshalBinary() ([]byte, error) {
+	if cpi == nil || (cpi.Length == 0 && len(cpi.StreamPIDEntries) == 0) {
+		return make([]byte, 4), nil
+	}
+
+	if len(cpi.StreamPIDEntries) > 0xFF {
+		return nil, fmt.Errorf("too many StreamPIDEntries: %d", len(cpi.StreamPIDEntries))
+	}
+
+	entries := &bytes.Buffer{}
+	epMaps := &bytes.Buffer{}
+
+	// EP map addresses are relative to the byte after CPIType, which is
+	// followed by 1 reserved byte, the entry count and 12 bytes per entry.
+	epMapsStart := 2 + 12*len(cpi.StreamPIDEntries)
+
+	for i, entry := range cpi.StreamPIDEntries {
+		epMap, err := entry.marshalEPMap()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal EP map of StreamPIDEntry[%d]: %w", i, err)
+		}
+
+		data, err := entry.marshalBinary(uint32(epMapsStart + epMaps.Len()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal StreamPIDEntry[%d]: %w", i, err)
+		}
+
+		entries.Write(data)
+		epMaps.Write(epMap)
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	// 0b00001111
+	//       ^^^^
+	buf.WriteByte(cpi.CPIType & 0x0F)
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(uint8(len(cpi.StreamPIDEntries)))
+	buf.Write(entries.Bytes())
+	buf.Write(epMaps.Bytes())
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// linkCourseEntries records the fine entries each coarse entry covers.
+func (entry *StreamPIDEntry) linkCourseEntries() {
+	fineEntries := entry.FineEntries
+	if fineEntries == nil {
+		fineEntries = []*FineEntry{}
+	}
+	for i, ce := range entry.CourseEntries {
+		end := uint32(len(fineEntries))
+		if i+1 < len(entry.CourseEntries) {
+			end = min(end, entry.CourseEntries[i+1].RefToEPFineID)
+		}
+		start := min(ce.RefToEPFineID, end)
+		ce.fineEntries = fineEntries[start:end:end]
+	}
+}
+
+// refToEPFineIDs returns the RefToEPFineID of each coarse entry for the
+// fine entries as they are now. A coarse entry read from a file refers to
+// the first of the fine entries it covered that is still there, or, when
+// none is, to where the next coarse entry starts, so fine entries may be
+// removed or inserted. Any other coarse entry keeps its RefToEPFineID.
+func (entry *StreamPIDEntry) refToEPFineIDs() []uint32 {
+	index := make(map[*FineEntry]uint32, len(entry.FineEntries))
+	for j, fe := range entry.FineEntries {
+		if _, ok := index[fe]; !ok {
+			index[fe] = uint32(j)
+		}
+	}
+
+	refs := make([]uint32, len(entry.CourseEntries))
+	next := uint32(len(entry.FineEntries))
+	for i := len(entry.CourseEntries) - 1; i >= 0; i-- {
+		ce := entry.CourseEntries[i]
+		refs[i] = ce.RefToEPFineID
+		if ce.fineEntries != nil {
+			refs[i] = next
+			for _, fe := range ce.fineEntries {
+				if j, ok := index[fe]; ok {
+					refs[i] = j
+					break
+				}
+			}
+		}
+		next = refs[i]
+	}
+	return refs
+}
+
+// marshalBinary encodes the 12 byte StreamPID entry with the given EP map
+// address. The coarse and fine entry counts come from the entry slices.
+func (entry *StreamPIDEntry) marshalBinary(epMapStreamStartAddr uint32) ([]byte, error) {
+	if len(entry.CourseEntries) > 0xFFFF {
+		return nil, fmt.Errorf("too many CourseEntries: %d", len(entry.CourseEntries))
+	}
+	if len(entry.FineEntries) > 0x3FFFF {
+		return nil, fmt.Errorf("too many FineEntries: %d", len(entry.FineEntries))
+	}
+	numberOfFineEntries := uint32(len(entry.FineEntries))
+
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, entry.StreamPID)
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	// 0b00111111_11111111_11111111_11111111
+	//     ^^^^ EPStreamType
+	//         ^^ ^^^^^^^^ ^^^^^^ NumberOfEPCoarseEntries
+	//                           ^^ ^^^^^^^^ NumberOfEPFineEntries (high 10 bits)
+	binary.Write(buf, binary.BigEndian,
+		uint32(entry.EPStreamType&0x0F)<<26|
+			uint32(len(entry.CourseEntries))<<10|
+			numberOfFineEntries>>8)
+
+	// NumberOfEPFineEntries (low 8 bits)
+	buf.WriteByte(uint8(numberOfFineEntries))
+
+	binary.Write(buf, binary.BigEndian, epMapStreamStartAddr)
+
+	return buf.Bytes(), nil
+}
+
+// marshalEPMap encodes the EP map of one stream PID: the fine table
+// address, the coarse entries and then the fine entries.
+func (entry *StreamPIDEntry) marshalEPMap() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// The fine table directly follows the coarse entries.
+	binary.Write(buf, binary.BigEndian, uint32(4+8*len(entry.CourseEntries)))
+
+	refs := entry.refToEPFineIDs()
+	for i, ce := range entry.CourseEntries {
+		courseEntry := *ce
+		courseEntry.RefToEPFineID = refs[i]
+		data, err := courseEntry.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal CourseEntry[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	for i, fineEntry := range entry.FineEntries {
+		data, err := fineEntry.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal FineEntry[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary encodes the 8 byte coarse EP entry.
+func (ce *CourseEntry) MarshalBinary() ([]byte, error) {
+	if ce.RefToEPFineID > 0x3FFFF {
+		return nil, fmt.Errorf("RefToEPFineID %d does not fit in 18 bits", ce.RefToEPFineID)
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 0b11111111_11111111_11000000_00000000 RefToEPFineID
+	// 0b00000000_00000000_00111111_11111111 PTSEPCoarse
+	binary.Write(buf, binary.BigEndian, ce.RefToEPFineID<<14|uint32(ce.PTSEPCoarse&0x3FFF))
+
+	binary.Write(buf, binary.BigEndian, ce.SPNEPCoarse)
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary encodes the 4 byte fine EP entry.
+func (fe *FineEntry) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// 0b10000000_00000000_00000000_00000000 IsAngleChangePoint
+	// 0b01110000_00000000_00000000_00000000 IEndPositionOffset
+	// 0b00001111_11111110_00000000_00000000 PTSEPFine
+	// 0b00000000_00000001_11111111_11111111 SPNEPFine
+	binary.Write(buf, binary.BigEndian,
+		uint32(setFlag(fe.IsAngleChangePoint, 0x80))<<24|
+			uint32(fe.IEndPositionOffset&0x07)<<28|
+			uint32(fe.PTSEPFine&0x07FF)<<17|
+			fe.SPNEPFine&0x0001FFFF)
+
+	return buf.Bytes(), nil
+}
+
 func (cpi *CPI) String() string {
 	return fmt.Sprintf(
 		"CPI{"+
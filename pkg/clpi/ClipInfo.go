@@ -1,9 +1,12 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type ClipInfo struct {
@@ -100,6 +103,48 @@ func ReadClipInfo(file io.ReadSeeker, offsets *OffsetsUint32) (clipInfo *ClipInf
 	return clipInfo, nil
 }
 
+// MarshalBinary encodes the ClipInfo section, including its length field.
+// The following clip fields are only written when IsCC5 is set.
+func (clipInfo *ClipInfo) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// 2-byte reserve space
+	buf.Write(make([]byte, 2))
+
+	buf.WriteByte(clipInfo.ClipStreamType)
+	buf.WriteByte(byte(clipInfo.ApplicationType))
+
+	// 0b00000000_00000000_00000000_00000001
+	//                                     ^ IsCC5
+	binary.Write(buf, binary.BigEndian, uint32(setFlag(clipInfo.IsCC5, 0x01)))
+
+	binary.Write(buf, binary.BigEndian, clipInfo.TSRecordingRate)
+	binary.Write(buf, binary.BigEndian, clipInfo.NumberOfSourcePackets)
+
+	// 128-byte reserve space
+	buf.Write(make([]byte, 128))
+
+	buf.Write(clipInfo.TSTypeInfoBlock[:])
+
+	if clipInfo.IsCC5 {
+		// 1-byte reserve space
+		buf.WriteByte(0)
+
+		buf.WriteByte(clipInfo.FollowingClipStreamType)
+
+		// 4-byte reserve space
+		buf.Write(make([]byte, 4))
+
+		buf.Write(clipInfo.FollowingClipInformationFileName[:])
+		buf.Write(clipInfo.FollowingClipCodecIdentifier[:])
+
+		// 1-byte reserve space
+		buf.WriteByte(0)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (clipInfo *ClipInfo) String() string {
 	return fmt.Sprintf(
 		"ClipInfo{"+
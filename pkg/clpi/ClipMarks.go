@@ -1,9 +1,13 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 type ClipMarks struct {
@@ -16,9 +20,9 @@ type ClipMarkEntry struct {
 	_              uint8  // 8-bit reserved (usually 0x00)
 	MarkType       uint8  // 8-bit unsigned integer
 	MarkPID        uint16 // 16-bit unsigned integer
-	MarkTimeStamp  uint32 // 32-bit unsigned integer
+	MarkTimeStamp  clock.Ticks45k
 	MarkEntryPoint uint32 // 32-bit unsigned integer
-	MarkDuration   uint32 // 32-bit unsigned integer
+	MarkDuration   clock.Ticks45k
 }
 
 func ReadClipMarks(file io.ReadSeeker, offsets *OffsetsUint32) (clipMarks *ClipMarks, err error) {
@@ -53,7 +57,6 @@ func ReadClipMarks(file io.ReadSeeker, offsets *OffsetsUint32) (clipMarks *ClipM
 		if clipMarks.MarkEntries[i], err = ReadClipMarkEntry(file); err != nil {
 			return nil, err
 		}
-		fmt.Printf("DEBUG: [%d] MarkEntry: %+v\n", i, clipMarks.MarkEntries[i])
 	}
 
 	return clipMarks, nil
@@ -63,6 +66,11 @@ func ReadClipMarkEntry(file io.ReadSeeker) (clipMarkEntry *ClipMarkEntry, err er
 
 	clipMarkEntry = &ClipMarkEntry{}
 
+	// 1-byte reserve space
+	if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+
 	if err := binary.Read(file, binary.BigEndian, &clipMarkEntry.MarkType); err != nil {
 		return nil, err
 	}
@@ -86,6 +94,49 @@ func ReadClipMarkEntry(file io.ReadSeeker) (clipMarkEntry *ClipMarkEntry, err er
 	return clipMarkEntry, err
 }
 
+// MarshalBinary encodes the ClipMarks section, including its length field.
+// ClipMarks without a Length or entries are written as a zero length,
+// the form most discs use.
+func (clipMarks *ClipMarks) MarshalBinary() ([]byte, error) {
+	if clipMarks == nil || (clipMarks.Length == 0 && len(clipMarks.MarkEntries) == 0) {
+		return make([]byte, 4), nil
+	}
+
+	if len(clipMarks.MarkEntries) > 0xFFFF {
+		return nil, fmt.Errorf("too many MarkEntries: %d", len(clipMarks.MarkEntries))
+	}
+
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, uint16(len(clipMarks.MarkEntries)))
+
+	for i, markEntry := range clipMarks.MarkEntries {
+		data, err := markEntry.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MarkEntry[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// MarshalBinary encodes the 16 byte ClipMarkEntry.
+func (entry *ClipMarkEntry) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(entry.MarkType)
+	binary.Write(buf, binary.BigEndian, entry.MarkPID)
+	binary.Write(buf, binary.BigEndian, entry.MarkTimeStamp)
+	binary.Write(buf, binary.BigEndian, entry.MarkEntryPoint)
+	binary.Write(buf, binary.BigEndian, entry.MarkDuration)
+
+	return buf.Bytes(), nil
+}
+
 func (clipMarks *ClipMarks) String() string {
 
 	return fmt.Sprintf(
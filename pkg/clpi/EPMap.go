@@ -0,0 +1,123 @@
+package clpi
+
+import (
+	"sort"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+/*
+	Remarks:
+
+	The EP map stores each entry point split over two tables. A coarse
+	entry covers the fine entries from its RefToEPFineID up to the next
+	coarse entry's, and holds the high bits of their PTS and SPN:
+
+		PTS (33 bits) = PTSEPCoarse (bits 32..19) | PTSEPFine (bits 19..9)
+		SPN (32 bits) = SPNEPCoarse (bits 31..17) | SPNEPFine (bits 16..0)
+
+	Bit 19 of the PTS is in both fields and is taken from the fine entry.
+	The low 9 bits of the PTS are not stored.
+
+	An SPN counts 192-byte source packets from the start of the clip's
+	.m2ts file.
+*/
+
+// EntryPoint is one entry point of an EP map, with its PTS and SPN
+// rebuilt from a coarse and a fine entry.
+type EntryPoint struct {
+	PTS                clock.Ticks90k
+	SPN                uint32
+	IsAngleChangePoint bool
+	IEndPositionOffset uint8
+}
+
+// EntryPoints are the entry points of one stream PID, in EP map order.
+type EntryPoints []*EntryPoint
+
+// EntryPointSPN returns the SPN of the entry point described by a fine
+// entry and the coarse entry it belongs to.
+func EntryPointSPN(ce *CourseEntry, fe *FineEntry) uint32 {
+	return ce.SPNEPCoarse&^0x1FFFF | fe.SPNEPFine&0x1FFFF
+}
+
+// SPNToOffset returns the byte offset of a source packet in the .m2ts file.
+func SPNToOffset(spn uint32) int64 {
+	return int64(spn) * 192
+}
+
+// ByteOffset returns the byte offset of the entry point in the .m2ts file.
+func (ep *EntryPoint) ByteOffset() int64 {
+	return SPNToOffset(ep.SPN)
+}
+
+// EntryPoints returns every entry point of the EP map of the stream.
+// Fine entries outside the range of every coarse entry are skipped.
+func (entry *StreamPIDEntry) EntryPoints() EntryPoints {
+	points := make(EntryPoints, 0, len(entry.FineEntries))
+	for i, ce := range entry.CourseEntries {
+		end := uint32(len(entry.FineEntries))
+		if i+1 < len(entry.CourseEntries) {
+			end = min(end, entry.CourseEntries[i+1].RefToEPFineID)
+		}
+		for j := ce.RefToEPFineID; j < end; j++ {
+			fe := entry.FineEntries[j]
+			points = append(points, &EntryPoint{
+				PTS:                EntryPointPTS(ce, fe),
+				SPN:                EntryPointSPN(ce, fe),
+				IsAngleChangePoint: fe.IsAngleChangePoint,
+				IEndPositionOffset: fe.IEndPositionOffset,
+			})
+		}
+	}
+	return points
+}
+
+// StreamPIDEntry returns the EP map entry of the stream with the given
+// PID, or nil.
+func (cpi *CPI) StreamPIDEntry(pid uint16) *StreamPIDEntry {
+	if cpi == nil {
+		return nil
+	}
+	for _, entry := range cpi.StreamPIDEntries {
+		if entry.StreamPID == pid {
+			return entry
+		}
+	}
+	return nil
+}
+
+// EntryPoints returns the entry points of the stream with the given PID,
+// or nil when the EP map has none for it.
+func (cpi *CPI) EntryPoints(pid uint16) EntryPoints {
+	if entry := cpi.StreamPIDEntry(pid); entry != nil {
+		return entry.EntryPoints()
+	}
+	return nil
+}
+
+// At returns the last entry point at or before pts, which is where
+// decoding has to start to present pts. It returns nil when pts is before
+// the first entry point. The entry points must be in PTS order, as they
+// are within one STC sequence.
+func (points EntryPoints) At(pts clock.Ticks90k) *EntryPoint {
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].PTS > pts
+	})
+	if i == 0 {
+		return nil
+	}
+	return points[i-1]
+}
+
+// AngleChangePoints returns the entry points where a multi-angle clip
+// may switch to another angle.
+func (points EntryPoints) AngleChangePoints() EntryPoints {
+	angleChangePoints := EntryPoints{}
+	for _, point := range points {
+		if point.IsAngleChangePoint {
+			angleChangePoints = append(angleChangePoints, point)
+		}
+	}
+	return angleChangePoints
+}
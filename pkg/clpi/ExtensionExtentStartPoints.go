@@ -1,9 +1,12 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // ExtensionExtentStartPoints implements the ExtensionEntryData interface.
@@ -45,6 +48,18 @@ func (esp *ExtensionExtentStartPoints) Read(file io.ReadSeeker, offsets *Offsets
 	return nil
 }
 
+// MarshalBinary encodes the extent start points, including the length field.
+func (esp *ExtensionExtentStartPoints) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, uint32(len(esp.PointEntries)))
+	for _, pointEntry := range esp.PointEntries {
+		binary.Write(buf, binary.BigEndian, pointEntry.Point)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (esp *ExtensionExtentStartPoints) String() string {
 	return fmt.Sprintf(
 		"ExtensionExtentStartPoints{"+
@@ -35,6 +35,12 @@ func (dmc *ExtensionLPCMDownMixCoefficient) Read(file io.ReadSeeker, offsets *Of
 	return nil
 }
 
+// MarshalBinary is not supported yet: the layout of this extension is not
+// decoded (see Read), so there is nothing faithful to write back.
+func (dmc *ExtensionLPCMDownMixCoefficient) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("LPCM down mix coefficient extension is not supported")
+}
+
 func (dmc *ExtensionLPCMDownMixCoefficient) String() string {
 	return fmt.Sprintf(
 		"ExtensionLPCMDownMixCoefficient{"+
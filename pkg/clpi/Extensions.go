@@ -1,8 +1,12 @@
 package clpi
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // This how extensions are organized...
@@ -33,7 +37,9 @@ func ReadExtensions(file io.ReadSeeker, offsets *OffsetsUint32) (extensions *Ext
 	}
 
 	// 12-bytes total
-	extensions.MetaData, err = ReadMetaData(file)
+	if extensions.MetaData, err = ReadMetaData(file); err != nil {
+		return nil, fmt.Errorf("failed calling ReadMetaData(): %w", err)
+	}
 
 	// Sanity check
 	// These should sum together to equal the EOF.
@@ -53,6 +59,61 @@ func ReadExtensions(file io.ReadSeeker, offsets *OffsetsUint32) (extensions *Ext
 	return extensions, err
 }
 
+// MarshalBinary encodes the extensions block.
+// The entries are laid out back to back after the entries metadata, in
+// the order of EntriesData; the type and version of each entry are taken
+// from EntriesMetaData, while the start addresses and lengths are recomputed.
+func (extensions *Extensions) MarshalBinary() ([]byte, error) {
+	if len(extensions.EntriesData) != len(extensions.EntriesMetaData) {
+		return nil, fmt.Errorf("extensions have %d data entries but %d metadata entries",
+			len(extensions.EntriesData), len(extensions.EntriesMetaData))
+	}
+	if len(extensions.EntriesData) > 0xFF {
+		return nil, fmt.Errorf("too many extension entries: %d", len(extensions.EntriesData))
+	}
+
+	// 12-bytes metadata, plus 12-bytes for-each metadata entry
+	dataStart := 12 + 12*len(extensions.EntriesData)
+
+	entriesMetaData := &bytes.Buffer{}
+	entriesData := &bytes.Buffer{}
+	for i, entryData := range extensions.EntriesData {
+		if entryData == nil {
+			return nil, fmt.Errorf("extension entry %d has no data", i)
+		}
+
+		data, err := entryData.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ExtensionEntryData[%d]: %w", i, err)
+		}
+
+		entryMeta := ExtensionEntryMetaData{
+			ExtDataType:         extensions.EntriesMetaData[i].ExtDataType,
+			ExtDataVersion:      extensions.EntriesMetaData[i].ExtDataVersion,
+			ExtDataStartAddress: uint32(dataStart + entriesData.Len()),
+			ExtDataLength:       uint32(len(data)),
+		}
+		binary.Write(entriesMetaData, binary.BigEndian, entryMeta)
+		entriesData.Write(data)
+	}
+
+	buf := &bytes.Buffer{}
+	if len(extensions.EntriesData) > 0 {
+		binary.Write(buf, binary.BigEndian, uint32(dataStart))
+	} else {
+		binary.Write(buf, binary.BigEndian, uint32(0))
+	}
+
+	// 3-byte reserve space
+	buf.Write(make([]byte, 3))
+
+	buf.WriteByte(uint8(len(extensions.EntriesData)))
+	buf.Write(entriesMetaData.Bytes())
+	buf.Write(entriesData.Bytes())
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (e *Extensions) String() string {
 	return fmt.Sprintf(
 		"Extensions{"+
@@ -1,8 +1,10 @@
 package clpi
 
 import (
+	"fmt"
 	"io"
-	"log"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
 )
 
 // Because there are many different types of extension data...
@@ -11,6 +13,7 @@ import (
 // That way each extension can calculate boundaries.
 type ExtensionEntryData interface {
 	Read(io.ReadSeeker, *OffsetsUint32, *ExtensionEntryMetaData) error
+	MarshalBinary() ([]byte, error)
 }
 
 // ReadExtensionEntryData reads the extension entry data from the provided io.ReadSeeker.
@@ -42,26 +45,25 @@ func ReadExtensionEntryData(file io.ReadSeeker, offsets *OffsetsUint32, metaData
 		case // CPI SS
 			entryMeta.ExtDataType == 2 && entryMeta.ExtDataVersion == 6:
 			entriesData[i] = &ExtensionCPISS{}
-		}
 
-		// Skip any unimplemented extensions.
-		if entriesData[i] != nil {
+		case // AVCHD maker's private data
+			entryMeta.ExtDataType == avchd.EXT_DATA_TYPE_MAKERS_PRIVATE_DATA:
+			entriesData[i] = &ExtensionMakersPrivateData{}
+		}
 
-			if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
-				// XXX - if the extension fails, it's not fatal.
-				// xxx - because some extensions might have errors (MVC mostly)
-				//return nil, fmt.Errorf("failed to read ExtensionEntryData: %w", err)
-				//fmt.Printf("\n\n WARNING! EXTENSION ERROR WHILE PARSING:\t[%+v:%+v]\n\n", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
+		// Keep unimplemented extensions as opaque bytes.
+		if entriesData[i] == nil {
+			entriesData[i] = &ExtensionRaw{}
+		}
 
-				// XXX - temporary fatal error to help debugging.
-				// This should be removed when all extensions are implemented.
-				log.Fatalf("failed to read ExtensionEntryData: %v\n", err)
+		if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
+			return nil, &ParseError{
+				Offset: offsets.Start + int64(entryMeta.ExtDataStartAddress),
+				Path:   fmt.Sprintf("Extensions.EntriesData[%d]", i),
+				Err: fmt.Errorf("%w: type %d version %d: %w",
+					ErrExtension, entryMeta.ExtDataType, entryMeta.ExtDataVersion, err),
 			}
-
-		} else {
-			log.Fatal("Extension not implemented: ", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
 		}
-
 	}
 
 	return entriesData, nil
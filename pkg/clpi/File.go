@@ -0,0 +1,68 @@
+package clpi
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed .clpi clip information file: the sections
+// ParseCLPI returns one by one.
+type File struct {
+	Header       *CLPIHeader
+	ClipInfo     *ClipInfo
+	SequenceInfo *SequenceInfo
+	ProgramInfo  *ProgramInfo
+	CPI          *CPI
+	ClipMarks    *ClipMarks
+	Extensions   *Extensions
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *CLPIHeader,
+	clipInfo *ClipInfo,
+	sequenceInfo *SequenceInfo,
+	programInfo *ProgramInfo,
+	cpi *CPI,
+	clipMarks *ClipMarks,
+	extensions *Extensions,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensions}, nil
+}
+
+// ParseFile parses the .clpi clip information file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseCLPI(filePath))
+}
+
+// ParseFS parses the .clpi clip information file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseCLPIFS(fsys, name))
+}
+
+// ParseReader parses a .clpi clip information file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseCLPI(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as a .clpi clip information file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses a .clpi clip information file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
+
+// Write serializes the file to w with WriteCLPI.
+func (file *File) Write(w io.Writer) error {
+	return WriteCLPI(w, file.Header, file.ClipInfo, file.SequenceInfo, file.ProgramInfo, file.CPI, file.ClipMarks, file.Extensions)
+}
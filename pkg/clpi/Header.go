@@ -1,9 +1,12 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // CLPIHeader represents the 40 byte header of a CLPI file
@@ -16,6 +19,8 @@ type CLPIHeader struct {
 	CPI           *OffsetsUint32
 	ClipMarks     *OffsetsUint32
 	Extensions    *OffsetsUint32
+
+	sections []*navfile.Section // The header and each section as read; see WriteCLPI
 }
 
 // OffsetsUint32 represents the start and stop offsets of a section in the CLPI file.
@@ -94,6 +99,26 @@ func ReadCLPIHeader(file io.ReadSeeker) (header *CLPIHeader, err error) {
 	return header, nil
 }
 
+// MarshalBinary encodes the 40 byte header.
+// The section start addresses are taken from the SequenceInfo, ProgramInfo,
+// CPI, ClipMarks and Extensions offsets; WriteCLPI sets them before calling this.
+func (header *CLPIHeader) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, header.TypeIndicator)
+	binary.Write(buf, binary.BigEndian, header.VersionNumber)
+	binary.Write(buf, binary.BigEndian, uint32(header.SequenceInfo.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.ProgramInfo.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.CPI.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.ClipMarks.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.Extensions.Start))
+
+	// 12-byte reserve space
+	buf.Write(make([]byte, 12))
+
+	return buf.Bytes(), nil
+}
+
 func (offsets *OffsetsUint32) String() string {
 	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
 }
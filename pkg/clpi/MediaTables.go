@@ -1,6 +1,10 @@
 package clpi
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
 
 // CharacterCodeType is the text stream character (symbol/glyph) encoding standard.
 type CharacterCodeType uint8
@@ -177,6 +181,12 @@ func VideoRate(code VideoRateType) string {
 	}
 }
 
+// VideoFrameRate returns the frame rate for a video rate code,
+// or the zero FrameRate for an unknown code.
+func VideoFrameRate(code VideoRateType) clock.FrameRate {
+	return clock.VideoRate(uint8(code))
+}
+
 // AudioFormatType defines the audio codec format.
 type AudioFormatType uint8
 
@@ -1,9 +1,12 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type ProgramInfo struct {
@@ -43,6 +46,30 @@ func ReadProgramInfo(file io.ReadSeeker, offsets *OffsetsUint32) (programInfo *P
 	return programInfo, nil
 }
 
+// MarshalBinary encodes the ProgramInfo section, including its length field.
+func (pi *ProgramInfo) MarshalBinary() ([]byte, error) {
+	if len(pi.Programs) > 0xFF {
+		return nil, fmt.Errorf("too many Programs: %d", len(pi.Programs))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(uint8(len(pi.Programs)))
+
+	for i, program := range pi.Programs {
+		data, err := program.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal Program[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (pi *ProgramInfo) String() string {
 	return fmt.Sprintf(
 		"ProgramInfo{"+
@@ -93,6 +120,32 @@ func ReadProgram(file io.ReadSeeker) (p *Program, err error) {
 	return p, nil
 }
 
+// MarshalBinary encodes the Program and its ProgramStreams.
+func (p *Program) MarshalBinary() ([]byte, error) {
+	if len(p.ProgramStreams) > 0xFF {
+		return nil, fmt.Errorf("too many ProgramStreams: %d", len(p.ProgramStreams))
+	}
+
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, p.SPNProgramSequenceStart)
+	binary.Write(buf, binary.BigEndian, p.ProgramMapPID)
+	buf.WriteByte(uint8(len(p.ProgramStreams)))
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	for i, programStream := range p.ProgramStreams {
+		data, err := programStream.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ProgramStream[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (p *Program) String() string {
 	return fmt.Sprintf(
 		"Program{"+
@@ -121,8 +174,28 @@ func (ps *ProgramStream) String() string {
 	)
 }
 
+// MarshalBinary encodes the StreamPID and its StreamCodingInfo block.
+// The on-disk layout has room for exactly one StreamCodingInfo.
+func (ps *ProgramStream) MarshalBinary() ([]byte, error) {
+	if len(ps.StreamCodingInfo) != 1 {
+		return nil, fmt.Errorf("expected 1 StreamCodingInfo, got %d", len(ps.StreamCodingInfo))
+	}
+
+	data, err := ps.StreamCodingInfo[0].MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamCodingInfo: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, ps.StreamPID)
+	buf.Write(data)
+
+	return buf.Bytes(), nil
+}
+
 type StreamCodingInfo interface {
 	Read(io.ReadSeeker) error
+	MarshalBinary() ([]byte, error)
 	SetLength(uint8)
 	SetStreamCodingType(StreamCodingType)
 	SetISRCode([12]byte)
@@ -186,6 +259,25 @@ func (base *BaseStreamCodingInfo) SetStreamCodingType(code StreamCodingType) {
 }
 func (base *BaseStreamCodingInfo) SetISRCode(code [12]byte) { base.ISRCode = code }
 
+// marshalBinary builds a length-prefixed StreamCodingInfo block from the
+// coding type, the type specific fields and the ISRC.
+// Discs pad these blocks to a fixed size, so a Length larger than the
+// content is kept and the difference is written as zeros.
+func (base *BaseStreamCodingInfo) marshalBinary(fields []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte(byte(base.StreamCodingType))
+	buf.Write(fields)
+	buf.Write(base.ISRCode[:])
+
+	// tail padding
+	if padding := int(base.Length) - buf.Len(); padding > 0 {
+		buf.Write(make([]byte, padding))
+	}
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
 type StreamCodingInfoH264 struct {
 	BaseStreamCodingInfo
 	VideoFormat      VideoFormatType      // 4-bits 0b11110000
@@ -372,6 +464,41 @@ func (s *StreamCodingTypeText) Read(file io.ReadSeeker) error {
 	return nil
 }
 
+func (s *StreamCodingInfoH264) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary([]byte{
+		byte(s.VideoFormat)<<4 | byte(s.FrameRate)&0x0F,
+		byte(s.VideoAspectRatio)<<4 | setFlag(s.OCFlag, 0x02),
+	})
+}
+
+func (s *StreamCodingInfoH265) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary([]byte{
+		byte(s.VideoFormat)<<4 | byte(s.FrameRate)&0x0F,
+		byte(s.VideoAspectRatio)<<4 | setFlag(s.OCFlag, 0x02) | setFlag(s.CRFlag, 0x01),
+		s.DynamicRangeType<<4 | s.ColorSpace&0x0F,
+		setFlag(s.HDRPlusFlag, 0x80),
+	})
+}
+
+func (s *StreamCodingInfoAudio) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary(append(
+		[]byte{byte(s.AudioFormat)<<4 | byte(s.SampleRate)&0x0F},
+		s.LanguageCode[:]...,
+	))
+}
+
+func (s *StreamCodingTypePG) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary(s.LanguageCode[:])
+}
+
+func (s *StreamCodingTypeIG) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary(s.LanguageCode[:])
+}
+
+func (s *StreamCodingTypeText) MarshalBinary() ([]byte, error) {
+	return s.marshalBinary(append([]byte{s.CharacterCode}, s.LanguageCode[:]...))
+}
+
 func (sci *StreamCodingInfoH264) String() string {
 	return fmt.Sprintf(
 		"StreamCodingInfoH264{"+
@@ -1,9 +1,13 @@
 package clpi
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 type SequenceInfo struct {
@@ -22,8 +26,8 @@ type ATCSequence struct {
 type STCSequence struct {
 	PCRPID                uint16
 	SPNSTCStart           uint32
-	PresentationStartTime uint32
-	PresentationEndTime   uint32
+	PresentationStartTime clock.Ticks45k
+	PresentationEndTime   clock.Ticks45k
 }
 
 func ReadSequenceInfo(file io.ReadSeeker, offsets *OffsetsUint32) (sequenceInfo *SequenceInfo, err error) {
@@ -74,7 +78,9 @@ func ReadATCSequence(file io.ReadSeeker) (atcSequence *ATCSequence, err error) {
 
 	atcSequence.STCSequences = make([]*STCSequence, atcSequence.NumberOfSTCSequences)
 	for i := range atcSequence.STCSequences {
-		atcSequence.STCSequences[i], err = ReadSTCSequences(file)
+		if atcSequence.STCSequences[i], err = ReadSTCSequences(file); err != nil {
+			return nil, err
+		}
 	}
 
 	return atcSequence, nil
@@ -101,6 +107,65 @@ func ReadSTCSequences(file io.ReadSeeker) (stcSequence *STCSequence, err error)
 	return stcSequence, nil
 }
 
+// MarshalBinary encodes the SequenceInfo section, including its length field.
+func (sequenceInfo *SequenceInfo) MarshalBinary() ([]byte, error) {
+	if len(sequenceInfo.ATCSequences) > 0xFF {
+		return nil, fmt.Errorf("too many ATCSequences: %d", len(sequenceInfo.ATCSequences))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(uint8(len(sequenceInfo.ATCSequences)))
+
+	for i, atcSequence := range sequenceInfo.ATCSequences {
+		data, err := atcSequence.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ATCSequence[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// MarshalBinary encodes the ATCSequence and its STCSequences.
+func (atcSequence *ATCSequence) MarshalBinary() ([]byte, error) {
+	if len(atcSequence.STCSequences) > 0xFF {
+		return nil, fmt.Errorf("too many STCSequences: %d", len(atcSequence.STCSequences))
+	}
+
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, atcSequence.SPNATCStart)
+	buf.WriteByte(uint8(len(atcSequence.STCSequences)))
+	buf.WriteByte(atcSequence.OffsetSTCID)
+
+	for i, stcSequence := range atcSequence.STCSequences {
+		data, err := stcSequence.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal STCSequence[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary encodes the 14 byte STCSequence.
+func (stcSequence *STCSequence) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, stcSequence.PCRPID)
+	binary.Write(buf, binary.BigEndian, stcSequence.SPNSTCStart)
+	binary.Write(buf, binary.BigEndian, stcSequence.PresentationStartTime)
+	binary.Write(buf, binary.BigEndian, stcSequence.PresentationEndTime)
+
+	return buf.Bytes(), nil
+}
+
 func (sequenceInfo *SequenceInfo) String() string {
 	return fmt.Sprintf(
 		"SequenceInfo{"+
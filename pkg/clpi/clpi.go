@@ -1,9 +1,14 @@
 package clpi
 
 import (
+	"encoding"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 func CalculateEndOffset[U uint8 | uint16 | uint32](file io.ReadSeeker, length U) (int64, error) {
@@ -14,6 +19,13 @@ func CalculateEndOffset[U uint8 | uint16 | uint32](file io.ReadSeeker, length U)
 	return currentPos + int64(length), nil
 }
 
+func setFlag(flag bool, mask uint8) uint8 {
+	if flag {
+		return mask
+	}
+	return 0
+}
+
 func ParseCLPI(filePath string) (
 	header *CLPIHeader,
 	clipInfo *ClipInfo,
@@ -24,49 +36,213 @@ func ParseCLPI(filePath string) (
 	extensiondata *Extensions,
 	err error,
 ) {
-
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	return parseCLPI(file, filePath)
+}
+
+// ParseCLPIFS is ParseCLPI for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseCLPIFS(fsys fs.FS, name string) (
+	header *CLPIHeader,
+	clipInfo *ClipInfo,
+	sequenceInfo *SequenceInfo,
+	programInfo *ProgramInfo,
+	cpi *CPI,
+	clipMarks *ClipMarks,
+	extensiondata *Extensions,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseCLPI(file, name)
+}
+
+func parseCLPI(file io.ReadSeeker, filePath string) (
+	header *CLPIHeader,
+	clipInfo *ClipInfo,
+	sequenceInfo *SequenceInfo,
+	programInfo *ProgramInfo,
+	cpi *CPI,
+	clipMarks *ClipMarks,
+	extensiondata *Extensions,
+	err error,
+) {
 	// Header
 	if header, err = ReadCLPIHeader(file); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
 	}
 
 	// ClipInfo
 	if clipInfo, err = ReadClipInfo(file, header.ClipInfo); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read clipinfo: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "ClipInfo", header.ClipInfo.Start, err)
 	}
 
 	// SequenceInfo
 	if sequenceInfo, err = ReadSequenceInfo(file, header.SequenceInfo); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read sequence info: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "SequenceInfo", header.SequenceInfo.Start, err)
 	}
 
 	// ProgramInfo
 	if programInfo, err = ReadProgramInfo(file, header.ProgramInfo); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read program info: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "ProgramInfo", header.ProgramInfo.Start, err)
 	}
 
 	// CPI
 	if cpi, err = ReadCPI(file, header.CPI); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read CPI: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "CPI", header.CPI.Start, err)
 	}
 
 	// Clip Marks
 	if clipMarks, err = ReadClipMarks(file, header.ClipMarks); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read ClipMarks: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "ClipMarks", header.ClipMarks.Start, err)
 	}
 
 	// Extensions
 	if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
 		if extensiondata, err = ReadExtensions(file, header.Extensions); err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read Extension Data: %w", err)
+			return nil, nil, nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Extensions", header.Extensions.Start, err)
 		}
 	}
 
+	if header.sections, err = readSections(file, header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to read sections: %w", err)
+	}
+
 	return header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata, nil
 }
+
+// readSections keeps the header and every section as read, for WriteCLPI
+// to write the unchanged ones back as they were.
+func readSections(
+	file io.ReadSeeker,
+	header *CLPIHeader,
+	clipInfo *ClipInfo,
+	sequenceInfo *SequenceInfo,
+	programInfo *ProgramInfo,
+	cpi *CPI,
+	clipMarks *ClipMarks,
+	extensiondata *Extensions,
+) (sections []*navfile.Section, err error) {
+	structures := []encoding.BinaryMarshaler{header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks}
+	offsets := []*OffsetsUint32{
+		{Start: 0, Stop: 40},
+		header.ClipInfo,
+		header.SequenceInfo,
+		header.ProgramInfo,
+		header.CPI,
+		header.ClipMarks,
+	}
+	if extensiondata != nil {
+		structures = append(structures, extensiondata)
+		offsets = append(offsets, header.Extensions)
+	}
+
+	sections = make([]*navfile.Section, len(structures))
+	for i, structure := range structures {
+		encoded, err := structure.MarshalBinary()
+		if err != nil {
+			encoded = nil
+		}
+		if sections[i], err = navfile.ReadSection(file, offsets[i].Start, offsets[i].Stop, encoded); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// WriteCLPI serializes the clip information structures to file in the CLPI
+// layout. A section left as ParseCLPI read it is written back byte for
+// byte, with the padding that followed it, and at its original address
+// when the sections before it leave that free, so an unedited file comes
+// out identical. An edited section is re-encoded: every length, count and
+// offset field is recomputed from the data, including the EP-map addresses
+// of the CPI, so the structures may be edited before writing, and reserved
+// bits and padding are written as zeros, except inside StreamCodingInfo
+// blocks which keep their recorded length. The header offsets are updated
+// to describe the written file.
+func WriteCLPI(
+	file io.Writer,
+	header *CLPIHeader,
+	clipInfo *ClipInfo,
+	sequenceInfo *SequenceInfo,
+	programInfo *ProgramInfo,
+	cpi *CPI,
+	clipMarks *ClipMarks,
+	extensiondata *Extensions,
+) error {
+	clipInfoBytes, err := clipInfo.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal clipinfo: %w", err)
+	}
+
+	sequenceInfoBytes, err := sequenceInfo.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal sequence info: %w", err)
+	}
+
+	programInfoBytes, err := programInfo.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal program info: %w", err)
+	}
+
+	cpiBytes, err := cpi.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal CPI: %w", err)
+	}
+
+	clipMarksBytes, err := clipMarks.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal ClipMarks: %w", err)
+	}
+
+	var extensionBytes []byte
+	if extensiondata != nil {
+		if extensionBytes, err = extensiondata.MarshalBinary(); err != nil {
+			return fmt.Errorf("failed to marshal Extension Data: %w", err)
+		}
+	}
+
+	// Lay the sections out after the 40-byte header.
+	section := func(i int) *navfile.Section {
+		if i < len(header.sections) {
+			return header.sections[i]
+		}
+		return nil
+	}
+	layout := &navfile.Layout{Base: 40}
+	header.ClipInfo = newOffsets(layout.Add(section(1), clipInfoBytes))
+	header.SequenceInfo = newOffsets(layout.Add(section(2), sequenceInfoBytes))
+	header.ProgramInfo = newOffsets(layout.Add(section(3), programInfoBytes))
+	header.CPI = newOffsets(layout.Add(section(4), cpiBytes))
+	header.ClipMarks = newOffsets(layout.Add(section(5), clipMarksBytes))
+	header.Extensions = &OffsetsUint32{Start: 0, Stop: 0}
+	if len(extensionBytes) > 0 {
+		header.Extensions = newOffsets(layout.Add(section(6), extensionBytes))
+	}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	for _, data := range [][]byte{section(0).Bytes(headerBytes), layout.Bytes()} {
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newOffsets(start, stop int64) *OffsetsUint32 {
+	return &OffsetsUint32{Start: start, Stop: stop}
+}
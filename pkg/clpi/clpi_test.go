@@ -0,0 +1,269 @@
+package clpi
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+func TestWriteCLPIRoundTrip(t *testing.T) {
+	// testdata/00001.clpi is hand-built: one ATC/STC sequence, a program
+	// with padded video, audio and PG coding info blocks, a CPI with coarse
+	// and fine EP entries, empty ClipMarks and an extent start points
+	// extension. 00002.clpi is the same clip with zero fill after some of
+	// the sections, as authoring tools leave. Both write back as read.
+	for _, filePath := range []string{"testdata/00001.clpi", "testdata/00002.clpi"} {
+		want, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := writeCLPI(t, filePath); !bytes.Equal(got, want) {
+			for i := range min(len(got), len(want)) {
+				if got[i] != want[i] {
+					t.Fatalf("WriteCLPI(%s) output differs at byte %d (got %d bytes, want %d)", filePath, i, len(got), len(want))
+				}
+			}
+			t.Fatalf("WriteCLPI(%s) output is %d bytes, want %d", filePath, len(got), len(want))
+		}
+	}
+}
+
+// writeCLPI parses filePath and writes it back.
+func writeCLPI(t *testing.T, filePath string) []byte {
+	t.Helper()
+	header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata, err := ParseCLPI(filePath)
+	if err != nil {
+		t.Fatalf("ParseCLPI(%s) error = %v", filePath, err)
+	}
+	got := &bytes.Buffer{}
+	if err := WriteCLPI(got, header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata); err != nil {
+		t.Fatalf("WriteCLPI(%s) error = %v", filePath, err)
+	}
+	return got.Bytes()
+}
+
+// TestWriteCLPIDiscClips round-trips the clips of a real disc, which
+// cannot be shipped here. Point CLPI_TESTDATA at a CLIPINF directory to
+// run it. Each clip must write back as read.
+func TestWriteCLPIDiscClips(t *testing.T) {
+	dir := os.Getenv("CLPI_TESTDATA")
+	if dir == "" {
+		t.Skip("CLPI_TESTDATA is not set")
+	}
+	filePaths, err := filepath.Glob(filepath.Join(dir, "*.clpi"))
+	if err != nil || len(filePaths) == 0 {
+		t.Fatalf("no clips in %s: %v", dir, err)
+	}
+	for _, filePath := range filePaths {
+		want, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := writeCLPI(t, filePath); !bytes.Equal(got, want) {
+			t.Errorf("WriteCLPI(%s) does not reproduce the file", filePath)
+		}
+	}
+}
+
+func TestWriteCLPIKeepsUnchangedSections(t *testing.T) {
+	data, err := os.ReadFile("testdata/00002.clpi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata, err := ParseCLPI("testdata/00002.clpi")
+	if err != nil {
+		t.Fatalf("ParseCLPI() error = %v", err)
+	}
+	cpiStart, clipMarksStart := header.CPI.Start, header.ClipMarks.Start
+
+	// Drop the first fine entry: the second coarse entry now starts at 1.
+	entry := cpi.StreamPIDEntries[0]
+	entry.FineEntries = entry.FineEntries[1:]
+
+	buf := &bytes.Buffer{}
+	if err := WriteCLPI(buf, header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata); err != nil {
+		t.Fatalf("WriteCLPI() error = %v", err)
+	}
+	got := buf.Bytes()
+
+	if header.CPI.Start != cpiStart || header.ClipMarks.Start != clipMarksStart {
+		t.Errorf("CPI and ClipMarks start at %d and %d, want %d and %d",
+			header.CPI.Start, header.ClipMarks.Start, cpiStart, clipMarksStart)
+	}
+	if !bytes.Equal(got[40:cpiStart], data[40:cpiStart]) || !bytes.Equal(got[clipMarksStart:], data[clipMarksStart:]) {
+		t.Errorf("WriteCLPI() changed the sections around the CPI")
+	}
+
+	parsed, err := ParseBytes(got)
+	if err != nil {
+		t.Fatalf("ParseBytes() of the edited file error = %v", err)
+	}
+	gotEntry := parsed.CPI.StreamPIDEntries[0]
+	if ref := gotEntry.CourseEntries[1].RefToEPFineID; ref != 1 {
+		t.Errorf("CourseEntries[1].RefToEPFineID = %d, want 1", ref)
+	}
+	if points := gotEntry.EntryPoints(); len(points) != 2 || points[1].SPN != 1000 {
+		t.Errorf("EntryPoints() = %v, want the last two", points)
+	}
+}
+
+func TestWriteCLPIRecomputesEPMap(t *testing.T) {
+	header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata, err := ParseCLPI("testdata/00001.clpi")
+	if err != nil {
+		t.Fatalf("ParseCLPI() error = %v", err)
+	}
+
+	// Cut the clip after the first coarse entry and add a clip mark.
+	entry := cpi.StreamPIDEntries[0]
+	entry.CourseEntries = entry.CourseEntries[:1]
+	entry.FineEntries = entry.FineEntries[:2]
+	clipMarks.MarkEntries = append(clipMarks.MarkEntries, &ClipMarkEntry{
+		MarkType:      1,
+		MarkPID:       0x1011,
+		MarkTimeStamp: 90000,
+	})
+
+	out, err := os.CreateTemp(t.TempDir(), "*.clpi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	if err := WriteCLPI(out, header, clipInfo, sequenceInfo, programInfo, cpi, clipMarks, extensiondata); err != nil {
+		t.Fatalf("WriteCLPI() error = %v", err)
+	}
+
+	_, _, _, _, gotCPI, gotMarks, gotExtensions, err := ParseCLPI(out.Name())
+	if err != nil {
+		t.Fatalf("ParseCLPI() of the edited file error = %v", err)
+	}
+
+	gotEntry := gotCPI.StreamPIDEntries[0]
+	if gotEntry.NumberOfEPCoarseEntries != 1 || gotEntry.NumberOfEPFineEntries != 2 {
+		t.Errorf("got %d coarse and %d fine entries, want 1 and 2",
+			gotEntry.NumberOfEPCoarseEntries, gotEntry.NumberOfEPFineEntries)
+	}
+	if gotEntry.EPFineTableStartAddress != 12 {
+		t.Errorf("EPFineTableStartAddress = %d, want 12", gotEntry.EPFineTableStartAddress)
+	}
+	if fine := gotEntry.FineEntries[1]; fine.PTSEPFine != 0x020 || fine.SPNEPFine != 0x100 {
+		t.Errorf("FineEntries[1] = %v, want PTSEPFine 32 and SPNEPFine 256", fine)
+	}
+	if gotMarks.NumberOfClipMarks != 1 || gotMarks.MarkEntries[0].MarkTimeStamp != 90000 {
+		t.Errorf("ClipMarks = %v, want the added mark", gotMarks)
+	}
+	if len(gotExtensions.EntriesData) != 1 {
+		t.Errorf("got %d extension entries, want 1", len(gotExtensions.EntriesData))
+	}
+}
+
+func TestEntryPointPTS(t *testing.T) {
+	// PTS 0x1_2345_6600: coarse holds bits 32..19, fine bits 19..9.
+	const pts = 0x123456600
+	coarse := &CourseEntry{PTSEPCoarse: uint16(pts >> 19)}
+	fine := &FineEntry{PTSEPFine: uint16(pts >> 9 & 0x7FF)}
+
+	if got := EntryPointPTS(coarse, fine); got != pts {
+		t.Errorf("EntryPointPTS() = %#x, want %#x", uint64(got), uint64(pts))
+	}
+}
+
+func TestEntryPoints(t *testing.T) {
+	// Two coarse entries: the first covers fine entries 0 and 1, the
+	// second fine entry 2, across a carry into SPN bit 17.
+	cpi := &CPI{StreamPIDEntries: []*StreamPIDEntry{{
+		StreamPID: 0x1011,
+		CourseEntries: []*CourseEntry{
+			{RefToEPFineID: 0, PTSEPCoarse: 0x0000, SPNEPCoarse: 0x00000},
+			{RefToEPFineID: 2, PTSEPCoarse: 0x0003, SPNEPCoarse: 0x20000},
+		},
+		FineEntries: []*FineEntry{
+			{PTSEPFine: 0x000, SPNEPFine: 0x00000, IsAngleChangePoint: true},
+			{PTSEPFine: 0x100, SPNEPFine: 0x10000},
+			{PTSEPFine: 0x500, SPNEPFine: 0x00010, IsAngleChangePoint: true},
+		},
+	}}}
+
+	points := cpi.EntryPoints(0x1011)
+	want := []EntryPoint{
+		{PTS: 0, SPN: 0, IsAngleChangePoint: true},
+		{PTS: 0x100 << 9, SPN: 0x10000},
+		{PTS: 1<<20 | 0x500<<9, SPN: 0x20010, IsAngleChangePoint: true},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("EntryPoints() returned %d entry points, want %d", len(points), len(want))
+	}
+	for i := range want {
+		if *points[i] != want[i] {
+			t.Errorf("EntryPoints()[%d] = %+v, want %+v", i, *points[i], want[i])
+		}
+	}
+
+	if got := points.At(0x100<<9 - 1); got != points[0] {
+		t.Errorf("At() just before the second entry point = %+v", got)
+	}
+	if got := points.At(0x100 << 9); got != points[1] {
+		t.Errorf("At() on the second entry point = %+v", got)
+	}
+	if got := points.At(clock.PTSMask); got != points[2] {
+		t.Errorf("At() after the last entry point = %+v", got)
+	}
+	if got := points[2].ByteOffset(); got != 0x20010*192 {
+		t.Errorf("ByteOffset() = %d", got)
+	}
+	if got := points.AngleChangePoints(); len(got) != 2 || got[1] != points[2] {
+		t.Errorf("AngleChangePoints() = %v", got)
+	}
+	if got := cpi.EntryPoints(0x1100); got != nil {
+		t.Errorf("EntryPoints() of an unknown PID = %v, want nil", got)
+	}
+}
+
+func TestParseCLPIExtensions(t *testing.T) {
+	// The fixtures are testdata/00001.clpi with its extension entry retagged
+	// to a type and version nobody knows, then claiming more data than the
+	// extensions block holds.
+	tests := []struct {
+		name     string
+		filePath string
+		wantRaw  []byte      // Data of EntriesData[0], an ExtensionRaw
+		wantErr  *ParseError // Without its Err
+	}{
+		{
+			name:     "unknown extension",
+			filePath: "testdata/unknown_extension.clpi",
+			wantRaw:  []byte{0, 0, 0, 0x0C, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0x13, 0x88},
+		},
+		{
+			name:     "extension overrun",
+			filePath: "testdata/extension_overrun.clpi",
+			wantErr:  &ParseError{File: "testdata/extension_overrun.clpi", Offset: 412, Path: "Extensions.EntriesData[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, _, _, _, extensiondata, err := ParseCLPI(tt.filePath)
+			if tt.wantErr != nil {
+				var parseErr *ParseError
+				if !errors.Is(err, ErrExtension) || !errors.As(err, &parseErr) {
+					t.Fatalf("ParseCLPI() error = %v, want a ParseError wrapping ErrExtension", err)
+				}
+				if got := (ParseError{File: parseErr.File, Offset: parseErr.Offset, Path: parseErr.Path}); got != *tt.wantErr {
+					t.Errorf("ParseError = %+v, want %+v", got, *tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCLPI() error = %v", err)
+			}
+			raw, ok := extensiondata.EntriesData[0].(*ExtensionRaw)
+			if !ok || !bytes.Equal(raw.Data, tt.wantRaw) {
+				t.Errorf("EntriesData[0] = %v, want an ExtensionRaw of % x", extensiondata.EntriesData[0], tt.wantRaw)
+			}
+		})
+	}
+}
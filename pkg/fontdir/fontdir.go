@@ -3,6 +3,8 @@ package fontdir
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"strings"
 )
@@ -36,11 +38,41 @@ func ParseFontDirectory(filePath string) (*FontDirectory, error) {
 		return nil, fmt.Errorf("failed to read XML file %s: %v", filePath, err)
 	}
 
+	return parseFontDirectory(xmlData)
+}
+
+// ParseFontDirectoryFS is ParseFontDirectory for the file name in fsys,
+// such as a disc image opened with package udf.
+func ParseFontDirectoryFS(fsys fs.FS, name string) (*FontDirectory, error) {
+	xmlData, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XML file %s: %v", name, err)
+	}
+
+	return parseFontDirectory(xmlData)
+}
+
+// ParseFontDirectoryReader parses a dvb.fontindex file read from r.
+func ParseFontDirectoryReader(r io.Reader) (*FontDirectory, error) {
+	xmlData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XML: %v", err)
+	}
+
+	return parseFontDirectory(xmlData)
+}
+
+// ParseFontDirectoryBytes parses a dvb.fontindex file held in memory.
+func ParseFontDirectoryBytes(xmlData []byte) (*FontDirectory, error) {
+	return parseFontDirectory(xmlData)
+}
+
+func parseFontDirectory(xmlData []byte) (*FontDirectory, error) {
 	var fontDir FontDirectory
 	decoder := xml.NewDecoder(strings.NewReader(string(xmlData)))
 	decoder.Strict = true // Enforce strict XML parsing to align with DTD
 
-	err = decoder.Decode(&fontDir)
+	err := decoder.Decode(&fontDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse XML: %v", err)
 	}
@@ -0,0 +1,37 @@
+package hdmv
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoObject is wrapped by errors for a missing movie object.
+	ErrNoObject = errors.New("no such movie object")
+
+	// ErrNoTitle is wrapped by errors for a missing index.bdmv title.
+	ErrNoTitle = errors.New("no such title")
+
+	// ErrNoPlaylist is wrapped by errors for a play command naming a
+	// missing playlist, PlayItem or mark.
+	ErrNoPlaylist = errors.New("no such playlist")
+
+	// ErrCommand is wrapped by errors for a command the VM cannot run.
+	ErrCommand = errors.New("invalid navigation command")
+)
+
+// CommandError reports the command a run failed at.
+// Use errors.Is on it to test for the sentinel errors above.
+type CommandError struct {
+	Object  int // Movie object number
+	Command int // Command index in the object
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("movie_object %d command %d: %v", e.Object, e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,454 @@
+package hdmv
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+)
+
+/*
+	Remarks:
+
+	BuildGraph splits every movie object into basic blocks, runs of
+	commands that are entered at the first and left at the last. A block
+	ends at any branch, compare or play command, and a new one starts at
+	every goto target and after every compare.
+
+	Edges follow what the last command of a block can do next:
+
+		next    on to the following command, also after a play or a call
+		goto    goto with an immediate target
+		true    the compare held; run the next command
+		false   the compare failed; skip it
+		jump    jump_object or jump_title
+		call    call_object or call_title
+		play    a play command, to the playlist it plays
+
+	A jump, call or goto through a register has no known target; its edge
+	is kept with Dynamic set. An edge with no target at all runs off the
+	end of the object.
+
+	The analysis flags:
+
+		unreachable commands  no path from the object's first command
+		                      reaches them
+		endless loops         cycles of blocks with no play command and
+		                      no edge out of the cycle
+		unreferenced objects  no path from a title of index.bdmv reaches
+		                      them
+
+	Menu buttons run commands from the IG stream, which can jump to any
+	object, so an unreferenced object may still be used by a menu.
+*/
+
+// EdgeKind says how control leaves a block.
+type EdgeKind uint8
+
+const (
+	EdgeNext EdgeKind = iota
+	EdgeGoto
+	EdgeTrue
+	EdgeFalse
+	EdgeJump
+	EdgeCall
+	EdgePlay
+)
+
+func (kind EdgeKind) String() string {
+	switch kind {
+	case EdgeNext:
+		return "next"
+	case EdgeGoto:
+		return "goto"
+	case EdgeTrue:
+		return "true"
+	case EdgeFalse:
+		return "false"
+	case EdgeJump:
+		return "jump"
+	case EdgeCall:
+		return "call"
+	case EdgePlay:
+		return "play"
+	}
+	return fmt.Sprintf("EdgeKind(%d)", uint8(kind))
+}
+
+// TitleNode is one title of index.bdmv.
+type TitleNode struct {
+	Name      string // "first_playback", "top_menu" or the title number
+	Number    uint32 // As held in PSR 4
+	Object    int    // Movie object run, or -1
+	BDJObject string // BD-J object run, for BD-J titles
+}
+
+// Block is a basic block of a movie object.
+type Block struct {
+	Object   int
+	Start    int // Commands [Start, End) of the object
+	End      int
+	Commands []*mobj.NavigationCommand
+	Edges    []*Edge
+}
+
+// Edge leaves a block for another block, a title or a playlist.
+type Edge struct {
+	Kind     EdgeKind
+	Block    *Block
+	Title    *TitleNode
+	Playlist string // 5-digit name, for EdgePlay
+	Dynamic  bool   // The target is in a register
+}
+
+// Graph is the control-flow graph of a disc's movie objects.
+type Graph struct {
+	Titles  []*TitleNode // First Playback, Top Menu, then title 1 on
+	Objects [][]*Block   // Blocks of each movie object, in command order
+
+	Unreachable         []Step     // Commands no path within their object reaches
+	Loops               [][]*Block // Endless loops without a play
+	UnreferencedObjects []int      // Objects no title reaches
+}
+
+// Playlists returns the sorted names of the playlists played.
+func (graph *Graph) Playlists() []string {
+	var names []string
+	for _, blocks := range graph.Objects {
+		for _, block := range blocks {
+			for _, edge := range block.Edges {
+				if edge.Kind == EdgePlay && !edge.Dynamic && !slices.Contains(names, edge.Playlist) {
+					names = append(names, edge.Playlist)
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// BuildGraph builds and analyses the control-flow graph of movieObjects,
+// entered from the titles of indexes.
+func BuildGraph(indexes *indx.Indexes, movieObjects *mobj.MovieObjects) *Graph {
+	graph := &Graph{}
+	graph.Titles = append(graph.Titles,
+		newTitleNode("first_playback", TitleFirstPlayback, indexes.FirstPlaybackTitle),
+		newTitleNode("top_menu", TitleTopMenu, indexes.TopMenuTitle),
+	)
+	for i, title := range indexes.Titles {
+		graph.Titles = append(graph.Titles, newTitleNode(fmt.Sprint(i+1), uint32(i+1), title))
+	}
+
+	graph.Objects = make([][]*Block, len(movieObjects.MovieObjects))
+	for i, movieObject := range movieObjects.MovieObjects {
+		graph.Objects[i] = splitBlocks(i, movieObject.NavigationCommands)
+	}
+	for _, blocks := range graph.Objects {
+		for _, block := range blocks {
+			graph.addEdges(block)
+		}
+	}
+
+	graph.findUnreachable()
+	graph.findLoops()
+	graph.findUnreferenced()
+	return graph
+}
+
+func newTitleNode(name string, number uint32, title *indx.Title) *TitleNode {
+	node := &TitleNode{Name: name, Number: number, Object: -1}
+	switch {
+	case title == nil:
+	case title.ObjectType == ObjectTypeHDMV:
+		node.Object = int(title.RefToMovieObjectID)
+	case title.ObjectType == ObjectTypeBDJ:
+		node.BDJObject = string(title.RefToBDJObjectID[:])
+	}
+	return node
+}
+
+// endsBlock reports whether a command is the last of its block.
+func endsBlock(nav *mobj.NavigationCommand) bool {
+	switch nav.CommandGroup {
+	case 0:
+		return nav.CommandSubGroup != 0 || nav.BranchOption != mobj.MOBJ_BRANCH_OPTION_SUB0_NOP
+	case 1:
+		return true
+	}
+	return false
+}
+
+func splitBlocks(object int, commands []*mobj.NavigationCommand) []*Block {
+	leaders := map[int]bool{0: true}
+	for i, nav := range commands {
+		if endsBlock(nav) {
+			leaders[i+1] = true
+		}
+		if nav.CommandGroup == 1 {
+			leaders[i+2] = true
+		}
+		if isGoto(nav) && nav.ImmediateValueFlagDest {
+			leaders[int(nav.Destination)] = true
+		}
+	}
+
+	var blocks []*Block
+	for i := range commands {
+		if leaders[i] {
+			blocks = append(blocks, &Block{Object: object, Start: i})
+		}
+		block := blocks[len(blocks)-1]
+		block.End = i + 1
+		block.Commands = commands[block.Start:block.End]
+	}
+	return blocks
+}
+
+func isGoto(nav *mobj.NavigationCommand) bool {
+	return nav.CommandGroup == 0 && nav.CommandSubGroup == 0 && nav.BranchOption == mobj.MOBJ_BRANCH_OPTION_SUB0_GOTO
+}
+
+// blockAt returns the block of object starting at command, or nil.
+func (graph *Graph) blockAt(object, command int) *Block {
+	if object < 0 || object >= len(graph.Objects) {
+		return nil
+	}
+	for _, block := range graph.Objects[object] {
+		if block.Start == command {
+			return block
+		}
+	}
+	return nil
+}
+
+func (graph *Graph) title(number uint32) *TitleNode {
+	if number < 1 || int(number)+1 >= len(graph.Titles) {
+		return nil
+	}
+	return graph.Titles[number+1]
+}
+
+func (graph *Graph) addEdges(block *Block) {
+	nav := block.Commands[len(block.Commands)-1]
+	last := block.End - 1
+	next := func(kind EdgeKind, command int) {
+		block.Edges = append(block.Edges, &Edge{Kind: kind, Block: graph.blockAt(block.Object, command)})
+	}
+	dynamic := !nav.ImmediateValueFlagDest
+
+	switch {
+	case nav.CommandGroup == 1:
+		next(EdgeTrue, last+1)
+		next(EdgeFalse, last+2)
+	case isGoto(nav) && dynamic:
+		block.Edges = append(block.Edges, &Edge{Kind: EdgeGoto, Dynamic: true})
+	case isGoto(nav):
+		next(EdgeGoto, int(nav.Destination))
+	case nav.CommandGroup == 0 && nav.CommandSubGroup == 0 && nav.BranchOption == mobj.MOBJ_BRANCH_OPTION_SUB0_BREAK:
+	case nav.CommandGroup == 0 && nav.CommandSubGroup == 1:
+		kind := EdgeJump
+		switch nav.BranchOption {
+		case mobj.MOBJ_BRANCH_OPTION_SUB1_RESUME:
+			return
+		case mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_OBJECT, mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_TITLE:
+			kind = EdgeCall
+		}
+		edge := &Edge{Kind: kind, Dynamic: dynamic}
+		if !dynamic {
+			switch nav.BranchOption {
+			case mobj.MOBJ_BRANCH_OPTION_SUB1_JUMP_OBJECT, mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_OBJECT:
+				edge.Block = graph.blockAt(int(nav.Destination), 0)
+			default:
+				edge.Title = graph.title(nav.Destination)
+			}
+		}
+		if edge.Dynamic || edge.Block != nil || edge.Title != nil {
+			block.Edges = append(block.Edges, edge)
+		}
+		if kind == EdgeCall {
+			next(EdgeNext, last+1)
+		}
+	case nav.CommandGroup == 0 && nav.CommandSubGroup == 2:
+		switch nav.BranchOption {
+		case mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYLIST, mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYITEM, mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYMARK:
+			edge := &Edge{Kind: EdgePlay, Dynamic: dynamic}
+			if !dynamic {
+				edge.Playlist = fmt.Sprintf("%05d", nav.Destination)
+			}
+			block.Edges = append(block.Edges, edge)
+		case mobj.MOBJ_BRANCH_OPTION_SUB2_LINKITEM, mobj.MOBJ_BRANCH_OPTION_SUB2_LINKMARK:
+			block.Edges = append(block.Edges, &Edge{Kind: EdgePlay, Dynamic: true})
+		}
+		next(EdgeNext, last+1)
+	default:
+		next(EdgeNext, last+1)
+	}
+}
+
+// Plays reports whether the block ends in a play command.
+func (block *Block) Plays() bool {
+	for _, edge := range block.Edges {
+		if edge.Kind == EdgePlay {
+			return true
+		}
+	}
+	return false
+}
+
+// successors returns the blocks an edge of block leads to, following
+// jumps to a title on to the title's object.
+func (graph *Graph) successors(block *Block) []*Block {
+	var blocks []*Block
+	for _, edge := range block.Edges {
+		switch {
+		case edge.Block != nil:
+			blocks = append(blocks, edge.Block)
+		case edge.Title != nil:
+			if target := graph.blockAt(edge.Title.Object, 0); target != nil {
+				blocks = append(blocks, target)
+			}
+		}
+	}
+	return blocks
+}
+
+// findUnreachable marks commands no path within their own object reaches.
+// An object with a dynamic goto is skipped, as any command may be its target.
+func (graph *Graph) findUnreachable() {
+	for object, blocks := range graph.Objects {
+		if len(blocks) == 0 {
+			continue
+		}
+		reached := map[*Block]bool{}
+		stack := []*Block{blocks[0]}
+		dynamic := false
+		for len(stack) > 0 {
+			block := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if reached[block] {
+				continue
+			}
+			reached[block] = true
+			for _, edge := range block.Edges {
+				switch {
+				case edge.Kind == EdgeGoto && edge.Dynamic:
+					dynamic = true
+				case edge.Block != nil && edge.Block.Object == object && edge.Kind != EdgeJump && edge.Kind != EdgeCall:
+					stack = append(stack, edge.Block)
+				}
+			}
+		}
+		if dynamic {
+			continue
+		}
+		for _, block := range blocks {
+			if !reached[block] {
+				for command := block.Start; command < block.End; command++ {
+					graph.Unreachable = append(graph.Unreachable, Step{object, command})
+				}
+			}
+		}
+	}
+}
+
+// findLoops finds the strongly connected components of the block graph
+// that contain a cycle, play nothing and cannot be left.
+func (graph *Graph) findLoops() {
+	index := map[*Block]int{}
+	lowLink := map[*Block]int{}
+	onStack := map[*Block]bool{}
+	var stack []*Block
+	var connect func(block *Block)
+	connect = func(block *Block) {
+		index[block] = len(index)
+		lowLink[block] = index[block]
+		stack = append(stack, block)
+		onStack[block] = true
+		for _, next := range graph.successors(block) {
+			if _, seen := index[next]; !seen {
+				connect(next)
+				lowLink[block] = min(lowLink[block], lowLink[next])
+			} else if onStack[next] {
+				lowLink[block] = min(lowLink[block], index[next])
+			}
+		}
+		if lowLink[block] != index[block] {
+			return
+		}
+		var component []*Block
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == block {
+				break
+			}
+		}
+		if graph.endlessLoop(component) {
+			slices.SortFunc(component, func(a, b *Block) int {
+				if a.Object != b.Object {
+					return a.Object - b.Object
+				}
+				return a.Start - b.Start
+			})
+			graph.Loops = append(graph.Loops, component)
+		}
+	}
+	for _, blocks := range graph.Objects {
+		for _, block := range blocks {
+			if _, seen := index[block]; !seen {
+				connect(block)
+			}
+		}
+	}
+}
+
+func (graph *Graph) endlessLoop(component []*Block) bool {
+	cyclic := len(component) > 1
+	for _, block := range component {
+		if block.Plays() {
+			return false
+		}
+		for _, next := range graph.successors(block) {
+			if !slices.Contains(component, next) {
+				return false
+			}
+			if next == block {
+				cyclic = true
+			}
+		}
+		if len(graph.successors(block)) < len(block.Edges) {
+			return false // A dynamic edge, or one that ends the run
+		}
+	}
+	return cyclic
+}
+
+// findUnreferenced lists the objects no path from a title reaches.
+func (graph *Graph) findUnreferenced() {
+	reached := map[int]bool{}
+	var stack []*Block
+	for _, title := range graph.Titles {
+		if block := graph.blockAt(title.Object, 0); block != nil {
+			stack = append(stack, block)
+		}
+	}
+	seen := map[*Block]bool{}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[block] {
+			continue
+		}
+		seen[block] = true
+		reached[block.Object] = true
+		stack = append(stack, graph.successors(block)...)
+	}
+	for object := range graph.Objects {
+		if !reached[object] {
+			graph.UnreferencedObjects = append(graph.UnreferencedObjects, object)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package hdmv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WriteDOT writes the graph in Graphviz DOT, one cluster per movie object.
+// Unreachable commands are grey, endless loops red, and unreferenced
+// objects dashed.
+func (graph *Graph) WriteDOT(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph hdmv {")
+	fmt.Fprintln(out, `  node [shape=box, fontname="monospace", fontsize=10];`)
+
+	for _, title := range graph.Titles {
+		label := "title " + title.Name
+		if title.Number == TitleFirstPlayback || title.Number == TitleTopMenu {
+			label = strings.ReplaceAll(title.Name, "_", " ")
+		}
+		fmt.Fprintf(out, "  %s [label=%s, shape=ellipse];\n", titleID(title), quote(label))
+		switch {
+		case title.BDJObject != "":
+			fmt.Fprintf(out, "  %s -> %s;\n", titleID(title), bdjID(title.BDJObject))
+			fmt.Fprintf(out, "  %s [label=%s, shape=component];\n", bdjID(title.BDJObject), quote("BD-J "+title.BDJObject))
+		case graph.blockAt(title.Object, 0) != nil:
+			fmt.Fprintf(out, "  %s -> %s;\n", titleID(title), blockID(graph.blockAt(title.Object, 0)))
+		}
+	}
+	for _, playlist := range graph.Playlists() {
+		fmt.Fprintf(out, "  %s [label=%s, shape=note];\n", playlistID(playlist), quote(playlist+".mpls"))
+	}
+
+	unreachable := map[Step]bool{}
+	for _, step := range graph.Unreachable {
+		unreachable[step] = true
+	}
+	looping := map[*Block]bool{}
+	for _, loop := range graph.Loops {
+		for _, block := range loop {
+			looping[block] = true
+		}
+	}
+
+	for object, blocks := range graph.Objects {
+		fmt.Fprintf(out, "  subgraph cluster_%d {\n", object)
+		fmt.Fprintf(out, "    label=%s;\n", quote(fmt.Sprintf("movie_object %d", object)))
+		if slices.Contains(graph.UnreferencedObjects, object) {
+			fmt.Fprintln(out, "    style=dashed;")
+		}
+		for _, block := range blocks {
+			var label strings.Builder
+			for i, nav := range block.Commands {
+				fmt.Fprintf(&label, "%04d  %s\\l", block.Start+i, escape(nav.Disassemble()))
+			}
+			attributes := ""
+			switch {
+			case looping[block]:
+				attributes = ", color=red"
+			case unreachable[Step{object, block.Start}]:
+				attributes = ", style=filled, fillcolor=lightgrey"
+			}
+			fmt.Fprintf(out, "    %s [label=\"%s\"%s];\n", blockID(block), label.String(), attributes)
+		}
+		fmt.Fprintln(out, "  }")
+	}
+
+	for _, blocks := range graph.Objects {
+		for _, block := range blocks {
+			for i, edge := range block.Edges {
+				target := ""
+				switch {
+				case edge.Dynamic:
+					target = fmt.Sprintf("%s_%d", blockID(block), i)
+					fmt.Fprintf(out, "  %s [label=\"?\", shape=circle];\n", target)
+				case edge.Block != nil:
+					target = blockID(edge.Block)
+				case edge.Title != nil:
+					target = titleID(edge.Title)
+				case edge.Kind == EdgePlay:
+					target = playlistID(edge.Playlist)
+				default:
+					continue // Runs off the end of the object
+				}
+				fmt.Fprintf(out, "  %s -> %s [label=%s%s];\n", blockID(block), target, quote(edge.Kind.String()), edgeStyle(edge.Kind))
+			}
+		}
+	}
+
+	fmt.Fprintln(out, "}")
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write DOT: %w", err)
+	}
+	return nil
+}
+
+func edgeStyle(kind EdgeKind) string {
+	switch kind {
+	case EdgeFalse:
+		return ", style=dashed"
+	case EdgeJump, EdgeCall:
+		return ", style=bold"
+	case EdgePlay:
+		return ", color=blue"
+	}
+	return ""
+}
+
+func titleID(title *TitleNode) string {
+	return "title_" + title.Name
+}
+
+func blockID(block *Block) string {
+	return fmt.Sprintf("o%d_c%d", block.Object, block.Start)
+}
+
+func playlistID(name string) string {
+	return "playlist_" + name
+}
+
+func bdjID(name string) string {
+	return "bdj_" + name
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
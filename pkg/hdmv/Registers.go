@@ -0,0 +1,171 @@
+package hdmv
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	NumberOfGPRs = 4096
+	NumberOfPSRs = 128
+)
+
+// Player status registers the VM reads or writes.
+const (
+	PSRIGStream        = 0
+	PSRPrimaryAudio    = 1
+	PSRPGStream        = 2
+	PSRAngle           = 3
+	PSRTitle           = 4
+	PSRChapter         = 5
+	PSRPlaylist        = 6
+	PSRPlayItem        = 7
+	PSRTime            = 8
+	PSRNavTimer        = 9
+	PSRSelectedButton  = 10
+	PSRMenuPage        = 11
+	PSRTextUserStyle   = 12
+	PSRParentalLevel   = 13
+	PSRSecondaryStream = 14
+	PSRAudioLanguage   = 16
+	PSRPGLanguage      = 17
+	PSRMenuLanguage    = 18
+	PSRCountryCode     = 19
+	PSRRegionCode      = 20
+	PSRBackup          = 36 // PSRs 36 to 44 back up PSRs 4 to 12 over a call
+)
+
+// Player status registers that describe the player's capabilities and
+// output, read when selecting streams.
+const (
+	PSRAudioCapability      = 15
+	PSROutputModePreference = 21
+	PSR3DCapability         = 24
+	PSRVideoCapability      = 29
+	PSRTextCapability       = 30 // TextST capability
+	PSRPlayerProfile        = 31
+)
+
+// Region codes of PSR 20.
+const (
+	RegionA uint32 = 0x01
+	RegionB uint32 = 0x02
+	RegionC uint32 = 0x04
+)
+
+// Registers are the two register files of a player.
+type Registers struct {
+	GPR [NumberOfGPRs]uint32
+	PSR [NumberOfPSRs]uint32
+}
+
+// initialPSRs are the values a player starts with, where not 0.
+var initialPSRs = map[int]uint32{
+	PSRIGStream:        1,
+	PSRPrimaryAudio:    0xFF,
+	PSRPGStream:        0x0FFF,
+	PSRAngle:           1,
+	PSRTitle:           0xFFFF,
+	PSRChapter:         0xFFFF,
+	PSRSelectedButton:  0xFFFF,
+	PSRTextUserStyle:   0xFF,
+	PSRParentalLevel:   0xFF,
+	PSRSecondaryStream: 0xFFFF,
+	PSRAudioCapability: 0xFFFF,
+	PSRAudioLanguage:   0xFFFFFF,
+	PSRPGLanguage:      0xFFFFFF,
+	PSRMenuLanguage:    0xFFFFFF,
+	PSRCountryCode:     0xFFFF,
+	PSRRegionCode:      RegionA,
+	PSRVideoCapability: 0x03,
+	PSRTextCapability:  0x1FFFF,
+	PSRPlayerProfile:   0x080200,
+}
+
+// Settings are the player settings a run starts from. Empty fields keep
+// the player defaults.
+type Settings struct {
+	AudioLanguage string // PSR 16, ISO 639-2, e.g. "eng"
+	PGLanguage    string // PSR 17
+	MenuLanguage  string // PSR 18
+	CountryCode   string // PSR 19, ISO 3166-1 alpha-2, e.g. "us"
+	RegionCode    uint32 // PSR 20, RegionA, RegionB or RegionC
+	ParentalLevel *uint32
+	Output3D      bool           // PSRs 21 and 24: 3D output on a 3D display
+	PSRs          map[int]uint32 // Any PSR, applied last
+	Seed          uint64         // Of the rnd command
+}
+
+// Registers returns the registers a run starts with.
+func (settings *Settings) Registers() (*Registers, error) {
+	registers := &Registers{}
+	for psr, value := range initialPSRs {
+		registers.PSR[psr] = value
+	}
+
+	for _, s := range []struct {
+		psr   int
+		value string
+		size  int
+	}{
+		{PSRAudioLanguage, settings.AudioLanguage, 3},
+		{PSRPGLanguage, settings.PGLanguage, 3},
+		{PSRMenuLanguage, settings.MenuLanguage, 3},
+		{PSRCountryCode, settings.CountryCode, 2},
+	} {
+		if s.value == "" {
+			continue
+		}
+		value, err := packCode(strings.ToLower(s.value), s.size)
+		if err != nil {
+			return nil, fmt.Errorf("PSR %d: %w", s.psr, err)
+		}
+		registers.PSR[s.psr] = value
+	}
+	if settings.RegionCode != 0 {
+		registers.PSR[PSRRegionCode] = settings.RegionCode
+	}
+	if settings.ParentalLevel != nil {
+		registers.PSR[PSRParentalLevel] = *settings.ParentalLevel
+	}
+	if settings.Output3D {
+		registers.PSR[PSROutputModePreference] |= 1
+		registers.PSR[PSR3DCapability] |= 1
+	}
+	for psr, value := range settings.PSRs {
+		if psr < 0 || psr >= NumberOfPSRs {
+			return nil, fmt.Errorf("no PSR %d", psr)
+		}
+		registers.PSR[psr] = value
+	}
+	return registers, nil
+}
+
+// packCode packs a language or country code into a PSR, one ASCII
+// character per byte, e.g. "eng" is 0x656E67.
+func packCode(code string, size int) (uint32, error) {
+	if len(code) != size {
+		return 0, fmt.Errorf("code %q is not %d letters", code, size)
+	}
+	var value uint32
+	for i := range size {
+		if code[i] < 'a' || code[i] > 'z' {
+			return 0, fmt.Errorf("code %q is not lower case ASCII letters", code)
+		}
+		value = value<<8 | uint32(code[i])
+	}
+	return value, nil
+}
+
+// ParseRegion returns the PSR 20 value of region "A", "B" or "C".
+func ParseRegion(region string) (uint32, error) {
+	switch strings.ToUpper(region) {
+	case "A":
+		return RegionA, nil
+	case "B":
+		return RegionB, nil
+	case "C":
+		return RegionC, nil
+	}
+	return 0, fmt.Errorf("unknown region %q", region)
+}
@@ -0,0 +1,500 @@
+package hdmv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+)
+
+// End is why a run stopped.
+type End uint8
+
+const (
+	EndStop End = iota
+	EndBDJ
+	EndLoop
+	EndStepLimit
+	EndError
+)
+
+func (end End) String() string {
+	switch end {
+	case EndStop:
+		return "stop"
+	case EndBDJ:
+		return "bd-j"
+	case EndLoop:
+		return "loop"
+	case EndStepLimit:
+		return "step limit"
+	case EndError:
+		return "error"
+	}
+	return fmt.Sprintf("End(%d)", uint8(end))
+}
+
+// Step is one executed command.
+type Step struct {
+	Object  int
+	Command int
+}
+
+// Play is one play command the run reached.
+type Play struct {
+	Step
+	Title    uint32 // PSR 4 at the time
+	Playlist string
+	PlayItem int // -1 unless play_pl_pi or link_pi
+	Mark     int // -1 unless play_pl_pm or link_mk
+	Duration clock.Ticks45k
+}
+
+// Result is the outcome of one run.
+type Result struct {
+	Plays     []*Play
+	Trace     []Step
+	End       End
+	BDJObject string // The BD-J object reached, when End is EndBDJ
+	Err       error  // Why, when End is EndError; a *CommandError when a command failed
+	Registers *Registers
+}
+
+// Feature returns the longest play of the run, or nil when nothing played.
+func (result *Result) Feature() *Play {
+	var feature *Play
+	for _, play := range result.Plays {
+		if feature == nil || play.Duration > feature.Duration {
+			feature = play
+		}
+	}
+	return feature
+}
+
+// frame is where a call_object or call_title returns to.
+type frame struct {
+	object int
+	pc     int
+	psr    [9]uint32 // PSRs 4 to 12
+}
+
+// run is the state of one run.
+type run struct {
+	vm        *VM
+	registers *Registers
+	random    *rand.Rand
+	result    *Result
+	object    int
+	pc        int
+	suspended *frame
+	seen      map[uint64]bool // States of the play commands reached
+}
+
+// RunFirstPlayback runs the First Playback title.
+func (vm *VM) RunFirstPlayback() *Result {
+	return vm.start(vm.Indexes.FirstPlaybackTitle, TitleFirstPlayback)
+}
+
+// RunTopMenu runs the Top Menu title.
+func (vm *VM) RunTopMenu() *Result {
+	return vm.start(vm.Indexes.TopMenuTitle, TitleTopMenu)
+}
+
+// RunTitle runs title number n, from 1, as a title search would.
+func (vm *VM) RunTitle(n int) *Result {
+	if n < 1 || n > len(vm.Indexes.Titles) {
+		return &Result{End: EndError, Err: fmt.Errorf("%w: %d", ErrNoTitle, n)}
+	}
+	return vm.start(vm.Indexes.Titles[n-1], uint32(n))
+}
+
+func (vm *VM) start(title *indx.Title, number uint32) *Result {
+	registers, err := vm.Settings.Registers()
+	if err != nil {
+		return &Result{End: EndError, Err: err}
+	}
+	r := &run{
+		vm:        vm,
+		registers: registers,
+		random:    rand.New(rand.NewPCG(vm.Settings.Seed, 0)),
+		result:    &Result{Registers: registers},
+		seen:      map[uint64]bool{},
+	}
+	if err := r.enterTitle(title, number); err != nil {
+		r.result.End, r.result.Err = EndError, err
+	} else if r.result.End != EndBDJ {
+		r.execute()
+	}
+	return r.result
+}
+
+// enterTitle sets PSR 4 and moves to the title's movie object, or ends
+// the run at a BD-J title.
+func (r *run) enterTitle(title *indx.Title, number uint32) error {
+	if title == nil {
+		return fmt.Errorf("%w: %d", ErrNoTitle, number)
+	}
+	r.registers.PSR[PSRTitle] = number
+	switch title.ObjectType {
+	case ObjectTypeHDMV:
+		return r.enterObject(int(title.RefToMovieObjectID))
+	case ObjectTypeBDJ:
+		r.result.End = EndBDJ
+		r.result.BDJObject = string(title.RefToBDJObjectID[:])
+		return nil
+	}
+	return fmt.Errorf("%w: title %d has object type %d", ErrNoTitle, number, title.ObjectType)
+}
+
+func (r *run) enterObject(object int) error {
+	if object < 0 || object >= len(r.vm.MovieObjects.MovieObjects) {
+		return fmt.Errorf("%w: %d", ErrNoObject, object)
+	}
+	r.object, r.pc = object, 0
+	return nil
+}
+
+func (r *run) fail(pc int, err error) {
+	r.result.End = EndError
+	r.result.Err = &CommandError{Object: r.object, Command: pc, Err: err}
+}
+
+// execute runs commands until the run ends.
+func (r *run) execute() {
+	for {
+		commands := r.vm.MovieObjects.MovieObjects[r.object].NavigationCommands
+		if r.pc < 0 || r.pc >= len(commands) {
+			r.result.End = EndStop
+			return
+		}
+		if len(r.result.Trace) >= r.vm.MaxSteps {
+			r.result.End = EndStepLimit
+			return
+		}
+		pc := r.pc
+		r.result.Trace = append(r.result.Trace, Step{r.object, pc})
+		r.pc++
+
+		done, err := r.step(commands[pc], pc)
+		if err != nil {
+			r.fail(pc, err)
+			return
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// step executes one command. It reports whether the run has ended.
+func (r *run) step(nav *mobj.NavigationCommand, pc int) (bool, error) {
+	dst, src := nav.Operands()
+	switch nav.CommandGroup {
+	case 0:
+		switch nav.CommandSubGroup {
+		case 0:
+			return r.branch(nav, dst)
+		case 1:
+			return r.jump(nav, dst)
+		case 2:
+			return r.play(nav, pc, dst, src)
+		}
+	case 1:
+		if nav.CommandSubGroup == 0 {
+			return false, r.compare(nav, dst, src)
+		}
+	case 2:
+		switch nav.CommandSubGroup {
+		case 0:
+			return false, r.set(nav, dst, src)
+		case 1:
+			return false, r.setSystem(nav, dst, src)
+		}
+	}
+	return false, fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+}
+
+func (r *run) read(operand mobj.Operand) uint32 {
+	switch {
+	case operand.Immediate:
+		return operand.Value
+	case operand.PSR:
+		return r.registers.PSR[operand.Value]
+	}
+	return r.registers.GPR[operand.Value]
+}
+
+// write stores into a GPR. Writes to a PSR or an immediate are ignored.
+func (r *run) write(operand mobj.Operand, value uint32) {
+	if !operand.Immediate && !operand.PSR {
+		r.registers.GPR[operand.Value] = value
+	}
+}
+
+func (r *run) branch(nav *mobj.NavigationCommand, dst mobj.Operand) (bool, error) {
+	switch nav.BranchOption {
+	case mobj.MOBJ_BRANCH_OPTION_SUB0_NOP:
+		return false, nil
+	case mobj.MOBJ_BRANCH_OPTION_SUB0_GOTO:
+		r.pc = int(r.read(dst))
+		return false, nil
+	case mobj.MOBJ_BRANCH_OPTION_SUB0_BREAK:
+		r.result.End = EndStop
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+}
+
+func (r *run) jump(nav *mobj.NavigationCommand, dst mobj.Operand) (bool, error) {
+	target := r.read(dst)
+	switch nav.BranchOption {
+	case mobj.MOBJ_BRANCH_OPTION_SUB1_JUMP_OBJECT:
+		return false, r.enterObject(int(target))
+	case mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_OBJECT:
+		r.suspend()
+		return false, r.enterObject(int(target))
+	case mobj.MOBJ_BRANCH_OPTION_SUB1_JUMP_TITLE, mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_TITLE:
+		if target < 1 || int(target) > len(r.vm.Indexes.Titles) {
+			return false, fmt.Errorf("%w: %d", ErrNoTitle, target)
+		}
+		if nav.BranchOption == mobj.MOBJ_BRANCH_OPTION_SUB1_CALL_TITLE {
+			r.suspend()
+		}
+		if err := r.enterTitle(r.vm.Indexes.Titles[target-1], target); err != nil {
+			return false, err
+		}
+		return r.result.End == EndBDJ, nil
+	case mobj.MOBJ_BRANCH_OPTION_SUB1_RESUME:
+		if r.suspended == nil {
+			r.result.End = EndStop
+			return true, nil
+		}
+		r.object, r.pc = r.suspended.object, r.suspended.pc
+		copy(r.registers.PSR[PSRTitle:], r.suspended.psr[:])
+		r.suspended = nil
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+}
+
+// suspend saves where a call returns to, backing up PSRs 4 to 12 into
+// PSRs 36 to 44 as a player does.
+func (r *run) suspend() {
+	saved := &frame{object: r.object, pc: r.pc}
+	copy(saved.psr[:], r.registers.PSR[PSRTitle:])
+	copy(r.registers.PSR[PSRBackup:], saved.psr[:])
+	r.suspended = saved
+}
+
+func (r *run) play(nav *mobj.NavigationCommand, pc int, dst, src mobj.Operand) (bool, error) {
+	play := &Play{
+		Step:     Step{r.object, pc},
+		Title:    r.registers.PSR[PSRTitle],
+		PlayItem: -1,
+		Mark:     -1,
+	}
+	number := r.registers.PSR[PSRPlaylist]
+	switch nav.BranchOption {
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYLIST:
+		number = r.read(dst)
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYITEM:
+		number, play.PlayItem = r.read(dst), int(r.read(src))
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_PLAYMARK:
+		number, play.Mark = r.read(dst), int(r.read(src))
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_TERMINATE:
+		return false, nil
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_LINKITEM:
+		play.PlayItem = int(r.read(dst))
+	case mobj.MOBJ_BRANCH_OPTION_SUB2_LINKMARK:
+		play.Mark = int(r.read(dst))
+	default:
+		return false, fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+	}
+	play.Playlist = fmt.Sprintf("%05d", number)
+	if err := r.checkPlay(play); err != nil {
+		return false, err
+	}
+
+	r.registers.PSR[PSRPlaylist] = number
+	r.registers.PSR[PSRPlayItem] = uint32(max(play.PlayItem, 0))
+	r.registers.PSR[PSRTime] = 0
+
+	// The same play from the same state plays the same from then on.
+	state := r.state()
+	if r.seen[state] {
+		r.result.End = EndLoop
+		return true, nil
+	}
+	r.seen[state] = true
+	r.result.Plays = append(r.result.Plays, play)
+	return false, nil
+}
+
+// checkPlay looks the playlist of a play up and sets its duration.
+// Without playlists every play is taken as valid.
+func (r *run) checkPlay(play *Play) error {
+	if r.vm.Playlists == nil {
+		return nil
+	}
+	playlist, ok := r.vm.Playlists[play.Playlist]
+	if !ok || playlist.PlayList == nil {
+		return fmt.Errorf("%w: %s", ErrNoPlaylist, play.Playlist)
+	}
+	if play.PlayItem >= len(playlist.PlayList.PlayItems) {
+		return fmt.Errorf("%w: %s has no PlayItem %d", ErrNoPlaylist, play.Playlist, play.PlayItem)
+	}
+	if play.Mark >= 0 && (playlist.Marks == nil || play.Mark >= len(playlist.Marks.Marks)) {
+		return fmt.Errorf("%w: %s has no mark %d", ErrNoPlaylist, play.Playlist, play.Mark)
+	}
+	play.Duration = playlist.Duration()
+	return nil
+}
+
+// state hashes where the run is and every register.
+func (r *run) state() uint64 {
+	hash := fnv.New64a()
+	binary.Write(hash, binary.BigEndian, [2]uint32{uint32(r.object), uint32(r.pc)})
+	binary.Write(hash, binary.BigEndian, r.registers)
+	return hash.Sum64()
+}
+
+func (r *run) compare(nav *mobj.NavigationCommand, dst, src mobj.Operand) error {
+	a, b := r.read(dst), r.read(src)
+	var result bool
+	switch nav.CompareOption {
+	case mobj.MOBJ_COMPARE_OPTION_BC:
+		result = a&b != 0
+	case mobj.MOBJ_COMPARE_OPTION_EQ:
+		result = a == b
+	case mobj.MOBJ_COMPARE_OPTION_NE:
+		result = a != b
+	case mobj.MOBJ_COMPARE_OPTION_GE:
+		result = a >= b
+	case mobj.MOBJ_COMPARE_OPTION_GT:
+		result = a > b
+	case mobj.MOBJ_COMPARE_OPTION_LE:
+		result = a <= b
+	case mobj.MOBJ_COMPARE_OPTION_LT:
+		result = a < b
+	default:
+		return fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+	}
+	if !result {
+		r.pc++
+	}
+	return nil
+}
+
+// set runs the arithmetic commands. Results saturate at 0 and
+// 0xFFFFFFFF; dividing by 0 gives 0xFFFFFFFF.
+func (r *run) set(nav *mobj.NavigationCommand, dst, src mobj.Operand) error {
+	a, b := r.read(dst), r.read(src)
+	var value uint32
+	switch nav.SetOption {
+	case mobj.MOBJ_SET_OPTION_SUB0_MOVE:
+		value = b
+	case mobj.MOBJ_SET_OPTION_SUB0_SWAP:
+		r.write(src, a)
+		value = b
+	case mobj.MOBJ_SET_OPTION_SUB0_ADD:
+		value = uint32(min(uint64(a)+uint64(b), 0xFFFFFFFF))
+	case mobj.MOBJ_SET_OPTION_SUB0_SUB:
+		value = a - min(a, b)
+	case mobj.MOBJ_SET_OPTION_SUB0_MUL:
+		value = uint32(min(uint64(a)*uint64(b), 0xFFFFFFFF))
+	case mobj.MOBJ_SET_OPTION_SUB0_DIV, mobj.MOBJ_SET_OPTION_SUB0_MOD:
+		switch {
+		case b == 0:
+			value = 0xFFFFFFFF
+		case nav.SetOption == mobj.MOBJ_SET_OPTION_SUB0_DIV:
+			value = a / b
+		default:
+			value = a % b
+		}
+	case mobj.MOBJ_SET_OPTION_SUB0_RND:
+		if b > 0 {
+			value = r.random.Uint32N(b) + 1
+		}
+	case mobj.MOBJ_SET_OPTION_SUB0_AND:
+		value = a & b
+	case mobj.MOBJ_SET_OPTION_SUB0_OR:
+		value = a | b
+	case mobj.MOBJ_SET_OPTION_SUB0_XOR:
+		value = a ^ b
+	case mobj.MOBJ_SET_OPTION_SUB0_BITSET:
+		value = a | 1<<(b&0x1F)
+	case mobj.MOBJ_SET_OPTION_SUB0_BITCLR:
+		value = a &^ (1 << (b & 0x1F))
+	case mobj.MOBJ_SET_OPTION_SUB0_SHIFTLEFT:
+		value = a << min(b, 32)
+	case mobj.MOBJ_SET_OPTION_SUB0_SHIFTRIGHT:
+		value = a >> min(b, 32)
+	default:
+		return fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+	}
+	r.write(dst, value)
+	return nil
+}
+
+// setSystem runs the set system commands that change a PSR. The others,
+// such as popup_off or still_on, only matter to a player's display.
+func (r *run) setSystem(nav *mobj.NavigationCommand, dst, src mobj.Operand) error {
+	psr := &r.registers.PSR
+	switch nav.SetOption {
+	case mobj.MOBJ_SET_OPTION_SUB1_SETSTREAM:
+		if value, ok := r.field(nav.Destination, nav.ImmediateValueFlagDest, 31, 16, 0xFFF); ok {
+			psr[PSRPrimaryAudio] = value
+		}
+		if value, ok := r.field(nav.Destination, nav.ImmediateValueFlagDest, 15, 0, 0xFFF); ok {
+			psr[PSRPGStream] = psr[PSRPGStream]&^0x80000FFF | value | nav.Destination<<17&0x80000000
+		}
+		if value, ok := r.field(nav.Source, nav.ImmediateValueFlagSrc, 31, 16, 0xFF); ok {
+			psr[PSRIGStream] = value
+		}
+		if value, ok := r.field(nav.Source, nav.ImmediateValueFlagSrc, 15, 0, 0xFF); ok {
+			psr[PSRAngle] = value
+		}
+	case mobj.MOBJ_SET_OPTION_SUB1_SETSECONDARYSTREAM:
+		if value, ok := r.field(nav.Destination, nav.ImmediateValueFlagDest, 31, 16, 0xFF); ok {
+			psr[PSRSecondaryStream] = psr[PSRSecondaryStream]&^0x8000FF00 | value<<8 | nav.Destination<<1&0x80000000
+		}
+		if value, ok := r.field(nav.Destination, nav.ImmediateValueFlagDest, 15, 0, 0xFF); ok {
+			psr[PSRSecondaryStream] = psr[PSRSecondaryStream]&^0xFF | value
+		}
+		if value, ok := r.field(nav.Source, nav.ImmediateValueFlagSrc, 31, 16, 0xFF); ok {
+			psr[PSRPGStream] = psr[PSRPGStream]&^0x4FFF0000 | value<<16 | nav.Source&0x40000000
+		}
+	case mobj.MOBJ_SET_OPTION_SUB1_BUTTONPAGE:
+		if value, ok := r.field(nav.Destination, nav.ImmediateValueFlagDest, 31, 0, 0xFFFF); ok {
+			psr[PSRSelectedButton] = value
+		}
+		if value, ok := r.field(nav.Source, nav.ImmediateValueFlagSrc, 31, 0, 0xFF); ok {
+			psr[PSRMenuPage] = value
+		}
+	case mobj.MOBJ_SET_OPTION_SUB1_SETNVTIMER:
+		psr[PSRNavTimer] = r.read(src)
+	case mobj.MOBJ_SET_OPTION_SUB1_ENABLEBUTTON, mobj.MOBJ_SET_OPTION_SUB1_DISABLEBUTTON,
+		mobj.MOBJ_SET_OPTION_SUB1_POPUPOFF, mobj.MOBJ_SET_OPTION_SUB1_STILLON, mobj.MOBJ_SET_OPTION_SUB1_STILLOFF,
+		mobj.MOBJ_SET_OPTION_SUB1_OUTPUTMODE, mobj.MOBJ_SET_OPTION_SUB1_STREAMSS:
+	default:
+		return fmt.Errorf("%w: %s", ErrCommand, nav.Disassemble())
+	}
+	return nil
+}
+
+// field reads one flagged number packed into a set_stream style operand:
+// the number itself when immediate, otherwise the GPR it names.
+func (r *run) field(operand uint32, immediate bool, flagBit, shift uint, mask uint32) (uint32, bool) {
+	if operand&(1<<flagBit) == 0 {
+		return 0, false
+	}
+	value := operand >> shift & mask
+	if !immediate {
+		value = r.registers.GPR[value&0xFFF] & mask
+	}
+	return value, true
+}
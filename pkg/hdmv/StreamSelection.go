@@ -0,0 +1,293 @@
+package hdmv
+
+/*
+	Remarks:
+
+	A player picks the streams of a PlayItem from its STN table when
+	the stream PSRs hold no valid choice, as they do at start. The rules
+	follow the BD-ROM player model:
+
+	Primary audio (PSR 1). A stream is judged on three conditions:
+
+		A  the player can decode it: PSR 15, see below
+		B  its language is the audio language of PSR 16
+		C  a multi-channel stream plays as surround; always true for
+		   mono and stereo
+
+	The first stream meeting A, B and C wins, else the first meeting A
+	and B, then A and C, then A alone. Without a playable stream no
+	audio is selected.
+
+	PG and text subtitles (PSR 2). PG is always playable; text subtitles
+	need bit 0 of PSR 30. The first playable stream in the subtitle
+	language of PSR 17 is selected and displayed. Failing that the
+	first playable stream is selected with the display off, so that only
+	its forced captions show.
+
+	IG (PSR 0). The first stream in the menu language of PSR 18, else
+	the first stream.
+
+	Secondary audio and video (PSR 14). The first secondary audio stream
+	the player can decode that may be mixed with the selected primary
+	audio, and the first secondary video stream. Both are selected but
+	not shown until the user or a program turns them on.
+
+	3D. A player set to 3D output, bit 0 of PSR 21, with a 3D capable
+	display, bit 0 of PSR 24, plays the dependent view of the PlayItem's
+	MVC extension. The 3D subtitle and menu entries of that extension are
+	not decoded, so 3D plays the 2D PG and IG choices.
+
+	PSR 15 holds two bits per audio format, 01 for stereo and 10 for
+	surround output:
+
+		bits  0-1   LPCM 48 and 96 kHz
+		bits  2-3   LPCM 192 kHz
+		bits  4-5   Dolby Digital Plus
+		bits  6-7   Dolby Digital Plus, dependent stream
+		bits  8-9   DTS-HD core
+		bits 10-11  DTS-HD extension
+		bits 12-13  Dolby lossless
+		bits 14-15  Dolby Digital
+
+	Dolby TrueHD falls back on its Dolby Digital core and DTS-HD on its
+	DTS core when the player lacks the extension.
+*/
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// StreamChoice is a stream selected from one list of an STN table.
+type StreamChoice struct {
+	Number int // From 1, as held in its PSR
+	Stream *mpls.Stream
+	Reason string // The rule that selected it
+}
+
+// StreamSelection is what a player plays of a PlayItem by default.
+// A nil choice means the list has no stream the player can use.
+type StreamSelection struct {
+	PrimaryAudio   *StreamChoice
+	PG             *StreamChoice // PG or text subtitles
+	PGDisplay      bool          // False: only forced captions show
+	IG             *StreamChoice
+	SecondaryAudio *StreamChoice // Selected, but not mixed in
+	SecondaryVideo *StreamChoice // Selected, but not shown
+	DependentView  *mpls.MVCStream
+}
+
+// SelectStreams returns the streams a player with the given registers
+// selects from a PlayItem's STN table. mvc, which may be nil, is the
+// PlayItem's entry of the playlist's MVC extension.
+func SelectStreams(table *mpls.StreamTable, mvc *mpls.MVCStream, registers *Registers) *StreamSelection {
+	selection := &StreamSelection{}
+	if table == nil {
+		return selection
+	}
+	psr := &registers.PSR
+
+	selection.PrimaryAudio = selectPrimaryAudio(streamsOf(table, mpls.STREAM_TYPE_PRIMARY_AUDIO), psr)
+	selection.PG, selection.PGDisplay = selectPG(streamsOf(table, mpls.STREAM_TYPE_PG), psr)
+	selection.IG = selectByLanguage(streamsOf(table, mpls.STREAM_TYPE_IG), psr[PSRMenuLanguage], "menu language")
+	selection.SecondaryAudio = selectSecondaryAudio(streamsOf(table, mpls.STREAM_TYPE_SECONDARY_AUDIO), selection.PrimaryAudio, psr)
+	if streams := streamsOf(table, mpls.STREAM_TYPE_SECONDARY_VIDEO); len(streams) > 0 {
+		selection.SecondaryVideo = &StreamChoice{Number: 1, Stream: streams[0], Reason: "first"}
+	}
+	if mvc != nil && psr[PSROutputModePreference]&1 != 0 && psr[PSR3DCapability]&1 != 0 {
+		selection.DependentView = mvc
+	}
+	return selection
+}
+
+// Apply writes the selection to the stream PSRs, as a player does when
+// it starts the PlayItem.
+func (selection *StreamSelection) Apply(registers *Registers) {
+	psr := &registers.PSR
+	psr[PSRPrimaryAudio] = choiceNumber(selection.PrimaryAudio, 0xFF)
+	psr[PSRPGStream] = psr[PSRPGStream]&^0x80000FFF | choiceNumber(selection.PG, 0xFFF)
+	if selection.PGDisplay {
+		psr[PSRPGStream] |= 0x80000000
+	}
+	psr[PSRIGStream] = choiceNumber(selection.IG, 0xFF)
+	psr[PSRSecondaryStream] = psr[PSRSecondaryStream]&^0xC000FFFF |
+		choiceNumber(selection.SecondaryVideo, 0xFF)<<8 |
+		choiceNumber(selection.SecondaryAudio, 0xFF)
+}
+
+func choiceNumber(choice *StreamChoice, none uint32) uint32 {
+	if choice == nil {
+		return none
+	}
+	return uint32(choice.Number)
+}
+
+func streamsOf(table *mpls.StreamTable, kind mpls.StreamTypeKindOf) []*mpls.Stream {
+	for _, item := range table.Items {
+		if item.KindOf == kind {
+			return item.Streams
+		}
+	}
+	return nil
+}
+
+func selectPrimaryAudio(streams []*mpls.Stream, psr *[NumberOfPSRs]uint32) *StreamChoice {
+	// Best first, by the conditions of the Remarks
+	rules := []struct {
+		language, surround bool
+		reason             string
+	}{
+		{true, true, "audio language and surround"},
+		{true, false, "audio language"},
+		{false, true, "surround"},
+		{false, false, "playable"},
+	}
+	for _, rule := range rules {
+		for i, stream := range streams {
+			attr := audioAttributes(stream)
+			if attr == nil {
+				continue
+			}
+			playable, surround := audioCapability(attr, psr[PSRAudioCapability])
+			if !playable {
+				continue
+			}
+			if rule.language && !languageIs(attr.LanguageCode, psr[PSRAudioLanguage]) {
+				continue
+			}
+			if rule.surround && isMultiChannel(attr) && !surround {
+				continue
+			}
+			return &StreamChoice{Number: i + 1, Stream: stream, Reason: rule.reason}
+		}
+	}
+	return nil
+}
+
+func selectPG(streams []*mpls.Stream, psr *[NumberOfPSRs]uint32) (*StreamChoice, bool) {
+	var playable []int
+	for i, stream := range streams {
+		if language := graphicsLanguage(stream); language != nil && pgPlayable(stream, psr) {
+			playable = append(playable, i)
+		}
+	}
+
+	for _, i := range playable {
+		if languageIs(*graphicsLanguage(streams[i]), psr[PSRPGLanguage]) {
+			return &StreamChoice{Number: i + 1, Stream: streams[i], Reason: "subtitle language"}, true
+		}
+	}
+	if len(playable) > 0 {
+		return &StreamChoice{Number: playable[0] + 1, Stream: streams[playable[0]], Reason: "first playable, forced captions only"}, false
+	}
+	return nil, false
+}
+
+func pgPlayable(stream *mpls.Stream, psr *[NumberOfPSRs]uint32) bool {
+	if _, ok := stream.Attr.(*mpls.TextAttributes); ok {
+		return psr[PSRTextCapability]&1 != 0
+	}
+	return true
+}
+
+func selectByLanguage(streams []*mpls.Stream, language uint32, reason string) *StreamChoice {
+	for i, stream := range streams {
+		if code := graphicsLanguage(stream); code != nil && languageIs(*code, language) {
+			return &StreamChoice{Number: i + 1, Stream: stream, Reason: reason}
+		}
+	}
+	if len(streams) > 0 {
+		return &StreamChoice{Number: 1, Stream: streams[0], Reason: "first"}
+	}
+	return nil
+}
+
+func selectSecondaryAudio(streams []*mpls.Stream, audio *StreamChoice, psr *[NumberOfPSRs]uint32) *StreamChoice {
+	if audio == nil {
+		return nil
+	}
+	for i, stream := range streams {
+		attr, ok := stream.Attr.(*mpls.SecondaryAudioAttributes)
+		if !ok {
+			continue
+		}
+		// The references hold primary audio stream numbers from 0.
+		if !slices.Contains(attr.PrimaryAudioRefs, uint8(audio.Number-1)) {
+			continue
+		}
+		if playable, _ := audioCapability(&attr.PrimaryAudioAttributes, psr[PSRAudioCapability]); playable {
+			return &StreamChoice{Number: i + 1, Stream: stream, Reason: "mixes with primary audio"}
+		}
+	}
+	return nil
+}
+
+func audioAttributes(stream *mpls.Stream) *mpls.PrimaryAudioAttributes {
+	switch attr := stream.Attr.(type) {
+	case *mpls.PrimaryAudioAttributes:
+		return attr
+	case *mpls.SecondaryAudioAttributes:
+		return &attr.PrimaryAudioAttributes
+	}
+	return nil
+}
+
+func graphicsLanguage(stream *mpls.Stream) *[3]byte {
+	switch attr := stream.Attr.(type) {
+	case *mpls.PGAttributes:
+		return &attr.LanguageCode
+	case *mpls.IGAttributes:
+		return &attr.LanguageCode
+	case *mpls.TextAttributes:
+		return &attr.LanguageCode
+	}
+	return nil
+}
+
+func isMultiChannel(attr *mpls.PrimaryAudioAttributes) bool {
+	return attr.Format == mpls.AUDIO_FORMAT_MULTICHANNEL || attr.Format == mpls.AUDIO_FORMAT_COMBO
+}
+
+// audioCapability reports whether a player with the PSR 15 capability
+// decodes the stream, and whether it outputs it as surround.
+func audioCapability(attr *mpls.PrimaryAudioAttributes, capability uint32) (playable, surround bool) {
+	bits := func(shift uint) uint32 { return capability >> shift & 0x3 }
+
+	var pair uint32
+	switch attr.StreamCodingType {
+	case mpls.STREAM_TYPE_AUDIO_LPCM:
+		pair = bits(0)
+		if attr.Rate == mpls.AUDIO_RATE_192kHZ {
+			pair = bits(2)
+		}
+	case mpls.STREAM_TYPE_AUDIO_AC3PLUS:
+		pair = bits(4)
+	case mpls.STREAM_TYPE_AUDIO_AC3PLUS_SECONDARY:
+		pair = bits(6)
+	case mpls.STREAM_TYPE_AUDIO_DTS:
+		pair = bits(8)
+	case mpls.STREAM_TYPE_AUDIO_DTSHD, mpls.STREAM_TYPE_AUDIO_DTSHD_MASTER, mpls.STREAM_TYPE_AUDIO_DTSHD_SECONDARY:
+		if pair = bits(10); pair == 0 {
+			pair = bits(8)
+		}
+	case mpls.STREAM_TYPE_AUDIO_TRUHD:
+		if pair = bits(12); pair == 0 {
+			pair = bits(14)
+		}
+	case mpls.STREAM_TYPE_AUDIO_AC3:
+		pair = bits(14)
+	}
+	return pair != 0, pair&0x2 != 0
+}
+
+// languageIs reports whether code is the language packed into a PSR.
+// An unset PSR, 0xFFFFFF, matches nothing.
+func languageIs(code [3]byte, psr uint32) bool {
+	if psr == 0xFFFFFF {
+		return false
+	}
+	packed := []byte{byte(psr >> 16), byte(psr >> 8), byte(psr)}
+	return bytes.EqualFold(code[:], packed)
+}
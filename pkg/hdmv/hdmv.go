@@ -0,0 +1,113 @@
+package hdmv
+
+/*
+	Remarks:
+
+	Package hdmv simulates the HDMV navigation of a disc: the movie
+	objects of MovieObject.bdmv, started from the titles of index.bdmv,
+	running against the player's register files.
+
+		GPR  4096 general purpose registers, all 0 at start
+		PSR   128 player status registers, set from the player settings
+
+	Branch commands move between objects and titles, compare commands
+	skip the next command when false, and set commands change GPRs and,
+	through set_stream and friends, the stream PSRs. A program may not
+	write a PSR with the arithmetic commands; such writes are ignored.
+
+	A play command is taken to play its playlist to the end, after which
+	the movie object carries on with the next command, as on a player
+	nobody touches. The playlists are looked up to check the PlayItem or
+	mark number and to give each play a duration.
+
+	A run ends when:
+
+		stop       the object runs off its last command or breaks
+		bd-j       a jump or call reaches a BD-J title
+		loop       a play command is reached again with the same registers,
+		           e.g. a menu waiting for input
+		step limit more than MaxSteps commands ran without a repeat
+		error      a command refers to an object, title or playlist that
+		           does not exist
+
+	Interactive graphics button commands live in the IG stream, not in
+	MovieObject.bdmv, so menu selections are not simulated. The rnd
+	command uses a fixed seed so runs are repeatable.
+*/
+
+import (
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// MaxSteps is the default limit on the commands one run executes.
+const MaxSteps = 10000
+
+// Object types of an index.bdmv title.
+const (
+	ObjectTypeHDMV uint8 = 1
+	ObjectTypeBDJ  uint8 = 2
+)
+
+// Special values of PSR 4, the title number.
+const (
+	TitleTopMenu       uint32 = 0
+	TitleFirstPlayback uint32 = 0xFFFF
+)
+
+// Playlist is one PLAYLIST/xxxxx.mpls file, as far as the VM needs it.
+type Playlist struct {
+	PlayList *mpls.PlayList
+	Marks    *mpls.PlaylistMarks
+}
+
+// Duration returns the sum of the IN/OUT span of every PlayItem.
+func (playlist *Playlist) Duration() (ticks clock.Ticks45k) {
+	if playlist.PlayList == nil {
+		return 0
+	}
+	for _, playItem := range playlist.PlayList.PlayItems {
+		if playItem.OUTTime > playItem.INTime {
+			ticks = ticks.Add(playItem.OUTTime.Sub(playItem.INTime))
+		}
+	}
+	return ticks
+}
+
+// VM runs the movie objects of one disc. Each Run starts from a fresh
+// set of registers made from Settings.
+type VM struct {
+	Indexes      *indx.Indexes
+	MovieObjects *mobj.MovieObjects
+	Playlists    map[string]*Playlist // Keyed by 5-digit name, e.g. "00800"
+	Settings     *Settings
+	MaxSteps     int
+}
+
+// New returns a VM for a disc. The settings are checked here so that
+// every run can use them.
+func New(indexes *indx.Indexes, movieObjects *mobj.MovieObjects, playlists map[string]*Playlist, settings *Settings) (*VM, error) {
+	if indexes == nil {
+		return nil, fmt.Errorf("no index.bdmv indexes")
+	}
+	if movieObjects == nil {
+		return nil, fmt.Errorf("no MovieObject.bdmv movie objects")
+	}
+	if settings == nil {
+		settings = &Settings{}
+	}
+	if _, err := settings.Registers(); err != nil {
+		return nil, err
+	}
+	return &VM{
+		Indexes:      indexes,
+		MovieObjects: movieObjects,
+		Playlists:    playlists,
+		Settings:     settings,
+		MaxSteps:     MaxSteps,
+	}, nil
+}
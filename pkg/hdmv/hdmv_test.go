@@ -0,0 +1,350 @@
+package hdmv
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+	"github.com/parasense/bdmv_go/pkg/indx"
+	"github.com/parasense/bdmv_go/pkg/mobj"
+	"github.com/parasense/bdmv_go/pkg/mpls"
+)
+
+// cmd builds a command from its opcode word.
+func cmd(opcode, dst, src uint32) *mobj.NavigationCommand {
+	return &mobj.NavigationCommand{
+		OperandCount:           uint8(opcode >> 29),
+		CommandGroup:           uint8(opcode >> 27 & 0x03),
+		CommandSubGroup:        uint8(opcode >> 24 & 0x07),
+		ImmediateValueFlagDest: opcode&0x00800000 != 0,
+		ImmediateValueFlagSrc:  opcode&0x00400000 != 0,
+		BranchOption:           uint8(opcode >> 16 & 0x0F),
+		CompareOption:          uint8(opcode >> 8 & 0x0F),
+		SetOption:              uint8(opcode & 0x1F),
+		Destination:            dst,
+		Source:                 src,
+	}
+}
+
+func object(commands ...*mobj.NavigationCommand) *mobj.MovieObject {
+	return &mobj.MovieObject{NavigationCommands: commands}
+}
+
+func hdmvTitle(id uint16) *indx.Title {
+	return &indx.Title{ObjectType: ObjectTypeHDMV, RefToMovieObjectID: id}
+}
+
+func playlist(seconds uint32, marks int) *Playlist {
+	return &Playlist{
+		PlayList: &mpls.PlayList{PlayItems: []*mpls.PlayItem{{OUTTime: clock.Ticks45k(seconds * 45000)}}},
+		Marks:    &mpls.PlaylistMarks{Marks: make([]*mpls.MarkEntry, marks)},
+	}
+}
+
+func testVM(t *testing.T, settings *Settings) *VM {
+	t.Helper()
+	indexes := &indx.Indexes{
+		FirstPlaybackTitle: hdmvTitle(0),
+		TopMenuTitle:       hdmvTitle(2),
+		Titles: []*indx.Title{
+			hdmvTitle(1),
+			{ObjectType: ObjectTypeBDJ, RefToBDJObjectID: [5]byte{'0', '0', '0', '0', '1'}},
+			hdmvTitle(4),
+			hdmvTitle(5),
+		},
+	}
+	movieObjects := &mobj.MovieObjects{MovieObjects: []*mobj.MovieObject{
+		// First Playback: a warning in the audio language, then title 1.
+		object(
+			cmd(0x48400300, 0x80000000|PSRAudioLanguage, 0x667261), // if PSR[16] != "fra"
+			cmd(0x20810000, 4, 0), // goto 4
+			cmd(0x22800000, 2, 0), // play_pl 00002
+			cmd(0x21810000, 1, 0), // jump_title 1
+			cmd(0x22800000, 1, 0), // play_pl 00001
+			cmd(0x21810000, 1, 0), // jump_title 1
+		),
+		// Title 1: count down, call object 3, then the feature.
+		object(
+			cmd(0x50400001, 0, 3),   // mov GPR[0], 3
+			cmd(0x50400004, 0, 1),   // sub GPR[0], 1
+			cmd(0x48400300, 0, 0),   // if GPR[0] != 0
+			cmd(0x20810000, 1, 0),   // goto 1
+			cmd(0x21820000, 3, 0),   // call_object 3
+			cmd(0x42C20000, 800, 1), // play_pl_pm 00800, 1
+			cmd(0x00020000, 0, 0),   // break
+			cmd(0x22800000, 1, 0),   // play_pl 00001, never reached
+		),
+		// Top menu: a menu playlist over and over.
+		object(
+			cmd(0x22800000, 1, 0), // play_pl 00001
+			cmd(0x20810000, 0, 0), // goto 0
+		),
+		// Called by title 1.
+		object(
+			cmd(0x50000001, 1, 0x80000000|PSRTitle), // mov GPR[1], PSR[4]
+			cmd(0x50400001, 0x80000000|PSRTitle, 7), // mov PSR[4], 7, ignored
+			cmd(0x01040000, 0, 0),                   // resume
+		),
+		object(cmd(0x22800000, 99, 0)), // Title 3: play_pl 00099
+		object(cmd(0x20810000, 0, 0)),  // Title 4: goto 0
+		object(cmd(0x00000000, 0, 0)),  // Not referenced: nop
+	}}
+	playlists := map[string]*Playlist{
+		"00001": playlist(10, 0),
+		"00002": playlist(12, 0),
+		"00800": playlist(5400, 2),
+	}
+	vm, err := New(indexes, movieObjects, playlists, settings)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return vm
+}
+
+func playlists(result *Result) (names []string) {
+	for _, play := range result.Plays {
+		names = append(names, play.Playlist)
+	}
+	return names
+}
+
+func TestRun(t *testing.T) {
+	t.Run("first playback", func(t *testing.T) {
+		result := testVM(t, nil).RunFirstPlayback()
+		if result.End != EndStop || result.Err != nil {
+			t.Fatalf("End = %s, Err = %v", result.End, result.Err)
+		}
+		if got := playlists(result); len(got) != 2 || got[0] != "00001" || got[1] != "00800" {
+			t.Errorf("plays = %v, want [00001 00800]", got)
+		}
+		if feature := result.Feature(); feature == nil || feature.Playlist != "00800" || feature.Mark != 1 || feature.Title != 1 {
+			t.Errorf("Feature() = %+v", feature)
+		}
+		if result.Registers.GPR[0] != 0 || result.Registers.GPR[1] != 1 {
+			t.Errorf("GPR[0], GPR[1] = %d, %d, want 0, 1", result.Registers.GPR[0], result.Registers.GPR[1])
+		}
+		if result.Registers.PSR[PSRTitle] != 1 || result.Registers.PSR[PSRBackup] != 1 {
+			t.Errorf("PSR[4], PSR[36] = %d, %d, want 1, 1", result.Registers.PSR[PSRTitle], result.Registers.PSR[PSRBackup])
+		}
+	})
+
+	t.Run("audio language", func(t *testing.T) {
+		result := testVM(t, &Settings{AudioLanguage: "FRA"}).RunFirstPlayback()
+		if got := playlists(result); len(got) != 2 || got[0] != "00002" {
+			t.Errorf("plays = %v, want [00002 00800]", got)
+		}
+	})
+
+	t.Run("menu loop", func(t *testing.T) {
+		result := testVM(t, nil).RunTopMenu()
+		if result.End != EndLoop || len(result.Plays) != 1 {
+			t.Errorf("End = %s with %d plays, want loop with 1", result.End, len(result.Plays))
+		}
+	})
+
+	t.Run("BD-J", func(t *testing.T) {
+		result := testVM(t, nil).RunTitle(2)
+		if result.End != EndBDJ || result.BDJObject != "00001" {
+			t.Errorf("End = %s, BDJObject = %q", result.End, result.BDJObject)
+		}
+	})
+
+	t.Run("missing playlist", func(t *testing.T) {
+		result := testVM(t, nil).RunTitle(3)
+		var commandErr *CommandError
+		if result.End != EndError || !errors.Is(result.Err, ErrNoPlaylist) || !errors.As(result.Err, &commandErr) || commandErr.Object != 4 {
+			t.Errorf("End = %s, Err = %v", result.End, result.Err)
+		}
+	})
+
+	t.Run("step limit", func(t *testing.T) {
+		vm := testVM(t, nil)
+		vm.MaxSteps = 50
+		if result := vm.RunTitle(4); result.End != EndStepLimit || len(result.Trace) != 50 {
+			t.Errorf("End = %s after %d steps", result.End, len(result.Trace))
+		}
+	})
+
+	t.Run("no title", func(t *testing.T) {
+		if result := testVM(t, nil).RunTitle(9); !errors.Is(result.Err, ErrNoTitle) {
+			t.Errorf("Err = %v, want ErrNoTitle", result.Err)
+		}
+	})
+}
+
+func TestSettings(t *testing.T) {
+	level := uint32(4)
+	registers, err := (&Settings{
+		AudioLanguage: "jpn",
+		CountryCode:   "US",
+		RegionCode:    RegionB,
+		ParentalLevel: &level,
+		PSRs:          map[int]uint32{15: 0x1},
+	}).Registers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for psr, want := range map[int]uint32{
+		PSRAudioLanguage: 0x6A706E,
+		PSRPGLanguage:    0xFFFFFF,
+		PSRCountryCode:   0x7573,
+		PSRRegionCode:    RegionB,
+		PSRParentalLevel: 4,
+		15:               1,
+	} {
+		if got := registers.PSR[psr]; got != want {
+			t.Errorf("PSR[%d] = 0x%X, want 0x%X", psr, got, want)
+		}
+	}
+
+	if _, err := (&Settings{MenuLanguage: "english"}).Registers(); err == nil {
+		t.Error("Registers() accepted a 7-letter language code")
+	}
+}
+
+func TestGraph(t *testing.T) {
+	vm := testVM(t, nil)
+	graph := BuildGraph(vm.Indexes, vm.MovieObjects)
+
+	// Title 1 splits at the countdown loop, the call, the play and the break.
+	var starts []int
+	for _, block := range graph.Objects[1] {
+		starts = append(starts, block.Start)
+	}
+	if want := []int{0, 1, 3, 4, 5, 6, 7}; !slices.Equal(starts, want) {
+		t.Errorf("title 1 blocks start at %v, want %v", starts, want)
+	}
+	compare := graph.Objects[1][1]
+	if len(compare.Edges) != 2 || compare.Edges[0].Kind != EdgeTrue || compare.Edges[0].Block.Start != 3 ||
+		compare.Edges[1].Kind != EdgeFalse || compare.Edges[1].Block.Start != 4 {
+		t.Errorf("compare edges = %+v", compare.Edges)
+	}
+	call := graph.Objects[1][3]
+	if len(call.Edges) != 2 || call.Edges[0].Kind != EdgeCall || call.Edges[0].Block != graph.Objects[3][0] {
+		t.Errorf("call edges = %+v", call.Edges)
+	}
+	if jump := graph.Objects[0][3].Edges[0]; jump.Kind != EdgeJump || jump.Title != graph.Titles[2] {
+		t.Errorf("jump_title edge = %+v", jump)
+	}
+
+	if want := []string{"00001", "00002", "00099", "00800"}; !slices.Equal(graph.Playlists(), want) {
+		t.Errorf("Playlists() = %v, want %v", graph.Playlists(), want)
+	}
+	if len(graph.Unreachable) != 1 || graph.Unreachable[0] != (Step{1, 7}) {
+		t.Errorf("Unreachable = %v, want [{1 7}]", graph.Unreachable)
+	}
+	// The countdown and the menu loop can be left or play; title 4 cannot.
+	if len(graph.Loops) != 1 || len(graph.Loops[0]) != 1 || graph.Loops[0][0] != graph.Objects[5][0] {
+		t.Errorf("Loops = %v, want title 4's goto", graph.Loops)
+	}
+	if !slices.Equal(graph.UnreferencedObjects, []int{6}) {
+		t.Errorf("UnreferencedObjects = %v, want [6]", graph.UnreferencedObjects)
+	}
+
+	out := &bytes.Buffer{}
+	if err := graph.WriteDOT(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"title_first_playback -> o0_c0;",
+		`o1_c1 -> o1_c4 [label="false", style=dashed];`,
+		`o0_c3 -> title_1 [label="jump", style=bold];`,
+		`o2_c0 -> playlist_00001 [label="play", color=blue];`,
+		`title_2 -> bdj_00001;`,
+		"o5_c0 [label=\"0000  goto 0\\l\", color=red];",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("DOT output lacks %s", want)
+		}
+	}
+}
+
+func audio(coding mpls.StreamCodingType, format mpls.AudioFormatType, language string) *mpls.Stream {
+	attr := &mpls.PrimaryAudioAttributes{Format: format, Rate: mpls.AUDIO_RATE_48kHZ}
+	attr.StreamCodingType = coding
+	copy(attr.LanguageCode[:], language)
+	return &mpls.Stream{Attr: attr}
+}
+
+func subtitle(coding mpls.StreamCodingType, language string) *mpls.Stream {
+	graphics := mpls.GraphicsAttributes{}
+	graphics.StreamCodingType = coding
+	copy(graphics.LanguageCode[:], language)
+	if coding == mpls.STREAM_TYPE_SUB_TEXT {
+		return &mpls.Stream{Attr: &mpls.TextAttributes{GraphicsAttributes: graphics}}
+	}
+	return &mpls.Stream{Attr: &mpls.PGAttributes{GraphicsAttributes: graphics}}
+}
+
+func TestSelectStreams(t *testing.T) {
+	secondary := &mpls.SecondaryAudioAttributes{
+		PrimaryAudioAttributes:        *audio(mpls.STREAM_TYPE_AUDIO_AC3PLUS_SECONDARY, mpls.AUDIO_FORMAT_STEREO, "eng").Attr.(*mpls.PrimaryAudioAttributes),
+		SecondaryAudioExtraAttributes: mpls.SecondaryAudioExtraAttributes{NumberOfPrimaryAudioRef: 1, PrimaryAudioRefs: []uint8{1}},
+	}
+	table := &mpls.StreamTable{Items: []*mpls.StreamItem{
+		{KindOf: mpls.STREAM_TYPE_PRIMARY_AUDIO, Streams: []*mpls.Stream{
+			audio(mpls.STREAM_TYPE_AUDIO_TRUHD, mpls.AUDIO_FORMAT_MULTICHANNEL, "eng"),
+			audio(mpls.STREAM_TYPE_AUDIO_AC3, mpls.AUDIO_FORMAT_STEREO, "eng"),
+			audio(mpls.STREAM_TYPE_AUDIO_DTS, mpls.AUDIO_FORMAT_MULTICHANNEL, "fra"),
+		}},
+		{KindOf: mpls.STREAM_TYPE_PG, Streams: []*mpls.Stream{
+			subtitle(mpls.STREAM_TYPE_SUB_TEXT, "deu"),
+			subtitle(mpls.STREAM_TYPE_SUB_PG, "eng"),
+			subtitle(mpls.STREAM_TYPE_SUB_PG, "fra"),
+		}},
+		{KindOf: mpls.STREAM_TYPE_SECONDARY_AUDIO, Streams: []*mpls.Stream{{Attr: secondary}}},
+	}}
+
+	tests := []struct {
+		name      string
+		settings  *Settings
+		audio, pg int // Stream numbers, 0 for none
+		display   bool
+		secondary int
+	}{
+		{"defaults", &Settings{}, 1, 1, false, 0},
+		{"french", &Settings{AudioLanguage: "fra", PGLanguage: "eng"}, 3, 2, true, 0},
+		{"stereo only", &Settings{AudioLanguage: "eng", PSRs: map[int]uint32{PSRAudioCapability: 0x5555}}, 2, 1, false, 1},
+		{"stereo without lossless", &Settings{AudioLanguage: "eng", PSRs: map[int]uint32{PSRAudioCapability: 0x8000}}, 1, 1, false, 0},
+		{"dts only", &Settings{AudioLanguage: "eng", PSRs: map[int]uint32{PSRAudioCapability: 0x0200}}, 3, 1, false, 0},
+		{"no audio", &Settings{PSRs: map[int]uint32{PSRAudioCapability: 0}}, 0, 1, false, 0},
+		{"text subtitles", &Settings{PGLanguage: "deu"}, 1, 1, true, 0},
+		{"no text subtitles", &Settings{PGLanguage: "deu", PSRs: map[int]uint32{PSRTextCapability: 0}}, 1, 2, false, 0},
+		{"truehd core in stereo", &Settings{AudioLanguage: "eng", PSRs: map[int]uint32{PSRAudioCapability: 0x4FFF}}, 2, 1, false, 1},
+	}
+	for _, test := range tests {
+		registers, err := test.settings.Registers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		selection := SelectStreams(table, nil, registers)
+		if got := choiceNumber(selection.PrimaryAudio, 0); got != uint32(test.audio) {
+			t.Errorf("%s: primary audio = %d (%v), want %d", test.name, got, selection.PrimaryAudio, test.audio)
+		}
+		if got := choiceNumber(selection.PG, 0); got != uint32(test.pg) || selection.PGDisplay != test.display {
+			t.Errorf("%s: PG = %d display %t, want %d display %t", test.name, got, selection.PGDisplay, test.pg, test.display)
+		}
+		if got := choiceNumber(selection.SecondaryAudio, 0); got != uint32(test.secondary) {
+			t.Errorf("%s: secondary audio = %d, want %d", test.name, got, test.secondary)
+		}
+	}
+
+	// The selection lands in the stream PSRs, and 3D needs the setting.
+	mvc := &mpls.MVCStream{}
+	registers, _ := (&Settings{PGLanguage: "eng"}).Registers()
+	selection := SelectStreams(table, mvc, registers)
+	selection.Apply(registers)
+	if registers.PSR[PSRPrimaryAudio] != 1 || registers.PSR[PSRPGStream] != 0x80000002 || registers.PSR[PSRIGStream] != 0xFF {
+		t.Errorf("Apply() PSRs 0-2 = %#x %#x %#x", registers.PSR[0], registers.PSR[1], registers.PSR[2])
+	}
+	if selection.DependentView != nil {
+		t.Error("2D player selected the dependent view")
+	}
+	registers, _ = (&Settings{Output3D: true}).Registers()
+	if SelectStreams(table, mvc, registers).DependentView != mvc {
+		t.Error("3D player did not select the dependent view")
+	}
+}
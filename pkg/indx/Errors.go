@@ -0,0 +1,18 @@
+package indx
+
+import (
+	"errors"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+var (
+	// ErrExtension is wrapped by errors from decoding an extension entry
+	// of a known type and version. Unknown entries are not an error; they
+	// are kept as an ExtensionRaw.
+	ErrExtension = errors.New("malformed extension data")
+)
+
+// ParseError reports where in a file parsing failed.
+// Use errors.Is on it to test for the sentinel errors above.
+type ParseError = navfile.ParseError
@@ -24,8 +24,10 @@ type HEVCEntry struct {
 // Read reads the ExtensionHEVC from the provided file.
 func (hevc *ExtensionHEVC) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
 
+	hevc.HEVCEntry = &HEVCEntry{}
+
 	// Jump to the start offset
-	if _, err := file.Seek(int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
+	if _, err := file.Seek(offsets.Start+int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", entryMeta.ExtDataStartAddress, err)
 	}
 
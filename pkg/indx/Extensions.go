@@ -33,7 +33,9 @@ func ReadExtensions(file io.ReadSeeker, offsets *OffsetsUint32) (extensions *Ext
 	}
 
 	// 12-bytes total
-	extensions.MetaData, err = ReadMetaData(file)
+	if extensions.MetaData, err = ReadMetaData(file); err != nil {
+		return nil, fmt.Errorf("failed calling ReadMetaData(): %w", err)
+	}
 
 	// Sanity check
 	// These should sum together to equal the EOF.
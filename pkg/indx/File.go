@@ -0,0 +1,57 @@
+package indx
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed index.bdmv file: the sections
+// ParseINDX returns one by one.
+type File struct {
+	Header     *INDXHeader
+	AppInfo    *AppInfo
+	Indexes    *Indexes
+	Extensions *Extensions
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *INDXHeader,
+	appInfo *AppInfo,
+	indexes *Indexes,
+	extensions *Extensions,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, appInfo, indexes, extensions}, nil
+}
+
+// ParseFile parses the index.bdmv file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseINDX(filePath))
+}
+
+// ParseFS parses the index.bdmv file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseINDXFS(fsys, name))
+}
+
+// ParseReader parses an index.bdmv file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseINDX(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as an index.bdmv file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses an index.bdmv file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
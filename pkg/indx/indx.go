@@ -2,7 +2,12 @@ package indx
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 func ParseINDX(filePath string) (
@@ -18,25 +23,53 @@ func ParseINDX(filePath string) (
 	}
 	defer file.Close()
 
+	return parseINDX(file, filePath)
+}
+
+// ParseINDXFS is ParseINDX for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseINDXFS(fsys fs.FS, name string) (
+	header *INDXHeader,
+	appinfo *AppInfo,
+	indexes *Indexes,
+	extensiondata *Extensions,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseINDX(file, name)
+}
+
+func parseINDX(file io.ReadSeeker, filePath string) (
+	header *INDXHeader,
+	appinfo *AppInfo,
+	indexes *Indexes,
+	extensiondata *Extensions,
+	err error,
+) {
 	// Header
 	if header, err = ReadINDXHeader(file); err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
 	}
 
 	// AppInfo
 	if appinfo, err = ReadAppInfo(file, header.AppInfo); err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("failed to read appinfo: %w", err)
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "AppInfo", header.AppInfo.Start, err)
 	}
 
 	// Indexes
 	if indexes, err = ReadIndexes(file, header.Indexes); err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("failed to read indexes: %w", err)
+		return nil, nil, nil, nil, navfile.SectionError(filePath, "Indexes", header.Indexes.Start, err)
 	}
 
 	// Extensions
 	if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
 		if extensiondata, err = ReadExtensions(file, header.Extensions); err != nil {
-			return nil, nil, nil, nil, fmt.Errorf("failed to read Extension Data: %w", err)
+			return nil, nil, nil, nil, navfile.SectionError(filePath, "Extensions", header.Extensions.Start, err)
 		}
 	}
 	return header, appinfo, indexes, extensiondata, nil
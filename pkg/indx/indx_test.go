@@ -0,0 +1,51 @@
+package indx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseINDXExtensions(t *testing.T) {
+	// The fixtures hold one extension entry of a type and version nobody
+	// knows, the second claiming more data than the extensions block holds.
+	tests := []struct {
+		name     string
+		filePath string
+		wantRaw  []byte      // Data of EntriesData[0], an ExtensionRaw
+		wantErr  *ParseError // Without its Err
+	}{
+		{
+			name:     "unknown extension",
+			filePath: "testdata/unknown_extension.bdmv",
+			wantRaw:  []byte{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "extension overrun",
+			filePath: "testdata/extension_overrun.bdmv",
+			wantErr:  &ParseError{File: "testdata/extension_overrun.bdmv", Offset: 156, Path: "Extensions.EntriesData[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, extensiondata, err := ParseINDX(tt.filePath)
+			if tt.wantErr != nil {
+				var parseErr *ParseError
+				if !errors.Is(err, ErrExtension) || !errors.As(err, &parseErr) {
+					t.Fatalf("ParseINDX() error = %v, want a ParseError wrapping ErrExtension", err)
+				}
+				if got := (ParseError{File: parseErr.File, Offset: parseErr.Offset, Path: parseErr.Path}); got != *tt.wantErr {
+					t.Errorf("ParseError = %+v, want %+v", got, *tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseINDX() error = %v", err)
+			}
+			raw, ok := extensiondata.EntriesData[0].(*ExtensionRaw)
+			if !ok || !bytes.Equal(raw.Data, tt.wantRaw) {
+				t.Errorf("EntriesData[0] = %v, want an ExtensionRaw of % x", extensiondata.EntriesData[0], tt.wantRaw)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package m2ts
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSync is wrapped when a transport packet does not start with the
+	// 0x47 sync byte, as in a stream that is encrypted or not BDAV.
+	ErrSync = errors.New("lost transport packet sync")
+
+	// ErrPSI is wrapped by errors from decoding a PAT or PMT section,
+	// including a CRC mismatch.
+	ErrPSI = errors.New("malformed PSI section")
+
+	// ErrPES is wrapped by errors from decoding a PES packet header.
+	ErrPES = errors.New("malformed PES packet")
+)
+
+// ParseError reports where in a stream reading failed.
+// Use errors.Is on it to test for the sentinel errors above.
+type ParseError struct {
+	File   string // Path of the file, empty when not read with Open
+	Offset int64  // Byte offset of the source packet that failed
+	Path   string // What was being decoded, e.g. "PES(0x1011)"
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s at offset %d: %v", e.Path, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("%s: %s at offset %d: %v", e.File, e.Path, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
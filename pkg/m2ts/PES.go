@@ -0,0 +1,118 @@
+package m2ts
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+// PES is one reassembled PES packet of an elementary stream.
+type PES struct {
+	PID              uint16
+	StreamType       uint8  // From the PMT
+	Offset           int64  // Byte offset of the source packet it starts in
+	ArrivalTimeStamp uint32 // Of that source packet
+	RandomAccess     bool   // random_access_indicator of that source packet
+	Discontinuity    bool   // A continuity_counter gap inside the packet
+
+	StreamID               uint8
+	PacketLength           uint16 // PES_packet_length; 0 for unbounded video
+	DataAlignmentIndicator bool
+	HasPTS                 bool
+	HasDTS                 bool
+	PTS                    clock.Ticks90k
+	DTS                    clock.Ticks90k
+	Data                   []byte // The elementary stream bytes after the header
+}
+
+// Stream IDs whose PES packets have no optional header.
+const (
+	streamIDProgramStreamMap       = 0xBC
+	streamIDPaddingStream          = 0xBE
+	streamIDPrivateStream2         = 0xBF
+	streamIDECM                    = 0xF0
+	streamIDEMM                    = 0xF1
+	streamIDDSMCC                  = 0xF2
+	streamIDH2221TypeE             = 0xF8
+	streamIDProgramStreamDirectory = 0xFF
+)
+
+// pesBuffer collects the payloads of one PES packet.
+type pesBuffer struct {
+	stream           *ElementaryStream
+	offset           int64
+	arrivalTimeStamp uint32
+	randomAccess     bool
+	discontinuity    bool
+	continuity       uint8
+	data             []byte
+}
+
+// complete reports whether the buffer holds all PES_packet_length bytes.
+func (b *pesBuffer) complete() bool {
+	if len(b.data) < 6 {
+		return false
+	}
+	length := int(binary.BigEndian.Uint16(b.data[4:6]))
+	return length != 0 && len(b.data) >= 6+length
+}
+
+func decodePES(data []byte) (*PES, error) {
+	if len(data) < 6 || data[0] != 0 || data[1] != 0 || data[2] != 1 {
+		return nil, fmt.Errorf("%w: no packet_start_code_prefix", ErrPES)
+	}
+	pes := &PES{
+		StreamID:     data[3],
+		PacketLength: binary.BigEndian.Uint16(data[4:6]),
+	}
+	if pes.PacketLength != 0 {
+		if len(data) < 6+int(pes.PacketLength) {
+			return nil, fmt.Errorf("%w: %d of %d bytes", ErrPES, len(data)-6, pes.PacketLength)
+		}
+		data = data[:6+int(pes.PacketLength)]
+	}
+
+	switch pes.StreamID {
+	case streamIDProgramStreamMap, streamIDPaddingStream, streamIDPrivateStream2, streamIDECM,
+		streamIDEMM, streamIDDSMCC, streamIDH2221TypeE, streamIDProgramStreamDirectory:
+		pes.Data = data[6:]
+		return pes, nil
+	}
+
+	if len(data) < 9 || data[6]>>6 != 0x02 {
+		return nil, fmt.Errorf("%w: bad optional header", ErrPES)
+	}
+	pes.DataAlignmentIndicator = data[6]&0x04 != 0
+	ptsDTSFlags := data[7] >> 6
+	headerLength := int(data[8])
+	if 9+headerLength > len(data) {
+		return nil, fmt.Errorf("%w: PES_header_data_length %d overruns the packet", ErrPES, headerLength)
+	}
+	header := data[9 : 9+headerLength]
+
+	switch ptsDTSFlags {
+	case 0x02:
+		if len(header) < 5 {
+			return nil, fmt.Errorf("%w: short PTS", ErrPES)
+		}
+		pes.HasPTS, pes.PTS = true, decodeTimestamp(header[0:5])
+	case 0x03:
+		if len(header) < 10 {
+			return nil, fmt.Errorf("%w: short PTS/DTS", ErrPES)
+		}
+		pes.HasPTS, pes.PTS = true, decodeTimestamp(header[0:5])
+		pes.HasDTS, pes.DTS = true, decodeTimestamp(header[5:10])
+	}
+
+	pes.Data = data[9+headerLength:]
+	return pes, nil
+}
+
+// decodeTimestamp decodes the 33-bit PTS or DTS spread over 5 bytes
+// with marker bits.
+func decodeTimestamp(b []byte) clock.Ticks90k {
+	return clock.Ticks90k(b[0]>>1&0x07)<<30 |
+		clock.Ticks90k(binary.BigEndian.Uint16(b[1:3])>>1)<<15 |
+		clock.Ticks90k(binary.BigEndian.Uint16(b[3:5])>>1)
+}
@@ -0,0 +1,219 @@
+package m2ts
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// PAT is the program association table, on PID 0.
+type PAT struct {
+	TransportStreamID uint16
+	Version           uint8
+	Programs          []*PATProgram
+}
+
+type PATProgram struct {
+	ProgramNumber uint16
+	PID           uint16 // PMT PID, or the network PID for program 0
+}
+
+// PMT is the program map table of one program.
+type PMT struct {
+	ProgramNumber uint16
+	Version       uint8
+	PCRPID        uint16
+	Descriptors   []*Descriptor
+	Streams       []*ElementaryStream
+}
+
+// ElementaryStream is one stream entry of a PMT. StreamType uses the
+// same codes as the clpi and mpls coding types, e.g. 0x1B for H.264.
+type ElementaryStream struct {
+	StreamType  uint8
+	PID         uint16
+	Descriptors []*Descriptor
+}
+
+type Descriptor struct {
+	Tag  uint8
+	Data []byte
+}
+
+const (
+	tableIDPAT = 0x00
+	tableIDPMT = 0x02
+)
+
+// sectionBuffer reassembles PSI sections from transport packet payloads.
+type sectionBuffer struct {
+	data    []byte
+	started bool
+}
+
+// push adds one payload and returns the sections it completes.
+func (s *sectionBuffer) push(unitStart bool, payload []byte) [][]byte {
+	if unitStart {
+		if len(payload) == 0 {
+			return nil
+		}
+		pointer := int(payload[0])
+		payload = payload[1:]
+		if pointer > len(payload) {
+			pointer = len(payload)
+		}
+		// The bytes before the pointer end the section already started.
+		var sections [][]byte
+		if s.started {
+			s.data = append(s.data, payload[:pointer]...)
+			sections = s.split()
+		}
+		s.data = append(s.data[:0], payload[pointer:]...)
+		s.started = true
+		return append(sections, s.split()...)
+	}
+	if !s.started {
+		return nil
+	}
+	s.data = append(s.data, payload...)
+	return s.split()
+}
+
+// split removes the complete sections from the front of the buffer.
+// A 0xFF table_id starts the stuffing that fills the rest of a packet.
+func (s *sectionBuffer) split() [][]byte {
+	var sections [][]byte
+	for len(s.data) > 0 && s.data[0] != 0xFF && len(s.data) >= 3 {
+		length := 3 + int(binary.BigEndian.Uint16(s.data[1:3])&0x0FFF)
+		if len(s.data) < length {
+			return sections
+		}
+		section := make([]byte, length)
+		copy(section, s.data)
+		sections = append(sections, section)
+		s.data = s.data[length:]
+	}
+	if len(s.data) > 0 && s.data[0] == 0xFF {
+		s.data = s.data[:0]
+		s.started = false
+	}
+	return sections
+}
+
+// checkSection validates the long section header and CRC of data and
+// returns its table_id_extension, version and the body between the header
+// and the CRC. current is false for a section that is not yet in effect.
+func checkSection(data []byte, tableID uint8) (extension uint16, version uint8, current bool, body []byte, err error) {
+	if data[0] != tableID {
+		return 0, 0, false, nil, fmt.Errorf("%w: table_id 0x%02X, want 0x%02X", ErrPSI, data[0], tableID)
+	}
+	if data[1]&0x80 == 0 || len(data) < 12 {
+		return 0, 0, false, nil, fmt.Errorf("%w: short section of table 0x%02X", ErrPSI, tableID)
+	}
+	if crc := crc32MPEG2(data); crc != 0 {
+		return 0, 0, false, nil, fmt.Errorf("%w: CRC mismatch in table 0x%02X", ErrPSI, tableID)
+	}
+	extension = binary.BigEndian.Uint16(data[3:5])
+	version = data[5] >> 1 & 0x1F
+	current = data[5]&0x01 != 0
+	return extension, version, current, data[8 : len(data)-4], nil
+}
+
+// decodePAT decodes a PAT section. It returns nil for a section that is
+// not yet current.
+func decodePAT(data []byte) (*PAT, error) {
+	extension, version, current, body, err := checkSection(data, tableIDPAT)
+	if err != nil || !current {
+		return nil, err
+	}
+	if len(body)%4 != 0 {
+		return nil, fmt.Errorf("%w: PAT program loop of %d bytes", ErrPSI, len(body))
+	}
+	pat := &PAT{TransportStreamID: extension, Version: version}
+	for i := 0; i < len(body); i += 4 {
+		pat.Programs = append(pat.Programs, &PATProgram{
+			ProgramNumber: binary.BigEndian.Uint16(body[i:]),
+			PID:           binary.BigEndian.Uint16(body[i+2:]) & 0x1FFF,
+		})
+	}
+	return pat, nil
+}
+
+// decodePMT decodes a PMT section. It returns nil for a section that is
+// not yet current.
+func decodePMT(data []byte) (*PMT, error) {
+	extension, version, current, body, err := checkSection(data, tableIDPMT)
+	if err != nil || !current {
+		return nil, err
+	}
+	if len(body) < 4 {
+		return nil, fmt.Errorf("%w: short PMT", ErrPSI)
+	}
+	pmt := &PMT{
+		ProgramNumber: extension,
+		Version:       version,
+		PCRPID:        binary.BigEndian.Uint16(body[0:2]) & 0x1FFF,
+	}
+	infoLength := int(binary.BigEndian.Uint16(body[2:4]) & 0x0FFF)
+	body = body[4:]
+	if infoLength > len(body) {
+		return nil, fmt.Errorf("%w: program_info_length %d overruns the PMT", ErrPSI, infoLength)
+	}
+	if pmt.Descriptors, err = decodeDescriptors(body[:infoLength]); err != nil {
+		return nil, err
+	}
+	body = body[infoLength:]
+
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, fmt.Errorf("%w: short PMT stream entry", ErrPSI)
+		}
+		stream := &ElementaryStream{
+			StreamType: body[0],
+			PID:        binary.BigEndian.Uint16(body[1:3]) & 0x1FFF,
+		}
+		esInfoLength := int(binary.BigEndian.Uint16(body[3:5]) & 0x0FFF)
+		body = body[5:]
+		if esInfoLength > len(body) {
+			return nil, fmt.Errorf("%w: ES_info_length %d overruns the PMT", ErrPSI, esInfoLength)
+		}
+		if stream.Descriptors, err = decodeDescriptors(body[:esInfoLength]); err != nil {
+			return nil, err
+		}
+		body = body[esInfoLength:]
+		pmt.Streams = append(pmt.Streams, stream)
+	}
+	return pmt, nil
+}
+
+func decodeDescriptors(data []byte) ([]*Descriptor, error) {
+	descriptors := []*Descriptor{}
+	for len(data) > 0 {
+		if len(data) < 2 || int(data[1]) > len(data)-2 {
+			return nil, fmt.Errorf("%w: descriptor overruns its loop", ErrPSI)
+		}
+		length := int(data[1])
+		descriptors = append(descriptors, &Descriptor{
+			Tag:  data[0],
+			Data: append([]byte(nil), data[2:2+length]...),
+		})
+		data = data[2+length:]
+	}
+	return descriptors, nil
+}
+
+// crc32MPEG2 computes the CRC-32/MPEG-2 of data. Over a whole section,
+// including its CRC_32 field, the result is 0.
+func crc32MPEG2(data []byte) uint32 {
+	crc := uint32(0xFFFFFFFF)
+	for _, b := range data {
+		crc ^= uint32(b) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
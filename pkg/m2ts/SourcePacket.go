@@ -0,0 +1,110 @@
+package m2ts
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+// SourcePacket is one 192-byte BDAV source packet.
+type SourcePacket struct {
+	Offset int64 // Byte offset in the stream
+
+	// TP_extra_header
+	CopyPermissionIndicator uint8
+	ArrivalTimeStamp        uint32 // 30 bits, 27 MHz
+
+	// Transport packet header
+	TransportErrorIndicator    bool
+	PayloadUnitStartIndicator  bool
+	TransportPriority          bool
+	PID                        uint16
+	TransportScramblingControl uint8
+	AdaptationFieldControl     uint8
+	ContinuityCounter          uint8
+
+	AdaptationField *AdaptationField // nil when absent
+	Payload         []byte           // nil when absent
+}
+
+// AdaptationField holds the flags and the PCR of an adaptation field.
+// The other optional fields are not decoded.
+type AdaptationField struct {
+	Length                            uint8
+	DiscontinuityIndicator            bool
+	RandomAccessIndicator             bool
+	ElementaryStreamPriorityIndicator bool
+	PCRFlag                           bool
+	OPCRFlag                          bool
+	SplicingPointFlag                 bool
+	TransportPrivateDataFlag          bool
+	AdaptationFieldExtensionFlag      bool
+	PCRBase                           clock.Ticks90k // Valid when PCRFlag is set
+	PCRExtension                      uint16         // 9 bits, 27 MHz
+}
+
+// PCR returns the program clock reference on the 27 MHz system clock.
+func (af *AdaptationField) PCR() uint64 {
+	return uint64(af.PCRBase)*300 + uint64(af.PCRExtension)
+}
+
+// decodeSourcePacket decodes the 192 bytes of a source packet.
+// The payload refers to data.
+func decodeSourcePacket(data []byte) (*SourcePacket, error) {
+	if data[4] != SyncByte {
+		return nil, fmt.Errorf("%w: byte 0x%02X", ErrSync, data[4])
+	}
+
+	extraHeader := binary.BigEndian.Uint32(data[0:4])
+	packet := &SourcePacket{
+		CopyPermissionIndicator:    uint8(extraHeader >> 30),
+		ArrivalTimeStamp:           extraHeader & 0x3FFFFFFF,
+		TransportErrorIndicator:    data[5]&0x80 != 0,
+		PayloadUnitStartIndicator:  data[5]&0x40 != 0,
+		TransportPriority:          data[5]&0x20 != 0,
+		PID:                        binary.BigEndian.Uint16(data[5:7]) & 0x1FFF,
+		TransportScramblingControl: data[7] >> 6,
+		AdaptationFieldControl:     data[7] >> 4 & 0x03,
+		ContinuityCounter:          data[7] & 0x0F,
+	}
+
+	payload := data[8:]
+	if packet.AdaptationFieldControl&0x02 != 0 {
+		length := int(payload[0])
+		if length > len(payload)-1 {
+			return nil, fmt.Errorf("adaptation field length %d overruns the packet", length)
+		}
+		packet.AdaptationField = decodeAdaptationField(payload[:1+length])
+		payload = payload[1+length:]
+	}
+	if packet.AdaptationFieldControl&0x01 != 0 {
+		packet.Payload = payload
+	}
+	return packet, nil
+}
+
+func decodeAdaptationField(data []byte) *AdaptationField {
+	af := &AdaptationField{Length: data[0]}
+	if af.Length == 0 {
+		return af
+	}
+	flags := data[1]
+	af.DiscontinuityIndicator = flags&0x80 != 0
+	af.RandomAccessIndicator = flags&0x40 != 0
+	af.ElementaryStreamPriorityIndicator = flags&0x20 != 0
+	af.PCRFlag = flags&0x10 != 0
+	af.OPCRFlag = flags&0x08 != 0
+	af.SplicingPointFlag = flags&0x04 != 0
+	af.TransportPrivateDataFlag = flags&0x02 != 0
+	af.AdaptationFieldExtensionFlag = flags&0x01 != 0
+
+	if af.PCRFlag && len(data) >= 8 {
+		pcr := uint64(binary.BigEndian.Uint32(data[2:6]))<<16 | uint64(binary.BigEndian.Uint16(data[6:8]))
+		af.PCRBase = clock.Ticks90k(pcr >> 15)
+		af.PCRExtension = uint16(pcr & 0x1FF)
+	} else {
+		af.PCRFlag = false
+	}
+	return af
+}
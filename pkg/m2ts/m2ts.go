@@ -0,0 +1,331 @@
+package m2ts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"sort"
+)
+
+/*
+	Remarks:
+
+	A BDAV MPEG-2 transport stream (BDMV/STREAM/xxxxx.m2ts) is a run of
+	192-byte source packets. Each is a 4-byte TP_extra_header followed by
+	an ordinary 188-byte transport packet:
+
+		copy_permission_indicator   2 bits
+		arrival_time_stamp         30 bits, on the 27 MHz arrival clock
+		transport_packet          188 bytes, starting with the 0x47 sync byte
+
+	Source packets are written in aligned units of 32 (6144 bytes), the
+	block AACS encrypts. Encrypted units are not decrypted here; reading
+	one fails with ErrSync.
+
+	The PIDs a BD-ROM stream uses:
+		0x0000          PAT
+		0x001F          SIT
+		0x0100          PMT
+		0x1001          PCR
+		0x1011          primary video
+		0x1012          dependent view (MVC) video
+		0x1100..0x111F  primary audio
+		0x1200..0x121F  presentation graphics
+		0x1400..0x141F  interactive graphics
+		0x1800..0x181F  text subtitles
+		0x1A00..0x1A1F  secondary audio
+		0x1B00..0x1B1F  secondary video
+
+	The Reader decodes the PAT and every PMT it lists as they go by, and
+	reassembles PES packets for the elementary streams of those PMTs.
+	A PES packet with a PES_packet_length ends when that many bytes have
+	arrived; one without (video) ends at the next payload_unit_start on
+	its PID, or at the end of the stream.
+*/
+
+const (
+	SourcePacketSize    = 192
+	TransportPacketSize = 188
+	AlignedUnitSize     = 32 * SourcePacketSize
+	SyncByte            = 0x47
+
+	PIDPAT  = 0x0000
+	PIDNull = 0x1FFF
+)
+
+// Reader reads source packets from a BDAV transport stream.
+// PAT and PMTs hold the program tables seen so far.
+type Reader struct {
+	PAT  *PAT
+	PMTs map[uint16]*PMT // Keyed by program number
+
+	r       io.Reader
+	name    string // File name for errors, empty for NewReader
+	offset  int64  // Byte offset of the next source packet
+	buf     [SourcePacketSize]byte
+	streams map[uint16]*ElementaryStream // Keyed by PID, from the PMTs
+	psi     map[uint16]*sectionBuffer    // Keyed by PID: PAT and PMTs
+	pes     map[uint16]*pesBuffer        // Keyed by PID: elementary streams
+	ready   []*PES                       // Completed PES packets not yet returned
+	eof     bool
+}
+
+// NewReader returns a Reader that reads source packets from r,
+// which should be positioned at the start of a source packet.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		PMTs:    map[uint16]*PMT{},
+		r:       r,
+		streams: map[uint16]*ElementaryStream{},
+		psi:     map[uint16]*sectionBuffer{PIDPAT: {}},
+		pes:     map[uint16]*pesBuffer{},
+	}
+}
+
+// File is a Reader over an open .m2ts file.
+type File struct {
+	*Reader
+	file io.Closer
+}
+
+// Open opens a .m2ts file for reading. Close it when done.
+func Open(filePath string) (*File, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	reader := NewReader(file)
+	reader.name = filePath
+	return &File{Reader: reader, file: file}, nil
+}
+
+// OpenFS opens the .m2ts file name in fsys, such as a disc image opened
+// with package udf. Close it when done.
+func OpenFS(fsys fs.FS, name string) (*File, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	reader := NewReader(file)
+	reader.name = name
+	return &File{Reader: reader, file: file}, nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
+
+// Offset returns the byte offset of the next source packet.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Stream returns the elementary stream on pid from the PMTs seen so far,
+// or nil.
+func (r *Reader) Stream(pid uint16) *ElementaryStream {
+	return r.streams[pid]
+}
+
+// ReadPacket reads and decodes the next source packet, and updates PAT
+// and PMTs when it carries a part of them. At the end of the stream it
+// returns io.EOF; a partial packet at the end is io.ErrUnexpectedEOF.
+// The packet has its own copy of the bytes, so it stays valid after the
+// next read.
+func (r *Reader) ReadPacket() (*SourcePacket, error) {
+	offset := r.offset
+	if _, err := io.ReadFull(r.r, r.buf[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, r.error(offset, "SourcePacket", err)
+		}
+		return nil, err
+	}
+	r.offset += SourcePacketSize
+
+	packet, err := decodeSourcePacket(bytes.Clone(r.buf[:]))
+	if err != nil {
+		return nil, r.error(offset, "SourcePacket", err)
+	}
+	packet.Offset = offset
+
+	if section, ok := r.psi[packet.PID]; ok && packet.Payload != nil && packet.TransportScramblingControl == 0 {
+		for _, data := range section.push(packet.PayloadUnitStartIndicator, packet.Payload) {
+			if err := r.handleSection(packet.PID, data); err != nil {
+				return nil, r.error(offset, fmt.Sprintf("PSI(0x%04X)", packet.PID), err)
+			}
+		}
+	}
+	return packet, nil
+}
+
+// ReadPES returns the next complete PES packet of an elementary stream
+// listed in a PMT. At the end of the stream the PES packets still open
+// are returned in PID order, then io.EOF.
+func (r *Reader) ReadPES() (*PES, error) {
+	for len(r.ready) == 0 {
+		if r.eof {
+			return nil, io.EOF
+		}
+		packet, err := r.ReadPacket()
+		if err == io.EOF {
+			r.eof = true
+			r.flush()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := r.pushPES(packet); err != nil {
+			return nil, r.error(packet.Offset, fmt.Sprintf("PES(0x%04X)", packet.PID), err)
+		}
+	}
+	pes := r.ready[0]
+	r.ready = r.ready[1:]
+	return pes, nil
+}
+
+// Packets iterates over the remaining source packets. It stops after
+// the first error other than io.EOF, which it yields.
+func (r *Reader) Packets() iter.Seq2[*SourcePacket, error] {
+	return func(yield func(*SourcePacket, error) bool) {
+		for {
+			packet, err := r.ReadPacket()
+			if err == io.EOF {
+				return
+			}
+			if !yield(packet, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// PESUnits iterates over the remaining PES packets, as ReadPES returns
+// them. It stops after the first error other than io.EOF, which it yields.
+func (r *Reader) PESUnits() iter.Seq2[*PES, error] {
+	return func(yield func(*PES, error) bool) {
+		for {
+			pes, err := r.ReadPES()
+			if err == io.EOF {
+				return
+			}
+			if !yield(pes, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleSection decodes a complete PAT or PMT section.
+func (r *Reader) handleSection(pid uint16, data []byte) error {
+	if pid == PIDPAT {
+		pat, err := decodePAT(data)
+		if err != nil || pat == nil {
+			return err
+		}
+		r.PAT = pat
+		for _, program := range pat.Programs {
+			if program.ProgramNumber == 0 {
+				continue // network PID
+			}
+			if _, ok := r.psi[program.PID]; !ok {
+				r.psi[program.PID] = &sectionBuffer{}
+			}
+		}
+		return nil
+	}
+
+	pmt, err := decodePMT(data)
+	if err != nil || pmt == nil {
+		return err
+	}
+	if old, ok := r.PMTs[pmt.ProgramNumber]; ok && old.Version == pmt.Version {
+		return nil
+	}
+	r.PMTs[pmt.ProgramNumber] = pmt
+	for _, stream := range pmt.Streams {
+		r.streams[stream.PID] = stream
+	}
+	return nil
+}
+
+// pushPES adds the payload of packet to the PES packet open on its PID.
+func (r *Reader) pushPES(packet *SourcePacket) error {
+	stream, ok := r.streams[packet.PID]
+	if !ok || packet.Payload == nil || packet.TransportScramblingControl != 0 {
+		return nil
+	}
+	buffer := r.pes[packet.PID]
+	if packet.PayloadUnitStartIndicator {
+		if buffer != nil {
+			if err := r.finish(packet.PID, buffer); err != nil {
+				return err
+			}
+		}
+		buffer = &pesBuffer{
+			stream:           stream,
+			offset:           packet.Offset,
+			arrivalTimeStamp: packet.ArrivalTimeStamp,
+			randomAccess:     packet.AdaptationField != nil && packet.AdaptationField.RandomAccessIndicator,
+			continuity:       packet.ContinuityCounter,
+		}
+		r.pes[packet.PID] = buffer
+	} else if buffer == nil {
+		return nil // joined the stream in the middle of a PES packet
+	} else {
+		if packet.ContinuityCounter == buffer.continuity {
+			return nil // duplicate packet
+		}
+		if packet.ContinuityCounter != (buffer.continuity+1)&0x0F {
+			buffer.discontinuity = true
+		}
+		buffer.continuity = packet.ContinuityCounter
+	}
+	buffer.data = append(buffer.data, packet.Payload...)
+
+	if buffer.complete() {
+		return r.finish(packet.PID, buffer)
+	}
+	return nil
+}
+
+// finish decodes the PES packet in buffer and queues it.
+func (r *Reader) finish(pid uint16, buffer *pesBuffer) error {
+	delete(r.pes, pid)
+	pes, err := decodePES(buffer.data)
+	if err != nil {
+		return err
+	}
+	pes.PID = pid
+	pes.StreamType = buffer.stream.StreamType
+	pes.Offset = buffer.offset
+	pes.ArrivalTimeStamp = buffer.arrivalTimeStamp
+	pes.RandomAccess = buffer.randomAccess
+	pes.Discontinuity = buffer.discontinuity
+	r.ready = append(r.ready, pes)
+	return nil
+}
+
+// flush queues every PES packet still open, in PID order. Ones that do
+// not decode are dropped, since the stream may simply have been cut.
+func (r *Reader) flush() {
+	pids := make([]uint16, 0, len(r.pes))
+	for pid := range r.pes {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	for _, pid := range pids {
+		r.finish(pid, r.pes[pid])
+	}
+}
+
+func (r *Reader) error(offset int64, path string, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+	return &ParseError{File: r.name, Offset: offset, Path: path, Err: err}
+}
@@ -0,0 +1,243 @@
+package m2ts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/parasense/bdmv_go/pkg/clock"
+)
+
+// testPacket builds a source packet whose payload is padded to 184 bytes
+// with adaptation field stuffing. A nil payload gives an adaptation-only packet.
+func testPacket(pid uint16, unitStart bool, cc uint8, ats uint32, payload []byte, adaptation []byte) []byte {
+	packet := make([]byte, 0, SourcePacketSize)
+	packet = binary.BigEndian.AppendUint32(packet, 0x40000000|ats)
+	header := uint16(pid)
+	if unitStart {
+		header |= 0x4000
+	}
+	packet = append(packet, SyncByte)
+	packet = binary.BigEndian.AppendUint16(packet, header)
+
+	control := uint8(0x01)
+	if payload == nil {
+		control = 0x02
+	} else if len(payload) < 184 || adaptation != nil {
+		control = 0x03
+	}
+	packet = append(packet, control<<4|cc)
+	if control&0x02 != 0 {
+		length := 183 - len(payload)
+		field := []byte{uint8(length)}
+		if length > 0 {
+			field = append(field, adaptation...)
+			if len(adaptation) == 0 {
+				field = append(field, 0x00)
+			}
+			field = append(field, bytes.Repeat([]byte{0xFF}, 1+length-len(field))...)
+		}
+		packet = append(packet, field...)
+	}
+	return append(packet, payload...)
+}
+
+// testSection builds a long-form PSI section with its CRC.
+func testSection(tableID uint8, extension uint16, body []byte) []byte {
+	section := []byte{tableID, 0, 0}
+	binary.BigEndian.PutUint16(section[1:], 0xB000|uint16(5+len(body)+4))
+	section = binary.BigEndian.AppendUint16(section, extension)
+	section = append(section, 0xC1, 0x00, 0x00) // version 0, current
+	section = append(section, body...)
+	return binary.BigEndian.AppendUint32(section, crc32MPEG2(section))
+}
+
+func testTimestamp(prefix uint8, ts clock.Ticks90k) []byte {
+	return []byte{
+		prefix<<4 | uint8(ts>>29)&0x0E | 1,
+		uint8(ts >> 22), uint8(ts>>14)&0xFE | 1,
+		uint8(ts >> 7), uint8(ts<<1) | 1,
+	}
+}
+
+func testPES(streamID uint8, bounded bool, pts, dts clock.Ticks90k, data []byte) []byte {
+	header := testTimestamp(0x3, pts)
+	header = append(header, testTimestamp(0x1, dts)...)
+	pes := []byte{0, 0, 1, streamID, 0, 0, 0x84, 0xC0, uint8(len(header))}
+	pes = append(pes, header...)
+	pes = append(pes, data...)
+	if bounded {
+		binary.BigEndian.PutUint16(pes[4:], uint16(len(pes)-6))
+	}
+	return pes
+}
+
+func testStream(t *testing.T) []byte {
+	t.Helper()
+	stream := &bytes.Buffer{}
+
+	pat := testSection(tableIDPAT, 1, []byte{0x00, 0x01, 0xE1, 0x00})
+	stream.Write(testPacket(PIDPAT, true, 0, 0, append([]byte{0}, pat...), nil))
+
+	// The PMT is split over two packets.
+	pmt := testSection(tableIDPMT, 1, []byte{
+		0xF0, 0x01, 0xF0, 0x00, // PCR PID 0x1001, no program descriptors
+		0x1B, 0xF0, 0x11, 0xF0, 0x00, // H.264 on 0x1011
+		0x81, 0xF1, 0x00, 0xF0, 0x03, 0x05, 0x01, 0xAA, // AC-3 on 0x1100 with a descriptor
+	})
+	stream.Write(testPacket(0x0100, true, 0, 10, append([]byte{0}, pmt[:10]...), nil))
+	stream.Write(testPacket(0x0100, false, 1, 20, pmt[10:], nil))
+
+	// PCR only.
+	pcr := []byte{0x10, 0x00, 0x00, 0x00, 0x01, 0x7E, 0x05}
+	stream.Write(testPacket(0x1001, false, 0, 30, nil, pcr))
+
+	// Unbounded video over three packets, with a gap in the continuity counter.
+	video := testPES(0xE0, false, 90000, 87000, bytes.Repeat([]byte{0x11}, 400))
+	stream.Write(testPacket(0x1011, true, 0, 40, video[:182], []byte{0x40}))
+	stream.Write(testPacket(0x1011, false, 1, 50, video[182:366], nil))
+	stream.Write(testPacket(0x1011, false, 3, 60, video[366:], nil))
+
+	// Bounded audio in one packet, ending the audio PES packet right away.
+	audio := testPES(0xBD, true, 93000, 93000, bytes.Repeat([]byte{0x22}, 100))
+	stream.Write(testPacket(0x1100, true, 0, 70, audio, nil))
+
+	// A second video PES packet ends the first and is left open at EOF.
+	video2 := testPES(0xE0, false, 93003, 90003, []byte{0x33})
+	stream.Write(testPacket(0x1011, true, 4, 80, video2, nil))
+	return stream.Bytes()
+}
+
+func TestReadPacket(t *testing.T) {
+	reader := NewReader(bytes.NewReader(testStream(t)))
+
+	var packets []*SourcePacket
+	for packet, err := range reader.Packets() {
+		if err != nil {
+			t.Fatalf("Packets() error = %v", err)
+		}
+		packets = append(packets, packet)
+	}
+	if len(packets) != 9 {
+		t.Fatalf("got %d packets, want 9", len(packets))
+	}
+
+	// Every packet kept holds its own payload, not the last one read.
+	stream := testStream(t)
+	for _, packet := range packets {
+		end := packet.Offset + SourcePacketSize
+		if want := stream[end-int64(len(packet.Payload)) : end]; !bytes.Equal(packet.Payload, want) {
+			t.Errorf("packet at %d: payload changed after later reads", packet.Offset)
+		}
+	}
+
+	pcr := packets[3]
+	if pcr.PID != 0x1001 || pcr.ArrivalTimeStamp != 30 || pcr.CopyPermissionIndicator != 1 || pcr.Offset != 3*SourcePacketSize {
+		t.Errorf("PCR packet header = %+v", pcr)
+	}
+	if pcr.Payload != nil || pcr.AdaptationField == nil || !pcr.AdaptationField.PCRFlag {
+		t.Fatalf("PCR packet adaptation field = %+v", pcr.AdaptationField)
+	}
+	if got := pcr.AdaptationField.PCRBase; got != 2 {
+		t.Errorf("PCRBase = %d, want 2", got)
+	}
+	if got := pcr.AdaptationField.PCR(); got != 2*300+5 {
+		t.Errorf("PCR() = %d, want 605", got)
+	}
+	if !packets[4].AdaptationField.RandomAccessIndicator || !packets[4].PayloadUnitStartIndicator {
+		t.Errorf("first video packet = %+v", packets[4])
+	}
+
+	if reader.PAT == nil || len(reader.PAT.Programs) != 1 || reader.PAT.Programs[0].PID != 0x0100 {
+		t.Fatalf("PAT = %+v", reader.PAT)
+	}
+	pmt := reader.PMTs[1]
+	if pmt == nil || pmt.PCRPID != 0x1001 || len(pmt.Streams) != 2 {
+		t.Fatalf("PMT = %+v", pmt)
+	}
+	if audio := reader.Stream(0x1100); audio == nil || audio.StreamType != 0x81 ||
+		len(audio.Descriptors) != 1 || audio.Descriptors[0].Tag != 0x05 {
+		t.Errorf("audio stream = %+v", audio)
+	}
+}
+
+func TestReadPES(t *testing.T) {
+	reader := NewReader(bytes.NewReader(testStream(t)))
+
+	var units []*PES
+	for pes, err := range reader.PESUnits() {
+		if err != nil {
+			t.Fatalf("PESUnits() error = %v", err)
+		}
+		units = append(units, pes)
+	}
+	if len(units) != 3 {
+		t.Fatalf("got %d PES packets, want 3", len(units))
+	}
+
+	audio, video, video2 := units[0], units[1], units[2]
+	if audio.PID != 0x1100 || audio.StreamType != 0x81 || audio.StreamID != 0xBD || len(audio.Data) != 100 {
+		t.Errorf("audio = PID 0x%X type 0x%X stream 0x%X, %d bytes", audio.PID, audio.StreamType, audio.StreamID, len(audio.Data))
+	}
+	if !audio.HasPTS || audio.PTS != 93000 {
+		t.Errorf("audio PTS = %v %d", audio.HasPTS, audio.PTS)
+	}
+
+	if video.PID != 0x1011 || video.Offset != 4*SourcePacketSize || video.ArrivalTimeStamp != 40 || !video.RandomAccess {
+		t.Errorf("video = PID 0x%X at %d, ATS %d, random access %v", video.PID, video.Offset, video.ArrivalTimeStamp, video.RandomAccess)
+	}
+	if !video.HasPTS || !video.HasDTS || video.PTS != 90000 || video.DTS != 87000 {
+		t.Errorf("video PTS/DTS = %d/%d", video.PTS, video.DTS)
+	}
+	if len(video.Data) != 400 || !bytes.Equal(video.Data, bytes.Repeat([]byte{0x11}, 400)) {
+		t.Errorf("video data is %d bytes", len(video.Data))
+	}
+	if !video.Discontinuity {
+		t.Error("video continuity gap not reported")
+	}
+
+	if video2.PTS != 93003 || !bytes.Equal(video2.Data, []byte{0x33}) || video2.Discontinuity {
+		t.Errorf("video at EOF = %+v", video2)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	stream := testStream(t)
+
+	t.Run("lost sync", func(t *testing.T) {
+		broken := bytes.Clone(stream)
+		broken[2*SourcePacketSize+4] = 0x00
+		reader := NewReader(bytes.NewReader(broken))
+		var err error
+		for _, err = range reader.Packets() {
+			if err != nil {
+				break
+			}
+		}
+		var parseErr *ParseError
+		if !errors.Is(err, ErrSync) || !errors.As(err, &parseErr) || parseErr.Offset != 2*SourcePacketSize {
+			t.Errorf("error = %v, want ErrSync at offset %d", err, 2*SourcePacketSize)
+		}
+	})
+
+	t.Run("PAT CRC", func(t *testing.T) {
+		broken := bytes.Clone(stream)
+		broken[SourcePacketSize-1] ^= 0xFF // the PAT's CRC
+		_, err := NewReader(bytes.NewReader(broken)).ReadPacket()
+		if !errors.Is(err, ErrPSI) {
+			t.Errorf("ReadPacket() error = %v, want ErrPSI", err)
+		}
+	})
+
+	t.Run("partial packet", func(t *testing.T) {
+		reader := NewReader(bytes.NewReader(stream[:SourcePacketSize+100]))
+		if _, err := reader.ReadPacket(); err != nil {
+			t.Fatalf("ReadPacket() error = %v", err)
+		}
+		if _, err := reader.ReadPacket(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadPacket() error = %v, want io.ErrUnexpectedEOF", err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -30,11 +31,46 @@ func ParseMETA(filePath string) (discLib *DiscLib, err error) {
 		return discLib, fmt.Errorf("error reading file %s: %w", filePath, err)
 	}
 
+	return parseMETA(data, filePath)
+}
+
+// ParseMETAFS is ParseMETA for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseMETAFS(fsys fs.FS, name string) (discLib *DiscLib, err error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return &DiscLib{}, fmt.Errorf("error reading file %s: %w", name, err)
+	}
+
+	return parseMETA(data, name)
+}
+
+// ParseMETAReader parses a bdmt_xxx.xml file read from r.
+func ParseMETAReader(r io.Reader) (discLib *DiscLib, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &DiscLib{}, fmt.Errorf("error reading XML: %w", err)
+	}
+
+	return ParseMETABytes(data)
+}
+
+// ParseMETABytes parses a bdmt_xxx.xml file held in memory.
+func ParseMETABytes(data []byte) (discLib *DiscLib, err error) {
+	discLib = &DiscLib{}
+
 	// Unmarshal the XML data into the DiscLib struct
 	err = xml.Unmarshal(data, &discLib)
 	if err != nil {
-		return discLib, fmt.Errorf("error unmarshaling XML from %s: %w", filePath, err)
+		return discLib, fmt.Errorf("error unmarshaling XML: %w", err)
 	}
 
 	return discLib, nil
 }
+
+func parseMETA(data []byte, filePath string) (discLib *DiscLib, err error) {
+	if discLib, err = ParseMETABytes(data); err != nil {
+		return discLib, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return discLib, nil
+}
@@ -0,0 +1,90 @@
+package meta
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+/*
+	Remarks:
+
+	META/TN holds one tnmt_xxx_yyyyy.xml file per language and playlist,
+	e.g. tnmt_eng_00800.xml. It names the chapters of that playlist, in
+	mark order, as <name> elements inside <chapters>. The namespace and
+	the elements around <chapters> vary between authoring tools, so only
+	that pair is looked for.
+*/
+
+// TrackNames is the content of one META/TN/tnmt_xxx_yyyyy.xml file.
+type TrackNames struct {
+	ChapterNames []string
+}
+
+func ParseTrackNames(filePath string) (trackNames *TrackNames, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	if trackNames, err = ReadTrackNames(file); err != nil {
+		return nil, fmt.Errorf("error unmarshaling XML from %s: %w", filePath, err)
+	}
+	return trackNames, nil
+}
+
+// ParseTrackNamesFS is ParseTrackNames for the file name in fsys, such as
+// a disc image opened with package udf.
+func ParseTrackNamesFS(fsys fs.FS, name string) (trackNames *TrackNames, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if trackNames, err = ReadTrackNames(file); err != nil {
+		return nil, fmt.Errorf("error unmarshaling XML from %s: %w", name, err)
+	}
+	return trackNames, nil
+}
+
+// ReadTrackNames collects the text of every <name> element whose parent is <chapters>.
+func ReadTrackNames(r io.Reader) (*TrackNames, error) {
+	trackNames := &TrackNames{ChapterNames: []string{}}
+	decoder := xml.NewDecoder(r)
+
+	var parents []string
+	var text *strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "name" && len(parents) > 0 && parents[len(parents)-1] == "chapters" {
+				text = &strings.Builder{}
+			}
+			parents = append(parents, t.Name.Local)
+		case xml.CharData:
+			if text != nil {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			parents = parents[:len(parents)-1]
+			if text != nil && t.Name.Local == "name" {
+				trackNames.ChapterNames = append(trackNames.ChapterNames, strings.TrimSpace(text.String()))
+				text = nil
+			}
+		}
+	}
+	return trackNames, nil
+}
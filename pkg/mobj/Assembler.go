@@ -0,0 +1,432 @@
+package mobj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+	Remarks:
+
+	Assemble reads back the listing WriteListing writes, so a listing can
+	be dumped, edited and assembled into a MovieObject.bdmv again:
+
+		movie_object 0 resume
+		  0000  if PSR[16] != 0x667261    ; PSR[16] audio language
+		  0001  goto feature
+		  0002  play_pl 00002
+		feature:
+		  0003  jump_title 1
+
+	A movie_object line starts each movie object; they are numbered from 0
+	in order, and take the flags resume, menu_call_mask and
+	title_search_mask. The command number at the start of a line is
+	optional and ignored, as is everything after a ';' and lines starting
+	with '#'. A line "name:" labels the command after it, and goto takes
+	a label of its own movie object as well as a command number.
+
+	Operands are GPR[n], PSR[n], or immediates in decimal or 0x hex.
+	Playlists are decimal, so 00800 is playlist 800 and not octal. Within
+	set_stream, set_sec_stream and button_page, only the fields written
+	are set, and "-" stands for none at all. Fields with a display
+	flag, e.g. pg, need "on" or "off" after the value.
+
+	The operand count of a command is the number of operands it is
+	written with, except that set_stream and the other player setting
+	commands with operands always count 2, as authoring tools write them.
+	An operand a command does not use is 0.
+*/
+
+// pendingGoto is a goto whose label is resolved at the end of its movie object.
+type pendingGoto struct {
+	line  int
+	nav   *NavigationCommand
+	label string
+}
+
+// Assemble reads a program listing and encodes it as movie objects.
+// Errors are an *AssemblyError carrying the line number.
+func Assemble(r io.Reader) (*MovieObjects, error) {
+	movieObjects := &MovieObjects{}
+	var (
+		mobj   *MovieObject
+		labels map[string]int
+		gotos  []pendingGoto
+	)
+	resolve := func() error {
+		for _, pending := range gotos {
+			target, ok := labels[pending.label]
+			if !ok {
+				return &AssemblyError{Line: pending.line, Err: fmt.Errorf("undefined label %q", pending.label)}
+			}
+			pending.nav.Destination = uint32(target)
+		}
+		gotos = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), ";")
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if fields := strings.Fields(text); fields[0] == "movie_object" {
+			if err := resolve(); err != nil {
+				return nil, err
+			}
+			var err error
+			if mobj, err = assembleHeader(fields, len(movieObjects.MovieObjects)); err != nil {
+				return nil, &AssemblyError{Line: line, Err: err}
+			}
+			movieObjects.MovieObjects = append(movieObjects.MovieObjects, mobj)
+			labels = map[string]int{}
+			continue
+		}
+		if mobj == nil {
+			return nil, &AssemblyError{Line: line, Err: fmt.Errorf("command before the first movie_object")}
+		}
+
+		if label, ok := strings.CutSuffix(text, ":"); ok {
+			if !isLabel(label) {
+				return nil, &AssemblyError{Line: line, Err: fmt.Errorf("bad label %q", label)}
+			}
+			if _, ok := labels[label]; ok {
+				return nil, &AssemblyError{Line: line, Err: fmt.Errorf("label %q defined twice", label)}
+			}
+			labels[label] = len(mobj.NavigationCommands)
+			continue
+		}
+
+		// The command number of a listing
+		if number, rest, ok := strings.Cut(text, " "); ok && isDecimal(number) {
+			text = strings.TrimSpace(rest)
+		}
+
+		var label string
+		if mnemonic, operand, _ := strings.Cut(text, " "); mnemonic == "goto" && isLabel(strings.TrimSpace(operand)) {
+			label = strings.TrimSpace(operand)
+			text = "goto 0"
+		}
+		nav, err := AssembleCommand(text)
+		if err != nil {
+			return nil, &AssemblyError{Line: line, Err: err}
+		}
+		if label != "" {
+			gotos = append(gotos, pendingGoto{line: line, nav: nav, label: label})
+		}
+		mobj.NavigationCommands = append(mobj.NavigationCommands, nav)
+		mobj.NumberOfNavigationCommands = uint16(len(mobj.NavigationCommands))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read listing: %w", err)
+	}
+	if err := resolve(); err != nil {
+		return nil, err
+	}
+
+	movieObjects.NumberOfMovieObjects = uint16(len(movieObjects.MovieObjects))
+	data, err := movieObjects.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	movieObjects.Length = uint32(len(data) - 4)
+	return movieObjects, nil
+}
+
+func assembleHeader(fields []string, id int) (*MovieObject, error) {
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("movie_object needs a number")
+	}
+	if n, err := strconv.Atoi(fields[1]); err != nil || n != id {
+		return nil, fmt.Errorf("movie_object %s out of order, want %d", fields[1], id)
+	}
+	mobj := &MovieObject{}
+	for _, flag := range fields[2:] {
+		switch flag {
+		case "resume":
+			mobj.ResumeIntentionFlag = true
+		case "menu_call_mask":
+			mobj.MenuCallMask = true
+		case "title_search_mask":
+			mobj.TitleSearchMask = true
+		default:
+			return nil, fmt.Errorf("unknown movie_object flag %q", flag)
+		}
+	}
+	return mobj, nil
+}
+
+// AssembleCommand encodes one line of assembly, as Disassemble writes it.
+func AssembleCommand(text string) (*NavigationCommand, error) {
+	mnemonic, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	rest = strings.TrimSpace(rest)
+	var operands []string
+	if rest != "" {
+		operands = strings.Split(rest, ",")
+		for i := range operands {
+			operands[i] = strings.TrimSpace(operands[i])
+		}
+	}
+
+	switch mnemonic {
+	case ".word":
+		return assembleWords(operands)
+	case "if":
+		fields := strings.Fields(rest)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("if needs the form: if A op B")
+		}
+		op := slices.Index(compareOperators, fields[1])
+		if op <= 0 {
+			return nil, fmt.Errorf("unknown compare operator %q", fields[1])
+		}
+		nav := &NavigationCommand{CommandGroup: 1, CompareOption: uint8(op)}
+		return nav, nav.setOperands(fields[0], fields[2])
+	}
+
+	for sub, names := range branchMnemonics {
+		if option := slices.Index(names, mnemonic); option >= 0 {
+			nav := &NavigationCommand{CommandGroup: 0, CommandSubGroup: uint8(sub), BranchOption: uint8(option)}
+			want := 1
+			switch mnemonic {
+			case "nop", "break", "resume", "terminate_pl":
+				want = 0
+			case "play_pl_pi", "play_pl_pm":
+				want = 2
+			}
+			if len(operands) != want {
+				return nil, fmt.Errorf("%s takes %d operands, got %d", mnemonic, want, len(operands))
+			}
+			return nav, nav.setOperands(operands...)
+		}
+	}
+
+	for sub, names := range setMnemonics {
+		option := slices.Index(names, mnemonic)
+		if option <= 0 {
+			continue
+		}
+		nav := &NavigationCommand{CommandGroup: 2, CommandSubGroup: uint8(sub), SetOption: uint8(option)}
+		if sub == 0 {
+			if len(operands) != 2 {
+				return nil, fmt.Errorf("%s takes 2 operands, got %d", mnemonic, len(operands))
+			}
+			return nav, nav.setOperands(operands...)
+		}
+		return nav, nav.assembleSetSystem(mnemonic, operands)
+	}
+
+	return nil, fmt.Errorf("unknown mnemonic %q", mnemonic)
+}
+
+func (nav *NavigationCommand) assembleSetSystem(mnemonic string, operands []string) error {
+	want := 0
+	switch nav.SetOption {
+	case MOBJ_SET_OPTION_SUB1_SETSTREAM:
+		return nav.assembleFields(setStreamFields, operands)
+	case MOBJ_SET_OPTION_SUB1_SETSECONDARYSTREAM:
+		return nav.assembleFields(setSecondaryStreamFields, operands)
+	case MOBJ_SET_OPTION_SUB1_BUTTONPAGE:
+		effectsOff := len(operands) > 0 && operands[len(operands)-1] == "effects_off"
+		if effectsOff {
+			operands = operands[:len(operands)-1]
+		}
+		if err := nav.assembleFields(buttonPageFields, operands); err != nil {
+			return err
+		}
+		if effectsOff {
+			nav.Source |= buttonPageEffectsOff
+		}
+		return nil
+	case MOBJ_SET_OPTION_SUB1_SETNVTIMER, MOBJ_SET_OPTION_SUB1_STREAMSS:
+		want = 2
+	case MOBJ_SET_OPTION_SUB1_ENABLEBUTTON, MOBJ_SET_OPTION_SUB1_DISABLEBUTTON, MOBJ_SET_OPTION_SUB1_OUTPUTMODE:
+		want = 1
+	}
+	if len(operands) != want {
+		return fmt.Errorf("%s takes %d operands, got %d", mnemonic, want, len(operands))
+	}
+	if err := nav.setOperands(operands...); err != nil {
+		return err
+	}
+	if want > 0 {
+		nav.OperandCount = 2
+	}
+	return nil
+}
+
+// assembleFields packs "name=value [on|off]" operands into Destination
+// and Source. Each of the two holds either numbers or GPRs.
+func (nav *NavigationCommand) assembleFields(fields []streamField, operands []string) error {
+	nav.OperandCount = 2
+	nav.ImmediateValueFlagDest, nav.ImmediateValueFlagSrc = true, true
+	if len(operands) == 1 && operands[0] == "-" {
+		return nil
+	}
+
+	var dstSet, srcSet bool
+	for _, operand := range operands {
+		name, value, ok := strings.Cut(operand, "=")
+		i := slices.IndexFunc(fields, func(field streamField) bool { return field.name == name })
+		if !ok || i < 0 {
+			return fmt.Errorf("unknown field %q", operand)
+		}
+		field := fields[i]
+
+		words := strings.Fields(value)
+		onOff := ""
+		if field.onOffBit >= 0 {
+			if len(words) != 2 || (words[1] != "on" && words[1] != "off") {
+				return fmt.Errorf("field %s needs a value and on or off", name)
+			}
+			onOff = words[1]
+		} else if len(words) != 1 {
+			return fmt.Errorf("field %s needs a value", name)
+		}
+
+		number, immediate, err := parseOperand(words[0])
+		if err != nil {
+			return err
+		}
+		if !immediate {
+			if number&0x80000000 != 0 {
+				return fmt.Errorf("field %s takes a number or a GPR, not a PSR", name)
+			}
+		} else if number > field.mask {
+			return fmt.Errorf("field %s value %d is over %d", name, number, field.mask)
+		}
+
+		target, flag, set := &nav.Destination, &nav.ImmediateValueFlagDest, &dstSet
+		if field.source {
+			target, flag, set = &nav.Source, &nav.ImmediateValueFlagSrc, &srcSet
+		}
+		if *target&(1<<field.flagBit) != 0 {
+			return fmt.Errorf("field %s given twice", name)
+		}
+		if *set && *flag != immediate {
+			return fmt.Errorf("field %s mixes numbers and GPRs in one operand", name)
+		}
+		*set, *flag = true, immediate
+		*target |= 1<<field.flagBit | number<<field.shift
+		if onOff == "on" {
+			*target |= 1 << field.onOffBit
+		}
+	}
+	return nil
+}
+
+// setOperands sets Destination and Source, in that order, and the operand count.
+func (nav *NavigationCommand) setOperands(operands ...string) (err error) {
+	nav.OperandCount = uint8(len(operands))
+	if len(operands) > 0 {
+		if nav.Destination, nav.ImmediateValueFlagDest, err = parseOperand(operands[0]); err != nil {
+			return err
+		}
+	}
+	if len(operands) > 1 {
+		if nav.Source, nav.ImmediateValueFlagSrc, err = parseOperand(operands[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOperand parses GPR[n], PSR[n] or an immediate into the raw operand
+// and its immediate flag.
+func parseOperand(text string) (value uint32, immediate bool, err error) {
+	for _, register := range []struct {
+		prefix string
+		max    uint64
+		bit    uint32
+	}{
+		{"GPR[", 0xFFF, 0},
+		{"PSR[", 0x7F, 0x80000000},
+	} {
+		number, ok := strings.CutPrefix(text, register.prefix)
+		if !ok {
+			continue
+		}
+		number, ok = strings.CutSuffix(number, "]")
+		n, err := strconv.ParseUint(number, 10, 32)
+		if !ok || err != nil || n > register.max {
+			return 0, false, fmt.Errorf("bad register %q", text)
+		}
+		return register.bit | uint32(n), false, nil
+	}
+
+	n, err := parseNumber(text)
+	if err != nil {
+		return 0, false, fmt.Errorf("bad operand %q", text)
+	}
+	return n, true, nil
+}
+
+// parseNumber parses a decimal or 0x hex number. Leading zeros are decimal.
+func parseNumber(text string) (uint32, error) {
+	base := 10
+	if hex, ok := strings.CutPrefix(strings.ToLower(text), "0x"); ok {
+		text, base = hex, 16
+	}
+	n, err := strconv.ParseUint(text, base, 32)
+	return uint32(n), err
+}
+
+func assembleWords(operands []string) (*NavigationCommand, error) {
+	if len(operands) != 3 {
+		return nil, fmt.Errorf(".word takes 3 words, got %d", len(operands))
+	}
+	var words [3]uint32
+	for i, operand := range operands {
+		n, err := parseNumber(operand)
+		if err != nil {
+			return nil, fmt.Errorf("bad word %q", operand)
+		}
+		words[i] = n
+	}
+	opcode := words[0]
+	return &NavigationCommand{
+		OperandCount:           uint8(opcode >> 29),
+		CommandGroup:           uint8(opcode >> 27 & 0x03),
+		CommandSubGroup:        uint8(opcode >> 24 & 0x07),
+		ImmediateValueFlagDest: opcode&0x00800000 != 0,
+		ImmediateValueFlagSrc:  opcode&0x00400000 != 0,
+		BranchOption:           uint8(opcode >> 16 & 0x0F),
+		CompareOption:          uint8(opcode >> 8 & 0x0F),
+		SetOption:              uint8(opcode & 0x1F),
+		Destination:            words[1],
+		Source:                 words[2],
+	}, nil
+}
+
+func isLabel(text string) bool {
+	if text == "" || text[0] >= '0' && text[0] <= '9' {
+		return false
+	}
+	for _, c := range text {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDecimal(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
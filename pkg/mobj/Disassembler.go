@@ -0,0 +1,362 @@
+package mobj
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+	Remarks:
+
+	Every navigation command is 12 bytes: a 4-byte opcode and two 32-bit
+	operands, Destination and Source. An operand is an immediate value
+	when its ImmediateValueFlag is set, and a register otherwise:
+
+		bit 31 set    PSR, player status register 0 to 127 in bits 6..0
+		bit 31 clear  GPR, general purpose register 0 to 4095 in bits 11..0
+
+	The disassembly uses one line per command:
+
+		nop                    goto 3             break
+		jump_object 2          jump_title 4       call_object 2
+		call_title 4           resume
+		play_pl 00800          play_pl_pi 00800, 2
+		play_pl_pm 00800, 5    terminate_pl
+		link_pi 2              link_mk 5
+		if PSR[1] == 2         (also !=, >=, >, <=, < and & for bc)
+		mov GPR[3], 0x10       (also swap, add, sub, mul, div, mod, rnd,
+		                        and, or, xor, bset, bclr, shl and shr)
+		set_stream audio=2, pg=1 on, ig=1, angle=1
+		set_sec_stream video=1 on, audio=1, pip_pg=1 off
+		set_nv_timer 3, 60     button_page button=1, page=0
+		enable_button 3        disable_button 3
+		popup_off              still_on           still_off
+		set_output_mode 1      set_stream_ss ...
+
+	A compare command skips the command after it when the condition is
+	false. Immediates below 10 are written in decimal, larger ones in hex.
+	Within set_stream and set_sec_stream, register operands name a GPR
+	per field, e.g. audio=GPR[1].
+*/
+
+// Operand is a decoded Destination or Source operand.
+type Operand struct {
+	Immediate bool
+	PSR       bool   // A player status register rather than a GPR
+	Value     uint32 // The immediate value or the register number
+}
+
+// DecodeOperand decodes a raw Destination or Source.
+func DecodeOperand(value uint32, immediate bool) Operand {
+	switch {
+	case immediate:
+		return Operand{Immediate: true, Value: value}
+	case value&0x80000000 != 0:
+		return Operand{PSR: true, Value: value & 0x7F}
+	default:
+		return Operand{Value: value & 0xFFF}
+	}
+}
+
+func (o Operand) String() string {
+	switch {
+	case o.Immediate:
+		return immediate(o.Value)
+	case o.PSR:
+		return fmt.Sprintf("PSR[%d]", o.Value)
+	default:
+		return fmt.Sprintf("GPR[%d]", o.Value)
+	}
+}
+
+func immediate(value uint32) string {
+	if value < 10 {
+		return fmt.Sprintf("%d", value)
+	}
+	return fmt.Sprintf("0x%X", value)
+}
+
+// psrNames are the player status registers with a defined meaning.
+var psrNames = map[uint32]string{
+	0:  "IG stream number",
+	1:  "primary audio stream number",
+	2:  "PG/TextST stream number",
+	3:  "angle number",
+	4:  "title number",
+	5:  "chapter number",
+	6:  "playlist number",
+	7:  "PlayItem number",
+	8:  "presentation time",
+	9:  "navigation timer",
+	10: "selected button",
+	11: "menu page",
+	12: "TextST user style",
+	13: "parental level",
+	14: "secondary audio/video stream number",
+	15: "audio capability",
+	16: "audio language",
+	17: "PG/TextST language",
+	18: "menu language",
+	19: "country code",
+	20: "region code",
+	21: "output mode preference",
+	22: "stereoscopic status",
+	23: "display capability",
+	24: "3D capability",
+	29: "video capability",
+	30: "TextST capability",
+	31: "player profile and version",
+	36: "backup of PSR 4",
+	37: "backup of PSR 5",
+	38: "backup of PSR 6",
+	39: "backup of PSR 7",
+	40: "backup of PSR 8",
+	42: "backup of PSR 10",
+	43: "backup of PSR 11",
+	44: "backup of PSR 12",
+}
+
+// PSRName returns what player status register n holds, or "".
+func PSRName(n uint32) string {
+	return psrNames[n]
+}
+
+var branchMnemonics = [3][]string{
+	{"nop", "goto", "break"},
+	{"jump_object", "jump_title", "call_object", "call_title", "resume"},
+	{"play_pl", "play_pl_pi", "play_pl_pm", "terminate_pl", "link_pi", "link_mk"},
+}
+
+var compareOperators = []string{"", "&", "==", "!=", ">=", ">", "<=", "<"}
+
+var setMnemonics = [2][]string{
+	{"", "mov", "swap", "add", "sub", "mul", "div", "mod", "rnd", "and", "or", "xor", "bset", "bclr", "shl", "shr"},
+	{"", "set_stream", "set_nv_timer", "button_page", "enable_button", "disable_button",
+		"set_sec_stream", "popup_off", "still_on", "still_off", "set_output_mode", "set_stream_ss"},
+}
+
+// Mnemonic returns the assembler mnemonic of the command, e.g. "mov"
+// or "jump_title", "if" for a compare, or "" for an unknown opcode.
+func (nav *NavigationCommand) Mnemonic() string {
+	switch nav.CommandGroup {
+	case 0:
+		if int(nav.CommandSubGroup) < len(branchMnemonics) && int(nav.BranchOption) < len(branchMnemonics[nav.CommandSubGroup]) {
+			return branchMnemonics[nav.CommandSubGroup][nav.BranchOption]
+		}
+	case 1:
+		if nav.CommandSubGroup == 0 && nav.CompareOption != 0 && int(nav.CompareOption) < len(compareOperators) {
+			return "if"
+		}
+	case 2:
+		if int(nav.CommandSubGroup) < len(setMnemonics) && int(nav.SetOption) < len(setMnemonics[nav.CommandSubGroup]) {
+			return setMnemonics[nav.CommandSubGroup][nav.SetOption]
+		}
+	}
+	return ""
+}
+
+// Operands returns the decoded Destination and Source.
+func (nav *NavigationCommand) Operands() (dst, src Operand) {
+	return DecodeOperand(nav.Destination, nav.ImmediateValueFlagDest),
+		DecodeOperand(nav.Source, nav.ImmediateValueFlagSrc)
+}
+
+// Disassemble renders the command as one line of assembly, e.g.
+// "mov GPR[3], 0x10". An unknown opcode is rendered as ".word" and
+// the three 32-bit words of the command.
+func (nav *NavigationCommand) Disassemble() string {
+	mnemonic := nav.Mnemonic()
+	if mnemonic == "" {
+		return fmt.Sprintf(".word 0x%08X, 0x%08X, 0x%08X", nav.opcode(), nav.Destination, nav.Source)
+	}
+	dst, src := nav.Operands()
+
+	switch {
+	case nav.CommandGroup == 0 && nav.CommandSubGroup == 2:
+		switch nav.BranchOption {
+		case MOBJ_BRANCH_OPTION_SUB2_PLAYLIST:
+			return mnemonic + " " + playlistOperand(dst)
+		case MOBJ_BRANCH_OPTION_SUB2_PLAYITEM, MOBJ_BRANCH_OPTION_SUB2_PLAYMARK:
+			return mnemonic + " " + playlistOperand(dst) + ", " + decimalOperand(src)
+		case MOBJ_BRANCH_OPTION_SUB2_LINKITEM, MOBJ_BRANCH_OPTION_SUB2_LINKMARK:
+			return mnemonic + " " + decimalOperand(dst)
+		}
+		return mnemonic
+	case nav.CommandGroup == 0:
+		switch mnemonic {
+		case "nop", "break", "resume":
+			return mnemonic
+		}
+		return mnemonic + " " + decimalOperand(dst)
+	case nav.CommandGroup == 1:
+		return fmt.Sprintf("if %s %s %s", dst, compareOperators[nav.CompareOption], src)
+	case nav.CommandSubGroup == 1:
+		return nav.disassembleSetSystem(mnemonic, dst, src)
+	}
+	return fmt.Sprintf("%s %s, %s", mnemonic, dst, src)
+}
+
+// opcode returns the first 4 bytes of the command as stored on disc.
+func (nav *NavigationCommand) opcode() uint32 {
+	return uint32(nav.OperandCount&0x07)<<29 |
+		uint32(nav.CommandGroup&0x03)<<27 |
+		uint32(nav.CommandSubGroup&0x07)<<24 |
+		uint32(setFlag(nav.ImmediateValueFlagDest, 0x80)|setFlag(nav.ImmediateValueFlagSrc, 0x40)|nav.BranchOption&0x0F)<<16 |
+		uint32(nav.CompareOption&0x0F)<<8 |
+		uint32(nav.SetOption&0x1F)
+}
+
+// decimalOperand writes an immediate number, e.g. a title or a command
+// index, in decimal.
+func decimalOperand(o Operand) string {
+	if o.Immediate {
+		return fmt.Sprintf("%d", o.Value)
+	}
+	return o.String()
+}
+
+func playlistOperand(o Operand) string {
+	if o.Immediate {
+		return fmt.Sprintf("%05d", o.Value)
+	}
+	return o.String()
+}
+
+func (nav *NavigationCommand) disassembleSetSystem(mnemonic string, dst, src Operand) string {
+	switch nav.SetOption {
+	case MOBJ_SET_OPTION_SUB1_SETSTREAM:
+		return mnemonic + " " + nav.streamFields(setStreamFields)
+	case MOBJ_SET_OPTION_SUB1_SETSECONDARYSTREAM:
+		return mnemonic + " " + nav.streamFields(setSecondaryStreamFields)
+	case MOBJ_SET_OPTION_SUB1_BUTTONPAGE:
+		text := mnemonic + " " + nav.streamFields(buttonPageFields)
+		if nav.Source&buttonPageEffectsOff != 0 {
+			text += ", effects_off"
+		}
+		return text
+	case MOBJ_SET_OPTION_SUB1_SETNVTIMER:
+		return fmt.Sprintf("%s %s, %s", mnemonic, decimalOperand(dst), decimalOperand(src))
+	case MOBJ_SET_OPTION_SUB1_ENABLEBUTTON, MOBJ_SET_OPTION_SUB1_DISABLEBUTTON, MOBJ_SET_OPTION_SUB1_OUTPUTMODE:
+		return mnemonic + " " + decimalOperand(dst)
+	case MOBJ_SET_OPTION_SUB1_STREAMSS:
+		return fmt.Sprintf("%s %s, %s", mnemonic, dst, src)
+	}
+	return mnemonic
+}
+
+// streamField is one flagged number packed into a set_stream style operand.
+type streamField struct {
+	name     string
+	source   bool   // In Source rather than Destination
+	flagBit  uint   // Set when the field is used
+	shift    uint   // Position of the number
+	mask     uint32 // Width of the number
+	onOffBit int    // Display flag bit, or -1
+}
+
+var (
+	setStreamFields = []streamField{
+		{"audio", false, 31, 16, 0xFFF, -1},
+		{"pg", false, 15, 0, 0xFFF, 14},
+		{"ig", true, 31, 16, 0xFF, -1},
+		{"angle", true, 15, 0, 0xFF, -1},
+	}
+	setSecondaryStreamFields = []streamField{
+		{"video", false, 31, 16, 0xFF, 30},
+		{"audio", false, 15, 0, 0xFF, -1},
+		{"pip_pg", true, 31, 16, 0xFF, 30},
+	}
+	buttonPageFields = []streamField{
+		{"button", false, 31, 0, 0xFFFF, -1},
+		{"page", true, 31, 0, 0xFF, -1},
+	}
+)
+
+// buttonPageEffectsOff is the Source bit of button_page that turns the
+// page change effects off.
+const buttonPageEffectsOff = 0x40000000
+
+func (nav *NavigationCommand) streamFields(fields []streamField) string {
+	var parts []string
+	for _, field := range fields {
+		operand, immediate := nav.Destination, nav.ImmediateValueFlagDest
+		if field.source {
+			operand, immediate = nav.Source, nav.ImmediateValueFlagSrc
+		}
+		if operand&(1<<field.flagBit) == 0 {
+			continue
+		}
+		value := operand >> field.shift & field.mask
+		text := field.name + "="
+		if immediate {
+			text += fmt.Sprintf("%d", value)
+		} else {
+			text += fmt.Sprintf("GPR[%d]", value&0xFFF)
+		}
+		if field.onOffBit >= 0 {
+			if operand&(1<<field.onOffBit) != 0 {
+				text += " on"
+			} else {
+				text += " off"
+			}
+		}
+		parts = append(parts, text)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Comment names the well-known PSRs the command reads or writes,
+// e.g. "PSR[4] title number", or returns "".
+func (nav *NavigationCommand) Comment() string {
+	if nav.CommandGroup == 2 && nav.CommandSubGroup == 1 {
+		return ""
+	}
+	var names []string
+	dst, src := nav.Operands()
+	for _, operand := range []Operand{dst, src} {
+		if operand.PSR && PSRName(operand.Value) != "" {
+			name := fmt.Sprintf("PSR[%d] %s", operand.Value, PSRName(operand.Value))
+			if len(names) == 0 || names[0] != name {
+				names = append(names, name)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// WriteListing writes the movie object with the given id as a program
+// listing: a header line, then one numbered line of assembly per command.
+func (mobj *MovieObject) WriteListing(w io.Writer, id int) error {
+	var flags []string
+	if mobj.ResumeIntentionFlag {
+		flags = append(flags, "resume")
+	}
+	if mobj.MenuCallMask {
+		flags = append(flags, "menu_call_mask")
+	}
+	if mobj.TitleSearchMask {
+		flags = append(flags, "title_search_mask")
+	}
+	header := fmt.Sprintf("movie_object %d", id)
+	if len(flags) > 0 {
+		header += " " + strings.Join(flags, " ")
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return fmt.Errorf("failed to write listing: %w", err)
+	}
+
+	for i, nav := range mobj.NavigationCommands {
+		line := fmt.Sprintf("  %04d  %s", i, nav.Disassemble())
+		if comment := nav.Comment(); comment != "" {
+			line = fmt.Sprintf("%-40s ; %s", line, comment)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write listing: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package mobj
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+var (
+	// ErrExtension is wrapped by errors from decoding an extension entry
+	// of a known type and version. Unknown entries are not an error; they
+	// are kept as an ExtensionRaw.
+	ErrExtension = errors.New("malformed extension data")
+)
+
+// ParseError reports where in a file parsing failed.
+// Use errors.Is on it to test for the sentinel errors above.
+type ParseError = navfile.ParseError
+
+// AssemblyError reports the line of a listing that failed to assemble.
+type AssemblyError struct {
+	Line int // 1-based line number in the listing
+	Err  error
+}
+
+func (e *AssemblyError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *AssemblyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,19 @@
+package mobj
+
+import (
+	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+)
+
+// ExtensionRaw implements the ExtensionEntryData interface.
+// It holds the undecoded bytes of an extension this package does not
+// know.
+type ExtensionRaw struct {
+	navfile.ExtensionRaw
+}
+
+// Read reads ExtDataLength bytes of extension data.
+func (raw *ExtensionRaw) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
+	return raw.ReadEntry(file, offsets.Start+int64(entryMeta.ExtDataStartAddress), offsets.Stop, entryMeta.ExtDataLength)
+}
@@ -1,8 +1,8 @@
 package mobj
 
 import (
+	"fmt"
 	"io"
-	"log"
 )
 
 // Because there are many different types of extension data...
@@ -11,6 +11,7 @@ import (
 // That way each extension can calculate boundaries.
 type ExtensionEntryData interface {
 	Read(io.ReadSeeker, *OffsetsUint32, *ExtensionEntryMetaData) error
+	MarshalBinary() ([]byte, error)
 }
 
 // ReadExtensionEntryData reads the extension entry data from the provided io.ReadSeeker.
@@ -44,25 +45,19 @@ func ReadExtensionEntryData(file io.ReadSeeker, offsets *OffsetsUint32, metaData
 			//entriesData[i] = &ExtensionStaticMetaData{}
 		}
 
-		// Skip any unimplemented extensions.
-		if entriesData[i] != nil {
-
-			if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
-				// XXX - if the extension fails, it's not fatal.
-				// xxx - because some extensions might have errors (MVC mostly)
-				//return nil, fmt.Errorf("failed to read ExtensionEntryData: %w", err)
-				//fmt.Printf("\n\n WARNING! EXTENSION ERROR WHILE PARSING:\t[%+v:%+v]\n\n", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
-
-				// XXX - temporary fatal error to help debugging.
-				// This should be removed when all extensions are implemented.
-				log.Fatalf("failed to read ExtensionEntryData: %v\n", err)
+		// Keep unimplemented extensions as opaque bytes.
+		if entriesData[i] == nil {
+			entriesData[i] = &ExtensionRaw{}
+		}
 
+		if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
+			return nil, &ParseError{
+				Offset: offsets.Start + int64(entryMeta.ExtDataStartAddress),
+				Path:   fmt.Sprintf("Extensions.EntriesData[%d]", i),
+				Err: fmt.Errorf("%w: type %d version %d: %w",
+					ErrExtension, entryMeta.ExtDataType, entryMeta.ExtDataVersion, err),
 			}
-
-		} else {
-			log.Fatal("Extension not implemented: ", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
 		}
-
 	}
 
 	return entriesData, nil
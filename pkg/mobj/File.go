@@ -0,0 +1,60 @@
+package mobj
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed MovieObject.bdmv file: the sections
+// ParseMOBJ returns one by one.
+type File struct {
+	Header       *MOBJHeader
+	MovieObjects *MovieObjects
+	Extensions   *Extensions
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *MOBJHeader,
+	movieObjects *MovieObjects,
+	extensions *Extensions,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, movieObjects, extensions}, nil
+}
+
+// ParseFile parses the MovieObject.bdmv file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseMOBJ(filePath))
+}
+
+// ParseFS parses the MovieObject.bdmv file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseMOBJFS(fsys, name))
+}
+
+// ParseReader parses a MovieObject.bdmv file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseMOBJ(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as a MovieObject.bdmv file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses a MovieObject.bdmv file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
+
+// Write serializes the file to w with WriteMOBJ.
+func (file *File) Write(w io.Writer) error {
+	return WriteMOBJ(w, file.Header, file.MovieObjects, file.Extensions)
+}
@@ -1,6 +1,7 @@
 package mobj
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -55,6 +56,22 @@ func ReadMOBJHeader(file io.ReadSeeker) (header *MOBJHeader, err error) {
 	return header, nil
 }
 
+// MarshalBinary encodes the 40 byte header. The extension data start
+// address is taken from the Extensions offsets; WriteMOBJ sets them
+// before calling this.
+func (header *MOBJHeader) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, header.TypeIndicator)
+	binary.Write(buf, binary.BigEndian, header.VersionNumber)
+	binary.Write(buf, binary.BigEndian, uint32(header.Extensions.Start))
+
+	// 28-byte reserve space
+	buf.Write(make([]byte, 28))
+
+	return buf.Bytes(), nil
+}
+
 func (offsets *OffsetsUint32) String() string {
 	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
 }
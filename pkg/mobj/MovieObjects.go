@@ -1,9 +1,12 @@
 package mobj
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type MovieObjects struct {
@@ -76,7 +79,7 @@ func ReadMovieObject(file io.ReadSeeker) (mobj *MovieObject, err error) {
 		return nil, fmt.Errorf("failed to read play item length: %w", err)
 	}
 	mobj.ResumeIntentionFlag = (buffer&0x80 != 0)
-	mobj.MenuCallMask = (buffer&040 != 0)
+	mobj.MenuCallMask = (buffer&0x40 != 0)
 	mobj.TitleSearchMask = (buffer&0x20 != 0)
 
 	// skip 1-byte reserve space
@@ -107,7 +110,7 @@ func ReadNavCmd(file io.ReadSeeker) (nav *NavigationCommand, err error) {
 	}
 	nav.OperandCount = (buffer & 0xE0) >> 5 // 0b11100000
 	nav.CommandGroup = (buffer & 0x18) >> 3 // 0b00011000
-	nav.CommandSubGroup = (buffer & 0x03)   // 0b00000011
+	nav.CommandSubGroup = (buffer & 0x07)   // 0b00000111
 
 	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
 		return nil, fmt.Errorf("failed to read buffer: %w", err)
@@ -136,3 +139,61 @@ func ReadNavCmd(file io.ReadSeeker) (nav *NavigationCommand, err error) {
 
 	return nav, err
 }
+
+// MarshalBinary encodes the MovieObjects section. The length and the
+// number of movie objects are computed from MovieObjects.
+func (mobjs *MovieObjects) MarshalBinary() ([]byte, error) {
+	if len(mobjs.MovieObjects) > 0xFFFF {
+		return nil, fmt.Errorf("too many movie objects: %d", len(mobjs.MovieObjects))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 4-byte reserve space
+	buf.Write(make([]byte, 4))
+
+	binary.Write(buf, binary.BigEndian, uint16(len(mobjs.MovieObjects)))
+	for i, mobj := range mobjs.MovieObjects {
+		data, err := mobj.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MovieObject[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// MarshalBinary encodes one movie object. The number of navigation
+// commands is computed from NavigationCommands.
+func (mobj *MovieObject) MarshalBinary() ([]byte, error) {
+	if len(mobj.NavigationCommands) > 0xFFFF {
+		return nil, fmt.Errorf("too many navigation commands: %d", len(mobj.NavigationCommands))
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(setFlag(mobj.ResumeIntentionFlag, 0x80) | setFlag(mobj.MenuCallMask, 0x40) | setFlag(mobj.TitleSearchMask, 0x20))
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	binary.Write(buf, binary.BigEndian, uint16(len(mobj.NavigationCommands)))
+	for _, nav := range mobj.NavigationCommands {
+		data, err := nav.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary encodes the 12 byte navigation command.
+func (nav *NavigationCommand) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, nav.opcode())
+	binary.Write(buf, binary.BigEndian, nav.Destination)
+	binary.Write(buf, binary.BigEndian, nav.Source)
+	return buf.Bytes(), nil
+}
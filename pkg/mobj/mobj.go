@@ -3,8 +3,12 @@ package mobj
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"strings"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // Why this is not part of the standard library boggles the mind.
@@ -23,6 +27,13 @@ func PadPrintln(indent int, args ...any) {
 	fmt.Println(args...)
 }
 
+func setFlag(flag bool, mask uint8) uint8 {
+	if flag {
+		return mask
+	}
+	return 0
+}
+
 func CalculateEndOffset[U uint8 | uint16 | uint32](file io.ReadSeeker, length U) (int64, error) {
 	currentPos, err := file.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -44,22 +55,87 @@ func ParseMOBJ(filePath string) (
 	}
 	defer file.Close()
 
+	return parseMOBJ(file, filePath)
+}
+
+// ParseMOBJFS is ParseMOBJ for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseMOBJFS(fsys fs.FS, name string) (
+	header *MOBJHeader,
+	movieObjects *MovieObjects,
+	extensiondata *Extensions,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseMOBJ(file, name)
+}
+
+func parseMOBJ(file io.ReadSeeker, filePath string) (
+	header *MOBJHeader,
+	movieObjects *MovieObjects,
+	extensiondata *Extensions,
+	err error,
+) {
 	// Header
 	if header, err = ReadMOBJHeader(file); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
+		return nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
 	}
 
 	// MovieObjects
 	if movieObjects, err = ReadMovieObjects(file, header.MovieObjects); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to read appinfo: %w", err)
+		return nil, nil, nil, navfile.SectionError(filePath, "MovieObjects", header.MovieObjects.Start, err)
 	}
 
 	// Extensions
 	if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
 		if extensiondata, err = ReadExtensions(file, header.Extensions); err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to read Extension Data: %w", err)
+			return nil, nil, nil, navfile.SectionError(filePath, "Extensions", header.Extensions.Start, err)
 		}
 	}
 
 	return header, movieObjects, extensiondata, nil
 }
+
+// WriteMOBJ serializes the movie objects to file in the MOBJ layout.
+// The MovieObjects length and counts are recomputed from the data, and the
+// header offsets are updated to describe the written file. Reserved bits
+// are written as zeros.
+func WriteMOBJ(file io.Writer, header *MOBJHeader, movieObjects *MovieObjects, extensiondata *Extensions) error {
+	movieObjectsBytes, err := movieObjects.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal movie objects: %w", err)
+	}
+
+	var extensionBytes []byte
+	if extensiondata != nil {
+		if extensionBytes, err = extensiondata.MarshalBinary(); err != nil {
+			return fmt.Errorf("failed to marshal Extension Data: %w", err)
+		}
+	}
+
+	header.MovieObjects = &OffsetsUint32{Start: 40, Stop: 40 + int64(len(movieObjectsBytes))}
+	header.Extensions = &OffsetsUint32{Start: 0, Stop: 0}
+	if len(extensionBytes) > 0 {
+		header.Extensions = &OffsetsUint32{
+			Start: header.MovieObjects.Stop,
+			Stop:  header.MovieObjects.Stop + int64(len(extensionBytes)),
+		}
+	}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	for _, section := range [][]byte{headerBytes, movieObjectsBytes, extensionBytes} {
+		if _, err := file.Write(section); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return nil
+}
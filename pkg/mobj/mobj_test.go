@@ -0,0 +1,227 @@
+package mobj
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cmd builds a command from its opcode word, as libbluray and the spec list them.
+func cmd(opcode, dst, src uint32) *NavigationCommand {
+	return &NavigationCommand{
+		OperandCount:           uint8(opcode >> 29),
+		CommandGroup:           uint8(opcode >> 27 & 0x03),
+		CommandSubGroup:        uint8(opcode >> 24 & 0x07),
+		ImmediateValueFlagDest: opcode&0x00800000 != 0,
+		ImmediateValueFlagSrc:  opcode&0x00400000 != 0,
+		BranchOption:           uint8(opcode >> 16 & 0x0F),
+		CompareOption:          uint8(opcode >> 8 & 0x0F),
+		SetOption:              uint8(opcode & 0x1F),
+		Destination:            dst,
+		Source:                 src,
+	}
+}
+
+// disassembleTests are commands and their assembly.
+var disassembleTests = []struct {
+	nav  *NavigationCommand
+	want string
+}{
+	{cmd(0x00000000, 0, 0), "nop"},
+	{cmd(0x20810000, 5, 0), "goto 5"},
+	{cmd(0x00020000, 0, 0), "break"},
+	{cmd(0x21810000, 4, 0), "jump_title 4"},
+	{cmd(0x21010000, 1, 0), "jump_title GPR[1]"},
+	{cmd(0x21820000, 2, 0), "call_object 2"},
+	{cmd(0x01040000, 0, 0), "resume"},
+	{cmd(0x22800000, 800, 0), "play_pl 00800"},
+	{cmd(0x42C20000, 800, 5), "play_pl_pm 00800, 5"},
+	{cmd(0x02030000, 0, 0), "terminate_pl"},
+	{cmd(0x48400200, 0x80000001, 2), "if PSR[1] == 2"},
+	{cmd(0x48000100, 3, 0x80000004), "if GPR[3] & PSR[4]"},
+	{cmd(0x48400700, 3, 100), "if GPR[3] < 0x64"},
+	{cmd(0x50400001, 3, 0x10), "mov GPR[3], 0x10"},
+	{cmd(0x50000001, 0, 0x80000004), "mov GPR[0], PSR[4]"},
+	{cmd(0x5040000F, 7, 2), "shr GPR[7], 2"},
+	{cmd(0x51C00001, 0x80020000, 0x00008001), "set_stream audio=2, angle=1"},
+	{cmd(0x51400001, 0x0000C001, 0x80030000), "set_stream pg=GPR[1] on, ig=3"},
+	{cmd(0x51C00003, 0x80000002, 0x40000000), "button_page button=2, effects_off"},
+	{cmd(0x51800004, 3, 0), "enable_button 3"},
+	{cmd(0x11000007, 0, 0), "popup_off"},
+	{cmd(0x51C00002, 3, 60), "set_nv_timer 3, 60"},
+	{cmd(0x00070000, 1, 2), ".word 0x00070000, 0x00000001, 0x00000002"},
+}
+
+func TestDisassemble(t *testing.T) {
+	for _, tt := range disassembleTests {
+		if got := tt.nav.Disassemble(); got != tt.want {
+			t.Errorf("Disassemble(%08X) = %q, want %q", tt.nav.opcode(), got, tt.want)
+		}
+	}
+}
+
+func TestWriteListing(t *testing.T) {
+	movieObject := &MovieObject{
+		ResumeIntentionFlag: true,
+		NavigationCommands: []*NavigationCommand{
+			cmd(0x48400300, 0x80000004, 1),
+			cmd(0x21810000, 1, 0),
+			cmd(0x22800000, 800, 0),
+		},
+	}
+	out := &bytes.Buffer{}
+	if err := movieObject.WriteListing(out, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := "movie_object 2 resume\n" +
+		"  0000  if PSR[4] != 1                   ; PSR[4] title number\n" +
+		"  0001  jump_title 1\n" +
+		"  0002  play_pl 00800\n"
+	if out.String() != want {
+		t.Errorf("WriteListing() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestAssembleCommand(t *testing.T) {
+	for _, tt := range disassembleTests {
+		nav, err := AssembleCommand(tt.want)
+		if err != nil {
+			t.Errorf("AssembleCommand(%q) error = %v", tt.want, err)
+			continue
+		}
+		if *nav != *tt.nav {
+			t.Errorf("AssembleCommand(%q) = %08X %08X %08X, want %08X %08X %08X", tt.want,
+				nav.opcode(), nav.Destination, nav.Source, tt.nav.opcode(), tt.nav.Destination, tt.nav.Source)
+		}
+	}
+
+	for _, text := range []string{
+		"goto",
+		"mov GPR[4096], 1",
+		"if GPR[0] <> 1",
+		"set_stream pg=1",
+		"set_stream audio=1, pg=GPR[2] on",
+		"set_stream angle=PSR[3]",
+		"jump",
+	} {
+		if _, err := AssembleCommand(text); err == nil {
+			t.Errorf("AssembleCommand(%q) accepted bad assembly", text)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	listing := `# First Play goes straight to the feature.
+movie_object 0 resume menu_call_mask
+  0000  if PSR[16] != 0x667261    ; PSR[16] audio language
+  0001  goto feature
+  0002  play_pl 00002
+feature:
+  0003  jump_title 1
+movie_object 1 title_search_mask
+  play_pl_pm 00800, 0
+`
+	movieObjects, err := Assemble(strings.NewReader(listing))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movieObjects.NumberOfMovieObjects != 2 || movieObjects.Length != 4+2+(4+4*12)+(4+12) {
+		t.Errorf("NumberOfMovieObjects, Length = %d, %d", movieObjects.NumberOfMovieObjects, movieObjects.Length)
+	}
+	first := movieObjects.MovieObjects[0]
+	if !first.ResumeIntentionFlag || !first.MenuCallMask || first.TitleSearchMask || first.NumberOfNavigationCommands != 4 {
+		t.Errorf("movie object 0 = %+v", first)
+	}
+	if got := first.NavigationCommands[1].Disassemble(); got != "goto 3" {
+		t.Errorf("goto feature = %q, want goto 3", got)
+	}
+
+	// Through a file and back
+	path := filepath.Join(t.TempDir(), "MovieObject.bdmv")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &MOBJHeader{TypeIndicator: [4]byte{'M', 'O', 'B', 'J'}, VersionNumber: [4]byte{'0', '2', '0', '0'}}
+	if err := WriteMOBJ(file, header, movieObjects, nil); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	_, parsed, _, err := ParseMOBJ(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	for i, mobj := range parsed.MovieObjects {
+		mobj.WriteListing(out, i)
+	}
+	want := "movie_object 0 resume menu_call_mask\n" +
+		"  0000  if PSR[16] != 0x667261           ; PSR[16] audio language\n" +
+		"  0001  goto 3\n" +
+		"  0002  play_pl 00002\n" +
+		"  0003  jump_title 1\n" +
+		"movie_object 1 title_search_mask\n" +
+		"  0000  play_pl_pm 00800, 0\n"
+	if out.String() != want {
+		t.Errorf("listing after WriteMOBJ =\n%s\nwant\n%s", out, want)
+	}
+
+	for listing, line := range map[string]int{
+		"play_pl 1\n":                            1,
+		"movie_object 1\n":                       1,
+		"movie_object 0\n  goto nowhere\n":       2,
+		"movie_object 0\nnop\n\nmov GPR[0], x\n": 4,
+	} {
+		var assemblyErr *AssemblyError
+		if _, err := Assemble(strings.NewReader(listing)); !errors.As(err, &assemblyErr) || assemblyErr.Line != line {
+			t.Errorf("Assemble(%q) error = %v, want one on line %d", listing, err, line)
+		}
+	}
+}
+
+func TestParseMOBJExtensions(t *testing.T) {
+	// The fixtures hold one extension entry of a type and version nobody
+	// knows, the second claiming more data than the extensions block holds.
+	tests := []struct {
+		name     string
+		filePath string
+		wantRaw  []byte      // Data of EntriesData[0], an ExtensionRaw
+		wantErr  *ParseError // Without its Err
+	}{
+		{
+			name:     "unknown extension",
+			filePath: "testdata/unknown_extension.bdmv",
+			wantRaw:  []byte{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "extension overrun",
+			filePath: "testdata/extension_overrun.bdmv",
+			wantErr:  &ParseError{File: "testdata/extension_overrun.bdmv", Offset: 82, Path: "Extensions.EntriesData[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, extensiondata, err := ParseMOBJ(tt.filePath)
+			if tt.wantErr != nil {
+				var parseErr *ParseError
+				if !errors.Is(err, ErrExtension) || !errors.As(err, &parseErr) {
+					t.Fatalf("ParseMOBJ() error = %v, want a ParseError wrapping ErrExtension", err)
+				}
+				if got := (ParseError{File: parseErr.File, Offset: parseErr.Offset, Path: parseErr.Path}); got != *tt.wantErr {
+					t.Errorf("ParseError = %+v, want %+v", got, *tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMOBJ() error = %v", err)
+			}
+			raw, ok := extensiondata.EntriesData[0].(*ExtensionRaw)
+			if !ok || !bytes.Equal(raw.Data, tt.wantRaw) {
+				t.Errorf("EntriesData[0] = %v, want an ExtensionRaw of % x", extensiondata.EntriesData[0], tt.wantRaw)
+			}
+		})
+	}
+}
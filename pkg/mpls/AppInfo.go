@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // AppInfo holds application-specific information in an MPLS file.
@@ -67,6 +70,35 @@ func ReadAppInfo(file io.ReadSeeker, offsets *OffsetsUint32) (appinfo *AppInfo,
 	return appinfo, nil
 }
 
+// MarshalBinary encodes the AppInfo structure, including its Length field.
+func (appinfo *AppInfo) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// Reserve space 1 byte.
+	buf.WriteByte(0)
+
+	binary.Write(buf, binary.BigEndian, appinfo.PlaybackType)
+	binary.Write(buf, binary.BigEndian, appinfo.PlaybackCount)
+
+	userOptions, err := appinfo.UserOptions.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UserOptions: %w", err)
+	}
+	buf.Write(userOptions)
+
+	// flags 5 bits of 1 byte
+	buf.WriteByte(setFlag(appinfo.RandomAccessFlag, 0x80) |
+		setFlag(appinfo.AudioMixFlag, 0x40) |
+		setFlag(appinfo.LosslessBypassFlag, 0x20) |
+		setFlag(appinfo.MVCBaseViewRFlag, 0x10) |
+		setFlag(appinfo.SDRConversionNotificationFlag, 0x08))
+
+	// Reserve space 1 byte.
+	buf.WriteByte(0)
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (appinfo *AppInfo) String() string {
 	return fmt.Sprintf("AppInfo:\n"+
 		"  Length: %d\n"+
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // Testing:
@@ -24,30 +27,35 @@ type ExtensionMVCStream struct {
 }
 
 // MVCStream represents a single MVC (Multiview Video Coding) stream entry in an MPLS file.
+// Only the leading fields are decoded; the rest of the entry is kept
+// as-is in Remainder so it can be written back unchanged. An entry with
+// the broken 0xFF00 length (see above) is not decoded at all: Entry and
+// Attr are nil and Remainder holds the whole of it.
 type MVCStream struct {
 	Length                  uint16
 	FixedOffsetPopUpFlag    bool // 0b10000000
 	Entry                   StreamEntry
 	Attr                    StreamAttributes
 	NumberOfOffsetSequences uint8
-	//remainder               []byte
+	Remainder               []byte
 }
 
 // ReadMVC reads a single MVCStream entry from the provided io.ReadSeeker.
-// It expects the file (io.ReadSeeker) to be positioned at the start of the
-// MVCStream structure.
+// It expects the file (io.ReadSeeker) to be positioned just after the
+// Length field of the MVCStream structure.
 func (mvcStream *MVCStream) ReadMVC(file io.ReadSeeker, length uint16) (err error) {
-
-	pos, _ := ftell(file)
-	PadPrintf(2, "ReadMVC pos: %d\n", pos)
 	mvcStream.Length = length
 
+	end, err := CalculateEndOffset(file, length)
+	if err != nil {
+		return fmt.Errorf("failed calling CalculateEndOffset(): %w", err)
+	}
+
 	var buffer byte
 	if err := binary.Read(file, binary.BigEndian, &buffer); err != nil {
 		return fmt.Errorf("failed to read MVCStream.buffer: %w", err)
 	}
 	mvcStream.FixedOffsetPopUpFlag = buffer&0x80 != 0 // 0b10000000
-	PadPrintf(4, "MVCStream.FixedOffsetPopUpFlag: %+v\n", mvcStream.FixedOffsetPopUpFlag)
 
 	// 1-byte reserve space
 	if _, err := file.Seek(1, io.SeekCurrent); err != nil {
@@ -58,13 +66,11 @@ func (mvcStream *MVCStream) ReadMVC(file io.ReadSeeker, length uint16) (err erro
 	if err != nil {
 		return fmt.Errorf("failed to read StreamEntry: %w", err)
 	}
-	PadPrintf(4, "MVCStream.Entry: %+v\n", mvcStream.Entry)
 
 	mvcStream.Attr, err = ReadStreamAttributes(file, STREAM_TYPE_PRIMARY_VIDEO)
 	if err != nil {
 		return fmt.Errorf("failed to read StreamAttributes: %w", err)
 	}
-	PadPrintf(4, "MVCStream.Attr: %+v\n", mvcStream.Attr)
 
 	// 1-byte reserve space
 	if _, err := file.Seek(1, io.SeekCurrent); err != nil {
@@ -74,113 +80,130 @@ func (mvcStream *MVCStream) ReadMVC(file io.ReadSeeker, length uint16) (err erro
 	if err := binary.Read(file, binary.BigEndian, &mvcStream.NumberOfOffsetSequences); err != nil {
 		return fmt.Errorf("failed to read MVCStream.NumberOfOffsetSequences: %w", err)
 	}
-	PadPrintf(4, "MVCStream.NumberOfOffsetSequences: %d\n", mvcStream.NumberOfOffsetSequences)
+
+	// Keep whatever follows, up to the end of this entry.
+	pos, err := ftell(file)
+	if err != nil {
+		return fmt.Errorf("failed to get current position: %w", err)
+	}
+	if end < pos {
+		return fmt.Errorf("MVCStream.Length (%d) is too short", length)
+	}
+	mvcStream.Remainder = make([]byte, end-pos)
+	if err := binary.Read(file, binary.BigEndian, &mvcStream.Remainder); err != nil {
+		return fmt.Errorf("failed to read MVCStream.Remainder: %w", err)
+	}
+
 	return nil
 }
 
 func (extensionMVCStream *ExtensionMVCStream) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
 
-	PadPrintln(0, "MVC Extension:")
-	PadPrintln(2, "---")
-
 	// Calculate the Start/Stop offsets for this extension.
 	offsetStart := offsets.Start + int64(entryMeta.ExtDataStartAddress)
 	offsetStop := offsetStart + int64(entryMeta.ExtDataLength)
-	PadPrintf(2, "offsetStart == %d\n", offsetStart)
-	PadPrintf(2, "offsetStop  == %d\n", offsetStop)
-	PadPrintln(2, "---")
 
 	// Jump to the start offset
-	if _, err := file.Seek(int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", entryMeta.ExtDataStartAddress, err)
+	if _, err := file.Seek(offsetStart, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", offsetStart, err)
 	}
 
-	// XXX - DEBUG block
-	PadPrintln(0, "Extensions Entry DEBUG:")
-	PadPrintf(2, "ExtDataType == %d\n", entryMeta.ExtDataType)
-	PadPrintf(2, "ExtDataVersion == %d\n", entryMeta.ExtDataVersion)
-	PadPrintf(2, "ExtDataStartAddress == %d\n", entryMeta.ExtDataStartAddress)
-	PadPrintf(2, "ExtDataLength == %d\n", entryMeta.ExtDataLength)
-	fmt.Println("---")
-	// XXX - EO DEBUG block
-
 	var loopIterLength uint16
 	var loopIterEnd int64
-	for i, loopIterStart := 1, offsetStart; loopIterEnd < offsetStop; i, loopIterStart = i+1, loopIterEnd {
-
-		PadPrintf(4, "[%d] loopIterStart: %d\n", i, loopIterStart)
+	for loopIterStart := offsetStart; loopIterEnd < offsetStop; loopIterStart = loopIterEnd {
 
 		// Before reading the length field...
 		// Calculate if the uint16 (2-bytes) would go out of bounds.
 		if loopIterStart+2 > offsetStop {
-			PadPrintf(4, "[%d] Not enough space to read Length var uint16. break!\n", i)
-			PadPrintf(4, "[%d] %d+2 > %d\n", i, loopIterStart, offsetStop)
-
 			break
 		}
 
 		// Then go ahead to take the length uint16
 		if err := binary.Read(file, binary.BigEndian, &loopIterLength); err != nil {
-			return fmt.Errorf("[%d] failed to read MVCStream.Length: %w", i, err)
+			return fmt.Errorf("failed to read MVCStream.Length: %w", err)
 		}
-		PadPrintf(4, "[%d] loopIterLength: %+v\n", i, loopIterLength)
 
 		loopIterEnd = loopIterStart + 2 + int64(loopIterLength)
-		PadPrintf(4, "[%d] loopIterEnd: %d\n", i, loopIterEnd)
 
 		// Before initializing an instance of MVCStream, run sanity checks on the length.
 		if loopIterLength == 0 {
 			break
 
-		} else if loopIterLength == 0xFF00 {
-			continue // xxx - reject that case, for now...
-
 		} else if loopIterEnd > offsetStop {
 			// Next, if the length would go out of bounds, then bail
-			PadPrintf(4, "[%d] Not enough space to read Length of segment. break!\n", i)
-			PadPrintf(4, "[%d] %d+2 > %d\n", i, loopIterEnd, offsetStop)
 			break
-		}
 
-		// MVCStream item
-		MVCStream := &MVCStream{}
-
-		// This reads exactly 18-bytes + 2-bytes (length)
-		if err := MVCStream.ReadMVC(file, loopIterLength); err != nil {
-
-			// XXX - the error would be an IO error.
-			// Very unlikely give all thge sanity checks on boundaries.
+		} else if loopIterLength == 0xFF00 {
+			// Keep it undecoded, so it is written back and the entries
+			// after it still line up with their PlayItems.
+			mvcStream := &MVCStream{Length: loopIterLength, Remainder: make([]byte, loopIterLength)}
+			if _, err := io.ReadFull(file, mvcStream.Remainder); err != nil {
+				return fmt.Errorf("failed to read MVCStream: %w", err)
+			}
+			extensionMVCStream.MVCStreams = append(extensionMVCStream.MVCStreams, mvcStream)
 			continue
 		}
 
-		// Append the structure to the end.
-		extensionMVCStream.MVCStreams = append(extensionMVCStream.MVCStreams, MVCStream)
-
-		// Calculate the remaining length
-		remainderPos, _ := ftell(file)
-		remainderLen := loopIterEnd - remainderPos
-		PadPrintf(4, "[%d] remainderPos: %d\n", i, remainderPos)
-		PadPrintf(4, "[%d] remainderLen: %d\n", i, remainderLen)
-
-		var remainder []byte
-		remainder = make([]byte, remainderLen)
-		if err := binary.Read(file, binary.BigEndian, &remainder); err != nil {
-			return fmt.Errorf("failed to read MVCStream.remainder: %w", err)
-		}
-		PadPrintf(4, "[%d] MVCStream.remainder: %+v\n", i, remainder)
-
-		// Seek to the loop iteration end offset
-		if _, err := file.Seek(loopIterEnd, io.SeekStart); err != nil {
-			return fmt.Errorf("failed to seek past reserve space: %w", err)
+		// MVCStream item
+		mvcStream := &MVCStream{}
+		if err := mvcStream.ReadMVC(file, loopIterLength); err != nil {
+			return fmt.Errorf("failed to read MVCStream: %w", err)
 		}
 
-		PadPrintln(4, "")
-		PadPrintln(4, "---")
+		// Append the structure to the end.
+		extensionMVCStream.MVCStreams = append(extensionMVCStream.MVCStreams, mvcStream)
 	}
 
 	// Jump to the extension entry stop offset
 	if _, err := file.Seek(offsetStop, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek Entry Offset Stop: (%d); error: %w", offsetStart, err)
+		return fmt.Errorf("failed to seek Entry Offset Stop: (%d); error: %w", offsetStop, err)
 	}
 	return nil
 }
+
+// MarshalBinary encodes the MVCStream, including its Length field.
+func (mvcStream *MVCStream) MarshalBinary() ([]byte, error) {
+	if mvcStream.Entry == nil {
+		return navfile.WithLength[uint16](mvcStream.Remainder)
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte(setFlag(mvcStream.FixedOffsetPopUpFlag, 0x80))
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	entry, err := mvcStream.Entry.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamEntry: %w", err)
+	}
+	buf.Write(entry)
+
+	attr, err := mvcStream.Attr.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamAttributes: %w", err)
+	}
+	buf.Write(attr)
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	binary.Write(buf, binary.BigEndian, mvcStream.NumberOfOffsetSequences)
+	buf.Write(mvcStream.Remainder)
+
+	return navfile.WithLength[uint16](buf.Bytes())
+}
+
+// MarshalBinary encodes every MVCStream back to back.
+func (extensionMVCStream *ExtensionMVCStream) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for i, mvcStream := range extensionMVCStream.MVCStreams {
+		data, err := mvcStream.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MVCStream[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
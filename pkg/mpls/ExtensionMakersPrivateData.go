@@ -0,0 +1,33 @@
+package mpls
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
+)
+
+// ExtensionMakersPrivateData implements the ExtensionEntryData interface.
+// AVCHD camcorders use it to store the maker, model, recording time and
+// GPS fix of a recording in the .MPL file; see package avchd.
+type ExtensionMakersPrivateData struct {
+	*avchd.MakersPrivateData
+}
+
+// Read reads and decodes ExtDataLength bytes of maker's private data.
+func (mpd *ExtensionMakersPrivateData) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
+	raw := &ExtensionRaw{}
+	if err := raw.Read(file, offsets, entryMeta); err != nil {
+		return err
+	}
+
+	if mpd.MakersPrivateData, err = avchd.ParseMakersPrivateData(raw.Data); err != nil {
+		return fmt.Errorf("failed to decode maker's private data: %w", err)
+	}
+
+	return nil
+}
+
+func (mpd *ExtensionMakersPrivateData) String() string {
+	return fmt.Sprintf("ExtensionMakersPrivateData{Entries: %d}", len(mpd.Entries))
+}
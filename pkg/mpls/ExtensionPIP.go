@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // Testing NOTES:
@@ -61,21 +64,32 @@ type PIPDataEntry struct {
 // Read reads the PIP extension data from the provided file at the specified offsets
 func (pip *ExtensionPIP) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
 
-	// Jump to the start offset
-	if _, err := file.Seek(int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
+	// Jump to the start offset (relative to the start of the extensions)
+	if _, err := file.Seek(offsets.Start+int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", entryMeta.ExtDataStartAddress, err)
 	}
 
-	startPos, _ := ftell(file)
+	startPos, err := ftell(file)
+	if err != nil {
+		return fmt.Errorf("failed to get current position: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &pip.Length); err != nil {
+		return fmt.Errorf("failed reading ExtensionPIP.Length: %w", err)
+	}
+
+	if err := binary.Read(file, binary.BigEndian, &pip.NumberOfEntries); err != nil {
+		return fmt.Errorf("failed reading ExtensionPIP.NumberOfEntries: %w", err)
+	}
 
-	binary.Read(file, binary.BigEndian, &pip.Length)
-	binary.Read(file, binary.BigEndian, &pip.NumberOfEntries)
 	pip.PIPEntries = make([]*PIPEntry, pip.NumberOfEntries)
 
 	// Fill the PIPEntries
 	for i := range pip.PIPEntries {
 		pip.PIPEntries[i] = &PIPEntry{}
-		pip.PIPEntries[i].Read(file)
+		if err := pip.PIPEntries[i].Read(file); err != nil {
+			return fmt.Errorf("failed reading PIPEntry: %w", err)
+		}
 	}
 
 	// Fill the PIPData
@@ -170,3 +184,89 @@ func (pipEntry *PIPEntry) Read(file io.ReadSeeker) error {
 
 	return nil
 }
+
+// MarshalBinary encodes the PIP extension data, including its Length field.
+// The PIPData blocks are laid out after the PIPEntries, and each
+// DataAddress is recomputed to point at its block.
+func (pip *ExtensionPIP) MarshalBinary() ([]byte, error) {
+	// Length (4) + NumberOfEntries (2) + 14-bytes for-each PIPEntry
+	dataAddress := 4 + 2 + 14*len(pip.PIPEntries)
+
+	entries := &bytes.Buffer{}
+	datas := &bytes.Buffer{}
+	for i, pipEntry := range pip.PIPEntries {
+		data, err := pipEntry.Data.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal PIPData[%d]: %w", i, err)
+		}
+
+		entry, err := pipEntry.marshalBinary(uint32(dataAddress + datas.Len()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal PIPEntry[%d]: %w", i, err)
+		}
+
+		entries.Write(entry)
+		datas.Write(data)
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint16(len(pip.PIPEntries)))
+	buf.Write(entries.Bytes())
+	buf.Write(datas.Bytes())
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// marshalBinary encodes the 14 byte PIPEntry, pointing it at dataAddress.
+func (pipEntry *PIPEntry) marshalBinary(dataAddress uint32) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, pipEntry.ClipRef)
+	binary.Write(buf, binary.BigEndian, pipEntry.SecondaryVideoRef)
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte((pipEntry.TimelineType&0x0F)<<4 |
+		setFlag(pipEntry.LumaKeyFlag, 0x08) |
+		setFlag(pipEntry.TrickPlayFlag, 0x04))
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	if pipEntry.LumaKeyFlag {
+		buf.WriteByte(0)
+		binary.Write(buf, binary.BigEndian, pipEntry.UpperLimitLumaKey)
+	} else {
+		buf.Write(make([]byte, 2))
+	}
+
+	// 2-byte reserve space
+	buf.Write(make([]byte, 2))
+
+	binary.Write(buf, binary.BigEndian, dataAddress)
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary encodes the PIPData block.
+// NumberOfEntries is taken from the length of Entries.
+func (pipData *PIPData) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if pipData == nil {
+		binary.Write(buf, binary.BigEndian, uint16(0))
+		return buf.Bytes(), nil
+	}
+
+	binary.Write(buf, binary.BigEndian, uint16(len(pipData.Entries)))
+
+	for _, entry := range pipData.Entries {
+		binary.Write(buf, binary.BigEndian, entry.Time)
+
+		_tmp := uint32(entry.Xpos&0x0FFF)<<12 | uint32(entry.Ypos&0x0FFF)
+		buf.Write([]byte{byte(_tmp >> 16), byte(_tmp >> 8), byte(_tmp)})
+		buf.WriteByte(uint8(entry.ScaleFactor&0x0F) << 4)
+	}
+
+	return buf.Bytes(), nil
+}
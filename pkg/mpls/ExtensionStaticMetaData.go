@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // ExtensionStaticMetaData implements the ExtensionEntryData interface.
@@ -29,8 +32,8 @@ type StaticMetaDataEntry struct {
 // Read reads the ExtensionStaticMetaData from the provided file.
 func (staticMetaData *ExtensionStaticMetaData) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
 
-	// Jump to the start offset
-	if _, err := file.Seek(int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
+	// Jump to the start offset (relative to the start of the extensions)
+	if _, err := file.Seek(offsets.Start+int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", entryMeta.ExtDataStartAddress, err)
 	}
 
@@ -49,6 +52,12 @@ func (staticMetaData *ExtensionStaticMetaData) Read(file io.ReadSeeker, offsets
 
 	if staticMetaData.Count > 0 {
 		staticMetaData.Entries = make([]*StaticMetaDataEntry, staticMetaData.Count)
+		for i := range staticMetaData.Entries {
+			staticMetaData.Entries[i] = &StaticMetaDataEntry{}
+			if err := staticMetaData.Entries[i].Read(file); err != nil {
+				return fmt.Errorf("failed to read StaticMetaDataEntry: %w", err)
+			}
+		}
 	}
 
 	return nil
@@ -106,3 +115,52 @@ func (smEntry *StaticMetaDataEntry) Read(file io.ReadSeeker) (err error) {
 
 	return nil
 }
+
+// MarshalBinary encodes the ExtensionStaticMetaData, including its Length field.
+// Count is taken from the length of Entries.
+func (staticMetaData *ExtensionStaticMetaData) MarshalBinary() ([]byte, error) {
+	if len(staticMetaData.Entries) > 0xFF {
+		return nil, fmt.Errorf("too many StaticMetaDataEntries: %d", len(staticMetaData.Entries))
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte(uint8(len(staticMetaData.Entries)))
+
+	// 3-bytes reserve
+	buf.Write(make([]byte, 3))
+
+	for i, smEntry := range staticMetaData.Entries {
+		data, err := smEntry.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal StaticMetaDataEntry[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// MarshalBinary encodes the 28 byte StaticMetaDataEntry.
+func (smEntry *StaticMetaDataEntry) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte((smEntry.DynamicRangeType & 0x0F) << 4)
+
+	// 3-bytes reserve
+	buf.Write(make([]byte, 3))
+
+	for i := range 3 {
+		binary.Write(buf, binary.BigEndian, smEntry.DisplayPrimariesX[i])
+		binary.Write(buf, binary.BigEndian, smEntry.DisplayPrimariesY[i])
+	}
+
+	binary.Write(buf, binary.BigEndian, smEntry.WhitePointX)
+	binary.Write(buf, binary.BigEndian, smEntry.WhitePointY)
+	binary.Write(buf, binary.BigEndian, smEntry.MaxDisplayMasteringLuminance)
+	binary.Write(buf, binary.BigEndian, smEntry.MinDisplayMasteringLuminance)
+	binary.Write(buf, binary.BigEndian, smEntry.MaxCLL)
+	binary.Write(buf, binary.BigEndian, smEntry.MaxFALL)
+
+	return buf.Bytes(), nil
+}
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // ExtensionSubPath implements the ExtensionEntryData interface.
@@ -19,8 +22,8 @@ type ExtensionSubPath struct {
 // followed by the sub paths themselves. It returns an error if any occurs during reading.
 func (extensionSubPath *ExtensionSubPath) Read(file io.ReadSeeker, offsets *OffsetsUint32, entryMeta *ExtensionEntryMetaData) (err error) {
 
-	// Jump to the start offset
-	if _, err := file.Seek(int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
+	// Jump to the start offset (relative to the start of the extensions)
+	if _, err := file.Seek(offsets.Start+int64(entryMeta.ExtDataStartAddress), io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek Entry Offset Start: (%d); error: %w", entryMeta.ExtDataStartAddress, err)
 	}
 
@@ -41,3 +44,21 @@ func (extensionSubPath *ExtensionSubPath) Read(file io.ReadSeeker, offsets *Offs
 
 	return nil
 }
+
+// MarshalBinary encodes the ExtensionSubPath data, including its Length field.
+// Count is taken from the length of SubPaths.
+func (extensionSubPath *ExtensionSubPath) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, uint16(len(extensionSubPath.SubPaths)))
+
+	for i, subPath := range extensionSubPath.SubPaths {
+		data, err := subPath.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SubPath[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
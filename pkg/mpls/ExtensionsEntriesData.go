@@ -1,16 +1,21 @@
 package mpls
 
 import (
+	"fmt"
 	"io"
-	"log"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
 )
 
 // Because there are many different types of extension data...
 // Put them behind an interface and make the different extensions implement the interface.
 // Each extension has access to it's own metadata, and the overall extensions start/stop offsets.
 // That way each extension can calculate boundaries.
+// MarshalBinary encodes the extension data alone; Extensions.MarshalBinary
+// takes care of the surrounding metadata.
 type ExtensionEntryData interface {
 	Read(io.ReadSeeker, *OffsetsUint32, *ExtensionEntryMetaData) error
+	MarshalBinary() ([]byte, error)
 }
 
 // ReadExtensionEntryData reads the extension entry data from the provided io.ReadSeeker.
@@ -42,27 +47,25 @@ func ReadExtensionEntryData(file io.ReadSeeker, offsets *OffsetsUint32, metaData
 		case // Static metadata extension
 			entryMeta.ExtDataType == 3 && entryMeta.ExtDataVersion == 5:
 			entriesData[i] = &ExtensionStaticMetaData{}
-		}
 
-		// Skip any unimplemented extensions.
-		if entriesData[i] != nil {
-
-			if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
-				// XXX - if the extension fails, it's not fatal.
-				// xxx - because some extensions might have errors (MVC mostly)
-				//return nil, fmt.Errorf("failed to read ExtensionEntryData: %w", err)
-				//fmt.Printf("\n\n WARNING! EXTENSION ERROR WHILE PARSING:\t[%+v:%+v]\n\n", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
+		case // AVCHD maker's private data
+			entryMeta.ExtDataType == avchd.EXT_DATA_TYPE_MAKERS_PRIVATE_DATA:
+			entriesData[i] = &ExtensionMakersPrivateData{}
+		}
 
-				// XXX - temporary fatal error to help debugging.
-				// This should be removed when all extensions are implemented.
-				log.Fatalf("failed to read ExtensionEntryData: %v\n", err)
+		// Keep unimplemented extensions as opaque bytes.
+		if entriesData[i] == nil {
+			entriesData[i] = &ExtensionRaw{}
+		}
 
+		if err = entriesData[i].Read(file, offsets, entryMeta); err != nil {
+			return nil, &ParseError{
+				Offset: offsets.Start + int64(entryMeta.ExtDataStartAddress),
+				Path:   fmt.Sprintf("Extensions.EntriesData[%d]", i),
+				Err: fmt.Errorf("%w: type %d version %d: %w",
+					ErrExtension, entryMeta.ExtDataType, entryMeta.ExtDataVersion, err),
 			}
-
-		} else {
-			log.Fatal("Extension not implemented: ", entryMeta.ExtDataType, entryMeta.ExtDataVersion)
 		}
-
 	}
 
 	return entriesData, nil
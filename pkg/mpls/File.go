@@ -0,0 +1,64 @@
+package mpls
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed .mpls playlist file: the sections
+// ParseMPLS returns one by one.
+type File struct {
+	Header     *MPLSHeader
+	AppInfo    *AppInfo
+	PlayList   *PlayList
+	Marks      *PlaylistMarks
+	Extensions *Extensions
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *MPLSHeader,
+	appInfo *AppInfo,
+	playList *PlayList,
+	marks *PlaylistMarks,
+	extensions *Extensions,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, appInfo, playList, marks, extensions}, nil
+}
+
+// ParseFile parses the .mpls playlist file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseMPLS(filePath))
+}
+
+// ParseFS parses the .mpls playlist file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseMPLSFS(fsys, name))
+}
+
+// ParseReader parses a .mpls playlist file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseMPLS(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as a .mpls playlist file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses a .mpls playlist file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
+
+// Write serializes the file to w with WriteMPLS.
+func (file *File) Write(w io.Writer) error {
+	return WriteMPLS(w, file.Header, file.AppInfo, file.PlayList, file.Marks, file.Extensions)
+}
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // MPLSHeader represents the 40 byte header of an MPLS file
@@ -14,6 +17,8 @@ type MPLSHeader struct {
 	Playlist      *OffsetsUint32
 	Marks         *OffsetsUint32
 	Extensions    *OffsetsUint32
+
+	sections []*navfile.Section // The header and each section as read; see WriteMPLS
 }
 
 // OffsetsUint32 represents the start and stop offsets of a section in the MPLS file.
@@ -79,6 +84,24 @@ func ReadMPLSHeader(file io.ReadSeeker) (header *MPLSHeader, err error) {
 	return header, nil
 }
 
+// MarshalBinary encodes the 40 byte header.
+// The section start addresses are taken from the Playlist, Marks and
+// Extensions offsets; WriteMPLS sets them before calling this.
+func (header *MPLSHeader) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, header.TypeIndicator)
+	binary.Write(buf, binary.BigEndian, header.VersionNumber)
+	binary.Write(buf, binary.BigEndian, uint32(header.Playlist.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.Marks.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.Extensions.Start))
+
+	// 20-byte reserve space
+	buf.Write(make([]byte, 20))
+
+	return buf.Bytes(), nil
+}
+
 func (offsets *OffsetsUint32) String() string {
 	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
 }
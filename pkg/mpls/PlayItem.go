@@ -1,10 +1,13 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 // PlayItem represents a single item in the playlist
@@ -15,8 +18,8 @@ type PlayItem struct {
 	IsMultiAngle             bool
 	ConnectionCondition      uint8
 	RefToSTCID               uint8
-	INTime                   uint32 // Timestamp in 45kHz
-	OUTTime                  uint32 // Timestamp in 45kHz
+	INTime                   clock.Ticks45k
+	OUTTime                  clock.Ticks45k
 	UserOptions              *UserOptions
 	PlayItemRandomAccessFlag bool
 	StillMode                uint8 // 0x00 == none ; 0x01 == finite still time (StillTime takes a uint16 value) ; 0x02 == infinite still time
@@ -154,43 +157,107 @@ func ReadPlayItem(file io.ReadSeeker) (playItem *PlayItem, err error) {
 	return playItem, nil
 }
 
+// MarshalBinary encodes the PlayItem, including its Length field.
+// NumberOfAngles is taken from the length of Angles, whose first entry
+// duplicates the PlayItem's own clip and is not written again.
+func (playItem *PlayItem) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, playItem.ClipInformationFileName)
+	binary.Write(buf, binary.BigEndian, playItem.ClipCodecIdentifier)
+
+	// 1 byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(setFlag(playItem.IsMultiAngle, 0x10) | playItem.ConnectionCondition&0x0F)
+
+	binary.Write(buf, binary.BigEndian, playItem.RefToSTCID)
+	binary.Write(buf, binary.BigEndian, playItem.INTime)
+	binary.Write(buf, binary.BigEndian, playItem.OUTTime)
+
+	userOptions, err := playItem.UserOptions.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UserOptions: %w", err)
+	}
+	buf.Write(userOptions)
+
+	buf.WriteByte(setFlag(playItem.PlayItemRandomAccessFlag, 0x80))
+
+	binary.Write(buf, binary.BigEndian, playItem.StillMode)
+
+	// StillTime is only meaningful for a finite still
+	if playItem.StillMode == 1 {
+		binary.Write(buf, binary.BigEndian, playItem.StillTime)
+	} else {
+		buf.Write(make([]byte, 2))
+	}
+
+	if playItem.IsMultiAngle {
+		numberOfAngles := max(len(playItem.Angles), 1)
+		if numberOfAngles > 0xFF {
+			return nil, fmt.Errorf("too many angles: %d", numberOfAngles)
+		}
+		buf.WriteByte(uint8(numberOfAngles))
+
+		buf.WriteByte(setFlag(playItem.IsDifferentAudios, 0x02) |
+			setFlag(playItem.IsSeamlessAngleChange, 0x01))
+
+		for i := 1; i < len(playItem.Angles); i++ {
+			data, err := playItem.Angles[i].MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal PlayItemEntry: %w", err)
+			}
+			buf.Write(data)
+		}
+	}
+
+	streamTable, err := playItem.StreamTable.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamTable: %w", err)
+	}
+	buf.Write(streamTable)
+
+	return navfile.WithLength[uint16](buf.Bytes())
+}
+
 // Assert checks the integrity of the PlayItem fields.
-// It will log fatal errors if any assertion fails.
-func (playItem *PlayItem) Assert() {
+// The returned error wraps ErrAssertion.
+func (playItem *PlayItem) Assert() error {
 
 	// Length should not be zero
 	if playItem.Length == 0 {
-		log.Fatal("Assertion: playItem.Length must not equal zero.")
+		return fmt.Errorf("%w: playItem.Length must not equal zero", ErrAssertion)
 	}
 
 	// FileName should be numeric
 	if !isNumeric(playItem.ClipInformationFileName) {
-		log.Fatal("Assertion: playItem.ClipInformationFileName must be numeric.")
+		return fmt.Errorf("%w: playItem.ClipInformationFileName must be numeric", ErrAssertion)
 	}
 
 	// Codec ID should be upper case alpha numeric
 	if !isAlphanumericUppercase(playItem.ClipCodecIdentifier) {
-		log.Fatal("Assertion: playItem.ClipCodecIdentifier must be upper case ascii")
+		return fmt.Errorf("%w: playItem.ClipCodecIdentifier must be upper case ascii", ErrAssertion)
 	}
 
 	// INTime should always be less-than OUTTime
 	if playItem.INTime > playItem.OUTTime {
-		log.Fatal("Assertion: playItem.INTime must be less-than playItem.OUTTime.")
+		return fmt.Errorf("%w: playItem.INTime must be less-than playItem.OUTTime", ErrAssertion)
 	}
 
 	// multi-angle true entails NumberOfAngles is non-zero
 	if playItem.IsMultiAngle && playItem.NumberOfAngles == 0 {
-		log.Fatal("Assertion: NumberOfAngles must not equal zero when IsMultiAngle is true.")
+		return fmt.Errorf("%w: NumberOfAngles must not equal zero when IsMultiAngle is true", ErrAssertion)
 	}
 
 	// When stillMode == 1 (finite time), then StillTime must be greater-than zero
 	// When stillMode == 2 (infinite time), then StillTime must (probably) be zero
 	if playItem.StillMode == 1 && playItem.StillTime == 0 {
-		log.Fatal("Assertion: playItem.StillTime must not equal zero when playItem.StillMode equals one.")
+		return fmt.Errorf("%w: playItem.StillTime must not equal zero when playItem.StillMode equals one", ErrAssertion)
 	} else if playItem.StillMode == 2 && playItem.StillTime != 0 {
-		log.Fatal("Assertion: playItem.StillTime must equal zero when playItem.StillMode equals two.")
+		return fmt.Errorf("%w: playItem.StillTime must equal zero when playItem.StillMode equals two", ErrAssertion)
 	}
 
+	return nil
 }
 
 // String returns a string representation of the PlayItem.
@@ -1,6 +1,7 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -47,6 +48,15 @@ func ReadPlayItemEntry(file io.ReadSeeker) (*PlayItemEntry, error) {
 	return playItemEntry, nil
 }
 
+// MarshalBinary encodes the 10 byte PlayItemEntry.
+func (p *PlayItemEntry) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, p.FileName)
+	binary.Write(buf, binary.BigEndian, p.Codec)
+	binary.Write(buf, binary.BigEndian, p.RefToSTCID)
+	return buf.Bytes(), nil
+}
+
 func (p *PlayItemEntry) String() string {
 	return fmt.Sprintf("PlayItemEntry{FileName: %s, Codec: %s, RefToSTCID: %d}",
 		p.FileName, p.Codec, p.RefToSTCID)
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // PlayList represents the main playlist structure
@@ -50,10 +53,16 @@ func ReadPlayList(file io.ReadSeeker, offsets *OffsetsUint32) (playlist *PlayLis
 
 	playlist.PlayItems = make([]*PlayItem, playlist.NumberOfPlayItems)
 	for i := range uint16(playlist.NumberOfPlayItems) {
+		start, err := ftell(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PlayListItem position: %w", err)
+		}
 		if playlist.PlayItems[i], err = ReadPlayItem(file); err != nil {
 			return nil, fmt.Errorf("failed to read PlayListItem: %w", err)
 		}
-		playlist.PlayItems[i].Assert()
+		if err := playlist.PlayItems[i].Assert(); err != nil {
+			return nil, &ParseError{Offset: start, Path: fmt.Sprintf("PlayList.PlayItems[%d]", i), Err: err}
+		}
 	}
 
 	playlist.SubPaths = make([]*SubPath, playlist.NumberOfSubPaths)
@@ -67,6 +76,36 @@ func ReadPlayList(file io.ReadSeeker, offsets *OffsetsUint32) (playlist *PlayLis
 	return playlist, nil
 }
 
+// MarshalBinary encodes the PlayList, its PlayItems and its SubPaths.
+// NumberOfPlayItems and NumberOfSubPaths are taken from the slice lengths.
+func (playlist *PlayList) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// reserve space between Length and NumberOfPlayItems
+	buf.Write(make([]byte, 2))
+
+	binary.Write(buf, binary.BigEndian, uint16(len(playlist.PlayItems)))
+	binary.Write(buf, binary.BigEndian, uint16(len(playlist.SubPaths)))
+
+	for i, playItem := range playlist.PlayItems {
+		data, err := playItem.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal PlayItem[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	for i, subPath := range playlist.SubPaths {
+		data, err := subPath.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SubPath[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (playlist *PlayList) String() string {
 	return fmt.Sprintf("PlayList{Length: %d, NumberOfPlayItems: %d, NumberOfSubPaths: %d, PlayItems: %v, SubPaths: %v}",
 		playlist.Length, playlist.NumberOfPlayItems, playlist.NumberOfSubPaths, playlist.PlayItems, playlist.SubPaths)
@@ -1,9 +1,13 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 // TODO: Fix the timestamps to something more sensible.
@@ -32,9 +36,9 @@ type PlaylistMarks struct {
 type MarkEntry struct {
 	MarkType        uint8
 	RefToPlayItemID uint16
-	MarkTimeStamp   uint32 // in 45kHz ticks
+	MarkTimeStamp   clock.Ticks45k
 	EntryESPID      uint16
-	Duration        uint32 // in 45kHz ticks
+	Duration        clock.Ticks45k
 }
 
 // ReadMarks reads the PlaylistMarks from the provided file at the specified offsets.
@@ -103,3 +107,37 @@ func ReadMarkEntry(file io.ReadSeeker) (markEntry *MarkEntry, err error) {
 
 	return markEntry, nil
 }
+
+// MarshalBinary encodes the PlaylistMarks and every MarkEntry.
+// NumberOfMarks is taken from the length of Marks.
+func (marks *PlaylistMarks) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, uint16(len(marks.Marks)))
+
+	for i, markEntry := range marks.Marks {
+		data, err := markEntry.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MarkEntry[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
+// MarshalBinary encodes the 14 byte MarkEntry.
+func (markEntry *MarkEntry) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	binary.Write(buf, binary.BigEndian, markEntry.MarkType)
+	binary.Write(buf, binary.BigEndian, markEntry.RefToPlayItemID)
+	binary.Write(buf, binary.BigEndian, markEntry.MarkTimeStamp)
+	binary.Write(buf, binary.BigEndian, markEntry.EntryESPID)
+	binary.Write(buf, binary.BigEndian, markEntry.Duration)
+
+	return buf.Bytes(), nil
+}
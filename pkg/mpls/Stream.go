@@ -34,6 +34,21 @@ func ReadStream(file io.ReadSeeker, kindOf StreamTypeKindOf) (stream *Stream, er
 	return stream, nil
 }
 
+// MarshalBinary encodes the StreamEntry followed by the StreamAttributes.
+func (s *Stream) MarshalBinary() ([]byte, error) {
+	entry, err := s.Entry.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamEntry: %w", err)
+	}
+
+	attr, err := s.Attr.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StreamAttributes: %w", err)
+	}
+
+	return append(entry, attr...), nil
+}
+
 // String returns a string representation of the Stream.
 func (s *Stream) String() string {
 	return fmt.Sprintf("Stream{Entry: %s, Attr: %s}", s.Entry, s.Attr)
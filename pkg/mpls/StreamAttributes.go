@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // BasicAttributes is a base structure for all stream attributes.
@@ -101,9 +104,10 @@ type TextAttributes struct {
 	CharacterCode uint8
 }
 
-// StreamAttributes is an interface that defines the methods for reading and setting
+// StreamAttributes is an interface that defines the methods for reading, writing and setting
 type StreamAttributes interface {
 	Read(io.ReadSeeker) error
+	MarshalBinary() ([]byte, error)
 	SetLength(uint8)
 	SetStreamCodingType(StreamCodingType)
 }
@@ -408,6 +412,136 @@ func (attr *TextAttributes) Read(file io.ReadSeeker) (err error) {
 
 }
 
+// MarshalBinary implements the StreamAttributes interface for PrimaryVideoAttributesH264.
+func (attr *PrimaryVideoAttributesH264) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	buf.WriteByte(stnPack(uint8(attr.Format), uint8(attr.Rate)))
+
+	// 3 byte tail padding
+	buf.Write(make([]byte, 3))
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary implements the StreamAttributes interface for PrimaryVideoAttributesHEVC.
+func (attr *PrimaryVideoAttributesHEVC) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	buf.WriteByte(stnPack(uint8(attr.Format), uint8(attr.Rate)))
+	buf.WriteByte(stnPack(attr.DynamicRangeType, attr.ColorSpace))
+	buf.WriteByte(setFlag(attr.CRFlag, 0x80) | setFlag(attr.HDRPlusFlag, 0x40))
+
+	// 1 byte tail padding
+	buf.WriteByte(0)
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary implements the StreamAttributes interface for PrimaryAudioAttributes.
+func (attr *PrimaryAudioAttributes) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	buf.WriteByte(stnPack(uint8(attr.Format), uint8(attr.Rate)))
+	binary.Write(buf, binary.BigEndian, attr.LanguageCode)
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary implements the StreamAttributes interface for SecondaryAudioAttributes.
+// The extra attributes follow the length-prefixed attributes block.
+func (attr *SecondaryAudioAttributes) MarshalBinary() ([]byte, error) {
+	data, err := attr.PrimaryAudioAttributes.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	//
+	// Extra Attributes
+	//
+	refs, err := marshalStreamRefs(attr.PrimaryAudioRefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PrimaryAudioRefs: %w", err)
+	}
+
+	return append(data, refs...), nil
+}
+
+// MarshalBinary implements the StreamAttributes interface for SecondaryVideoAttributes.
+// The extra attributes follow the length-prefixed attributes block.
+func (attr *SecondaryVideoAttributes) MarshalBinary() ([]byte, error) {
+	data, err := attr.PrimaryVideoAttributesH264.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	//
+	// Extra Attributes
+	//
+	secondaryAudioRefs, err := marshalStreamRefs(attr.SecondaryAudioRefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SecondaryAudioRefs: %w", err)
+	}
+	data = append(data, secondaryAudioRefs...)
+
+	pipPGRefs, err := marshalStreamRefs(attr.PIPPGRefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PIPPGRefs: %w", err)
+	}
+
+	return append(data, pipPGRefs...), nil
+}
+
+// MarshalBinary implements the StreamAttributes interface for PGAttributes.
+func (attr *PGAttributes) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	binary.Write(buf, binary.BigEndian, attr.LanguageCode)
+
+	// 1 byte tail padding
+	buf.WriteByte(0)
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary implements the StreamAttributes interface for IGAttributes.
+func (attr *IGAttributes) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	binary.Write(buf, binary.BigEndian, attr.LanguageCode)
+
+	// 1 byte tail padding
+	buf.WriteByte(0)
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary implements the StreamAttributes interface for TextAttributes.
+func (attr *TextAttributes) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, attr.StreamCodingType)
+	binary.Write(buf, binary.BigEndian, attr.CharacterCode)
+	binary.Write(buf, binary.BigEndian, attr.LanguageCode)
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// marshalStreamRefs encodes a counted list of 1-byte stream references:
+// the count, 1-byte reserve space, the references, and 1-byte of tail
+// padding if the count is odd.
+func marshalStreamRefs(refs []uint8) ([]byte, error) {
+	if len(refs) > 0xFF {
+		return nil, fmt.Errorf("too many stream references: %d", len(refs))
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(uint8(len(refs)))
+	buf.WriteByte(0)
+	buf.Write(refs)
+	if len(refs)%2 != 0 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes(), nil
+}
+
 //
 // helper functions
 //
@@ -421,3 +555,6 @@ func stnFormat[formatType ~uint8](buffer *byte) formatType { return formatType(f
 func stnRate[rateType ~uint8](buffer *byte) rateType       { return rateType(fourBitsLow(buffer)) }
 func stnDynamicRangeType(buffer *byte) uint8               { return fourBitsHigh(buffer) }
 func stnColorSpace(buffer *byte) uint8                     { return fourBitsLow(buffer) }
+
+// Inverse of the split above: two 4-bit values into one byte
+func stnPack(high, low uint8) byte { return (high&0x0F)<<4 | low&0x0F }
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 // BasicStreamEntry is the base structure for all StreamEntry types.
@@ -43,9 +46,10 @@ type StreamEntryTypeIII struct {
 	RefToStreamPID uint16
 }
 
-// StreamEntry is an interface that defines the methods for reading and setting
+// StreamEntry is an interface that defines the methods for reading, writing and setting
 type StreamEntry interface {
 	Read(io.ReadSeeker) error
+	MarshalBinary() ([]byte, error)
 	SetLength(uint8)
 	SetStreamType(uint8)
 }
@@ -150,3 +154,42 @@ func (entry *StreamEntryTypeIII) Read(file io.ReadSeeker) (err error) {
 
 	return nil
 }
+
+// MarshalBinary encodes the StreamEntryTypeI structure, including its Length and StreamType.
+func (entry *StreamEntryTypeI) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, entry.StreamType)
+	binary.Write(buf, binary.BigEndian, entry.RefToStreamPID)
+
+	// 6 tail padding bytes
+	buf.Write(make([]byte, 6))
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary encodes the StreamEntryTypeII structure, including its Length and StreamType.
+func (entry *StreamEntryTypeII) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, entry.StreamType)
+	binary.Write(buf, binary.BigEndian, entry.RefToSubPathID)
+	binary.Write(buf, binary.BigEndian, entry.RefToSubClipID)
+	binary.Write(buf, binary.BigEndian, entry.RefToStreamPID)
+
+	// 4 tail padding bytes
+	buf.Write(make([]byte, 4))
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
+
+// MarshalBinary encodes the StreamEntryTypeIII structure, including its Length and StreamType.
+func (entry *StreamEntryTypeIII) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, entry.StreamType)
+	binary.Write(buf, binary.BigEndian, entry.RefToSubPathID)
+	binary.Write(buf, binary.BigEndian, entry.RefToStreamPID)
+
+	// 5 tail padding bytes
+	buf.Write(make([]byte, 5))
+
+	return navfile.WithLength[uint8](buf.Bytes())
+}
@@ -1,9 +1,12 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type StreamTypeKindOf string
@@ -115,10 +118,49 @@ func ReadStreamTable(file io.ReadSeeker) (*StreamTable, error) {
 	return streamTable, nil
 }
 
+// MarshalBinary encodes the StreamTable, including its Length field.
+// The per-kind counters are taken from the length of each Streams slice.
+func (streamTable *StreamTable) MarshalBinary() ([]byte, error) {
+	if len(streamTable.Items) != len(streamKinds) {
+		return nil, fmt.Errorf("stream table has %d items, expected %d", len(streamTable.Items), len(streamKinds))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// reserved 2-byte space
+	buf.Write(make([]byte, 2))
+
+	// The counter fields
+	for _, item := range streamTable.Items {
+		if len(item.Streams) > 0xFF {
+			return nil, fmt.Errorf("too many %s streams: %d", item.KindOf, len(item.Streams))
+		}
+		buf.WriteByte(uint8(len(item.Streams)))
+	}
+
+	// reserved 4-byte space
+	buf.Write(make([]byte, 4))
+
+	// The Streams
+	for _, item := range streamTable.Items {
+		for i, stream := range item.Streams {
+			data, err := stream.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal %s[%d]: %w", item.KindOf, i, err)
+			}
+			buf.Write(data)
+		}
+	}
+
+	return navfile.WithLength[uint16](buf.Bytes())
+}
+
+// Assert checks the integrity of the StreamTable fields.
+// The returned error wraps ErrAssertion.
 func (streamTable *StreamTable) Assert() error {
 	// Check if Length is zero
 	if streamTable.Length == 0 {
-		return fmt.Errorf("streamTable.Length cannot be zero")
+		return fmt.Errorf("%w: streamTable.Length cannot be zero", ErrAssertion)
 	}
 
 	// Check if there is at least one stream
@@ -127,13 +169,13 @@ func (streamTable *StreamTable) Assert() error {
 		totalStreams += item.NumberOf
 	}
 	if totalStreams == 0 {
-		return fmt.Errorf("At least one stream must be present")
+		return fmt.Errorf("%w: at least one stream must be present", ErrAssertion)
 	}
 
 	// Validate slice lengths against their corresponding NumberOf fields
 	for _, item := range streamTable.Items {
 		if len(item.Streams) != int(item.NumberOf) {
-			return fmt.Errorf("%s slice length (%d) does not match NumberOf%s (%d)", item.KindOf, len(item.Streams), item.KindOf, item.NumberOf)
+			return fmt.Errorf("%w: %s slice length (%d) does not match NumberOf%s (%d)", ErrAssertion, item.KindOf, len(item.Streams), item.KindOf, item.NumberOf)
 		}
 	}
 
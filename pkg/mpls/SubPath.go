@@ -11,12 +11,18 @@ There was exactly 1-byte of zero fill padding at the end, causing alignment havo
 So Please note that apparently it is allowed to have padding here.
 Documentation is scarce, but I've seen no indication this allows padding reserve space.
 IT DOES ALLOW FOR ARBITRARY PADDING or RESERVE SPACE.
+
+Update: the "padding" was the SubPlayItem RefToSTCID byte, which was not being read.
+SubPlayItems now honor their Length field, so any real padding is skipped there.
 */
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type SubPath struct {
@@ -78,12 +84,6 @@ func ReadSubPath(file io.ReadSeeker) (subPath *SubPath, err error) {
 		if subPath.SubPlayItems[i], err = ReadSubPlayItem(file); err != nil {
 			return nil, fmt.Errorf("failed to read SubPlayItem: %w", err)
 		}
-
-		// 1-byte reserve space
-		if _, err := file.Seek(1, io.SeekCurrent); err != nil {
-			return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
-		}
-
 	}
 
 	// Skip to the end
@@ -94,6 +94,41 @@ func ReadSubPath(file io.ReadSeeker) (subPath *SubPath, err error) {
 	return subPath, nil
 }
 
+// MarshalBinary encodes the SubPath and its SubPlayItems, including the Length field.
+// NumberOfSubPlayItems is taken from the length of SubPlayItems.
+func (subPath *SubPath) MarshalBinary() ([]byte, error) {
+	if len(subPath.SubPlayItems) > 0xFF {
+		return nil, fmt.Errorf("too many SubPlayItems: %d", len(subPath.SubPlayItems))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	binary.Write(buf, binary.BigEndian, subPath.SubPathType)
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(setFlag(subPath.IsRepeatSubPath, 0x01))
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(uint8(len(subPath.SubPlayItems)))
+
+	for i, subPlayItem := range subPath.SubPlayItems {
+		data, err := subPlayItem.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SubPlayItem[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
+
 func (subPath *SubPath) String() string {
 	return fmt.Sprintf("SubPath:\n"+
 		"  Length: %d\n"+
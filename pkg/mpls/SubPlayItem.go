@@ -1,9 +1,13 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 // SubPlayItem represents a sub-play item in an MPLS file.
@@ -14,10 +18,10 @@ type SubPlayItem struct {
 	ConnectionCondition      uint8   // 0b00011110
 	IsMultiClipEntries       bool    // 0b00000001
 	RefToSTCID               uint8
-	INTime                   uint32
-	OUTTime                  uint32
+	INTime                   clock.Ticks45k
+	OUTTime                  clock.Ticks45k
 	SyncPlaytItemID          uint16
-	SyncStartPTS             uint32
+	SyncStartPTS             clock.Ticks45k
 	NumberOfMultiClipEntries uint8
 	MultiClipEntries         []*PlayItemEntry
 }
@@ -32,6 +36,11 @@ func ReadSubPlayItem(file io.ReadSeeker) (subPlayItem *SubPlayItem, err error) {
 		return nil, fmt.Errorf("failed to read stream info length: %w", err)
 	}
 
+	end, err := CalculateEndOffset(file, subPlayItem.Length)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling CalculateEndOffset(): %w", err)
+	}
+
 	if err := binary.Read(file, binary.BigEndian, &subPlayItem.FileName); err != nil {
 		return nil, fmt.Errorf("failed to read stream info FileName: %w", err)
 	}
@@ -53,6 +62,10 @@ func ReadSubPlayItem(file io.ReadSeeker) (subPlayItem *SubPlayItem, err error) {
 	subPlayItem.ConnectionCondition = (buffer & 0x1e) >> 1 // 0b00011110
 	subPlayItem.IsMultiClipEntries = buffer&0x01 != 0      // 0b00000001
 
+	if err := binary.Read(file, binary.BigEndian, &subPlayItem.RefToSTCID); err != nil {
+		return nil, fmt.Errorf("failed to read RefToSTCID: %w", err)
+	}
+
 	if err := binary.Read(file, binary.BigEndian, &subPlayItem.INTime); err != nil {
 		return nil, fmt.Errorf("failed to read INTime: %w", err)
 	}
@@ -74,6 +87,11 @@ func ReadSubPlayItem(file io.ReadSeeker) (subPlayItem *SubPlayItem, err error) {
 			return nil, fmt.Errorf("failed to read NumberOfMultiClipEntries: %w", err)
 		}
 
+		// skip 1-byte reserve space
+		if _, err := file.Seek(1, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to seek past reserve space: %w", err)
+		}
+
 		if subPlayItem.NumberOfMultiClipEntries < 1 {
 			subPlayItem.NumberOfMultiClipEntries = 1
 		}
@@ -93,9 +111,56 @@ func ReadSubPlayItem(file io.ReadSeeker) (subPlayItem *SubPlayItem, err error) {
 		}
 	}
 
+	// Skip to the end, past any padding
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek end offset: %w", err)
+	}
+
 	return subPlayItem, nil
 }
 
+// MarshalBinary encodes the SubPlayItem, including its Length field.
+// NumberOfMultiClipEntries is taken from the length of MultiClipEntries, whose
+// first entry duplicates the SubPlayItem's own clip and is not written again.
+func (subPlayItem *SubPlayItem) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, subPlayItem.FileName)
+	binary.Write(buf, binary.BigEndian, subPlayItem.Codec)
+
+	// 3-byte reserve space
+	buf.Write(make([]byte, 3))
+
+	buf.WriteByte((subPlayItem.ConnectionCondition&0x0F)<<1 | setFlag(subPlayItem.IsMultiClipEntries, 0x01))
+
+	binary.Write(buf, binary.BigEndian, subPlayItem.RefToSTCID)
+	binary.Write(buf, binary.BigEndian, subPlayItem.INTime)
+	binary.Write(buf, binary.BigEndian, subPlayItem.OUTTime)
+	binary.Write(buf, binary.BigEndian, subPlayItem.SyncPlaytItemID)
+	binary.Write(buf, binary.BigEndian, subPlayItem.SyncStartPTS)
+
+	if subPlayItem.IsMultiClipEntries {
+		numberOfEntries := max(len(subPlayItem.MultiClipEntries), 1)
+		if numberOfEntries > 0xFF {
+			return nil, fmt.Errorf("too many MultiClipEntries: %d", numberOfEntries)
+		}
+		buf.WriteByte(uint8(numberOfEntries))
+
+		// 1-byte reserve space
+		buf.WriteByte(0)
+
+		for i := 1; i < len(subPlayItem.MultiClipEntries); i++ {
+			data, err := subPlayItem.MultiClipEntries[i].MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal PlayItemEntry: %w", err)
+			}
+			buf.Write(data)
+		}
+	}
+
+	return navfile.WithLength[uint16](buf.Bytes())
+}
+
 func (subPlayItem *SubPlayItem) String() string {
 	return fmt.Sprintf("SubPlayItem:\n"+
 		"  Length: %d\n"+
@@ -1,6 +1,7 @@
 package mpls
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -15,8 +16,10 @@ type UserOptions struct {
 	TimeSearch                       bool
 	SkipToNextPoint                  bool
 	SkipToPrevPoint                  bool
+	PlayFirstPlay                    bool
 	Stop                             bool
 	PauseOn                          bool
+	PauseOff                         bool
 	StillOff                         bool
 	ForwardPlay                      bool
 	BackwardPlay                     bool
@@ -41,6 +44,89 @@ type UserOptions struct {
 	SecondaryPGStreamNumberChange    bool
 }
 
+// userOperation names one operation of the mask.
+type userOperation struct {
+	name string
+	flag func(*UserOptions) *bool
+}
+
+// userOperations lists the operations in mask table order.
+var userOperations = []userOperation{
+	{"menu call", func(uo *UserOptions) *bool { return &uo.MenuCall }},
+	{"title search", func(uo *UserOptions) *bool { return &uo.TitleSearch }},
+	{"chapter search", func(uo *UserOptions) *bool { return &uo.ChapterSearch }},
+	{"time search", func(uo *UserOptions) *bool { return &uo.TimeSearch }},
+	{"skip to next chapter", func(uo *UserOptions) *bool { return &uo.SkipToNextPoint }},
+	{"skip to previous chapter", func(uo *UserOptions) *bool { return &uo.SkipToPrevPoint }},
+	{"play first play", func(uo *UserOptions) *bool { return &uo.PlayFirstPlay }},
+	{"stop", func(uo *UserOptions) *bool { return &uo.Stop }},
+	{"pause", func(uo *UserOptions) *bool { return &uo.PauseOn }},
+	{"unpause", func(uo *UserOptions) *bool { return &uo.PauseOff }},
+	{"still off", func(uo *UserOptions) *bool { return &uo.StillOff }},
+	{"fast forward", func(uo *UserOptions) *bool { return &uo.ForwardPlay }},
+	{"rewind", func(uo *UserOptions) *bool { return &uo.BackwardPlay }},
+	{"resume", func(uo *UserOptions) *bool { return &uo.Resume }},
+	{"move up", func(uo *UserOptions) *bool { return &uo.MoveUpSelectedButton }},
+	{"move down", func(uo *UserOptions) *bool { return &uo.MoveDownSelectedButton }},
+	{"move left", func(uo *UserOptions) *bool { return &uo.MoveLeftSelectedButton }},
+	{"move right", func(uo *UserOptions) *bool { return &uo.MoveRightSelectedButton }},
+	{"select button", func(uo *UserOptions) *bool { return &uo.SelectButton }},
+	{"activate button", func(uo *UserOptions) *bool { return &uo.ActivateButton }},
+	{"select and activate button", func(uo *UserOptions) *bool { return &uo.SelectAndActivateButton }},
+	{"audio change", func(uo *UserOptions) *bool { return &uo.PrimaryAudioStreamNumberChange }},
+	{"angle change", func(uo *UserOptions) *bool { return &uo.AngleNumberChange }},
+	{"popup on", func(uo *UserOptions) *bool { return &uo.PopupOn }},
+	{"popup off", func(uo *UserOptions) *bool { return &uo.PopupOff }},
+	{"subtitles on/off", func(uo *UserOptions) *bool { return &uo.PrimaryPGEnableDisable }},
+	{"subtitle change", func(uo *UserOptions) *bool { return &uo.PrimaryPGStreamNumberChange }},
+	{"secondary video on/off", func(uo *UserOptions) *bool { return &uo.SecondaryVideoEnableDisable }},
+	{"secondary video change", func(uo *UserOptions) *bool { return &uo.SecondaryVideoStreamNumberChange }},
+	{"secondary audio on/off", func(uo *UserOptions) *bool { return &uo.SecondaryAudioEnableDisable }},
+	{"secondary audio change", func(uo *UserOptions) *bool { return &uo.SecondaryAudioStreamNumberChange }},
+	{"secondary subtitle change", func(uo *UserOptions) *bool { return &uo.SecondaryPGStreamNumberChange }},
+}
+
+// Or returns the mask that prohibits what either mask prohibits, as a
+// player combines the AppInfo and PlayItem masks. A nil mask prohibits
+// nothing.
+func (userOptions *UserOptions) Or(other *UserOptions) *UserOptions {
+	combined := &UserOptions{}
+	for _, operation := range userOperations {
+		for _, mask := range []*UserOptions{userOptions, other} {
+			if mask != nil && *operation.flag(mask) {
+				*operation.flag(combined) = true
+			}
+		}
+	}
+	return combined
+}
+
+// Prohibited names the operations the mask prohibits, in mask table
+// order, e.g. "skip to next chapter" or "fast forward".
+func (userOptions *UserOptions) Prohibited() []string {
+	names := []string{}
+	if userOptions == nil {
+		return names
+	}
+	for _, operation := range userOperations {
+		if *operation.flag(userOptions) {
+			names = append(names, operation.name)
+		}
+	}
+	return names
+}
+
+// BlocksSkipping reports whether the mask keeps a viewer from getting
+// past the content: neither skipping ahead, fast forward, chapter search
+// nor time search is allowed.
+func (userOptions *UserOptions) BlocksSkipping() bool {
+	return userOptions != nil &&
+		userOptions.SkipToNextPoint &&
+		userOptions.ForwardPlay &&
+		userOptions.ChapterSearch &&
+		userOptions.TimeSearch
+}
+
 func (userOptions *UserOptions) getFlag(data *uint8, mask uint8) bool {
 	return *data&mask != 0
 }
@@ -62,12 +148,14 @@ func ReadUserOptions(file io.ReadSeeker) (userOptions *UserOptions, err error) {
 	userOptions.TimeSearch = userOptions.getFlag(&flagBuffer, 0x10)
 	userOptions.SkipToNextPoint = userOptions.getFlag(&flagBuffer, 0x08)
 	userOptions.SkipToPrevPoint = userOptions.getFlag(&flagBuffer, 0x04)
+	userOptions.PlayFirstPlay = userOptions.getFlag(&flagBuffer, 0x02)
 	userOptions.Stop = userOptions.getFlag(&flagBuffer, 0x01)
 
 	if err := binary.Read(file, binary.BigEndian, &flagBuffer); err != nil {
 		return nil, fmt.Errorf("failed to read UO mask table: %w", err)
 	}
 	userOptions.PauseOn = userOptions.getFlag(&flagBuffer, 0x80)
+	userOptions.PauseOff = userOptions.getFlag(&flagBuffer, 0x40)
 	userOptions.StillOff = userOptions.getFlag(&flagBuffer, 0x20)
 	userOptions.ForwardPlay = userOptions.getFlag(&flagBuffer, 0x10)
 	userOptions.BackwardPlay = userOptions.getFlag(&flagBuffer, 0x08)
@@ -111,6 +199,58 @@ func ReadUserOptions(file io.ReadSeeker) (userOptions *UserOptions, err error) {
 	return userOptions, nil
 }
 
+// MarshalBinary encodes the 8 byte User Options mask table.
+// A nil UserOptions encodes as a table with no operations masked.
+func (userOptions *UserOptions) MarshalBinary() ([]byte, error) {
+	if userOptions == nil {
+		return make([]byte, 8), nil
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte(setFlag(userOptions.MenuCall, 0x80) |
+		setFlag(userOptions.TitleSearch, 0x40) |
+		setFlag(userOptions.ChapterSearch, 0x20) |
+		setFlag(userOptions.TimeSearch, 0x10) |
+		setFlag(userOptions.SkipToNextPoint, 0x08) |
+		setFlag(userOptions.SkipToPrevPoint, 0x04) |
+		setFlag(userOptions.PlayFirstPlay, 0x02) |
+		setFlag(userOptions.Stop, 0x01))
+
+	buf.WriteByte(setFlag(userOptions.PauseOn, 0x80) |
+		setFlag(userOptions.PauseOff, 0x40) |
+		setFlag(userOptions.StillOff, 0x20) |
+		setFlag(userOptions.ForwardPlay, 0x10) |
+		setFlag(userOptions.BackwardPlay, 0x08) |
+		setFlag(userOptions.Resume, 0x04) |
+		setFlag(userOptions.MoveUpSelectedButton, 0x02) |
+		setFlag(userOptions.MoveDownSelectedButton, 0x01))
+
+	buf.WriteByte(setFlag(userOptions.MoveLeftSelectedButton, 0x80) |
+		setFlag(userOptions.MoveRightSelectedButton, 0x40) |
+		setFlag(userOptions.SelectButton, 0x20) |
+		setFlag(userOptions.ActivateButton, 0x10) |
+		setFlag(userOptions.SelectAndActivateButton, 0x08) |
+		setFlag(userOptions.PrimaryAudioStreamNumberChange, 0x04) |
+		setFlag(userOptions.AngleNumberChange, 0x01))
+
+	buf.WriteByte(setFlag(userOptions.PopupOn, 0x80) |
+		setFlag(userOptions.PopupOff, 0x40) |
+		setFlag(userOptions.PrimaryPGEnableDisable, 0x20) |
+		setFlag(userOptions.PrimaryPGStreamNumberChange, 0x10) |
+		setFlag(userOptions.SecondaryVideoEnableDisable, 0x08) |
+		setFlag(userOptions.SecondaryVideoStreamNumberChange, 0x04) |
+		setFlag(userOptions.SecondaryAudioEnableDisable, 0x02) |
+		setFlag(userOptions.SecondaryAudioStreamNumberChange, 0x01))
+
+	buf.WriteByte(setFlag(userOptions.SecondaryPGStreamNumberChange, 0x40))
+
+	// 3 byte reserve padding space
+	buf.Write(make([]byte, 3))
+
+	return buf.Bytes(), nil
+}
+
 func (userOptions *UserOptions) String() string {
 	return fmt.Sprintf("UserOptions:\n"+
 		"  MenuCall: %t\n"+
@@ -119,8 +259,10 @@ func (userOptions *UserOptions) String() string {
 		"  TimeSearch: %t\n"+
 		"  SkipToNextPoint: %t\n"+
 		"  SkipToPrevPoint: %t\n"+
+		"  PlayFirstPlay: %t\n"+
 		"  Stop: %t\n"+
 		"  PauseOn: %t\n"+
+		"  PauseOff: %t\n"+
 		"  StillOff: %t\n"+
 		"  ForwardPlay: %t\n"+
 		"  BackwardPlay: %t\n"+
@@ -149,8 +291,10 @@ func (userOptions *UserOptions) String() string {
 		userOptions.TimeSearch,
 		userOptions.SkipToNextPoint,
 		userOptions.SkipToPrevPoint,
+		userOptions.PlayFirstPlay,
 		userOptions.Stop,
 		userOptions.PauseOn,
+		userOptions.PauseOff,
 		userOptions.StillOff,
 		userOptions.ForwardPlay,
 		userOptions.BackwardPlay,
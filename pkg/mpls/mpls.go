@@ -1,11 +1,17 @@
 package mpls
 
 import (
+	"encoding"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
+	"github.com/parasense/bdmv_go/internal/navfile"
+	"github.com/parasense/bdmv_go/pkg/clock"
 )
 
 /*
@@ -44,13 +50,16 @@ func ftell(file io.ReadSeeker) (int64, error) {
 	return file.Seek(0, io.SeekCurrent)
 }
 
-// Parse45KhzTimestamp converts a timestamp in 45kHz units to a duration
-// returns a quantity of time in seconds.
+// Parse45KhzTimestamp converts a timestamp in 45kHz units to a duration.
+//
+// Deprecated: use clock.Ticks45k.Duration.
 func Parse45KhzTimestamp(timestamp uint32) time.Duration {
-	return time.Duration(uint32(timestamp) / 45000)
+	return clock.Ticks45k(timestamp).Duration()
 }
 
-// returns a quantity of time in seconds.
+// Convert45KhzTimeToSeconds returns a quantity of time in whole seconds.
+//
+// Deprecated: use clock.Ticks45k.Seconds.
 func Convert45KhzTimeToSeconds(timestamp uint32) uint32 {
 	return uint32(timestamp / 45000)
 }
@@ -72,6 +81,15 @@ func CalculateEndOffset[U uint8 | uint16 | uint32](file io.ReadSeeker, length U)
 	return currentPos + int64(length), nil
 }
 
+// setFlag returns mask when flag is true, otherwise zero.
+// It is the inverse of masking a flag out of a bit field.
+func setFlag(flag bool, mask uint8) uint8 {
+	if flag {
+		return mask
+	}
+	return 0
+}
+
 // ParseMPLS parses an MPLS file and returns the playlist details
 func ParseMPLS(filePath string) (
 	header *MPLSHeader,
@@ -87,32 +105,169 @@ func ParseMPLS(filePath string) (
 	}
 	defer file.Close()
 
+	return parseMPLS(file, filePath)
+}
+
+// ParseMPLSFS is ParseMPLS for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseMPLSFS(fsys fs.FS, name string) (
+	header *MPLSHeader,
+	appinfo *AppInfo,
+	playlist *PlayList,
+	chapterMarks *PlaylistMarks,
+	extensiondata *Extensions,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseMPLS(file, name)
+}
+
+func parseMPLS(file io.ReadSeeker, filePath string) (
+	header *MPLSHeader,
+	appinfo *AppInfo,
+	playlist *PlayList,
+	chapterMarks *PlaylistMarks,
+	extensiondata *Extensions,
+	err error,
+) {
 	// Header
 	if header, err = ReadMPLSHeader(file); err != nil {
-		return nil, nil, nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Header", 0, err)
 	}
 
 	// AppInfo
 	if appinfo, err = ReadAppInfo(file, header.AppInfo); err != nil {
-		return nil, nil, nil, nil, nil, fmt.Errorf("failed to read appinfo: %w", err)
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "AppInfo", header.AppInfo.Start, err)
 	}
 
 	// Playlist
 	if playlist, err = ReadPlayList(file, header.Playlist); err != nil {
-		return nil, nil, nil, nil, nil, fmt.Errorf("failed to read PlayList: %w", err)
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "PlayList", header.Playlist.Start, err)
 	}
 
 	// Marks
 	if chapterMarks, err = ReadMarks(file, header.Marks); err != nil {
-		return nil, nil, nil, nil, nil, fmt.Errorf("failed to read Chapter Marks: %w", err)
+		return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "PlaylistMarks", header.Marks.Start, err)
 	}
 
 	// Extensions
 	if header.Extensions.Start != 0 && header.Extensions.Stop != 0 {
 		if extensiondata, err = ReadExtensions(file, header.Extensions); err != nil {
-			return nil, nil, nil, nil, nil, fmt.Errorf("failed to read Extension Data: %w", err)
+			return nil, nil, nil, nil, nil, navfile.SectionError(filePath, "Extensions", header.Extensions.Start, err)
 		}
 	}
 
+	if header.sections, err = readSections(file, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to read sections: %w", err)
+	}
+
 	return header, appinfo, playlist, chapterMarks, extensiondata, nil
 }
+
+// readSections keeps the header and every section as read, for WriteMPLS
+// to write the unchanged ones back as they were.
+func readSections(
+	file io.ReadSeeker,
+	header *MPLSHeader,
+	appinfo *AppInfo,
+	playlist *PlayList,
+	chapterMarks *PlaylistMarks,
+	extensiondata *Extensions,
+) (sections []*navfile.Section, err error) {
+	structures := []encoding.BinaryMarshaler{header, appinfo, playlist, chapterMarks}
+	offsets := []*OffsetsUint32{{Start: 0, Stop: 40}, header.AppInfo, header.Playlist, header.Marks}
+	if extensiondata != nil {
+		structures = append(structures, extensiondata)
+		offsets = append(offsets, header.Extensions)
+	}
+
+	sections = make([]*navfile.Section, len(structures))
+	for i, structure := range structures {
+		encoded, err := structure.MarshalBinary()
+		if err != nil {
+			encoded = nil
+		}
+		if sections[i], err = navfile.ReadSection(file, offsets[i].Start, offsets[i].Stop, encoded); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// WriteMPLS serializes the playlist structures to file in the MPLS layout.
+// A section left as ParseMPLS read it is written back byte for byte, with
+// the padding that followed it, and at its original address when the
+// sections before it leave that free, so an unedited file comes out
+// identical. An edited section is re-encoded: every length, count and
+// offset field is recomputed from the data, so the structures may be
+// edited (e.g. marks or PlayItems removed) before writing, and reserved
+// bits and padding are written as zeros. The header offsets are updated
+// to describe the written file.
+func WriteMPLS(
+	file io.Writer,
+	header *MPLSHeader,
+	appinfo *AppInfo,
+	playlist *PlayList,
+	chapterMarks *PlaylistMarks,
+	extensiondata *Extensions,
+) error {
+	appinfoBytes, err := appinfo.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal appinfo: %w", err)
+	}
+
+	playlistBytes, err := playlist.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal PlayList: %w", err)
+	}
+
+	marksBytes, err := chapterMarks.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal Chapter Marks: %w", err)
+	}
+
+	var extensionBytes []byte
+	if extensiondata != nil {
+		if extensionBytes, err = extensiondata.MarshalBinary(); err != nil {
+			return fmt.Errorf("failed to marshal Extension Data: %w", err)
+		}
+	}
+
+	// Lay the sections out after the 40-byte header.
+	section := func(i int) *navfile.Section {
+		if i < len(header.sections) {
+			return header.sections[i]
+		}
+		return nil
+	}
+	layout := &navfile.Layout{Base: 40}
+	header.AppInfo = newOffsets(layout.Add(section(1), appinfoBytes))
+	header.Playlist = newOffsets(layout.Add(section(2), playlistBytes))
+	header.Marks = newOffsets(layout.Add(section(3), marksBytes))
+	header.Extensions = &OffsetsUint32{Start: 0, Stop: 0}
+	if len(extensionBytes) > 0 {
+		header.Extensions = newOffsets(layout.Add(section(4), extensionBytes))
+	}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	for _, data := range [][]byte{section(0).Bytes(headerBytes), layout.Bytes()} {
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newOffsets(start, stop int64) *OffsetsUint32 {
+	return &OffsetsUint32{Start: start, Stop: stop}
+}
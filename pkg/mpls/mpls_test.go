@@ -1,72 +1,474 @@
 package mpls
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
+	"testing/fstest"
+
+	"github.com/parasense/bdmv_go/pkg/avchd"
 )
 
 func TestParseMPLS(t *testing.T) {
-	type args struct {
-		filePath string
-	}
 	tests := []struct {
-		name              string
-		args              args
-		wantHeader        *MPLSHeader
-		wantAppinfo       *AppInfo
-		wantPlaylist      *PlayList
-		wantChapterMarks  *PlaylistMarks
-		wantExtensiondata *Extensions
-		wantErr           bool
+		name     string
+		filePath string
+		wantErr  bool
 	}{
-		{
-			name:       "valid MPLS file",
-			args:       args{filePath: "testdata/valid.mpls"}, // Place a test .mpls file in pkg/mpls/testdata/
-			wantHeader: &MPLSHeader{
-				// Fill with expected values, e.g., TypeIndicator: "MPLS0200"
-			},
-			wantAppinfo: &AppInfo{
-				// Fill with expected values
-			},
-			wantPlaylist: &PlayList{
-				// Fill with expected values
-			},
-			wantChapterMarks: &PlaylistMarks{
-				// Fill with expected values
-			},
-			wantExtensiondata: &Extensions{
-				// Fill with expected values
-			},
-			wantErr: false,
-		},
-		{
-			name:    "invalid MPLS file",
-			args:    args{filePath: "testdata/invalid.mpls"},
-			wantErr: true,
-		},
+		{name: "valid MPLS file", filePath: "testdata/00000.mpls"},
+		{name: "missing MPLS file", filePath: "testdata/invalid.mpls", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHeader, gotAppinfo, gotPlaylist, gotChapterMarks, gotExtensiondata, err := ParseMPLS(tt.args.filePath)
+			header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS(tt.filePath)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("ParseMPLS() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(gotHeader, tt.wantHeader) {
-				t.Errorf("ParseMPLS() gotHeader = %v, want %v", gotHeader, tt.wantHeader)
-			}
-			if !reflect.DeepEqual(gotAppinfo, tt.wantAppinfo) {
-				t.Errorf("ParseMPLS() gotAppinfo = %v, want %v", gotAppinfo, tt.wantAppinfo)
+				t.Fatalf("ParseMPLS() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(gotPlaylist, tt.wantPlaylist) {
-				t.Errorf("ParseMPLS() gotPlaylist = %v, want %v", gotPlaylist, tt.wantPlaylist)
+			if tt.wantErr {
+				return
 			}
-			if !reflect.DeepEqual(gotChapterMarks, tt.wantChapterMarks) {
-				t.Errorf("ParseMPLS() gotChapterMarks = %v, want %v", gotChapterMarks, tt.wantChapterMarks)
+			if string(header.TypeIndicator[:]) != "MPLS" || appinfo == nil || len(playlist.PlayItems) == 0 || chapterMarks == nil {
+				t.Errorf("ParseMPLS() = %s, %v, %v, %v", header, appinfo, playlist, chapterMarks)
 			}
-			if !reflect.DeepEqual(gotExtensiondata, tt.wantExtensiondata) {
-				t.Errorf("ParseMPLS() gotExtensiondata = %v, want %v", gotExtensiondata, tt.wantExtensiondata)
+			if header.Extensions.Start == 0 && extensiondata != nil {
+				t.Errorf("ParseMPLS() extension data %v without a start address", extensiondata)
 			}
 		})
 	}
 }
+
+// TestParseExtensions checks the extension entries are read from their
+// start address, which counts from the start of the extensions block.
+func TestParseExtensions(t *testing.T) {
+	header, _, _, _, extensiondata, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	if header.Extensions.Start == 0 || len(extensiondata.EntriesData) != 4 {
+		t.Fatalf("got %d extension entries at %d, want 4", len(extensiondata.EntriesData), header.Extensions.Start)
+	}
+
+	pip, ok := extensiondata.EntriesData[0].(*ExtensionPIP)
+	if !ok || len(pip.PIPEntries) != 1 {
+		t.Errorf("entry 0 = %T, want one PIP entry", extensiondata.EntriesData[0])
+	}
+
+	mvc, ok := extensiondata.EntriesData[1].(*ExtensionMVCStream)
+	if !ok || len(mvc.MVCStreams) != 1 {
+		t.Fatalf("entry 1 = %T, want one MVC stream", extensiondata.EntriesData[1])
+	}
+	mvcStream := mvc.MVCStreams[0]
+	if !mvcStream.FixedOffsetPopUpFlag || mvcStream.NumberOfOffsetSequences != 2 || !slices.Equal(mvcStream.Remainder, []byte{1, 2, 3, 4}) {
+		t.Errorf("MVC stream = %+v", mvcStream)
+	}
+
+	if subPaths, ok := extensiondata.EntriesData[2].(*ExtensionSubPath); !ok || len(subPaths.SubPaths) != 1 {
+		t.Errorf("entry 2 = %T, want one SubPath", extensiondata.EntriesData[2])
+	}
+
+	staticMetaData, ok := extensiondata.EntriesData[3].(*ExtensionStaticMetaData)
+	if !ok || len(staticMetaData.Entries) != 1 {
+		t.Fatalf("entry 3 = %T, want one static metadata entry", extensiondata.EntriesData[3])
+	}
+	if entry := staticMetaData.Entries[0]; entry.MaxCLL != 1000 || entry.MaxFALL != 400 {
+		t.Errorf("static metadata = %+v", entry)
+	}
+}
+
+// TestParseMVCKeepsBrokenEntries checks an MVC entry with the 0xFF00
+// length is kept, undecoded, and written back.
+func TestParseMVCKeepsBrokenEntries(t *testing.T) {
+	_, _, _, _, extensiondata, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	entry, err := extensiondata.EntriesData[1].(*ExtensionMVCStream).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(append([]byte{0xFF, 0x00}, make([]byte, 0xFF00)...), entry...)
+
+	mvc := &ExtensionMVCStream{}
+	meta := &ExtensionEntryMetaData{ExtDataLength: uint32(len(data))}
+	if err := mvc.Read(bytes.NewReader(data), &OffsetsUint32{}, meta); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(mvc.MVCStreams) != 2 || mvc.MVCStreams[0].Entry != nil || mvc.MVCStreams[1].Entry == nil {
+		t.Fatalf("got %d MVC streams, want the broken one and the one after it", len(mvc.MVCStreams))
+	}
+	if got, err := mvc.MarshalBinary(); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("MarshalBinary() did not reproduce the entries: %v", err)
+	}
+}
+
+// TestParseSubPlayItem checks the RefToSTCID byte is read, so the fields
+// after it line up.
+func TestParseSubPlayItem(t *testing.T) {
+	_, _, playlist, _, _, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	subPlayItems := playlist.SubPaths[0].SubPlayItems
+	if len(subPlayItems) != 2 {
+		t.Fatalf("got %d SubPlayItems, want 2", len(subPlayItems))
+	}
+	subPlayItem := subPlayItems[1]
+	if subPlayItem.RefToSTCID != 2 || subPlayItem.INTime != 1000 || subPlayItem.OUTTime != 2000 || subPlayItem.SyncStartPTS != 3000 {
+		t.Errorf("SubPlayItem = %+v", subPlayItem)
+	}
+	if len(subPlayItem.MultiClipEntries) != 2 || subPlayItem.MultiClipEntries[1].RefToSTCID != 3 {
+		t.Errorf("MultiClipEntries = %v", subPlayItem.MultiClipEntries)
+	}
+}
+
+func TestParseUserOptions(t *testing.T) {
+	_, appinfo, playlist, _, _, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	if uo := appinfo.UserOptions; !uo.ChapterSearch || uo.PlayFirstPlay || uo.PauseOff {
+		t.Errorf("AppInfo UserOptions = %+v", uo)
+	}
+	uo := playlist.PlayItems[1].UserOptions
+	if !uo.PlayFirstPlay || !uo.PauseOff || !uo.TimeSearch || uo.PauseOn || uo.Stop {
+		t.Errorf("PlayItem 1 UserOptions = %+v", uo)
+	}
+}
+
+// TestParseMPLSQuiet checks parsing prints nothing.
+func TestParseMPLSQuiet(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, _, _, _, _, parseErr := ParseMPLS("testdata/00000.mpls")
+	os.Stdout = stdout
+	w.Close()
+
+	output, _ := io.ReadAll(r)
+	if parseErr != nil || len(output) != 0 {
+		t.Errorf("ParseMPLS() printed %q, error = %v", output, parseErr)
+	}
+}
+
+// TestParseVariants reads one file through every entry point.
+func TestParseVariants(t *testing.T) {
+	data, err := os.ReadFile("testdata/00000.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseFile("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	variants := map[string]func() (*File, error){
+		"ParseBytes":    func() (*File, error) { return ParseBytes(data) },
+		"ParseReader":   func() (*File, error) { return ParseReader(bytes.NewReader(data)) },
+		"ParseReaderAt": func() (*File, error) { return ParseReaderAt(bytes.NewReader(data), int64(len(data))) },
+		"ParseFS": func() (*File, error) {
+			return ParseFS(fstest.MapFS{"PLAYLIST/00000.mpls": {Data: data}}, "PLAYLIST/00000.mpls")
+		},
+	}
+	for name, parse := range variants {
+		got, err := parse()
+		if err != nil {
+			t.Errorf("%s() error = %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s() differs from ParseFile()", name)
+		}
+	}
+
+	// The File writes back what it read.
+	buf := &bytes.Buffer{}
+	if err := want.Write(buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	again, err := ParseBytes(buf.Bytes())
+	if err != nil || len(again.PlayList.PlayItems) != len(want.PlayList.PlayItems) {
+		t.Errorf("ParseBytes(Write()) = %v, %v", again, err)
+	}
+
+	// Errors from a reader carry no file name; those from a path do.
+	var parseErr *ParseError
+	if _, err := ParseBytes(data[:60]); !errors.As(err, &parseErr) || parseErr.File != "" {
+		t.Errorf("ParseBytes(truncated) error = %v", err)
+	}
+}
+
+// writeMPLS parses filePath and writes it back.
+func writeMPLS(t *testing.T, filePath string) []byte {
+	t.Helper()
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS(filePath)
+	if err != nil {
+		t.Fatalf("ParseMPLS(%s) error = %v", filePath, err)
+	}
+	got := &bytes.Buffer{}
+	if err := WriteMPLS(got, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS(%s) error = %v", filePath, err)
+	}
+	return got.Bytes()
+}
+
+func TestWriteMPLSRoundTrip(t *testing.T) {
+	// testdata/00000.mpls is hand-built: two PlayItems (one multi-angle),
+	// a SubPath with a multi-clip SubPlayItem, three marks, and the PIP,
+	// MVC, SubPath and static metadata extensions. 00001.mpls is the same
+	// playlist with the zero fill between sections that authoring tools
+	// leave. Both write back as read.
+	for _, filePath := range []string{"testdata/00000.mpls", "testdata/00001.mpls"} {
+		want, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := writeMPLS(t, filePath)
+		if !bytes.Equal(got, want) {
+			for i := range min(len(got), len(want)) {
+				if got[i] != want[i] {
+					t.Fatalf("WriteMPLS(%s) output differs at byte %d (got %d bytes, want %d)", filePath, i, len(got), len(want))
+				}
+			}
+			t.Fatalf("WriteMPLS(%s) output is %d bytes, want %d", filePath, len(got), len(want))
+		}
+	}
+}
+
+func TestWriteMPLSKeepsUnchangedSections(t *testing.T) {
+	data, err := os.ReadFile("testdata/00001.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS("testdata/00001.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	playlistStart, marksStart, extensionsStart := header.Playlist.Start, header.Marks.Start, header.Extensions.Start
+
+	// Drop the last mark: the marks shrink, the extensions stay put.
+	chapterMarks.Marks = chapterMarks.Marks[:len(chapterMarks.Marks)-1]
+
+	buf := &bytes.Buffer{}
+	if err := WriteMPLS(buf, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS() error = %v", err)
+	}
+	got := buf.Bytes()
+
+	if header.Playlist.Start != playlistStart || header.Marks.Start != marksStart || header.Extensions.Start != extensionsStart {
+		t.Errorf("sections start at %d, %d, %d, want %d, %d, %d",
+			header.Playlist.Start, header.Marks.Start, header.Extensions.Start,
+			playlistStart, marksStart, extensionsStart)
+	}
+	if !bytes.Equal(got[:marksStart], data[:marksStart]) {
+		t.Errorf("WriteMPLS() changed the bytes before the marks")
+	}
+	if !bytes.Equal(got[extensionsStart:], data[extensionsStart:]) {
+		t.Errorf("WriteMPLS() changed the extensions")
+	}
+
+	parsed, err := ParseBytes(got)
+	if err != nil {
+		t.Fatalf("ParseBytes() of the edited file error = %v", err)
+	}
+	if len(parsed.Marks.Marks) != len(chapterMarks.Marks) {
+		t.Errorf("got %d marks, want %d", len(parsed.Marks.Marks), len(chapterMarks.Marks))
+	}
+}
+
+// TestWriteMPLSDiscPlaylists round-trips the playlists of a real disc,
+// which cannot be shipped here. Point MPLS_TESTDATA at a PLAYLIST
+// directory to run it. Each playlist must write back as read.
+func TestWriteMPLSDiscPlaylists(t *testing.T) {
+	dir := os.Getenv("MPLS_TESTDATA")
+	if dir == "" {
+		t.Skip("MPLS_TESTDATA is not set")
+	}
+	filePaths, err := filepath.Glob(filepath.Join(dir, "*.mpls"))
+	if err != nil || len(filePaths) == 0 {
+		t.Fatalf("no playlists in %s: %v", dir, err)
+	}
+	for _, filePath := range filePaths {
+		want, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := writeMPLS(t, filePath); !bytes.Equal(got, want) {
+			t.Errorf("WriteMPLS(%s) does not reproduce the file", filePath)
+		}
+	}
+}
+
+func TestWriteMPLSRecomputesLengths(t *testing.T) {
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+
+	// Drop the second PlayItem and every mark that refers to it.
+	playlist.PlayItems = playlist.PlayItems[:1]
+	chapterMarks.Marks = chapterMarks.Marks[:2]
+
+	out, err := os.CreateTemp(t.TempDir(), "*.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	if err := WriteMPLS(out, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS() error = %v", err)
+	}
+
+	_, _, gotPlaylist, gotMarks, gotExtensions, err := ParseMPLS(out.Name())
+	if err != nil {
+		t.Fatalf("ParseMPLS() of the edited file error = %v", err)
+	}
+	if gotPlaylist.NumberOfPlayItems != 1 {
+		t.Errorf("NumberOfPlayItems = %d, want 1", gotPlaylist.NumberOfPlayItems)
+	}
+	if gotMarks.NumberOfMarks != 2 {
+		t.Errorf("NumberOfMarks = %d, want 2", gotMarks.NumberOfMarks)
+	}
+	if len(gotExtensions.EntriesData) != 4 {
+		t.Errorf("got %d extension entries, want 4", len(gotExtensions.EntriesData))
+	}
+}
+
+func TestParseMPLSKeepsUnknownExtensions(t *testing.T) {
+	data, err := os.ReadFile("testdata/00000.mpls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, _, _, _, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+
+	// Retag the first extension entry with a type and version nobody knows.
+	metaStart := header.Extensions.Start + 12
+	copy(data[metaStart:], []byte{0x00, 0xFF, 0x00, 0xFF})
+
+	filePath := filepath.Join(t.TempDir(), "00000.mpls")
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS(filePath)
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	raw, ok := extensiondata.EntriesData[0].(*ExtensionRaw)
+	if !ok {
+		t.Fatalf("EntriesData[0] is %T, want *ExtensionRaw", extensiondata.EntriesData[0])
+	}
+	if len(raw.Data) != int(extensiondata.EntriesMetaData[0].ExtDataLength) {
+		t.Errorf("ExtensionRaw holds %d bytes, want %d", len(raw.Data), extensiondata.EntriesMetaData[0].ExtDataLength)
+	}
+
+	got := &bytes.Buffer{}
+	if err := WriteMPLS(got, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS() error = %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), data) {
+		t.Errorf("WriteMPLS() did not reproduce the unknown extension")
+	}
+}
+
+func TestParseMPLSReportsAssertions(t *testing.T) {
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	playlist.PlayItems[0].INTime = playlist.PlayItems[0].OUTTime + 1
+
+	filePath := filepath.Join(t.TempDir(), "00000.mpls")
+	out, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	if err := WriteMPLS(out, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS() error = %v", err)
+	}
+
+	_, _, _, _, _, err = ParseMPLS(filePath)
+	if !errors.Is(err, ErrAssertion) {
+		t.Fatalf("ParseMPLS() error = %v, want ErrAssertion", err)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("ParseMPLS() error is %T, want *ParseError", err)
+	}
+	// The first PlayItem follows the Length, reserved and counter fields.
+	want := ParseError{File: filePath, Offset: header.Playlist.Start + 10, Path: "PlayList.PlayItems[0]"}
+	if parseErr.File != want.File || parseErr.Offset != want.Offset || parseErr.Path != want.Path {
+		t.Errorf("ParseError = {%s %d %s}, want {%s %d %s}",
+			parseErr.File, parseErr.Offset, parseErr.Path, want.File, want.Offset, want.Path)
+	}
+}
+
+func TestParseMPLSMakersPrivateData(t *testing.T) {
+	header, appinfo, playlist, chapterMarks, extensiondata, err := ParseMPLS("testdata/00000.mpls")
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+
+	// An AVCHD camcorder's maker entry, with its make/model pack.
+	mpd := &avchd.MakersPrivateData{Entries: []*avchd.MakerEntry{{
+		MakerID:        avchd.MAKER_PANASONIC,
+		MakerModelCode: 0x0201,
+		Data:           []byte{avchd.PACK_MAKE_MODEL, 0x01, 0x03, 0x02, 0x01},
+	}}}
+	extensiondata.EntriesMetaData = append(extensiondata.EntriesMetaData,
+		&ExtensionEntryMetaData{ExtDataType: avchd.EXT_DATA_TYPE_MAKERS_PRIVATE_DATA, ExtDataVersion: 0x0100})
+	extensiondata.EntriesData = append(extensiondata.EntriesData, &ExtensionMakersPrivateData{mpd})
+
+	buf := &bytes.Buffer{}
+	if err := WriteMPLS(buf, header, appinfo, playlist, chapterMarks, extensiondata); err != nil {
+		t.Fatalf("WriteMPLS() error = %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "00000.MPL")
+	if err := os.WriteFile(filePath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, _, extensiondata, err = ParseMPLS(filePath)
+	if err != nil {
+		t.Fatalf("ParseMPLS() error = %v", err)
+	}
+	last := extensiondata.EntriesData[len(extensiondata.EntriesData)-1]
+	got, ok := last.(*ExtensionMakersPrivateData)
+	if !ok {
+		t.Fatalf("last entry is %T, want *ExtensionMakersPrivateData", last)
+	}
+	if len(got.Entries) != 1 || got.Entries[0].Metadata().MakerID != avchd.MAKER_PANASONIC {
+		t.Errorf("ExtensionMakersPrivateData = %+v", got.Entries)
+	}
+}
+
+func TestBlocksSkipping(t *testing.T) {
+	tests := []struct {
+		name string
+		mask *UserOptions
+		want bool
+	}{
+		{"none", nil, false},
+		{"skip and fast forward", &UserOptions{SkipToNextPoint: true, ForwardPlay: true}, false},
+		{"searches only", &UserOptions{ChapterSearch: true, TimeSearch: true}, false},
+		{"all but time search", &UserOptions{ChapterSearch: true, SkipToNextPoint: true, ForwardPlay: true}, false},
+		{"all four", &UserOptions{ChapterSearch: true, TimeSearch: true, SkipToNextPoint: true, ForwardPlay: true}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.mask.BlocksSkipping(); got != tt.want {
+			t.Errorf("%s: BlocksSkipping() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package sound
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// File is a parsed sound.bdmv file: the sections
+// ParseBCLK returns one by one.
+type File struct {
+	Header        *BCLKHeader
+	SoundMetaData *SoundMetaData
+	SoundData     *SoundData
+}
+
+// newFile gathers the sections of a parsed file.
+func newFile(
+	header *BCLKHeader,
+	soundMetaData *SoundMetaData,
+	soundData *SoundData,
+	err error,
+) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{header, soundMetaData, soundData}, nil
+}
+
+// ParseFile parses the sound.bdmv file at filePath.
+func ParseFile(filePath string) (*File, error) {
+	return newFile(ParseBCLK(filePath))
+}
+
+// ParseFS parses the sound.bdmv file name in fsys.
+func ParseFS(fsys fs.FS, name string) (*File, error) {
+	return newFile(ParseBCLKFS(fsys, name))
+}
+
+// ParseReader parses a sound.bdmv file read from r, which
+// starts at the beginning of the file. Errors do not name a file.
+func ParseReader(r io.ReadSeeker) (*File, error) {
+	return newFile(parseBCLK(r, ""))
+}
+
+// ParseReaderAt parses the size bytes of r as a sound.bdmv file, for
+// readers that cannot seek, such as objects in remote storage.
+func ParseReaderAt(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReader(io.NewSectionReader(r, 0, size))
+}
+
+// ParseBytes parses a sound.bdmv file held in memory.
+func ParseBytes(data []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(data))
+}
+
+// Write serializes the file to w with WriteBCLK.
+func (file *File) Write(w io.Writer) error {
+	return WriteBCLK(w, file.Header, file.SoundMetaData, file.SoundData)
+}
@@ -1,6 +1,7 @@
 package sound
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -67,6 +68,23 @@ func ReadBCLKHeader(file io.ReadSeeker) (header *BCLKHeader, err error) {
 	return header, nil
 }
 
+// MarshalBinary encodes the 40 byte header. The start addresses are taken
+// from the SoundObjects and Extensions offsets; WriteBCLK sets them
+// before calling this.
+func (header *BCLKHeader) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	binary.Write(buf, binary.BigEndian, header.TypeIndicator)
+	binary.Write(buf, binary.BigEndian, header.VersionNumber)
+	binary.Write(buf, binary.BigEndian, uint32(header.SoundObjects.Start))
+	binary.Write(buf, binary.BigEndian, uint32(header.Extensions.Start))
+
+	// 24-byte reserve space
+	buf.Write(make([]byte, 24))
+
+	return buf.Bytes(), nil
+}
+
 func (offsets *OffsetsUint32) String() string {
 	return fmt.Sprintf("{Start: %d, Stop: %d}", offsets.Start, offsets.Stop)
 }
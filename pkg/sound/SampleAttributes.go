@@ -1,6 +1,7 @@
 package sound
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -81,3 +82,27 @@ func ReadSampleAttributes(file io.ReadSeeker) (sampleAttr *SampleAttributes, err
 
 	return sampleAttr, err
 }
+
+// MarshalBinary encodes the 10 byte sample attributes. NumberOfFrames is
+// written back as the length of the sound in bytes.
+func (sampleAttr *SampleAttributes) MarshalBinary() ([]byte, error) {
+	var channels AudioChannelType
+	switch sampleAttr.NumberOfChannels {
+	case 1:
+		channels = AUDIO_CHANNELS_MONO
+	case 2:
+		channels = AUDIO_CHANNELS_STEREO
+	default:
+		return nil, fmt.Errorf("unsupported number of channels: %d", sampleAttr.NumberOfChannels)
+	}
+	if sampleAttr.SampleRate != 48000 || sampleAttr.BitsPerSample != 16 {
+		return nil, fmt.Errorf("unsupported sample format: %d Hz, %d bits", sampleAttr.SampleRate, sampleAttr.BitsPerSample)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(uint8(channels)<<4 | 1) // 48 kHz
+	buf.WriteByte(1 << 6)                 // 16 bits
+	binary.Write(buf, binary.BigEndian, sampleAttr.SoundDataIndex)
+	binary.Write(buf, binary.BigEndian, sampleAttr.NumberOfFrames*uint32(sampleAttr.BitsPerSample/8*sampleAttr.NumberOfChannels))
+	return buf.Bytes(), nil
+}
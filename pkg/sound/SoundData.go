@@ -1,6 +1,7 @@
 package sound
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -20,11 +21,12 @@ func ReadSoundData(file io.ReadSeeker, offsets *OffsetsUint32, soundMetaData *So
 
 	soundData.Data = make([]*Samples, soundMetaData.NumberOfSounds)
 	for i, attr := range soundMetaData.SampleAttrs {
-		fmt.Printf("attr[%d]: %+v\n", i, attr)
-		soundData.Data[i], err = ReadSoundDataBlock(file, offsets, attr)
+		if soundData.Data[i], err = ReadSoundDataBlock(file, offsets, attr); err != nil {
+			return nil, fmt.Errorf("failed to read sound %d: %w", i, err)
+		}
 	}
 
-	return soundData, err
+	return soundData, nil
 }
 
 // Reads ONE sound from the given sound attribute entry.
@@ -46,3 +48,12 @@ func ReadSoundDataBlock(file io.ReadSeeker, offsets *OffsetsUint32, attr *Sample
 
 	return data, err
 }
+
+// MarshalBinary encodes the samples of every sound, one after the other.
+func (soundData *SoundData) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, data := range soundData.Data {
+		binary.Write(buf, binary.BigEndian, *data)
+	}
+	return buf.Bytes(), nil
+}
@@ -1,9 +1,12 @@
 package sound
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/parasense/bdmv_go/internal/navfile"
 )
 
 type SoundMetaData struct {
@@ -44,3 +47,27 @@ func ReadSoundMetaData(file io.ReadSeeker, offsets *OffsetsUint32) (soundData *S
 
 	return soundData, err
 }
+
+// MarshalBinary encodes the SoundMetaData section. The length and the
+// number of sounds are computed from SampleAttrs.
+func (soundData *SoundMetaData) MarshalBinary() ([]byte, error) {
+	if len(soundData.SampleAttrs) > 0xFF {
+		return nil, fmt.Errorf("too many sounds: %d", len(soundData.SampleAttrs))
+	}
+
+	buf := &bytes.Buffer{}
+
+	// 1-byte reserve space
+	buf.WriteByte(0)
+
+	buf.WriteByte(uint8(len(soundData.SampleAttrs)))
+	for i, attr := range soundData.SampleAttrs {
+		data, err := attr.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SampleAttrs[%d]: %w", i, err)
+		}
+		buf.Write(data)
+	}
+
+	return navfile.WithLength[uint32](buf.Bytes())
+}
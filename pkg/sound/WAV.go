@@ -0,0 +1,147 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+	Remarks:
+
+	Menu sounds are 48 kHz, 16-bit LPCM, mono or stereo, stored big-endian
+	with the channels interleaved. A RIFF/WAV file holds the same samples
+	little-endian after a "fmt " chunk:
+
+		"RIFF" size "WAVE"
+		"fmt " 16 format=1 channels rate byte_rate block_align bits
+		"data" size samples...
+
+	Only that layout is read back: PCM, 16 bits, 48000 Hz, 1 or 2 channels.
+	Other chunks, e.g. "LIST", are skipped.
+*/
+
+// ErrUnsupportedWAV is returned for a WAV file that cannot be a menu sound.
+var ErrUnsupportedWAV = errors.New("unsupported WAV format")
+
+const wavFormatPCM = 1
+
+// wavFormat is the body of the "fmt " chunk.
+type wavFormat struct {
+	AudioFormat   uint16
+	NumChannels   uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// WriteWAV writes one sound as a RIFF/WAV file.
+func WriteWAV(w io.Writer, attr *SampleAttributes, samples *Samples) error {
+	dataLength := uint32(len(*samples) * 2)
+	format := wavFormat{
+		AudioFormat:   wavFormatPCM,
+		NumChannels:   uint16(attr.NumberOfChannels),
+		SampleRate:    attr.SampleRate,
+		ByteRate:      attr.SampleRate * uint32(attr.NumberOfChannels) * uint32(attr.BitsPerSample) / 8,
+		BlockAlign:    uint16(attr.NumberOfChannels) * uint16(attr.BitsPerSample) / 8,
+		BitsPerSample: uint16(attr.BitsPerSample),
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, 36+dataLength)
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(binary.Size(format)))
+	binary.Write(buf, binary.LittleEndian, format)
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, dataLength)
+	binary.Write(buf, binary.LittleEndian, *samples)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write WAV: %w", err)
+	}
+	return nil
+}
+
+// ReadWAV reads a RIFF/WAV file into the attributes and samples of one
+// sound. SoundDataIndex is left 0; WriteBCLK sets it.
+func ReadWAV(r io.Reader) (attr *SampleAttributes, samples *Samples, err error) {
+	var riff struct {
+		ID   [4]byte
+		Size uint32
+		Form [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &riff); err != nil {
+		return nil, nil, fmt.Errorf("failed to read RIFF header: %w", err)
+	}
+	if string(riff.ID[:]) != "RIFF" || string(riff.Form[:]) != "WAVE" {
+		return nil, nil, fmt.Errorf("%w: not a RIFF/WAVE file", ErrUnsupportedWAV)
+	}
+
+	var format *wavFormat
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, nil, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if chunk.Size < 16 {
+				return nil, nil, fmt.Errorf("%w: fmt chunk of %d bytes", ErrUnsupportedWAV, chunk.Size)
+			}
+			format = &wavFormat{}
+			if err := binary.Read(r, binary.LittleEndian, format); err != nil {
+				return nil, nil, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			if err := skip(r, int64(chunk.Size-16+chunk.Size%2)); err != nil {
+				return nil, nil, err
+			}
+			if format.AudioFormat != wavFormatPCM || format.BitsPerSample != 16 || format.SampleRate != 48000 ||
+				(format.NumChannels != 1 && format.NumChannels != 2) {
+				return nil, nil, fmt.Errorf("%w: format %d, %d bits, %d Hz, %d channels, want PCM, 16 bits, 48000 Hz, 1 or 2 channels",
+					ErrUnsupportedWAV, format.AudioFormat, format.BitsPerSample, format.SampleRate, format.NumChannels)
+			}
+
+		case "data":
+			if format == nil {
+				return nil, nil, fmt.Errorf("%w: data chunk before fmt chunk", ErrUnsupportedWAV)
+			}
+			if chunk.Size%uint32(format.BlockAlign) != 0 {
+				return nil, nil, fmt.Errorf("%w: data chunk of %d bytes is not whole frames", ErrUnsupportedWAV, chunk.Size)
+			}
+			samples = &Samples{}
+			*samples = make(Samples, chunk.Size/2)
+			if err := binary.Read(r, binary.LittleEndian, *samples); err != nil {
+				return nil, nil, fmt.Errorf("failed to read sample data: %w", err)
+			}
+			attr = &SampleAttributes{
+				NumberOfChannels: uint8(format.NumChannels),
+				SampleRate:       format.SampleRate,
+				BitsPerSample:    uint8(format.BitsPerSample),
+				NumberOfFrames:   chunk.Size / uint32(format.BlockAlign),
+			}
+			return attr, samples, nil
+
+		default:
+			if err := skip(r, int64(chunk.Size+chunk.Size%2)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// skip reads past n bytes of a chunk.
+func skip(r io.Reader, n int64) error {
+	if _, err := io.CopyN(io.Discard, r, n); err != nil {
+		return fmt.Errorf("failed to skip chunk: %w", err)
+	}
+	return nil
+}
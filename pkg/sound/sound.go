@@ -2,7 +2,11 @@ package sound
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+
+	"github.com/parasense/bdmv_go/internal/fsfile"
 )
 
 /*
@@ -24,6 +28,34 @@ func ParseBCLK(filePath string) (
 	}
 	defer file.Close()
 
+	return parseBCLK(file, filePath)
+}
+
+// ParseBCLKFS is ParseBCLK for the file name in fsys, such as a disc
+// image opened with package udf.
+func ParseBCLKFS(fsys fs.FS, name string) (
+	header *BCLKHeader,
+	soundMetaData *SoundMetaData,
+	soundData *SoundData,
+	//extensiondata *Extensions,
+	err error,
+) {
+	file, err := fsfile.Open(fsys, name)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseBCLK(file, name)
+}
+
+func parseBCLK(file io.ReadSeeker, filePath string) (
+	header *BCLKHeader,
+	soundMetaData *SoundMetaData,
+	soundData *SoundData,
+	//extensiondata *Extensions,
+	err error,
+) {
 	// Header
 	if header, err = ReadBCLKHeader(file); err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to read header: %w", err)
@@ -36,7 +68,9 @@ func ParseBCLK(filePath string) (
 
 	// Data
 	if soundMetaData.NumberOfSounds > 0 {
-		soundData, err = ReadSoundData(file, header.SoundObjects, soundMetaData)
+		if soundData, err = ReadSoundData(file, header.SoundObjects, soundMetaData); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read sound data: %w", err)
+		}
 	}
 
 	//// Extensions
@@ -48,3 +82,79 @@ func ParseBCLK(filePath string) (
 
 	return header, soundMetaData, soundData, nil
 }
+
+// WriteBCLK serializes the sounds to file in the BCLK layout. The
+// SoundMetaData length and number of sounds, and every sound's data index
+// and number of frames, are recomputed from soundData, and the header
+// offsets are updated to describe the written file. Extension data is not
+// written.
+func WriteBCLK(file io.Writer, header *BCLKHeader, soundMetaData *SoundMetaData, soundData *SoundData) error {
+	if len(soundMetaData.SampleAttrs) != len(soundData.Data) {
+		return fmt.Errorf("%d sample attributes for %d sounds", len(soundMetaData.SampleAttrs), len(soundData.Data))
+	}
+
+	var index uint32
+	for i, attr := range soundMetaData.SampleAttrs {
+		samples := len(*soundData.Data[i])
+		if attr.NumberOfChannels == 0 || samples%int(attr.NumberOfChannels) != 0 {
+			return fmt.Errorf("sound %d: %d samples are not whole frames of %d channels", i, samples, attr.NumberOfChannels)
+		}
+		attr.SoundDataIndex = index
+		attr.NumberOfFrames = uint32(samples / int(attr.NumberOfChannels))
+		index += uint32(samples * 2)
+	}
+	soundMetaData.NumberOfSounds = uint8(len(soundMetaData.SampleAttrs))
+
+	soundMetaDataBytes, err := soundMetaData.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal SoundMetaData: %w", err)
+	}
+	soundMetaData.Length = uint32(len(soundMetaDataBytes) - 4)
+
+	soundDataBytes, err := soundData.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal sound data: %w", err)
+	}
+
+	header.SoundMetaData = &OffsetsUint32{Start: 40, Stop: 40 + int64(len(soundMetaDataBytes))}
+	header.SoundObjects = &OffsetsUint32{Start: header.SoundMetaData.Stop, Stop: header.SoundMetaData.Stop + int64(len(soundDataBytes))}
+	header.Extensions = &OffsetsUint32{Start: 0, Stop: 0}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	for _, section := range [][]byte{headerBytes, soundMetaDataBytes, soundDataBytes} {
+		if _, err := file.Write(section); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return nil
+}
+
+// BuildBCLK reads WAV files, in order, into a sound.bdmv for WriteBCLK.
+// The first file is sound 0.
+func BuildBCLK(wavs ...io.Reader) (
+	header *BCLKHeader,
+	soundMetaData *SoundMetaData,
+	soundData *SoundData,
+	err error,
+) {
+	header = &BCLKHeader{
+		TypeIndicator: [4]byte{'B', 'C', 'L', 'K'},
+		VersionNumber: [4]byte{'0', '2', '0', '0'},
+	}
+	soundMetaData = &SoundMetaData{}
+	soundData = &SoundData{}
+	for i, wav := range wavs {
+		attr, samples, err := ReadWAV(wav)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("sound %d: %w", i, err)
+		}
+		soundMetaData.SampleAttrs = append(soundMetaData.SampleAttrs, attr)
+		soundData.Data = append(soundData.Data, samples)
+	}
+	soundMetaData.NumberOfSounds = uint8(len(soundMetaData.SampleAttrs))
+	return header, soundMetaData, soundData, nil
+}
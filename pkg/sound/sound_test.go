@@ -0,0 +1,97 @@
+package sound
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// wav writes a WAV file of the given samples, with a LIST chunk the
+// reader has to skip.
+func wav(t *testing.T, channels uint8, rate uint32, samples Samples) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	attr := &SampleAttributes{NumberOfChannels: channels, SampleRate: rate, BitsPerSample: 16}
+	if err := WriteWAV(buf, attr, &samples); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	list := []byte("LIST\x03\x00\x00\x00abc\x00")
+	return slices.Concat(data[:36], list, data[36:])
+}
+
+func TestBuildBCLK(t *testing.T) {
+	mono := Samples{1, 2, 0xFFFF}
+	stereo := Samples{10, 20, 30, 40}
+	header, soundMetaData, soundData, err := BuildBCLK(
+		bytes.NewReader(wav(t, 1, 48000, mono)),
+		bytes.NewReader(wav(t, 2, 48000, stereo)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "sound.bdmv")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteBCLK(file, header, soundMetaData, soundData); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	header, soundMetaData, soundData, err = ParseBCLK(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(header.TypeIndicator[:]) != "BCLK" || header.SoundObjects.Start != 40+4+2+2*10 {
+		t.Errorf("header = %s", header)
+	}
+	if soundMetaData.Length != 2+2*10 || soundMetaData.NumberOfSounds != 2 {
+		t.Errorf("Length, NumberOfSounds = %d, %d", soundMetaData.Length, soundMetaData.NumberOfSounds)
+	}
+	for i, want := range []struct {
+		channels uint8
+		index    uint32
+		frames   uint32
+		samples  Samples
+	}{
+		{1, 0, 3, mono},
+		{2, 6, 2, stereo},
+	} {
+		attr := soundMetaData.SampleAttrs[i]
+		if attr.NumberOfChannels != want.channels || attr.SoundDataIndex != want.index || attr.NumberOfFrames != want.frames {
+			t.Errorf("SampleAttrs[%d] = %+v", i, attr)
+		}
+		if !slices.Equal(*soundData.Data[i], want.samples) {
+			t.Errorf("Data[%d] = %v, want %v", i, *soundData.Data[i], want.samples)
+		}
+	}
+
+	// Back out to WAV, without the LIST chunk
+	out := &bytes.Buffer{}
+	if err := WriteWAV(out, soundMetaData.SampleAttrs[1], soundData.Data[1]); err != nil {
+		t.Fatal(err)
+	}
+	in := wav(t, 2, 48000, stereo)
+	if want := slices.Concat(in[:36], in[48:]); !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("WriteWAV() =\n% X\nwant\n% X", out.Bytes(), want)
+	}
+}
+
+func TestReadWAV(t *testing.T) {
+	if _, _, err := ReadWAV(bytes.NewReader(wav(t, 2, 44100, Samples{1, 2}))); !errors.Is(err, ErrUnsupportedWAV) {
+		t.Errorf("ReadWAV(44.1 kHz) error = %v, want ErrUnsupportedWAV", err)
+	}
+	if _, _, err := ReadWAV(bytes.NewReader(wav(t, 2, 48000, Samples{1, 2, 3}))); !errors.Is(err, ErrUnsupportedWAV) {
+		t.Errorf("ReadWAV(half a frame) error = %v, want ErrUnsupportedWAV", err)
+	}
+	if _, _, err := ReadWAV(bytes.NewReader([]byte("RIFF"))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadWAV(truncated) error = %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package udf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Tag identifiers of the descriptors this package reads.
+const (
+	TAG_PRIMARY_VOLUME        = 1
+	TAG_ANCHOR_VOLUME_POINTER = 2
+	TAG_PARTITION             = 5
+	TAG_LOGICAL_VOLUME        = 6
+	TAG_TERMINATING           = 8
+	TAG_FILE_SET              = 256
+	TAG_FILE_IDENTIFIER       = 257
+	TAG_ALLOCATION_EXTENT     = 258
+	TAG_FILE_ENTRY            = 261
+	TAG_EXTENDED_FILE_ENTRY   = 266
+)
+
+// METADATA_PARTITION_IDENTIFIER names a type 2 partition map of a
+// metadata partition.
+const METADATA_PARTITION_IDENTIFIER = "*UDF Metadata Partition"
+
+// ICB file types
+const (
+	FILE_TYPE_DIRECTORY = 4
+	FILE_TYPE_REGULAR   = 5
+	FILE_TYPE_SYMLINK   = 12
+	FILE_TYPE_METADATA  = 250
+	FILE_TYPE_MIRROR    = 251
+)
+
+// Allocation descriptor types in the low bits of the ICB flags.
+const (
+	AD_SHORT    = 0
+	AD_LONG     = 1
+	AD_EXTENDED = 2
+	AD_EMBEDDED = 3
+)
+
+// Extent types in the top two bits of an extent length.
+const (
+	EXTENT_RECORDED        = 0
+	EXTENT_NOT_RECORDED    = 1
+	EXTENT_NOT_ALLOCATED   = 2
+	EXTENT_NEXT_DESCRIPTOR = 3
+)
+
+// Tag is the 16 byte header every descriptor starts with.
+type Tag struct {
+	Identifier   uint16
+	Version      uint16
+	Checksum     uint8
+	SerialNumber uint16
+	CRC          uint16
+	CRCLength    uint16
+	Location     uint32
+}
+
+// ParseTag parses and verifies the tag at the start of data. location is
+// the block the descriptor was read from, which the tag must name.
+func ParseTag(data []byte, location uint32) (*Tag, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("descriptor tag is %d bytes long", len(data))
+	}
+	var sum uint8
+	for i, b := range data[:16] {
+		if i != 4 {
+			sum += b
+		}
+	}
+	tag := &Tag{
+		Identifier:   binary.LittleEndian.Uint16(data[0:]),
+		Version:      binary.LittleEndian.Uint16(data[2:]),
+		Checksum:     data[4],
+		SerialNumber: binary.LittleEndian.Uint16(data[6:]),
+		CRC:          binary.LittleEndian.Uint16(data[8:]),
+		CRCLength:    binary.LittleEndian.Uint16(data[10:]),
+		Location:     binary.LittleEndian.Uint32(data[12:]),
+	}
+	if sum != tag.Checksum {
+		return nil, fmt.Errorf("descriptor tag checksum 0x%02X, want 0x%02X", tag.Checksum, sum)
+	}
+	if tag.Location != location {
+		return nil, fmt.Errorf("descriptor %d tagged for block %d was read from block %d", tag.Identifier, tag.Location, location)
+	}
+	return tag, nil
+}
+
+// ExtentAD is an extent_ad: a run of sectors.
+type ExtentAD struct {
+	Length   uint32
+	Location uint32
+}
+
+func parseExtentAD(data []byte) ExtentAD {
+	return ExtentAD{binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint32(data[4:])}
+}
+
+// LBAddr is an lb_addr: a block of a partition.
+type LBAddr struct {
+	Block     uint32
+	Partition uint16
+}
+
+func parseLBAddr(data []byte) LBAddr {
+	return LBAddr{binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint16(data[4:])}
+}
+
+// AD is an allocation descriptor of any kind, brought to the long form.
+type AD struct {
+	Length   uint32 // Bytes, without the type bits
+	Type     uint8  // EXTENT_RECORDED, ...
+	Location LBAddr
+}
+
+func splitLength(length uint32) (uint32, uint8) {
+	return length & 0x3FFFFFFF, uint8(length >> 30)
+}
+
+func parseShortAD(data []byte, partition uint16) AD {
+	length, kind := splitLength(binary.LittleEndian.Uint32(data))
+	return AD{length, kind, LBAddr{binary.LittleEndian.Uint32(data[4:]), partition}}
+}
+
+func parseLongAD(data []byte) AD {
+	length, kind := splitLength(binary.LittleEndian.Uint32(data))
+	return AD{length, kind, parseLBAddr(data[4:])}
+}
+
+// parseExtendedAD reads an ext_ad: length, recorded length, information
+// length, then the extent location.
+func parseExtendedAD(data []byte) AD {
+	length, kind := splitLength(binary.LittleEndian.Uint32(data))
+	return AD{length, kind, parseLBAddr(data[12:])}
+}
+
+// ICBTag is the part of a File Entry that says what the file is.
+type ICBTag struct {
+	StrategyType uint16
+	FileType     uint8
+	Flags        uint16
+}
+
+// ADType returns the kind of allocation descriptors, AD_SHORT, ...
+func (icb ICBTag) ADType() uint8 {
+	return uint8(icb.Flags & 0x07)
+}
+
+// FileEntry is a File Entry or an Extended File Entry.
+type FileEntry struct {
+	Tag               *Tag
+	ICBTag            ICBTag
+	Permissions       uint32
+	InformationLength uint64
+	ModificationTime  time.Time
+	UniqueID          uint64
+	ADs               []AD   // For every AD type but AD_EMBEDDED
+	Data              []byte // AD_EMBEDDED
+	Partition         uint16 // Partition of the entry, for short_ads
+}
+
+// ParseFileEntry parses a File Entry or Extended File Entry block. The
+// allocation descriptors are read as they are; ones that continue in an
+// Allocation Extent Descriptor are left for the caller to follow.
+func ParseFileEntry(data []byte, location LBAddr) (*FileEntry, error) {
+	tag, err := ParseTag(data, location.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Where the fields differ between the two kinds.
+	var timeAt, idAt, lengthsAt int
+	switch tag.Identifier {
+	case TAG_FILE_ENTRY:
+		timeAt, idAt, lengthsAt = 84, 160, 168
+	case TAG_EXTENDED_FILE_ENTRY:
+		timeAt, idAt, lengthsAt = 92, 200, 208
+	default:
+		return nil, fmt.Errorf("descriptor %d is not a file entry", tag.Identifier)
+	}
+	if len(data) < lengthsAt+8 {
+		return nil, fmt.Errorf("file entry is %d bytes long", len(data))
+	}
+
+	entry := &FileEntry{
+		Tag: tag,
+		ICBTag: ICBTag{
+			StrategyType: binary.LittleEndian.Uint16(data[20:]),
+			FileType:     data[27],
+			Flags:        binary.LittleEndian.Uint16(data[34:]),
+		},
+		Permissions:       binary.LittleEndian.Uint32(data[44:]),
+		InformationLength: binary.LittleEndian.Uint64(data[56:]),
+		ModificationTime:  parseTimestamp(data[timeAt:]),
+		UniqueID:          binary.LittleEndian.Uint64(data[idAt:]),
+		Partition:         location.Partition,
+	}
+
+	extendedAttributes := int(binary.LittleEndian.Uint32(data[lengthsAt:]))
+	adLength := int(binary.LittleEndian.Uint32(data[lengthsAt+4:]))
+	start := lengthsAt + 8 + extendedAttributes
+	if start+adLength > len(data) || start+adLength < start {
+		return nil, fmt.Errorf("allocation descriptors overrun the file entry")
+	}
+	ads := data[start : start+adLength]
+
+	if entry.ICBTag.ADType() == AD_EMBEDDED {
+		entry.Data = ads
+		return entry, nil
+	}
+	if entry.ADs, err = parseADs(ads, entry.ICBTag.ADType(), location.Partition); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// parseADs reads a list of allocation descriptors. It stops at a zero
+// length one, which ends the list early.
+func parseADs(data []byte, adType uint8, partition uint16) (ads []AD, err error) {
+	var size int
+	var parse func([]byte) AD
+	switch adType {
+	case AD_SHORT:
+		size, parse = 8, func(data []byte) AD { return parseShortAD(data, partition) }
+	case AD_LONG:
+		size, parse = 16, parseLongAD
+	case AD_EXTENDED:
+		size, parse = 20, parseExtendedAD
+	default:
+		return nil, fmt.Errorf("%w: allocation descriptor type %d", ErrUnsupported, adType)
+	}
+	for len(data) >= size {
+		ad := parse(data[:size])
+		if ad.Length == 0 {
+			break
+		}
+		ads = append(ads, ad)
+		data = data[size:]
+	}
+	return ads, nil
+}
+
+// FileIdentifier is a File Identifier Descriptor: one directory entry.
+type FileIdentifier struct {
+	Characteristics uint8
+	ICB             AD
+	Name            string
+}
+
+// File characteristics
+const (
+	FID_HIDDEN    = 0x01
+	FID_DIRECTORY = 0x02
+	FID_DELETED   = 0x04
+	FID_PARENT    = 0x08
+)
+
+// ParseFileIdentifiers parses the contents of a directory.
+func ParseFileIdentifiers(data []byte) (fids []*FileIdentifier, err error) {
+	for len(data) >= 38 {
+		// The tag location is the block of the descriptor, which is not
+		// known once the directory is read as a stream; check the sum only.
+		tag, err := ParseTag(data, binary.LittleEndian.Uint32(data[12:]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file identifier: %w", err)
+		}
+		if tag.Identifier != TAG_FILE_IDENTIFIER {
+			return nil, fmt.Errorf("descriptor %d in a directory", tag.Identifier)
+		}
+		nameLength := int(data[19])
+		implementationUse := int(binary.LittleEndian.Uint16(data[36:]))
+		end := 38 + implementationUse + nameLength
+		if end > len(data) {
+			return nil, fmt.Errorf("file identifier overruns the directory")
+		}
+		fids = append(fids, &FileIdentifier{
+			Characteristics: data[18],
+			ICB:             parseLongAD(data[20:36]),
+			Name:            decodeDString(data[38+implementationUse : end]),
+		})
+		data = data[min((end+3)&^3, len(data)):]
+	}
+	return fids, nil
+}
+
+// decodeDString decodes OSTA compressed unicode: 8 (or 254) for one byte
+// per character, 16 (or 255) for UTF-16BE.
+func decodeDString(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	switch data[0] {
+	case 8, 254:
+		runes := make([]rune, len(data)-1)
+		for i, b := range data[1:] {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	case 16, 255:
+		units := make([]uint16, (len(data)-1)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(data[1+2*i:])
+		}
+		return string(utf16.Decode(units))
+	}
+	return ""
+}
+
+// decodeDStringField decodes a fixed size dstring, whose last byte holds
+// the length of the used part.
+func decodeDStringField(data []byte) string {
+	length := int(data[len(data)-1])
+	if length == 0 || length >= len(data) {
+		return ""
+	}
+	return strings.TrimRight(decodeDString(data[:length]), "\x00 ")
+}
+
+// parseTimestamp reads a 12 byte timestamp. The low 12 bits of the first
+// field are the offset from UTC in minutes when the type is 1.
+func parseTimestamp(data []byte) time.Time {
+	typeAndZone := binary.LittleEndian.Uint16(data)
+	year := int(int16(binary.LittleEndian.Uint16(data[2:])))
+	if year == 0 {
+		return time.Time{}
+	}
+	location := time.UTC
+	if typeAndZone>>12 == 1 {
+		offset := int16(typeAndZone<<4) >> 4
+		if offset != -2047 {
+			location = time.FixedZone("", int(offset)*60)
+		}
+	}
+	nanoseconds := (int(data[9])*10000 + int(data[10])*100 + int(data[11])) * 1000
+	return time.Date(year, time.Month(data[4]), int(data[5]), int(data[6]), int(data[7]), int(data[8]), nanoseconds, location)
+}
+
+// readSector reads count sectors starting at sector.
+func readSector(r io.ReaderAt, sector uint32, count int) ([]byte, error) {
+	data := make([]byte, count*SectorSize)
+	if _, err := r.ReadAt(data, int64(sector)*SectorSize); err != nil {
+		return nil, fmt.Errorf("failed to read sector %d: %w", sector, err)
+	}
+	return data, nil
+}
+
+// readAnchor reads the Anchor Volume Descriptor Pointer and returns the
+// main and reserve Volume Descriptor Sequences.
+func readAnchor(r io.ReaderAt) ([]ExtentAD, error) {
+	data, err := readSector(r, 256, 1)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := ParseTag(data, 256)
+	if err != nil {
+		return nil, fmt.Errorf("no anchor volume descriptor: %w", err)
+	}
+	if tag.Identifier != TAG_ANCHOR_VOLUME_POINTER {
+		return nil, fmt.Errorf("no anchor volume descriptor: descriptor %d at sector 256", tag.Identifier)
+	}
+	return []ExtentAD{parseExtentAD(data[16:]), parseExtentAD(data[24:])}, nil
+}
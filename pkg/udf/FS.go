@@ -0,0 +1,410 @@
+package udf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// FS is a UDF file system. It implements fs.FS, fs.ReadDirFS and
+// fs.StatFS; names are matched exactly, as UDF keeps their case.
+type FS struct {
+	VolumeIdentifier        string // From the Primary Volume Descriptor
+	LogicalVolumeIdentifier string // From the Logical Volume Descriptor
+
+	r          io.ReaderAt
+	partitions []*partition // By partition reference number
+
+	mu    sync.Mutex
+	nodes map[string]*node // By path, "." for the root
+}
+
+// partition maps the blocks of a partition reference to the image.
+type partition struct {
+	number   uint16    // Partition number of a physical partition
+	start    uint32    // First sector of a physical partition
+	metadata *fileData // The metadata file of a metadata partition
+}
+
+// node is a file whose File Entry has been read.
+type node struct {
+	name     string
+	entry    *FileEntry
+	data     *fileData
+	children []*FileIdentifier // Read when a directory is first listed
+}
+
+// New reads the UDF file system of r.
+func New(r io.ReaderAt) (*FS, error) {
+	sequences, err := readAnchor(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reserve sequence is a copy for when the main one is damaged.
+	fsys, rootICB, err := readVolume(r, sequences[0])
+	if err != nil {
+		var reserveErr error
+		if fsys, rootICB, reserveErr = readVolume(r, sequences[1]); reserveErr != nil {
+			return nil, err
+		}
+	}
+
+	root, err := fsys.readNode(".", rootICB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root directory: %w", err)
+	}
+	fsys.nodes = map[string]*node{".": root}
+	return fsys, nil
+}
+
+// readVolume reads a Volume Descriptor Sequence, sets up the partitions
+// and reads the File Set Descriptor. It returns the root directory ICB.
+func readVolume(r io.ReaderAt, sequence ExtentAD) (fsys *FS, rootICB AD, err error) {
+	fsys = &FS{r: r}
+	partitionStarts := map[uint16]uint32{}
+	var maps []byte
+	var mapCount int
+	var fileSet AD
+	var haveLogicalVolume bool
+
+	for i := range sequence.Length / SectorSize {
+		sector := sequence.Location + i
+		data, err := readSector(r, sector, 1)
+		if err != nil {
+			return nil, rootICB, err
+		}
+		tag, err := ParseTag(data, sector)
+		if err != nil {
+			return nil, rootICB, fmt.Errorf("failed to read volume descriptor: %w", err)
+		}
+
+		switch tag.Identifier {
+		case TAG_PRIMARY_VOLUME:
+			fsys.VolumeIdentifier = decodeDStringField(data[24:56])
+		case TAG_PARTITION:
+			partitionStarts[binary.LittleEndian.Uint16(data[22:])] = binary.LittleEndian.Uint32(data[188:])
+		case TAG_LOGICAL_VOLUME:
+			if blockSize := binary.LittleEndian.Uint32(data[212:]); blockSize != SectorSize {
+				return nil, rootICB, fmt.Errorf("%w: logical block size %d", ErrUnsupported, blockSize)
+			}
+			fsys.LogicalVolumeIdentifier = decodeDStringField(data[84:212])
+			fileSet = parseLongAD(data[248:264])
+			mapLength := binary.LittleEndian.Uint32(data[264:])
+			mapCount = int(binary.LittleEndian.Uint32(data[268:]))
+			if 440+int(mapLength) > len(data) {
+				return nil, rootICB, fmt.Errorf("partition maps overrun the logical volume descriptor")
+			}
+			maps = data[440 : 440+mapLength]
+			haveLogicalVolume = true
+		}
+		if tag.Identifier == TAG_TERMINATING {
+			break
+		}
+	}
+	if !haveLogicalVolume {
+		return nil, rootICB, errors.New("no logical volume descriptor")
+	}
+
+	if err := fsys.mapPartitions(maps, mapCount, partitionStarts); err != nil {
+		return nil, rootICB, err
+	}
+
+	// File Set Descriptor
+	data, err := fsys.readBlock(fileSet.Location)
+	if err != nil {
+		return nil, rootICB, fmt.Errorf("failed to read file set descriptor: %w", err)
+	}
+	tag, err := ParseTag(data, fileSet.Location.Block)
+	if err != nil {
+		return nil, rootICB, fmt.Errorf("failed to read file set descriptor: %w", err)
+	}
+	if tag.Identifier != TAG_FILE_SET {
+		return nil, rootICB, fmt.Errorf("descriptor %d where the file set descriptor should be", tag.Identifier)
+	}
+	return fsys, parseLongAD(data[400:416]), nil
+}
+
+// mapPartitions sets up the partition references of the partition maps.
+// Physical partitions come first, because a metadata partition's file
+// lives in one.
+func (fsys *FS) mapPartitions(maps []byte, count int, starts map[uint16]uint32) error {
+	fsys.partitions = make([]*partition, count)
+	type metadataMap struct {
+		reference, partitionNumber int
+		main, mirror               uint32
+	}
+	var metadataMaps []metadataMap
+
+	for reference := range count {
+		if len(maps) < 2 || int(maps[1]) > len(maps) || maps[1] < 6 {
+			return fmt.Errorf("partition map %d is truncated", reference)
+		}
+		entry := maps[:maps[1]]
+		maps = maps[maps[1]:]
+
+		switch entry[0] {
+		case 1:
+			number := binary.LittleEndian.Uint16(entry[4:])
+			start, ok := starts[number]
+			if !ok {
+				return fmt.Errorf("no partition descriptor for partition %d", number)
+			}
+			fsys.partitions[reference] = &partition{number: number, start: start}
+		case 2:
+			if len(entry) < 64 {
+				return fmt.Errorf("partition map %d is truncated", reference)
+			}
+			identifier := strings.TrimRight(string(entry[5:28]), "\x00")
+			if identifier != METADATA_PARTITION_IDENTIFIER {
+				return fmt.Errorf("%w: partition map %q", ErrUnsupported, identifier)
+			}
+			metadataMaps = append(metadataMaps, metadataMap{
+				reference:       reference,
+				partitionNumber: int(binary.LittleEndian.Uint16(entry[38:])),
+				main:            binary.LittleEndian.Uint32(entry[40:]),
+				mirror:          binary.LittleEndian.Uint32(entry[44:]),
+			})
+		default:
+			return fmt.Errorf("partition map %d has type %d", reference, entry[0])
+		}
+	}
+
+	for _, metadata := range metadataMaps {
+		// The metadata file is addressed in the physical partition of the
+		// same partition number.
+		physical := -1
+		for reference, p := range fsys.partitions {
+			if p != nil && p.metadata == nil && int(p.number) == metadata.partitionNumber {
+				physical = reference
+				break
+			}
+		}
+		if physical < 0 {
+			return fmt.Errorf("no physical partition %d for the metadata partition", metadata.partitionNumber)
+		}
+
+		var err error
+		for _, block := range []uint32{metadata.main, metadata.mirror} {
+			var file *node
+			if file, err = fsys.readNode("", AD{Location: LBAddr{block, uint16(physical)}}); err == nil {
+				fsys.partitions[metadata.reference] = &partition{metadata: file.data}
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read metadata file: %w", err)
+		}
+	}
+	return nil
+}
+
+// blockOffset returns the position of a logical block in the image.
+func (fsys *FS) blockOffset(address LBAddr) (int64, error) {
+	if int(address.Partition) >= len(fsys.partitions) || fsys.partitions[address.Partition] == nil {
+		return 0, fmt.Errorf("no partition reference %d", address.Partition)
+	}
+	p := fsys.partitions[address.Partition]
+	if p.metadata == nil {
+		return (int64(p.start) + int64(address.Block)) * SectorSize, nil
+	}
+	offset, ok := p.metadata.physical(int64(address.Block) * SectorSize)
+	if !ok {
+		return 0, fmt.Errorf("block %d is not recorded in the metadata file", address.Block)
+	}
+	return offset, nil
+}
+
+// readBlock reads one logical block.
+func (fsys *FS) readBlock(address LBAddr) ([]byte, error) {
+	offset, err := fsys.blockOffset(address)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, SectorSize)
+	if _, err := fsys.r.ReadAt(data, offset); err != nil {
+		return nil, fmt.Errorf("failed to read block %d: %w", address.Block, err)
+	}
+	return data, nil
+}
+
+// readNode reads the File Entry an ICB points at and maps its data.
+func (fsys *FS) readNode(name string, icb AD) (*node, error) {
+	data, err := fsys.readBlock(icb.Location)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := ParseFileEntry(data, icb.Location)
+	if err != nil {
+		return nil, err
+	}
+	fileData, err := fsys.mapData(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &node{name: name, entry: entry, data: fileData}, nil
+}
+
+// mapData turns the allocation descriptors of a File Entry into extents,
+// following Allocation Extent Descriptors.
+func (fsys *FS) mapData(entry *FileEntry) (*fileData, error) {
+	fileData := &fileData{r: fsys.r, size: int64(entry.InformationLength)}
+	if entry.ICBTag.ADType() == AD_EMBEDDED {
+		fileData.embedded = entry.Data
+		return fileData, nil
+	}
+
+	ads := entry.ADs
+	var position int64
+	for hops := 0; len(ads) > 0; hops++ {
+		ad := ads[0]
+		ads = ads[1:]
+
+		if ad.Type == EXTENT_NEXT_DESCRIPTOR {
+			if hops > 1024 {
+				return nil, errors.New("too many allocation extent descriptors")
+			}
+			data, err := fsys.readBlock(ad.Location)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read allocation extent descriptor: %w", err)
+			}
+			tag, err := ParseTag(data, ad.Location.Block)
+			if err != nil || tag.Identifier != TAG_ALLOCATION_EXTENT {
+				return nil, fmt.Errorf("no allocation extent descriptor at block %d", ad.Location.Block)
+			}
+			length := min(int(binary.LittleEndian.Uint32(data[20:])), len(data)-24)
+			if ads, err = parseADs(data[24:24+length], entry.ICBTag.ADType(), ad.Location.Partition); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		extent := extent{position: position, length: int64(ad.Length), physical: -1}
+		if ad.Type == EXTENT_RECORDED {
+			offset, err := fsys.blockOffset(ad.Location)
+			if err != nil {
+				return nil, err
+			}
+			extent.physical = offset
+		}
+		fileData.extents = append(fileData.extents, extent)
+		position += int64(ad.Length)
+	}
+	return fileData, nil
+}
+
+// lookup returns the node of a valid fs path, reading the directories on
+// the way.
+func (fsys *FS) lookup(name string) (*node, error) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	return fsys.walk(name)
+}
+
+// walk is lookup with fsys.mu held.
+func (fsys *FS) walk(name string) (*node, error) {
+	if n, ok := fsys.nodes[name]; ok {
+		return n, nil
+	}
+
+	parentName, base := ".", name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		parentName, base = name[:i], name[i+1:]
+	}
+	parent, err := fsys.walk(parentName)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.isDir() {
+		return nil, fs.ErrNotExist
+	}
+
+	children, err := fsys.children(parent)
+	if err != nil {
+		return nil, err
+	}
+	for _, fid := range children {
+		if fid.Name != base {
+			continue
+		}
+		n, err := fsys.readNode(base, fid.ICB)
+		if err != nil {
+			return nil, err
+		}
+		fsys.nodes[name] = n
+		return n, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+// children returns the entries of a directory, without the parent and
+// deleted ones. fsys.mu must be held.
+func (fsys *FS) children(dir *node) ([]*FileIdentifier, error) {
+	if dir.children != nil {
+		return dir.children, nil
+	}
+	data := make([]byte, dir.data.size)
+	if _, err := dir.data.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	fids, err := ParseFileIdentifiers(data)
+	if err != nil {
+		return nil, err
+	}
+	dir.children = []*FileIdentifier{}
+	for _, fid := range fids {
+		if fid.Characteristics&(FID_PARENT|FID_DELETED) == 0 {
+			dir.children = append(dir.children, fid)
+		}
+	}
+	return dir.children, nil
+}
+
+// Open opens the named file or directory.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	n, err := fsys.lookup(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if n.isDir() {
+		return &dir{fsys: fsys, name: name, node: n}, nil
+	}
+	return &file{node: n, SectionReader: io.NewSectionReader(n.data, 0, n.data.size)}, nil
+}
+
+// Stat returns a FileInfo for the named file.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	n, err := fsys.lookup(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return n.info(), nil
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.Unwrap(err)}
+	}
+	dir, ok := file.(*dir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(entries)
+	return entries, nil
+}
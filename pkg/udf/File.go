@@ -0,0 +1,194 @@
+package udf
+
+import (
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// extent is a run of a file's bytes. Unrecorded extents have no
+// physical position and read as zeros.
+type extent struct {
+	position int64 // In the file
+	length   int64
+	physical int64 // In the image, -1 when not recorded
+}
+
+// fileData reads a file's bytes from the image.
+type fileData struct {
+	r        io.ReaderAt
+	size     int64
+	embedded []byte
+	extents  []extent
+}
+
+// ReadAt reads the file's bytes at off.
+func (data *fileData) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= data.size {
+		return 0, io.EOF
+	}
+	if remaining := data.size - off; int64(len(p)) > remaining {
+		p, err = p[:remaining], io.EOF
+	}
+
+	if data.extents == nil {
+		n = copy(p, data.embedded[min(off, int64(len(data.embedded))):])
+		clear(p[n:])
+		return len(p), err
+	}
+
+	for n < len(p) {
+		position := off + int64(n)
+		i, found := slices.BinarySearchFunc(data.extents, position, func(e extent, position int64) int {
+			switch {
+			case e.position+e.length <= position:
+				return -1
+			case e.position > position:
+				return 1
+			}
+			return 0
+		})
+		if !found {
+			// Past the recorded extents: the rest is zeros.
+			clear(p[n:])
+			return len(p), err
+		}
+		e := data.extents[i]
+		chunk := p[n:min(len(p), n+int(e.position+e.length-position))]
+		if e.physical < 0 {
+			clear(chunk)
+		} else if _, readErr := data.r.ReadAt(chunk, e.physical+position-e.position); readErr != nil {
+			return n, readErr
+		}
+		n += len(chunk)
+	}
+	return n, err
+}
+
+// physical returns the position in the image of the file's byte at off.
+func (data *fileData) physical(off int64) (int64, bool) {
+	for _, e := range data.extents {
+		if off >= e.position && off < e.position+e.length && e.physical >= 0 {
+			return e.physical + off - e.position, true
+		}
+	}
+	return 0, false
+}
+
+func (n *node) isDir() bool {
+	return n.entry.ICBTag.FileType == FILE_TYPE_DIRECTORY
+}
+
+func (n *node) info() fs.FileInfo {
+	return &fileInfo{n}
+}
+
+// fileInfo describes a node.
+type fileInfo struct {
+	n *node
+}
+
+func (info *fileInfo) Name() string       { return info.n.name }
+func (info *fileInfo) Size() int64        { return info.n.data.size }
+func (info *fileInfo) ModTime() time.Time { return info.n.entry.ModificationTime }
+func (info *fileInfo) IsDir() bool        { return info.n.isDir() }
+func (info *fileInfo) Sys() any           { return info.n.entry }
+
+// Mode is read only: permissions are given to everybody who may read.
+func (info *fileInfo) Mode() fs.FileMode {
+	switch info.n.entry.ICBTag.FileType {
+	case FILE_TYPE_DIRECTORY:
+		return fs.ModeDir | 0o555
+	case FILE_TYPE_SYMLINK:
+		return fs.ModeSymlink | 0o444
+	}
+	return 0o444
+}
+
+// file is an open regular file. It implements io.ReadSeeker and
+// io.ReaderAt.
+type file struct {
+	node *node
+	*io.SectionReader
+}
+
+func (file *file) Stat() (fs.FileInfo, error) { return file.node.info(), nil }
+func (file *file) Close() error               { return nil }
+
+// dir is an open directory.
+type dir struct {
+	fsys   *FS
+	name   string
+	node   *node
+	offset int
+}
+
+func (dir *dir) Stat() (fs.FileInfo, error) { return dir.node.info(), nil }
+func (dir *dir) Close() error               { return nil }
+
+func (dir *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries, in directory order.
+func (dir *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	dir.fsys.mu.Lock()
+	children, err := dir.fsys.children(dir.node)
+	dir.fsys.mu.Unlock()
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: dir.name, Err: err}
+	}
+
+	rest := children[dir.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	dir.offset += len(rest)
+
+	entries := make([]fs.DirEntry, len(rest))
+	for i, fid := range rest {
+		entries[i] = &dirEntry{fsys: dir.fsys, dir: dir.name, fid: fid}
+	}
+	return entries, nil
+}
+
+// dirEntry is an entry of a directory. Its File Entry is only read when
+// Info is called.
+type dirEntry struct {
+	fsys *FS
+	dir  string
+	fid  *FileIdentifier
+}
+
+func (entry *dirEntry) Name() string { return entry.fid.Name }
+func (entry *dirEntry) IsDir() bool  { return entry.fid.Characteristics&FID_DIRECTORY != 0 }
+
+func (entry *dirEntry) Type() fs.FileMode {
+	if entry.IsDir() {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (entry *dirEntry) Info() (fs.FileInfo, error) {
+	name := entry.fid.Name
+	if entry.dir != "." {
+		name = entry.dir + "/" + name
+	}
+	return entry.fsys.Stat(name)
+}
+
+func (entry *dirEntry) String() string {
+	return fs.FormatDirEntry(entry)
+}
+
+func sortEntries(entries []fs.DirEntry) {
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+}
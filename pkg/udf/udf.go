@@ -0,0 +1,81 @@
+package udf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+/*
+	Remarks:
+
+	Blu-ray discs carry a UDF 2.50 or 2.60 file system (ECMA-167 with the
+	OSTA UDF profile). This package reads one from an image file so the
+	parsers can look at a disc without mounting it.
+
+	Everything is little-endian and counted in 2048 byte sectors:
+
+		sector 256                Anchor Volume Descriptor Pointer
+		Main Volume Descriptor Sequence:
+			Partition Descriptor      partition start and length
+			Logical Volume Descriptor partition maps, File Set Descriptor
+			Terminating Descriptor
+		File Set Descriptor       root directory ICB
+		File Entry / Extended File Entry  a file's allocation descriptors
+		File Identifier Descriptors       the entries of a directory
+
+	Logical blocks are addressed as (partition reference, block). A type 1
+	partition map is a plain physical partition. UDF 2.50 added the type 2
+	"*UDF Metadata Partition": its blocks are the blocks of the metadata
+	file, which lives in the physical partition and holds every File Entry
+	and directory. Blu-ray discs put all their metadata there. The
+	metadata mirror file is read when the main one is damaged.
+
+	A File Entry lists its data as short (same partition), long (any
+	partition) or extended allocation descriptors, or embeds the data in
+	itself. A descriptor of type 3 continues the list in an Allocation
+	Extent Descriptor. Extents that are allocated but not recorded read
+	as zeros.
+
+	Not supported: virtual (VAT) and sparable partitions, which BD-R and
+	BD-RE discs written incrementally use, and named streams.
+*/
+
+// SectorSize is the sector size of Blu-ray and DVD media.
+const SectorSize = 2048
+
+// ErrUnsupported reports a UDF feature this package does not read.
+var ErrUnsupported = errors.New("unsupported UDF feature")
+
+// Image is a file system read from an image file.
+type Image struct {
+	*FS
+	file *os.File
+}
+
+// Open reads the UDF file system of the image file at filePath.
+func Open(filePath string) (*Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	fsys, err := New(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return &Image{FS: fsys, file: file}, nil
+}
+
+// Close closes the image file.
+func (image *Image) Close() error {
+	return image.file.Close()
+}
+
+// IsImage reports whether r starts like a UDF volume: it has an Anchor
+// Volume Descriptor Pointer at sector 256.
+func IsImage(r io.ReaderAt) bool {
+	_, err := readAnchor(r)
+	return err == nil
+}
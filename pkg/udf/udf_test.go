@@ -0,0 +1,249 @@
+package udf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+)
+
+const partitionStart = 300
+
+// image builds a UDF volume one descriptor at a time.
+type image struct {
+	data []byte
+}
+
+func (img *image) sector(n uint32) []byte {
+	if end := int(n+1) * SectorSize; end > len(img.data) {
+		img.data = append(img.data, make([]byte, end-len(img.data))...)
+	}
+	return img.data[n*SectorSize : (n+1)*SectorSize]
+}
+
+// descriptor fills in the tag of the descriptor in b.
+func descriptor(b []byte, identifier uint16, location uint32) {
+	binary.LittleEndian.PutUint16(b[0:], identifier)
+	binary.LittleEndian.PutUint16(b[2:], 3)
+	binary.LittleEndian.PutUint32(b[12:], location)
+	var sum uint8
+	for i, v := range b[:16] {
+		if i != 4 {
+			sum += v
+		}
+	}
+	b[4] = sum
+}
+
+func longAD(length uint32, block uint32, partition uint16) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, length)
+	b = binary.LittleEndian.AppendUint32(b, block)
+	b = binary.LittleEndian.AppendUint16(b, partition)
+	return append(b, make([]byte, 6)...)
+}
+
+func shortAD(length uint32, block uint32) []byte {
+	return binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, length), block)
+}
+
+// fileEntry writes a File Entry, or an Extended File Entry, to b.
+func fileEntry(b []byte, location uint32, extended bool, fileType uint8, adType uint16, size uint64, ads []byte) {
+	identifier, lengthsAt := uint16(TAG_FILE_ENTRY), 168
+	if extended {
+		identifier, lengthsAt = TAG_EXTENDED_FILE_ENTRY, 208
+	}
+	b[27] = fileType
+	binary.LittleEndian.PutUint16(b[34:], adType)
+	binary.LittleEndian.PutUint64(b[56:], size)
+	binary.LittleEndian.PutUint32(b[lengthsAt+4:], uint32(len(ads)))
+	copy(b[lengthsAt+8:], ads)
+	descriptor(b, identifier, location)
+}
+
+// fid returns a File Identifier Descriptor.
+func fid(characteristics uint8, icb []byte, name string) []byte {
+	var encoded []byte
+	if name != "" {
+		encoded = append([]byte{8}, name...)
+		if bytes.ContainsFunc([]byte(name), func(r rune) bool { return r >= 0x80 }) {
+			encoded = []byte{16}
+			for _, unit := range utf16.Encode([]rune(name)) {
+				encoded = binary.BigEndian.AppendUint16(encoded, unit)
+			}
+		}
+	}
+	b := make([]byte, (38+len(encoded)+3)&^3)
+	b[18], b[19] = characteristics, uint8(len(encoded))
+	copy(b[20:], icb)
+	copy(b[38:], encoded)
+	descriptor(b, TAG_FILE_IDENTIFIER, 0)
+	return b
+}
+
+// testImage builds a volume with
+//
+//	BDMV/index.bdmv           embedded in its File Entry
+//	BDMV/STREAM/00001.m2ts    two recorded extents around an unrecorded one
+//	Ünïcode                   an empty file with a 16 bit name
+//
+// With metadata, every File Entry and directory is in a metadata
+// partition, as on a Blu-ray disc.
+func testImage(metadata bool) (data, stream []byte) {
+	// Sized up front, so the sectors handed out are not moved by growth.
+	img := &image{}
+	img.sector(partitionStart + 300)
+
+	// Anchor and Volume Descriptor Sequence
+	avdp := img.sector(256)
+	binary.LittleEndian.PutUint32(avdp[16:], 4*SectorSize)
+	binary.LittleEndian.PutUint32(avdp[20:], 32)
+	descriptor(avdp, TAG_ANCHOR_VOLUME_POINTER, 256)
+
+	pvd := img.sector(32)
+	copy(pvd[24:], "\x08MOVIE")
+	pvd[55] = 6
+	descriptor(pvd, TAG_PRIMARY_VOLUME, 32)
+
+	pd := img.sector(33)
+	binary.LittleEndian.PutUint16(pd[22:], 7)
+	binary.LittleEndian.PutUint32(pd[188:], partitionStart)
+	binary.LittleEndian.PutUint32(pd[192:], 1000)
+	descriptor(pd, TAG_PARTITION, 33)
+
+	// Logical blocks: the partition's, or the metadata file's.
+	partition, block := uint16(0), func(n uint32) []byte { return img.sector(partitionStart + n) }
+	lvd := img.sector(34)
+	binary.LittleEndian.PutUint32(lvd[212:], SectorSize)
+	maps, mapCount := []byte{1, 6, 1, 0, 7, 0}, uint32(1)
+	if metadata {
+		partition, mapCount = 1, 2
+		block = func(n uint32) []byte { return img.sector(partitionStart + 10 + n) }
+		metadataMap := make([]byte, 64)
+		metadataMap[0], metadataMap[1] = 2, 64
+		copy(metadataMap[5:], METADATA_PARTITION_IDENTIFIER)
+		binary.LittleEndian.PutUint16(metadataMap[38:], 7)
+		binary.LittleEndian.PutUint32(metadataMap[40:], 2) // Damaged main file
+		binary.LittleEndian.PutUint32(metadataMap[44:], 1) // Mirror
+		binary.LittleEndian.PutUint32(metadataMap[48:], ^uint32(0))
+		maps = append(maps, metadataMap...)
+
+		// The mirror metadata file: blocks 10-29 of the partition.
+		fileEntry(img.sector(partitionStart+1), 1, true, FILE_TYPE_MIRROR, AD_SHORT, 20*SectorSize, shortAD(20*SectorSize, 10))
+		img.sector(partitionStart + 2)
+	}
+	copy(lvd[248:], longAD(SectorSize, 0, partition))
+	binary.LittleEndian.PutUint32(lvd[264:], uint32(len(maps)))
+	binary.LittleEndian.PutUint32(lvd[268:], mapCount)
+	copy(lvd[440:], maps)
+	descriptor(lvd, TAG_LOGICAL_VOLUME, 34)
+	descriptor(img.sector(35), TAG_TERMINATING, 35)
+
+	// File Set Descriptor, block 0
+	fsd := block(0)
+	copy(fsd[400:], longAD(SectorSize, 1, partition))
+	descriptor(fsd, TAG_FILE_SET, 0)
+
+	// Root directory, entry at block 1, identifiers at block 2
+	root := concat(
+		fid(FID_DIRECTORY|FID_PARENT, longAD(SectorSize, 1, partition), ""),
+		fid(FID_DIRECTORY, longAD(SectorSize, 3, partition), "BDMV"),
+		fid(FID_DELETED, longAD(SectorSize, 9, partition), "gone"),
+		fid(0, longAD(SectorSize, 8, partition), "Ünïcode"),
+	)
+	copy(block(2), root)
+	fileEntry(block(1), 1, false, FILE_TYPE_DIRECTORY, AD_LONG, uint64(len(root)), longAD(uint32(len(root)), 2, partition))
+
+	// BDMV, embedded identifiers
+	bdmv := concat(
+		fid(FID_DIRECTORY|FID_PARENT, longAD(SectorSize, 1, partition), ""),
+		fid(0, longAD(SectorSize, 4, partition), "index.bdmv"),
+		fid(FID_DIRECTORY, longAD(SectorSize, 5, partition), "STREAM"),
+	)
+	fileEntry(block(3), 3, true, FILE_TYPE_DIRECTORY, AD_EMBEDDED, uint64(len(bdmv)), bdmv)
+	fileEntry(block(4), 4, false, FILE_TYPE_REGULAR, AD_EMBEDDED, 8, []byte("INDX0200"))
+
+	// STREAM, with the stream's data in the physical partition
+	streamDir := concat(
+		fid(FID_DIRECTORY|FID_PARENT, longAD(SectorSize, 3, partition), ""),
+		fid(0, longAD(SectorSize, 7, partition), "00001.m2ts"),
+	)
+	fileEntry(block(5), 5, true, FILE_TYPE_DIRECTORY, AD_EMBEDDED, uint64(len(streamDir)), streamDir)
+
+	stream = make([]byte, 3*SectorSize+100)
+	for i := range stream {
+		stream[i] = byte(i % 251)
+	}
+	copy(img.sector(partitionStart+100), stream[:SectorSize])
+	clear(stream[SectorSize : 2*SectorSize])
+	copy(img.sector(partitionStart+200), stream[2*SectorSize:3*SectorSize])
+	copy(img.sector(partitionStart+201), stream[3*SectorSize:])
+	ads := concat(
+		longAD(SectorSize, 100, 0),
+		longAD(EXTENT_NOT_RECORDED<<30|SectorSize, 0, 0),
+		longAD(SectorSize+100, 200, 0),
+	)
+	fileEntry(block(7), 7, false, FILE_TYPE_REGULAR, AD_LONG, uint64(len(stream)), ads)
+
+	fileEntry(block(8), 8, true, FILE_TYPE_REGULAR, AD_SHORT, 0, nil)
+	return img.data, stream
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestFS(t *testing.T) {
+	for _, metadata := range []bool{false, true} {
+		data, stream := testImage(metadata)
+		fsys, err := New(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("New(metadata %t) error = %v", metadata, err)
+		}
+		if fsys.VolumeIdentifier != "MOVIE" {
+			t.Errorf("VolumeIdentifier = %q", fsys.VolumeIdentifier)
+		}
+
+		if err := fstest.TestFS(fsys, "BDMV/index.bdmv", "BDMV/STREAM/00001.m2ts", "Ünïcode"); err != nil {
+			t.Errorf("metadata %t: %v", metadata, err)
+		}
+
+		if got, err := fs.ReadFile(fsys, "BDMV/STREAM/00001.m2ts"); err != nil || !bytes.Equal(got, stream) {
+			t.Errorf("ReadFile(00001.m2ts) = %d bytes, %v", len(got), err)
+		}
+		if got, err := fs.ReadFile(fsys, "BDMV/index.bdmv"); err != nil || string(got) != "INDX0200" {
+			t.Errorf("ReadFile(index.bdmv) = %q, %v", got, err)
+		}
+
+		// Files seek and read at offsets across extents.
+		file, _ := fsys.Open("BDMV/STREAM/00001.m2ts")
+		seeker := file.(io.ReadSeeker)
+		if _, err := seeker.Seek(SectorSize-10, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		chunk := make([]byte, SectorSize+20)
+		if _, err := io.ReadFull(seeker, chunk); err != nil || !bytes.Equal(chunk, stream[SectorSize-10:2*SectorSize+10]) {
+			t.Errorf("read across extents = %v", err)
+		}
+
+		if _, err := fsys.Stat("BDMV/gone"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(gone) error = %v", err)
+		}
+		if _, err := fsys.Stat("bdmv"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(bdmv) error = %v, names are case sensitive", err)
+		}
+	}
+}
+
+func TestNotUDF(t *testing.T) {
+	data := make([]byte, 300*SectorSize)
+	if IsImage(bytes.NewReader(data)) {
+		t.Error("IsImage(zeros) = true")
+	}
+	if _, err := New(bytes.NewReader(data[:100])); err == nil {
+		t.Error("New(short) accepted a truncated image")
+	}
+}